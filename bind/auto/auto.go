@@ -0,0 +1,156 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package auto implements a client which queries a BIND statistics channel
+// in whichever format it supports.
+package auto
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/internal/httpclient"
+	"github.com/prometheus-community/bind_exporter/bind/json"
+	"github.com/prometheus-community/bind_exporter/bind/xml"
+)
+
+// ProbeInterval is the minimum time between two attempts of the alternate
+// format, which protects servers from a client flapping between formats.
+const ProbeInterval = time.Minute
+
+type format struct {
+	name   bind.Format
+	client bind.Client
+}
+
+// Client implements bind.Client. It queries the JSON v1 API and falls back to
+// the XML v3 API if the preferred format is not supported by the server, in
+// which case the XML format becomes the preferred one. Falling back works in
+// both directions, so a client follows a server being reconfigured. A Client
+// is safe for concurrent use by multiple goroutines.
+type Client struct {
+	formats [2]format
+	logger  log.Logger
+	now     func() time.Time
+
+	mu        sync.Mutex
+	preferred int
+	lastProbe time.Time
+}
+
+// New returns a Client querying the statistics channel at url.
+func New(url string, opts ...bind.ClientOption) (*Client, error) {
+	j, err := json.New(url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x, err := xml.New(url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		formats: [2]format{
+			{name: bind.FormatJSONv1, client: j},
+			{name: bind.FormatXMLv3, client: x},
+		},
+		logger: loggerOf(opts),
+		now:    bind.NewClientOptions(opts...).Clock.Now,
+	}, nil
+}
+
+// NewClient returns an initialized Client. If c is nil, an http.Client based
+// on bind.DefaultHTTPClient is used. Contrary to New, invalid options are
+// reported by Stats.
+func NewClient(url string, c *http.Client, opts ...bind.ClientOption) *Client {
+	return &Client{
+		formats: [2]format{
+			{name: bind.FormatJSONv1, client: json.NewClient(url, c, opts...)},
+			{name: bind.FormatXMLv3, client: xml.NewClient(url, c, opts...)},
+		},
+		logger: loggerOf(opts),
+		now:    bind.NewClientOptions(opts...).Clock.Now,
+	}
+}
+
+// Format returns the format currently preferred by the client.
+func (c *Client) Format() bind.Format {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.formats[c.preferred].name
+}
+
+// Stats implements bind.Client. If the server does not support the preferred
+// format, i.e. answers with 404 Not Found or an HTML page, see
+// bind.StatusPageError, the alternate format is attempted once, unless it has
+// already been attempted within ProbeInterval.
+func (c *Client) Stats(ctx context.Context, groups ...bind.StatisticGroup) (bind.Statistics, error) {
+	c.mu.Lock()
+	preferred := c.preferred
+	c.mu.Unlock()
+
+	s, err := c.formats[preferred].client.Stats(ctx, groups...)
+	if err == nil || !(httpclient.IsNotFound(err) || httpclient.IsStatusPage(err)) {
+		return s, err
+	}
+
+	c.mu.Lock()
+	now := c.now()
+	if c.preferred != preferred || now.Sub(c.lastProbe) < ProbeInterval {
+		c.mu.Unlock()
+		return s, err
+	}
+	c.lastProbe = now
+	c.mu.Unlock()
+
+	alternate := 1 - preferred
+	as, aerr := c.formats[alternate].client.Stats(ctx, groups...)
+	if aerr != nil {
+		level.Debug(c.logger).Log("msg", "Alternate statistics format failed", "format", c.formats[alternate].name, "err", aerr)
+		return s, mergeStatusPages(err, aerr)
+	}
+
+	c.mu.Lock()
+	c.preferred = alternate
+	c.mu.Unlock()
+	level.Info(c.logger).Log("msg", "Switched statistics format", "from", c.formats[preferred].name, "to", c.formats[alternate].name, "err", err)
+	return as, nil
+}
+
+// mergeStatusPages returns err, or a bind.StatusPageError listing the paths
+// of both formats if both err and aerr report HTML pages.
+func mergeStatusPages(err, aerr error) error {
+	var perr, aperr *bind.StatusPageError
+	if !errors.As(err, &perr) || !errors.As(aerr, &aperr) {
+		return err
+	}
+	merged := &bind.StatusPageError{URL: perr.URL, Paths: map[bind.Format][]string{}}
+	for _, e := range []*bind.StatusPageError{perr, aperr} {
+		for f, p := range e.Paths {
+			merged.Paths[f] = p
+		}
+	}
+	return merged
+}
+
+func loggerOf(opts []bind.ClientOption) log.Logger {
+	if l := bind.NewClientOptions(opts...).Logger; l != nil {
+		return l
+	}
+	return log.NewNopLogger()
+}
@@ -0,0 +1,160 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auto
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus-community/bind_exporter/bind"
+)
+
+// formatServer serves the fixtures of the format set by its field.
+type formatServer struct {
+	mu     sync.Mutex
+	format string
+}
+
+func (s *formatServer) set(format string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.format = format
+}
+
+func (s *formatServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	format := s.format
+	s.mu.Unlock()
+	f := map[string]map[string]string{
+		"json": {
+			"/json/v1/server": "../../fixtures/json/server.json",
+			"/json/v1/zones":  "../../fixtures/json/zones.json",
+		},
+		"xml": {
+			"/xml/v3/server": "../../fixtures/xml/server.xml",
+			"/xml/v3/zones":  "../../fixtures/xml/zones.xml",
+		},
+	}[format][r.URL.Path]
+	if f == "" {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeFile(w, r, f)
+}
+
+func TestFallback(t *testing.T) {
+	srv := &formatServer{format: "json"}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	var logged []string
+	logger := log.LoggerFunc(func(keyvals ...interface{}) error {
+		for i := 0; i+1 < len(keyvals); i += 2 {
+			if keyvals[i] == "msg" {
+				logged = append(logged, keyvals[i+1].(string))
+			}
+		}
+		return nil
+	})
+	now := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	c := NewClient(ts.URL, nil, bind.WithLogger(logger))
+	c.now = func() time.Time { return now }
+
+	stats := func() (bind.Statistics, error) {
+		return c.Stats(context.Background(), bind.ServerStats)
+	}
+
+	s, err := stats()
+	if err != nil || s.Source.Format != bind.FormatJSONv1 {
+		t.Fatalf("want JSON statistics, got %s, %v", s.Source.Format, err)
+	}
+
+	// The server drops JSON support.
+	srv.set("xml")
+	s, err = stats()
+	if err != nil || s.Source.Format != bind.FormatXMLv3 {
+		t.Fatalf("want fallback to XML statistics, got %s, %v", s.Source.Format, err)
+	}
+	if c.Format() != bind.FormatXMLv3 {
+		t.Errorf("want XML to be preferred after fallback, got %s", c.Format())
+	}
+	if len(logged) != 1 || !strings.Contains(logged[0], "Switched") {
+		t.Errorf("want logged format switch, got %q", logged)
+	}
+
+	// Switching back within the probe interval is refused.
+	srv.set("json")
+	now = now.Add(ProbeInterval / 2)
+	if _, err := stats(); err == nil {
+		t.Fatalf("want error while probing is suppressed")
+	}
+
+	now = now.Add(ProbeInterval)
+	s, err = stats()
+	if err != nil || s.Source.Format != bind.FormatJSONv1 {
+		t.Fatalf("want fallback to JSON statistics, got %s, %v", s.Source.Format, err)
+	}
+
+	// Errors other than missing documents do not cause a fallback.
+	srv.set("none")
+	now = now.Add(2 * ProbeInterval)
+	ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	})
+	if _, err := stats(); err == nil || c.Format() != bind.FormatJSONv1 {
+		t.Errorf("want error without fallback, got %v with format %s", err, c.Format())
+	}
+}
+
+func TestStatusPageFallback(t *testing.T) {
+	// The server serves its status page for the JSON paths, which it does
+	// not know, and statistics at the XML paths.
+	srv := &formatServer{format: "xml"}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/json/") {
+			http.ServeFile(w, r, "../../fixtures/xml/status-page.xhtml")
+			return
+		}
+		srv.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, nil)
+	s, err := c.Stats(context.Background(), bind.ServerStats)
+	if err != nil || s.Source.Format != bind.FormatXMLv3 {
+		t.Fatalf("want fallback to XML statistics, got %s, %v", s.Source.Format, err)
+	}
+
+	// If both formats are answered with the status page, the error lists
+	// the paths of both.
+	ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "../../fixtures/xml/status-page.xhtml")
+	})
+	c = NewClient(ts.URL, nil)
+	_, err = c.Stats(context.Background(), bind.ServerStats)
+	var perr *bind.StatusPageError
+	if !errors.As(err, &perr) || len(perr.Paths) != 2 {
+		t.Fatalf("want status page error of both formats, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "json/v1 at /json/v1/server") || !strings.Contains(err.Error(), "xml/v3 at /xml/v3/server") {
+		t.Errorf("want paths of both formats, got %q", err)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auto
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/json"
+	"github.com/prometheus-community/bind_exporter/bind/xml"
+)
+
+// Report is the result of Diagnose.
+type Report struct {
+	URL     string
+	Results []Result
+}
+
+// Result is the outcome of querying the status document in one format.
+type Result struct {
+	Format bind.Format
+	// Err is nil if the status document has been fetched and decoded.
+	Err error
+	// BINDVersion is the version reported by the server, if any.
+	BINDVersion string
+}
+
+// OK reports whether the status document could be fetched in any format.
+func (r Report) OK() bool {
+	for _, res := range r.Results {
+		if res.Err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns a human readable summary of r.
+func (r Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "statistics channel %s:\n", r.URL)
+	for _, res := range r.Results {
+		if res.Err != nil {
+			fmt.Fprintf(&b, "  %s: %s\n", res.Format, res.Err)
+		} else {
+			fmt.Fprintf(&b, "  %s: ok, BIND %s\n", res.Format, res.BINDVersion)
+		}
+	}
+	return b.String()
+}
+
+// Diagnose queries the status document of the statistics channel at url in
+// every supported format and reports the outcome, which helps telling an
+// unreachable server from a server lacking support for a format. The status
+// document is the cheapest of the documents, see bind.StatusStats, so that
+// Diagnose does not load servers with many zones.
+func Diagnose(ctx context.Context, url string, opts ...bind.ClientOption) Report {
+	r := Report{URL: url}
+	clients := []struct {
+		format bind.Format
+		client bind.Client
+	}{
+		{format: bind.FormatJSONv1, client: json.NewClient(url, nil, opts...)},
+		{format: bind.FormatXMLv3, client: xml.NewClient(url, nil, opts...)},
+	}
+	for _, c := range clients {
+		s, err := c.client.Stats(ctx, bind.StatusStats)
+		r.Results = append(r.Results, Result{Format: c.format, Err: err, BINDVersion: s.Source.BINDVersion})
+	}
+	return r
+}
@@ -0,0 +1,66 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auto
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/bindtest"
+	"github.com/prometheus-community/bind_exporter/bind/json"
+	"github.com/prometheus-community/bind_exporter/bind/xml"
+)
+
+func TestDiagnose(t *testing.T) {
+	handler := bindtest.Handler()
+	var (
+		mu    sync.Mutex
+		paths []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		if strings.HasPrefix(r.URL.Path, "/json/") {
+			http.NotFound(w, r)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	r := Diagnose(context.Background(), srv.URL)
+	if !r.OK() {
+		t.Errorf("want ok report, got %s", r)
+	}
+	if len(r.Results) != 2 {
+		t.Fatalf("want results of 2 formats, got %s", r)
+	}
+	if res := r.Results[0]; res.Format != bind.FormatJSONv1 || res.Err == nil {
+		t.Errorf("want error of %s, got %+v", bind.FormatJSONv1, res)
+	}
+	if res := r.Results[1]; res.Format != bind.FormatXMLv3 || res.Err != nil || res.BINDVersion != "9.11.31" {
+		t.Errorf("want BIND 9.11.31 in %s, got %+v", bind.FormatXMLv3, res)
+	}
+	// Only the status documents are fetched.
+	if want := []string{json.StatusPath, xml.StatusPath}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("want requests of %v, got %v", want, paths)
+	}
+}
@@ -14,13 +14,17 @@
 package bind
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"time"
 )
 
 // Client queries the BIND API, parses the response and returns stats in a
-// generic format.
+// generic format. The clients of this module are safe for concurrent use by
+// multiple goroutines, so a single client may be shared.
 type Client interface {
-	Stats(...StatisticGroup) (Statistics, error)
+	Stats(context.Context, ...StatisticGroup) (Statistics, error)
 }
 
 const (
@@ -36,39 +40,232 @@ const (
 	ServerStats StatisticGroup = "server"
 	ViewStats   StatisticGroup = "view"
 	TaskStats   StatisticGroup = "tasks"
+	// StatusStats is the status document of the server, which reports only
+	// the boot, reconfiguration and current time and the version. It is
+	// much cheaper to produce than the other documents, e.g. for frequent
+	// liveness checks. The statistics of the other groups include it.
+	StatusStats StatisticGroup = "status"
+)
+
+// ParseStatisticGroup returns the StatisticGroup named s.
+func ParseStatisticGroup(s string) (StatisticGroup, error) {
+	switch g := StatisticGroup(s); g {
+	case ServerStats, ViewStats, TaskStats, StatusStats:
+		return g, nil
+	}
+	return "", fmt.Errorf("unknown stats group %q", s)
+}
+
+// ParseStatisticGroups parses a comma separated list of statistic groups, e.g.
+// "server,view". Duplicated groups are rejected.
+func ParseStatisticGroups(s string) ([]StatisticGroup, error) {
+	groups := []StatisticGroup{}
+	if len(s) == 0 {
+		return groups, nil
+	}
+	for _, name := range strings.Split(s, ",") {
+		g, err := ParseStatisticGroup(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, existing := range groups {
+			if existing == g {
+				return nil, fmt.Errorf("duplicated stats group %q", g)
+			}
+		}
+		groups = append(groups, g)
+	}
+	return groups, nil
+}
+
+// Format identifies a statistics format of BIND.
+type Format string
+
+// Supported statistics formats.
+const (
+	FormatXMLv3  Format = "xml/v3"
+	FormatJSONv1 Format = "json/v1"
+	// FormatXMLv2 is the format of the single document of BIND 9.9 and
+	// earlier, which the XML client falls back to.
+	FormatXMLv2 Format = "xml/v2"
+)
+
+// Source describes the origin of Statistics.
+type Source struct {
+	// Format is the format of the decoded documents.
+	Format Format
+	// SchemaVersion is the version of the statistics schema reported by the
+	// documents, e.g. "3.11" for XML or "1.7" for JSON.
+	SchemaVersion string
+	// BINDVersion is the version of the server. It is empty if the server
+	// document has not been fetched.
+	BINDVersion string
+	// FetchTime is the local time the first document was received.
+	FetchTime time.Time
+	// ZonesStrategy tells how the zones of the views selected by WithViews
+	// have been fetched. It is empty if the zones of all views have been
+	// fetched.
+	ZonesStrategy ZonesStrategy
+}
+
+// ZonesStrategy is the way the zones of selected views are fetched, see
+// WithViews.
+type ZonesStrategy string
+
+const (
+	// ZonesPerView means that the zones document of every view has been
+	// fetched.
+	ZonesPerView ZonesStrategy = "per-view"
+	// ZonesFiltered means that the zones document of all views has been
+	// fetched and the zones of other views have been dropped.
+	ZonesFiltered ZonesStrategy = "filtered"
 )
 
 // Statistics is a generic representation of BIND statistics.
+//
+// The lists of Statistics are in a canonical order, so that the statistics
+// decoded from equal documents are equal, including the order in which they
+// are iterated: the XML client keeps the order of the documents, and the JSON
+// client, whose objects have no order, sorts views, counters and gauges by
+// name. Zones are in the order of the documents in both formats. Extra,
+// QueriesByClass and Extensions are maps and have no order; Flatten and
+// ContentHash do not depend on it.
 type Statistics struct {
+	// Source describes the client and server which produced the statistics.
+	Source      Source
 	Server      Server
 	Views       []View
 	ZoneViews   []ZoneView
 	TaskManager TaskManager
+	// Memory holds the memory statistics of named, which the clients
+	// decode from the server document along with ServerStats. It is empty
+	// if the document does not report them.
+	Memory Memory
+	// ClockSkew is the estimated offset of the server clock relative to the
+	// local clock, positive if the server clock is ahead. It is zero if the
+	// server did not report its current time.
+	ClockSkew time.Duration
+	// MissingGroups lists the requested groups whose documents do not exist
+	// on the server version, see GroupOptional. Their statistics are empty.
+	MissingGroups []StatisticGroup
+	// Warnings lists likely misconfigurations of the server, see Validate,
+	// and anomalies of the documents, e.g. WarnBlankCounter.
+	Warnings []Warning
+	// OmittedWarnings is the number of warnings not listed in Warnings
+	// because of the limit of WithMaxWarnings.
+	OmittedWarnings int
+	// Decode describes the documents decoded by the clients, summed by the
+	// group they have been fetched for. The XML and JSON clients fetch the
+	// views along with the server document, so they are counted under
+	// ServerStats.
+	Decode map[StatisticGroup]DecodeInfo
+
+	// zones is the index of Zone.
+	zones *zoneIndex
+	// indexed is the cache of Indexed.
+	indexed *indexedCache
+	// Extensions holds the results of the section decoders registered with
+	// WithSectionDecoder and WithJSONSectionDecoder, keyed by the type or
+	// key of the section. It is nil if no section has been decoded.
+	Extensions map[string]any
+}
+
+// AddExtensions adds the decoded sections m to the Extensions of s, replacing
+// sections of the same type.
+func (s *Statistics) AddExtensions(m map[string]any) {
+	for k, v := range m {
+		if s.Extensions == nil {
+			s.Extensions = map[string]any{}
+		}
+		s.Extensions[k] = v
+	}
+}
+
+// SkewExceeds reports whether the absolute clock skew exceeds threshold.
+func (s Statistics) SkewExceeds(threshold time.Duration) bool {
+	if s.ClockSkew < 0 {
+		return -s.ClockSkew > threshold
+	}
+	return s.ClockSkew > threshold
 }
 
 // Server represents BIND server statistics.
 type Server struct {
 	BootTime         time.Time
 	ConfigTime       time.Time
+	CurrentTime      time.Time
 	IncomingQueries  []Counter
 	IncomingRequests []Counter
 	NameServerStats  []Counter
-	ZoneStatistics   []Counter
-	ServerRcodes     []Counter
+	// ZoneMaintenance holds the NOTIFY, SOA query and zone transfer
+	// counters of the server, i.e. the zonestat counters of the server
+	// document. They are totals over the zones of the server, unlike
+	// ZoneCounter.ZoneStats which holds those of a single zone. See
+	// Server.Maintenance.
+	ZoneMaintenance []Counter
+	// ZoneStatistics holds the same counters as ZoneMaintenance. Clients,
+	// StatisticsBuilder and the functions of the package returning
+	// statistics set both, but only ZoneMaintenance is read by the package.
+	//
+	// Deprecated: Use ZoneMaintenance, whose name does not suggest
+	// statistics of zones.
+	ZoneStatistics []Counter
+	ServerRcodes   []Counter
+	// Extra holds the server counters of sections unknown to the package,
+	// keyed by the type of the section, see Extra.
+	Extra Extra
+}
+
+// DefaultView is the name of the view of servers without explicitly
+// configured views, which holds all their zones and resolver statistics.
+const DefaultView = "_default"
+
+// ViewAlias returns alias for the view name DefaultView and name for any
+// other view. Output options like WithDefaultViewAlias use it, so that the
+// view is renamed consistently.
+func ViewAlias(name, alias string) string {
+	if name == DefaultView {
+		return alias
+	}
+	return name
 }
 
 // View represents statistics for a single BIND view.
 type View struct {
-	Name            string
-	Cache           []Gauge
-	ResolverStats   []Counter
+	Name string
+	// Cache holds the number of cached RRsets by type.
+	Cache []Gauge
+	// CacheMemory holds the memory used by the cache in bytes, e.g.
+	// HeapMemInUse and TreeMemInUse. It is separate from Cache, which
+	// counts RRsets.
+	CacheMemory []Gauge
+	// CacheStats holds the cache counters of the view, e.g. QueryHits and
+	// QueryMisses, see CacheCounterStat. It is nil if the server does not
+	// report them.
+	CacheStats    []Counter
+	ResolverStats []Counter
+	// ResolverGauges holds the resolver statistics of the view which report
+	// a current value rather than a count, e.g. NumFetch and QueryCurUDP,
+	// see ResolverGaugeStat. They are not included in ResolverStats. It is
+	// nil if the server does not report them, like older versions of BIND.
+	ResolverGauges  []Gauge
 	ResolverQueries []Counter
+	// QueryRTT is the histogram of resolver query round-trip times of the
+	// view in seconds, derived from the QryRTT counters in ResolverStats.
+	QueryRTT Histogram
+	// Extra holds the counters of resolver sections of the view unknown to
+	// the package, see Extra.
+	Extra Extra
 }
 
 // View represents statistics for a single BIND zone view.
 type ZoneView struct {
 	Name     string
 	ZoneData []ZoneCounter
+	// QueriesByClass holds the incoming queries of the zones of the view
+	// of all classes. It is nil if the zones do not report their queries
+	// by type, see QueriesByClass.
+	QueriesByClass QueriesByClass
 }
 
 // TaskManager contains information about all running tasks.
@@ -85,10 +282,45 @@ type Counter struct {
 
 // Counter represents a single zone counter value.
 type ZoneCounter struct {
-	Name   string
-	Serial string
+	// Name is the name of the zone in canonical form, see
+	// CanonicalZoneName.
+	Name string
+	// RawName is the name as reported by named if it differs from Name,
+	// e.g. "example.com." for a name reported with trailing dot.
+	RawName string
+	Serial  string
+	// ZoneStats holds the NOTIFY, SOA query and zone transfer counters of
+	// the zone. It is nil for zones without any such activity.
+	ZoneStats []Counter
+	// DNSSECSignStats counts the signatures generated per key tag. It is
+	// nil for zones which are not signed by named.
+	DNSSECSignStats []Counter
+	// DNSSECRefreshStats counts the signatures refreshed per key tag. It is
+	// nil for zones which are not signed by named.
+	DNSSECRefreshStats []Counter
+	// QueryResults holds the query result counters of the zone, e.g.
+	// QrySuccess and QryNXDOMAIN. BIND reports them with zone-statistics
+	// set to full.
+	QueryResults []Counter
+	// IncomingQueries holds the queries of the zone by type. They are not
+	// reported by the XML v2 schema.
+	IncomingQueries []Counter
+	// NameServerStats holds the remaining name server counters of the zone,
+	// which are only reported by the XML v2 schema.
+	NameServerStats []Counter
+	// Extra holds the counters of zone sections unknown to the package, see
+	// Extra.
+	Extra Extra
 }
 
+// Extra holds counter sections which are not modeled by the package, keyed by
+// their type attribute in XML or their key in JSON, so that counters added by
+// new BIND releases are not lost. Sections which the package knows but does
+// not decode, such as the socket statistics, are not included. It is nil if
+// there are no such sections. WithStrictDecoding rejects the documents
+// instead.
+type Extra map[string][]Counter
+
 // Gauge represents a single gauge value.
 type Gauge struct {
 	Name  string `xml:"name"`
@@ -97,11 +329,11 @@ type Gauge struct {
 
 // Task represents a single running task.
 type Task struct {
-	ID         string `xml:"id"`
-	Name       string `xml:"name"`
-	Quantum    int64  `xml:"quantum"`
-	References uint64 `xml:"references"`
-	State      string `xml:"state"`
+	ID         string    `xml:"id"`
+	Name       string    `xml:"name"`
+	Quantum    int64     `xml:"quantum"`
+	References uint64    `xml:"references"`
+	State      TaskState `xml:"state"`
 }
 
 // ThreadModel contains task and worker information.
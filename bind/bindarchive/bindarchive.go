@@ -0,0 +1,172 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bindarchive implements a stable JSON archive format of
+// bind.Statistics, e.g. for nightly dumps kept for trend analysis, which
+// later releases of the package keep reading.
+//
+// An archive is a JSON object, the envelope:
+//
+//	{
+//	  "format": "bindstats/v1",
+//	  "source": {
+//	    "format": "xml/v3",
+//	    "schema_version": "3.14",
+//	    "bind_version": "9.18.24",
+//	    "fetch_time": "2024-03-15T09:12:43.331Z",
+//	    "zones_strategy": "filtered"
+//	  },
+//	  "data": {"server": {...}, "views": [...], "zone_views": [...], ...}
+//	}
+//
+// The format names the version of the archive format. The source describes
+// where and when the statistics have been fetched, see bind.Source, so that
+// archives are self-describing; zones_strategy is omitted if empty. The data
+// holds the remaining fields of the statistics in types of the archive format
+// with explicit names, e.g. "name_server_stats", so that changes to
+// bind.Statistics do not change the format. Counters and gauges are objects
+// of name and value, durations are nanoseconds. The Extensions, which are
+// opaque to the package, are not archived.
+//
+// A release of the package which changes the encoding of the data or the
+// envelope increments the version of the format and adds a migration from the
+// previous version, so that ReadArchive reads the archives of all earlier
+// versions. The first version, bindstats/v0, held the source within the data,
+// which was bind.Statistics encoded with its Go field names.
+package bindarchive
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+)
+
+// Format is the archive format written by WriteArchive.
+const Format = "bindstats/v1"
+
+// ErrUnknownFormat is returned by ReadArchive for archives of a format
+// unknown to the package, e.g. written by a newer release.
+var ErrUnknownFormat = errors.New("unknown archive format")
+
+// envelope is the JSON object of an archive.
+type envelope struct {
+	Format string          `json:"format"`
+	Source *source         `json:"source,omitempty"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// source is the source block of the envelope, see bind.Source.
+type source struct {
+	Format        bind.Format        `json:"format"`
+	SchemaVersion string             `json:"schema_version"`
+	BINDVersion   string             `json:"bind_version"`
+	FetchTime     time.Time          `json:"fetch_time"`
+	ZonesStrategy bind.ZonesStrategy `json:"zones_strategy,omitempty"`
+}
+
+// newSource returns the source block describing s.
+func newSource(s bind.Source) *source {
+	return &source{
+		Format:        s.Format,
+		SchemaVersion: s.SchemaVersion,
+		BINDVersion:   s.BINDVersion,
+		FetchTime:     s.FetchTime,
+		ZonesStrategy: s.ZonesStrategy,
+	}
+}
+
+// bindSource returns the bind.Source described by s.
+func (s source) bindSource() bind.Source {
+	return bind.Source{
+		Format:        s.Format,
+		SchemaVersion: s.SchemaVersion,
+		BINDVersion:   s.BINDVersion,
+		FetchTime:     s.FetchTime,
+		ZonesStrategy: s.ZonesStrategy,
+	}
+}
+
+// migrations maps the formats of earlier versions to the migration of their
+// envelope to the next version.
+var migrations = map[string]func(*envelope) error{
+	"bindstats/v0": migrateV0,
+}
+
+// WriteArchive writes the archive of s to w, followed by a newline.
+func WriteArchive(w io.Writer, s bind.Statistics) error {
+	data, err := json.Marshal(newData(s))
+	if err != nil {
+		return fmt.Errorf("failed to encode statistics: %w", err)
+	}
+	env := envelope{Format: Format, Source: newSource(s.Source), Data: data}
+	if err := json.NewEncoder(w).Encode(env); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return nil
+}
+
+// ReadArchive reads an archive written by WriteArchive of the current or an
+// earlier release from r, migrating archives of earlier formats. It returns
+// an error wrapping ErrUnknownFormat for archives of other formats. Times are
+// those of the archive, in the offset from UTC they have been written with.
+func ReadArchive(r io.Reader) (bind.Statistics, error) {
+	var env envelope
+	if err := json.NewDecoder(r).Decode(&env); err != nil {
+		return bind.Statistics{}, fmt.Errorf("failed to read archive: %w", err)
+	}
+	for env.Format != Format {
+		migrate, ok := migrations[env.Format]
+		if !ok {
+			return bind.Statistics{}, fmt.Errorf("%w %q", ErrUnknownFormat, env.Format)
+		}
+		from := env.Format
+		if err := migrate(&env); err != nil {
+			return bind.Statistics{}, fmt.Errorf("failed to migrate archive of format %q: %w", from, err)
+		}
+	}
+	if env.Source == nil {
+		return bind.Statistics{}, errors.New("failed to read archive: missing source")
+	}
+	var d data
+	if err := json.Unmarshal(env.Data, &d); err != nil {
+		return bind.Statistics{}, fmt.Errorf("failed to read archive data: %w", err)
+	}
+	s, err := d.statistics()
+	if err != nil {
+		return bind.Statistics{}, fmt.Errorf("failed to read archive data: %w", err)
+	}
+	s.Source = env.Source.bindSource()
+	return s, nil
+}
+
+// migrateV0 migrates an archive of the format bindstats/v0, whose data is
+// bind.Statistics encoded with its Go field names, including the Source, to
+// bindstats/v1.
+func migrateV0(env *envelope) error {
+	var s bind.Statistics
+	if err := json.Unmarshal(env.Data, &s); err != nil {
+		return err
+	}
+	b, err := json.Marshal(newData(s))
+	if err != nil {
+		return err
+	}
+	env.Format = "bindstats/v1"
+	env.Source = newSource(s.Source)
+	env.Data = b
+	return nil
+}
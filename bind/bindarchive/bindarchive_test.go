@@ -0,0 +1,202 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindarchive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/bindtest"
+	"github.com/prometheus-community/bind_exporter/bind/xml"
+	"github.com/prometheus-community/bind_exporter/fixtures"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// fixtureStats returns the statistics of the fixture file, see
+// bindtest.FixtureStatistics, without the monotonic clock reading and the
+// location of the fetch time, which are not archived.
+func fixtureStats(t *testing.T, file string) (bind.Statistics, bool) {
+	t.Helper()
+	s, ok := bindtest.FixtureStatistics(t, file)
+	s.Source.FetchTime = s.Source.FetchTime.Round(0).UTC()
+	return s, ok
+}
+
+// writeV0 writes s in the format bindstats/v0, which held the source within
+// the data.
+func writeV0(t *testing.T, s bind.Statistics) *bytes.Buffer {
+	t.Helper()
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := json.NewEncoder(&b).Encode(map[string]any{"format": "bindstats/v0", "data": json.RawMessage(data)}); err != nil {
+		t.Fatal(err)
+	}
+	return &b
+}
+
+func TestArchiveFixtures(t *testing.T) {
+	files, err := fs.Glob(fixtures.FS, "*/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for _, file := range files {
+		s, ok := fixtureStats(t, file)
+		if !ok {
+			continue
+		}
+		n++
+		var b bytes.Buffer
+		if err := WriteArchive(&b, s); err != nil {
+			t.Fatalf("%s: %s", file, err)
+		}
+		if got, err := ReadArchive(&b); err != nil || !reflect.DeepEqual(s, got) {
+			t.Errorf("%s: want %+v, got %+v (%v)", file, s, got, err)
+		}
+		if got, err := ReadArchive(writeV0(t, s)); err != nil || !reflect.DeepEqual(s, got) {
+			t.Errorf("%s: want %+v migrated from bindstats/v0, got %+v (%v)", file, s, got, err)
+		}
+	}
+	if n == 0 {
+		t.Error("want fixtures")
+	}
+}
+
+func TestArchiveEnvelope(t *testing.T) {
+	s, ok := fixtureStats(t, "xml/server.xml")
+	if !ok {
+		t.FailNow()
+	}
+	s.Extensions = map[string]any{"quic": 1}
+	var b bytes.Buffer
+	if err := WriteArchive(&b, s); err != nil {
+		t.Fatal(err)
+	}
+	var env struct {
+		Format string
+		Source map[string]any
+		Data   map[string]json.RawMessage
+	}
+	if err := json.Unmarshal(b.Bytes(), &env); err != nil {
+		t.Fatal(err)
+	}
+	if env.Format != Format {
+		t.Errorf("want format %s, got %s", Format, env.Format)
+	}
+	want := map[string]any{
+		"format":         string(bind.FormatXMLv3),
+		"schema_version": s.Source.SchemaVersion,
+		"bind_version":   s.Source.BINDVersion,
+		"fetch_time":     s.Source.FetchTime.Format("2006-01-02T15:04:05.999999999Z07:00"),
+	}
+	if !reflect.DeepEqual(env.Source, want) {
+		t.Errorf("want source %v, got %v", want, env.Source)
+	}
+	for _, f := range []string{"Source", "source", "Extensions", "extensions"} {
+		if _, ok := env.Data[f]; ok {
+			t.Errorf("want no %s in the data", f)
+		}
+	}
+	for _, f := range []string{"server", "views", "zone_views", "task_manager", "memory"} {
+		if _, ok := env.Data[f]; !ok {
+			t.Errorf("want %s in the data", f)
+		}
+	}
+	got, err := ReadArchive(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Extensions != nil {
+		t.Errorf("want no extensions, got %v", got.Extensions)
+	}
+}
+
+// TestArchiveGolden tests that the archive of the paired XML v3 fixtures
+// equals the golden archive, which later releases must keep reading.
+func TestArchiveGolden(t *testing.T) {
+	const file = "testdata/archive-v1.json"
+	ts := bindtest.NewServerWith(map[string]string{
+		xml.ServerPath: "xml/server-paired.xml",
+		xml.ZonesPath:  "xml/zones-paired.xml",
+		xml.TasksPath:  "xml/tasks-busy.xml",
+	})
+	defer ts.Close()
+	s, err := xml.NewClient(ts.URL, nil).Stats(context.Background(), bind.ServerStats, bind.ViewStats, bind.TaskStats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Fix the values which depend on the time of the fetch.
+	s.Source.FetchTime = time.Date(2024, 3, 15, 9, 12, 43, 331000000, time.UTC)
+	s.ClockSkew = -1500 * time.Millisecond
+	s.Decode = map[bind.StatisticGroup]bind.DecodeInfo{
+		bind.ServerStats: {Views: 2, Counters: 310, Duration: 3 * time.Millisecond, SectionBytes: map[string]int64{"server": 15210, "views": 9422}},
+	}
+	var b bytes.Buffer
+	if err := WriteArchive(&b, s); err != nil {
+		t.Fatal(err)
+	}
+	if *update {
+		if err := os.WriteFile(file, b.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b.Bytes(), want) {
+		t.Errorf("archive differs from %s, run go test -update to update it\n%s", file, b.Bytes())
+	}
+	got, err := ReadArchive(bytes.NewReader(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, s) {
+		t.Errorf("want %+v read from %s, got %+v", s, file, got)
+	}
+}
+
+func TestReadArchiveErrors(t *testing.T) {
+	for _, tc := range []struct {
+		archive string
+		err     error
+	}{
+		{`{"format":"bindstats/v2","source":{},"data":{}}`, ErrUnknownFormat},
+		{`{"format":"","data":{}}`, ErrUnknownFormat},
+		{`{"format":"bindstats/v1","data":{}}`, nil},
+		{`{"format":"bindstats/v0","data":{"Source":"xml/v3"}}`, nil},
+		{`{"format":"bindstats/v1","source":{},"data":{"Views":{}}}`, nil},
+		{`{"format":"bindstats/v1","source":{},"data":{"views":[{"query_rtt":{"buckets":[{"upper_bound":"ten"}]}}]}}`, nil},
+		{`{"format":`, nil},
+	} {
+		_, err := ReadArchive(strings.NewReader(tc.archive))
+		if err == nil || tc.err != nil && !errors.Is(err, tc.err) {
+			t.Errorf("%s: want error %v, got %v", tc.archive, tc.err, err)
+		}
+	}
+}
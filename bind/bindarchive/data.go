@@ -0,0 +1,402 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindarchive
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+)
+
+// The types below are the encoding of the data of the format bindstats/v1.
+// They mirror bind.Statistics, but are owned by the archive format, so that
+// renaming or adding fields of the package changes no archive. Lists and maps
+// which are nil in the statistics are encoded as null, empty ones as empty.
+
+// data is the data of an archive.
+type data struct {
+	Server          server                `json:"server"`
+	Views           []view                `json:"views"`
+	ZoneViews       []zoneView            `json:"zone_views"`
+	TaskManager     taskManager           `json:"task_manager"`
+	Memory          memory                `json:"memory"`
+	ClockSkewNanos  int64                 `json:"clock_skew_ns"`
+	MissingGroups   []string              `json:"missing_groups"`
+	Warnings        []warning             `json:"warnings"`
+	OmittedWarnings int                   `json:"omitted_warnings"`
+	Decode          map[string]decodeInfo `json:"decode"`
+}
+
+type server struct {
+	BootTime         time.Time            `json:"boot_time"`
+	ConfigTime       time.Time            `json:"config_time"`
+	CurrentTime      time.Time            `json:"current_time"`
+	IncomingQueries  []counter            `json:"incoming_queries"`
+	IncomingRequests []counter            `json:"incoming_requests"`
+	NameServerStats  []counter            `json:"name_server_stats"`
+	ZoneMaintenance  []counter            `json:"zone_maintenance"`
+	ServerRcodes     []counter            `json:"server_rcodes"`
+	Extra            map[string][]counter `json:"extra"`
+}
+
+type view struct {
+	Name            string               `json:"name"`
+	Cache           []gauge              `json:"cache"`
+	CacheMemory     []gauge              `json:"cache_memory"`
+	CacheStats      []counter            `json:"cache_stats"`
+	ResolverStats   []counter            `json:"resolver_stats"`
+	ResolverGauges  []gauge              `json:"resolver_gauges"`
+	ResolverQueries []counter            `json:"resolver_queries"`
+	QueryRTT        histogram            `json:"query_rtt"`
+	Extra           map[string][]counter `json:"extra"`
+}
+
+type zoneView struct {
+	Name           string               `json:"name"`
+	Zones          []zone               `json:"zones"`
+	QueriesByClass map[string][]counter `json:"queries_by_class"`
+}
+
+type zone struct {
+	Name               string               `json:"name"`
+	RawName            string               `json:"raw_name,omitempty"`
+	Serial             string               `json:"serial"`
+	ZoneStats          []counter            `json:"zone_stats"`
+	DNSSECSignStats    []counter            `json:"dnssec_sign_stats"`
+	DNSSECRefreshStats []counter            `json:"dnssec_refresh_stats"`
+	QueryResults       []counter            `json:"query_results"`
+	IncomingQueries    []counter            `json:"incoming_queries"`
+	NameServerStats    []counter            `json:"name_server_stats"`
+	Extra              map[string][]counter `json:"extra"`
+}
+
+type taskManager struct {
+	Tasks       []task      `json:"tasks"`
+	ThreadModel threadModel `json:"thread_model"`
+}
+
+type task struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Quantum    int64  `json:"quantum"`
+	References uint64 `json:"references"`
+	State      string `json:"state"`
+}
+
+type threadModel struct {
+	Type           string `json:"type"`
+	WorkerThreads  uint64 `json:"worker_threads"`
+	DefaultQuantum uint64 `json:"default_quantum"`
+	TasksRunning   uint64 `json:"tasks_running"`
+}
+
+type memory struct {
+	Summary  memorySummary   `json:"summary"`
+	Contexts []memoryContext `json:"contexts"`
+}
+
+type memorySummary struct {
+	TotalUse    uint64 `json:"total_use"`
+	InUse       uint64 `json:"in_use"`
+	Malloced    uint64 `json:"malloced"`
+	BlockSize   uint64 `json:"block_size"`
+	ContextSize uint64 `json:"context_size"`
+	Lost        uint64 `json:"lost"`
+}
+
+type memoryContext struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	References uint64 `json:"references"`
+	Total      uint64 `json:"total"`
+	InUse      uint64 `json:"in_use"`
+	MaxInUse   uint64 `json:"max_in_use"`
+}
+
+type counter struct {
+	Name  string `json:"name"`
+	Value uint64 `json:"value"`
+}
+
+type gauge struct {
+	Name  string `json:"name"`
+	Value uint64 `json:"value"`
+}
+
+// histogram holds the upper bounds of its buckets as strings, as JSON has no
+// infinite numbers, e.g. "0.01" and "+Inf".
+type histogram struct {
+	Buckets []bucket `json:"buckets"`
+	Count   uint64   `json:"count"`
+}
+
+type bucket struct {
+	UpperBound string `json:"upper_bound"`
+	Count      uint64 `json:"count"`
+}
+
+type warning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Path    string `json:"path,omitempty"`
+}
+
+type decodeInfo struct {
+	Views         int              `json:"views"`
+	Zones         int              `json:"zones"`
+	Tasks         int              `json:"tasks"`
+	Counters      int              `json:"counters"`
+	DurationNanos int64            `json:"duration_ns"`
+	SectionBytes  map[string]int64 `json:"section_bytes"`
+}
+
+// mapSlice returns the results of f for the elements of s, or nil if s is
+// nil.
+func mapSlice[T, U any](s []T, f func(T) U) []U {
+	if s == nil {
+		return nil
+	}
+	out := make([]U, len(s))
+	for i, v := range s {
+		out[i] = f(v)
+	}
+	return out
+}
+
+// mapValues returns the results of f for the values of m by key, or nil if m
+// is nil.
+func mapValues[K comparable, T, U any](m map[K]T, f func(T) U) map[K]U {
+	if m == nil {
+		return nil
+	}
+	out := make(map[K]U, len(m))
+	for k, v := range m {
+		out[k] = f(v)
+	}
+	return out
+}
+
+// newData returns the data of s.
+func newData(s bind.Statistics) data {
+	d := data{
+		Server: server{
+			BootTime:         s.Server.BootTime,
+			ConfigTime:       s.Server.ConfigTime,
+			CurrentTime:      s.Server.CurrentTime,
+			IncomingQueries:  newCounters(s.Server.IncomingQueries),
+			IncomingRequests: newCounters(s.Server.IncomingRequests),
+			NameServerStats:  newCounters(s.Server.NameServerStats),
+			ZoneMaintenance:  newCounters(s.Server.ZoneMaintenance),
+			ServerRcodes:     newCounters(s.Server.ServerRcodes),
+			Extra:            mapValues(s.Server.Extra, newCounters),
+		},
+		Views:     mapSlice(s.Views, newView),
+		ZoneViews: mapSlice(s.ZoneViews, newZoneView),
+		TaskManager: taskManager{
+			Tasks: mapSlice(s.TaskManager.Tasks, func(t bind.Task) task {
+				return task{ID: t.ID, Name: t.Name, Quantum: t.Quantum, References: t.References, State: string(t.State)}
+			}),
+			ThreadModel: threadModel(s.TaskManager.ThreadModel),
+		},
+		Memory: memory{
+			Summary:  memorySummary(s.Memory.Summary),
+			Contexts: mapSlice(s.Memory.Contexts, func(c bind.MemoryContext) memoryContext { return memoryContext(c) }),
+		},
+		ClockSkewNanos:  int64(s.ClockSkew),
+		MissingGroups:   mapSlice(s.MissingGroups, func(g bind.StatisticGroup) string { return string(g) }),
+		Warnings:        mapSlice(s.Warnings, func(w bind.Warning) warning { return warning(w) }),
+		OmittedWarnings: s.OmittedWarnings,
+	}
+	if s.Decode != nil {
+		d.Decode = make(map[string]decodeInfo, len(s.Decode))
+		for g, i := range s.Decode {
+			d.Decode[string(g)] = decodeInfo{
+				Views:         i.Views,
+				Zones:         i.Zones,
+				Tasks:         i.Tasks,
+				Counters:      i.Counters,
+				DurationNanos: int64(i.Duration),
+				SectionBytes:  i.SectionBytes,
+			}
+		}
+	}
+	return d
+}
+
+func newView(v bind.View) view {
+	return view{
+		Name:            v.Name,
+		Cache:           newGauges(v.Cache),
+		CacheMemory:     newGauges(v.CacheMemory),
+		CacheStats:      newCounters(v.CacheStats),
+		ResolverStats:   newCounters(v.ResolverStats),
+		ResolverGauges:  newGauges(v.ResolverGauges),
+		ResolverQueries: newCounters(v.ResolverQueries),
+		QueryRTT: histogram{
+			Buckets: mapSlice(v.QueryRTT.Buckets, func(b bind.Bucket) bucket {
+				return bucket{UpperBound: strconv.FormatFloat(b.UpperBound, 'g', -1, 64), Count: b.Count}
+			}),
+			Count: v.QueryRTT.Count,
+		},
+		Extra: mapValues(v.Extra, newCounters),
+	}
+}
+
+func newZoneView(v bind.ZoneView) zoneView {
+	return zoneView{
+		Name: v.Name,
+		Zones: mapSlice(v.ZoneData, func(z bind.ZoneCounter) zone {
+			return zone{
+				Name:               z.Name,
+				RawName:            z.RawName,
+				Serial:             z.Serial,
+				ZoneStats:          newCounters(z.ZoneStats),
+				DNSSECSignStats:    newCounters(z.DNSSECSignStats),
+				DNSSECRefreshStats: newCounters(z.DNSSECRefreshStats),
+				QueryResults:       newCounters(z.QueryResults),
+				IncomingQueries:    newCounters(z.IncomingQueries),
+				NameServerStats:    newCounters(z.NameServerStats),
+				Extra:              mapValues(z.Extra, newCounters),
+			}
+		}),
+		QueriesByClass: mapValues(v.QueriesByClass, newCounters),
+	}
+}
+
+func newCounters(cs []bind.Counter) []counter {
+	return mapSlice(cs, func(c bind.Counter) counter { return counter{Name: c.Name, Value: c.Counter} })
+}
+
+func newGauges(gs []bind.Gauge) []gauge {
+	return mapSlice(gs, func(g bind.Gauge) gauge { return gauge{Name: g.Name, Value: g.Gauge} })
+}
+
+// statistics returns the statistics of d, without their source.
+func (d data) statistics() (bind.Statistics, error) {
+	s := bind.Statistics{
+		Server: bind.Server{
+			BootTime:         d.Server.BootTime,
+			ConfigTime:       d.Server.ConfigTime,
+			CurrentTime:      d.Server.CurrentTime,
+			IncomingQueries:  counters(d.Server.IncomingQueries),
+			IncomingRequests: counters(d.Server.IncomingRequests),
+			NameServerStats:  counters(d.Server.NameServerStats),
+			ZoneMaintenance:  counters(d.Server.ZoneMaintenance),
+			ZoneStatistics:   counters(d.Server.ZoneMaintenance),
+			ServerRcodes:     counters(d.Server.ServerRcodes),
+			Extra:            extra(d.Server.Extra),
+		},
+		ZoneViews: mapSlice(d.ZoneViews, zoneView.zoneView),
+		TaskManager: bind.TaskManager{
+			Tasks: mapSlice(d.TaskManager.Tasks, func(t task) bind.Task {
+				return bind.Task{ID: t.ID, Name: t.Name, Quantum: t.Quantum, References: t.References, State: bind.TaskState(t.State)}
+			}),
+			ThreadModel: bind.ThreadModel(d.TaskManager.ThreadModel),
+		},
+		Memory: bind.Memory{
+			Summary:  bind.MemorySummary(d.Memory.Summary),
+			Contexts: mapSlice(d.Memory.Contexts, func(c memoryContext) bind.MemoryContext { return bind.MemoryContext(c) }),
+		},
+		ClockSkew:       time.Duration(d.ClockSkewNanos),
+		MissingGroups:   mapSlice(d.MissingGroups, func(g string) bind.StatisticGroup { return bind.StatisticGroup(g) }),
+		Warnings:        mapSlice(d.Warnings, func(w warning) bind.Warning { return bind.Warning(w) }),
+		OmittedWarnings: d.OmittedWarnings,
+	}
+	var err error
+	s.Views = mapSlice(d.Views, func(v view) bind.View {
+		bv, verr := v.view()
+		if err == nil {
+			err = verr
+		}
+		return bv
+	})
+	if err != nil {
+		return bind.Statistics{}, err
+	}
+	if d.Decode != nil {
+		s.Decode = make(map[bind.StatisticGroup]bind.DecodeInfo, len(d.Decode))
+		for g, i := range d.Decode {
+			s.Decode[bind.StatisticGroup(g)] = bind.DecodeInfo{
+				Views:        i.Views,
+				Zones:        i.Zones,
+				Tasks:        i.Tasks,
+				Counters:     i.Counters,
+				Duration:     time.Duration(i.DurationNanos),
+				SectionBytes: i.SectionBytes,
+			}
+		}
+	}
+	return s, nil
+}
+
+func (v view) view() (bind.View, error) {
+	bv := bind.View{
+		Name:            v.Name,
+		Cache:           gauges(v.Cache),
+		CacheMemory:     gauges(v.CacheMemory),
+		CacheStats:      counters(v.CacheStats),
+		ResolverStats:   counters(v.ResolverStats),
+		ResolverGauges:  gauges(v.ResolverGauges),
+		ResolverQueries: counters(v.ResolverQueries),
+		QueryRTT:        bind.Histogram{Count: v.QueryRTT.Count},
+		Extra:           extra(v.Extra),
+	}
+	if v.QueryRTT.Buckets != nil {
+		bv.QueryRTT.Buckets = make([]bind.Bucket, len(v.QueryRTT.Buckets))
+	}
+	for i, b := range v.QueryRTT.Buckets {
+		ub, err := strconv.ParseFloat(b.UpperBound, 64)
+		if err != nil || math.IsNaN(ub) {
+			return bind.View{}, fmt.Errorf("invalid upper bound %q of the query RTT histogram of view %q", b.UpperBound, v.Name)
+		}
+		bv.QueryRTT.Buckets[i] = bind.Bucket{UpperBound: ub, Count: b.Count}
+	}
+	return bv, nil
+}
+
+func (v zoneView) zoneView() bind.ZoneView {
+	return bind.ZoneView{
+		Name: v.Name,
+		ZoneData: mapSlice(v.Zones, func(z zone) bind.ZoneCounter {
+			return bind.ZoneCounter{
+				Name:               z.Name,
+				RawName:            z.RawName,
+				Serial:             z.Serial,
+				ZoneStats:          counters(z.ZoneStats),
+				DNSSECSignStats:    counters(z.DNSSECSignStats),
+				DNSSECRefreshStats: counters(z.DNSSECRefreshStats),
+				QueryResults:       counters(z.QueryResults),
+				IncomingQueries:    counters(z.IncomingQueries),
+				NameServerStats:    counters(z.NameServerStats),
+				Extra:              extra(z.Extra),
+			}
+		}),
+		QueriesByClass: mapValues(v.QueriesByClass, counters),
+	}
+}
+
+func counters(cs []counter) []bind.Counter {
+	return mapSlice(cs, func(c counter) bind.Counter { return bind.Counter{Name: c.Name, Counter: c.Value} })
+}
+
+func gauges(gs []gauge) []bind.Gauge {
+	return mapSlice(gs, func(g gauge) bind.Gauge { return bind.Gauge{Name: g.Name, Gauge: g.Value} })
+}
+
+func extra(m map[string][]counter) bind.Extra {
+	return mapValues(m, counters)
+}
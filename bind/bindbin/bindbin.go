@@ -0,0 +1,544 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bindbin implements a compact binary encoding of bind.Statistics for
+// caching and shipping statistics between processes, which is considerably
+// smaller and faster than JSON for servers with many zones.
+//
+// An encoding starts with a format version byte, followed by the fields of
+// the statistics in order. Integers are encoded as varints, slices and
+// strings are prefixed by their length, and times use the encoding of
+// time.Time.MarshalBinary. Nil slices are distinguished from empty ones, so
+// that decoding returns statistics deeply equal to the encoded ones, except
+// for the monotonic clock readings of times. The Extensions, which are opaque
+// to the package, are not encoded.
+package bindbin
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+)
+
+// Version is the format version written by EncodeBinary. Version 2 added the
+// Extra counters, version 3 the Decode statistics, version 4 the paths of the
+// Warnings and OmittedWarnings, version 5 the QueriesByClass of zone views,
+// version 6 the ResolverGauges of views, version 7 the ZonesStrategy of the
+// Source, version 8 the SectionBytes of the Decode statistics, version 9
+// the Memory statistics, version 10 the RawName of zones and version 11 the
+// CacheStats of views.
+const Version byte = 11
+
+// Limits guarding the decoder against corrupt input.
+const (
+	maxStringLen = 1 << 20
+	maxSliceLen  = 1 << 24
+)
+
+// ErrUnknownVersion is returned by DecodeBinary for encodings of a format
+// version unknown to the package, e.g. written by a newer release.
+var ErrUnknownVersion = errors.New("unknown binary format version")
+
+// EncodeBinary writes the binary encoding of s to w.
+func EncodeBinary(w io.Writer, s bind.Statistics) error {
+	e := &encoder{w: bufio.NewWriter(w)}
+	e.w.WriteByte(Version)
+	e.statistics(s)
+	if err := e.w.Flush(); err != nil {
+		return fmt.Errorf("failed to write statistics: %w", err)
+	}
+	return nil
+}
+
+// DecodeBinary reads statistics encoded by EncodeBinary from r. It may read
+// beyond the end of the encoding.
+func DecodeBinary(r io.Reader) (bind.Statistics, error) {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	d := &decoder{r: br}
+	v, err := br.ReadByte()
+	if err != nil {
+		return bind.Statistics{}, fmt.Errorf("failed to read statistics: %w", err)
+	}
+	if v != Version {
+		return bind.Statistics{}, fmt.Errorf("%w %d", ErrUnknownVersion, v)
+	}
+	s := d.statistics()
+	if d.err != nil {
+		if d.err == io.EOF {
+			d.err = io.ErrUnexpectedEOF
+		}
+		return bind.Statistics{}, fmt.Errorf("failed to read statistics: %w", d.err)
+	}
+	return s, nil
+}
+
+type encoder struct {
+	w   *bufio.Writer
+	buf [binary.MaxVarintLen64]byte
+}
+
+func (e *encoder) uint(v uint64) {
+	e.w.Write(e.buf[:binary.PutUvarint(e.buf[:], v)])
+}
+
+func (e *encoder) int(v int64) {
+	e.w.Write(e.buf[:binary.PutVarint(e.buf[:], v)])
+}
+
+func (e *encoder) float(v float64) {
+	binary.LittleEndian.PutUint64(e.buf[:8], math.Float64bits(v))
+	e.w.Write(e.buf[:8])
+}
+
+func (e *encoder) string(s string) {
+	e.uint(uint64(len(s)))
+	e.w.WriteString(s)
+}
+
+func (e *encoder) time(t time.Time) {
+	b, err := t.MarshalBinary()
+	if err != nil {
+		// Only times with a fractional minute offset cannot be encoded.
+		b, _ = t.UTC().MarshalBinary()
+	}
+	e.uint(uint64(len(b)))
+	e.w.Write(b)
+}
+
+// length writes the length of a slice, shifted by one so that zero denotes a
+// nil slice.
+func (e *encoder) length(n int, isNil bool) {
+	if isNil {
+		e.uint(0)
+		return
+	}
+	e.uint(uint64(n) + 1)
+}
+
+func (e *encoder) counters(cs []bind.Counter) {
+	e.length(len(cs), cs == nil)
+	for _, c := range cs {
+		e.string(c.Name)
+		e.uint(c.Counter)
+	}
+}
+
+// extra writes the sections of x in the order of their types, so that equal
+// statistics have equal encodings.
+func (e *encoder) extra(x bind.Extra) {
+	e.length(len(x), x == nil)
+	for _, t := range x.Sections() {
+		e.string(t)
+		e.counters(x[t])
+	}
+}
+
+func (e *encoder) gauges(gs []bind.Gauge) {
+	e.length(len(gs), gs == nil)
+	for _, g := range gs {
+		e.string(g.Name)
+		e.uint(g.Gauge)
+	}
+}
+
+func (e *encoder) statistics(s bind.Statistics) {
+	e.string(string(s.Source.Format))
+	e.string(s.Source.SchemaVersion)
+	e.string(s.Source.BINDVersion)
+	e.time(s.Source.FetchTime)
+	e.string(string(s.Source.ZonesStrategy))
+
+	e.time(s.Server.BootTime)
+	e.time(s.Server.ConfigTime)
+	e.time(s.Server.CurrentTime)
+	e.counters(s.Server.IncomingQueries)
+	e.counters(s.Server.IncomingRequests)
+	e.counters(s.Server.NameServerStats)
+	e.counters(s.Server.ZoneMaintenance)
+	e.counters(s.Server.ServerRcodes)
+	e.extra(s.Server.Extra)
+
+	e.length(len(s.Views), s.Views == nil)
+	for _, v := range s.Views {
+		e.string(v.Name)
+		e.gauges(v.Cache)
+		e.gauges(v.CacheMemory)
+		e.counters(v.CacheStats)
+		e.counters(v.ResolverStats)
+		e.gauges(v.ResolverGauges)
+		e.counters(v.ResolverQueries)
+		e.extra(v.Extra)
+		e.length(len(v.QueryRTT.Buckets), v.QueryRTT.Buckets == nil)
+		for _, b := range v.QueryRTT.Buckets {
+			e.float(b.UpperBound)
+			e.uint(b.Count)
+		}
+		e.uint(v.QueryRTT.Count)
+	}
+
+	e.length(len(s.ZoneViews), s.ZoneViews == nil)
+	for _, v := range s.ZoneViews {
+		e.string(v.Name)
+		e.length(len(v.ZoneData), v.ZoneData == nil)
+		for _, z := range v.ZoneData {
+			e.string(z.Name)
+			e.string(z.RawName)
+			e.string(z.Serial)
+			e.counters(z.ZoneStats)
+			e.counters(z.DNSSECSignStats)
+			e.counters(z.DNSSECRefreshStats)
+			e.counters(z.QueryResults)
+			e.counters(z.IncomingQueries)
+			e.counters(z.NameServerStats)
+			e.extra(z.Extra)
+		}
+		e.extra(bind.Extra(v.QueriesByClass))
+	}
+
+	tm := s.TaskManager
+	e.length(len(tm.Tasks), tm.Tasks == nil)
+	for _, t := range tm.Tasks {
+		e.string(t.ID)
+		e.string(t.Name)
+		e.int(t.Quantum)
+		e.uint(t.References)
+		e.string(string(t.State))
+	}
+	e.string(tm.ThreadModel.Type)
+	e.uint(tm.ThreadModel.WorkerThreads)
+	e.uint(tm.ThreadModel.DefaultQuantum)
+	e.uint(tm.ThreadModel.TasksRunning)
+
+	for _, g := range s.Memory.Summary.Gauges() {
+		e.uint(g.Gauge)
+	}
+	e.length(len(s.Memory.Contexts), s.Memory.Contexts == nil)
+	for _, c := range s.Memory.Contexts {
+		e.string(c.ID)
+		e.string(c.Name)
+		e.uint(c.References)
+		e.uint(c.Total)
+		e.uint(c.InUse)
+		e.uint(c.MaxInUse)
+	}
+
+	e.int(int64(s.ClockSkew))
+	e.length(len(s.MissingGroups), s.MissingGroups == nil)
+	for _, g := range s.MissingGroups {
+		e.string(string(g))
+	}
+	e.length(len(s.Warnings), s.Warnings == nil)
+	for _, w := range s.Warnings {
+		e.string(w.Code)
+		e.string(w.Message)
+		e.string(w.Path)
+	}
+	e.uint(uint64(s.OmittedWarnings))
+	// The groups are sorted for the encoding to be deterministic.
+	groups := make([]bind.StatisticGroup, 0, len(s.Decode))
+	for g := range s.Decode {
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i] < groups[j] })
+	e.length(len(groups), s.Decode == nil)
+	for _, g := range groups {
+		i := s.Decode[g]
+		e.string(string(g))
+		e.int(int64(i.Views))
+		e.int(int64(i.Zones))
+		e.int(int64(i.Tasks))
+		e.int(int64(i.Counters))
+		e.int(int64(i.Duration))
+		sections := make([]string, 0, len(i.SectionBytes))
+		for k := range i.SectionBytes {
+			sections = append(sections, k)
+		}
+		sort.Strings(sections)
+		e.length(len(sections), i.SectionBytes == nil)
+		for _, k := range sections {
+			e.string(k)
+			e.int(i.SectionBytes[k])
+		}
+	}
+}
+
+// decoder reads the encoding of the encoder. Once an error occurred, it is
+// kept in err and all further reads return zero values.
+type decoder struct {
+	r   *bufio.Reader
+	err error
+	buf [8]byte
+}
+
+func (d *decoder) uint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(d.r)
+	d.err = err
+	return v
+}
+
+func (d *decoder) int() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadVarint(d.r)
+	d.err = err
+	return v
+}
+
+func (d *decoder) float() float64 {
+	if d.err != nil {
+		return 0
+	}
+	if _, d.err = io.ReadFull(d.r, d.buf[:]); d.err != nil {
+		return 0
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(d.buf[:]))
+}
+
+func (d *decoder) bytes() []byte {
+	n := d.uint()
+	if d.err != nil {
+		return nil
+	}
+	if n > maxStringLen {
+		d.err = fmt.Errorf("string of %d bytes exceeds limit", n)
+		return nil
+	}
+	b := make([]byte, n)
+	_, d.err = io.ReadFull(d.r, b)
+	return b
+}
+
+func (d *decoder) string() string {
+	return string(d.bytes())
+}
+
+func (d *decoder) time() time.Time {
+	b := d.bytes()
+	if d.err != nil {
+		return time.Time{}
+	}
+	var t time.Time
+	d.err = t.UnmarshalBinary(b)
+	return t
+}
+
+// length reads the length of a slice written by encoder.length. It returns
+// -1 for a nil slice, also after an error.
+func (d *decoder) length() int {
+	n := d.uint()
+	if d.err != nil || n == 0 {
+		return -1
+	}
+	if n-1 > maxSliceLen {
+		d.err = fmt.Errorf("slice of %d elements exceeds limit", n-1)
+		return -1
+	}
+	return int(n - 1)
+}
+
+// capacity returns the initial capacity of a slice of n elements, which is
+// bounded so that a corrupt length does not allocate excessive memory before
+// the input runs out.
+func capacity(n int) int {
+	if n > 1024 {
+		return 1024
+	}
+	return n
+}
+
+func (d *decoder) counters() []bind.Counter {
+	n := d.length()
+	if n < 0 {
+		return nil
+	}
+	cs := make([]bind.Counter, 0, capacity(n))
+	for i := 0; i < n && d.err == nil; i++ {
+		cs = append(cs, bind.Counter{Name: d.string(), Counter: d.uint()})
+	}
+	return cs
+}
+
+func (d *decoder) extra() bind.Extra {
+	n := d.length()
+	if n < 0 {
+		return nil
+	}
+	x := make(bind.Extra, capacity(n))
+	for i := 0; i < n && d.err == nil; i++ {
+		x[d.string()] = d.counters()
+	}
+	return x
+}
+
+func (d *decoder) gauges() []bind.Gauge {
+	n := d.length()
+	if n < 0 {
+		return nil
+	}
+	gs := make([]bind.Gauge, 0, capacity(n))
+	for i := 0; i < n && d.err == nil; i++ {
+		gs = append(gs, bind.Gauge{Name: d.string(), Gauge: d.uint()})
+	}
+	return gs
+}
+
+func (d *decoder) statistics() bind.Statistics {
+	var s bind.Statistics
+	s.Source.Format = bind.Format(d.string())
+	s.Source.SchemaVersion = d.string()
+	s.Source.BINDVersion = d.string()
+	s.Source.FetchTime = d.time()
+	s.Source.ZonesStrategy = bind.ZonesStrategy(d.string())
+
+	s.Server.BootTime = d.time()
+	s.Server.ConfigTime = d.time()
+	s.Server.CurrentTime = d.time()
+	s.Server.IncomingQueries = d.counters()
+	s.Server.IncomingRequests = d.counters()
+	s.Server.NameServerStats = d.counters()
+	s.Server.ZoneMaintenance = d.counters()
+	s.Server.ZoneStatistics = s.Server.ZoneMaintenance
+	s.Server.ServerRcodes = d.counters()
+	s.Server.Extra = d.extra()
+
+	if n := d.length(); n >= 0 {
+		s.Views = make([]bind.View, 0, capacity(n))
+		for i := 0; i < n && d.err == nil; i++ {
+			v := bind.View{Name: d.string()}
+			v.Cache = d.gauges()
+			v.CacheMemory = d.gauges()
+			v.CacheStats = d.counters()
+			v.ResolverStats = d.counters()
+			v.ResolverGauges = d.gauges()
+			v.ResolverQueries = d.counters()
+			v.Extra = d.extra()
+			if m := d.length(); m >= 0 {
+				v.QueryRTT.Buckets = make([]bind.Bucket, 0, capacity(m))
+				for j := 0; j < m && d.err == nil; j++ {
+					v.QueryRTT.Buckets = append(v.QueryRTT.Buckets, bind.Bucket{UpperBound: d.float(), Count: d.uint()})
+				}
+			}
+			v.QueryRTT.Count = d.uint()
+			s.Views = append(s.Views, v)
+		}
+	}
+
+	if n := d.length(); n >= 0 {
+		s.ZoneViews = make([]bind.ZoneView, 0, capacity(n))
+		for i := 0; i < n && d.err == nil; i++ {
+			v := bind.ZoneView{Name: d.string()}
+			if m := d.length(); m >= 0 {
+				v.ZoneData = make([]bind.ZoneCounter, 0, capacity(m))
+				for j := 0; j < m && d.err == nil; j++ {
+					z := bind.ZoneCounter{Name: d.string(), RawName: d.string(), Serial: d.string()}
+					z.ZoneStats = d.counters()
+					z.DNSSECSignStats = d.counters()
+					z.DNSSECRefreshStats = d.counters()
+					z.QueryResults = d.counters()
+					z.IncomingQueries = d.counters()
+					z.NameServerStats = d.counters()
+					z.Extra = d.extra()
+					v.ZoneData = append(v.ZoneData, z)
+				}
+			}
+			v.QueriesByClass = bind.QueriesByClass(d.extra())
+			s.ZoneViews = append(s.ZoneViews, v)
+		}
+	}
+
+	if n := d.length(); n >= 0 {
+		s.TaskManager.Tasks = make([]bind.Task, 0, capacity(n))
+		for i := 0; i < n && d.err == nil; i++ {
+			s.TaskManager.Tasks = append(s.TaskManager.Tasks, bind.Task{
+				ID:         d.string(),
+				Name:       d.string(),
+				Quantum:    d.int(),
+				References: d.uint(),
+				State:      bind.TaskState(d.string()),
+			})
+		}
+	}
+	tm := &s.TaskManager.ThreadModel
+	tm.Type = d.string()
+	tm.WorkerThreads = d.uint()
+	tm.DefaultQuantum = d.uint()
+	tm.TasksRunning = d.uint()
+
+	m := &s.Memory.Summary
+	for _, v := range []*uint64{&m.TotalUse, &m.InUse, &m.Malloced, &m.BlockSize, &m.ContextSize, &m.Lost} {
+		*v = d.uint()
+	}
+	if n := d.length(); n >= 0 {
+		s.Memory.Contexts = make([]bind.MemoryContext, 0, capacity(n))
+		for i := 0; i < n && d.err == nil; i++ {
+			s.Memory.Contexts = append(s.Memory.Contexts, bind.MemoryContext{
+				ID:         d.string(),
+				Name:       d.string(),
+				References: d.uint(),
+				Total:      d.uint(),
+				InUse:      d.uint(),
+				MaxInUse:   d.uint(),
+			})
+		}
+	}
+
+	s.ClockSkew = time.Duration(d.int())
+	if n := d.length(); n >= 0 {
+		s.MissingGroups = make([]bind.StatisticGroup, 0, capacity(n))
+		for i := 0; i < n && d.err == nil; i++ {
+			s.MissingGroups = append(s.MissingGroups, bind.StatisticGroup(d.string()))
+		}
+	}
+	if n := d.length(); n >= 0 {
+		s.Warnings = make([]bind.Warning, 0, capacity(n))
+		for i := 0; i < n && d.err == nil; i++ {
+			s.Warnings = append(s.Warnings, bind.Warning{Code: d.string(), Message: d.string(), Path: d.string()})
+		}
+	}
+	s.OmittedWarnings = int(d.uint())
+	if n := d.length(); n >= 0 {
+		s.Decode = make(map[bind.StatisticGroup]bind.DecodeInfo, capacity(n))
+		for i := 0; i < n && d.err == nil; i++ {
+			g := bind.StatisticGroup(d.string())
+			i := bind.DecodeInfo{
+				Views:    int(d.int()),
+				Zones:    int(d.int()),
+				Tasks:    int(d.int()),
+				Counters: int(d.int()),
+				Duration: time.Duration(d.int()),
+			}
+			if n := d.length(); n >= 0 {
+				i.SectionBytes = make(map[string]int64, capacity(n))
+				for j := 0; j < n && d.err == nil; j++ {
+					i.SectionBytes[d.string()] = d.int()
+				}
+			}
+			s.Decode[g] = i
+		}
+	}
+	return s
+}
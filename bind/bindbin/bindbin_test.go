@@ -0,0 +1,221 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindbin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/bindtest"
+	"github.com/prometheus-community/bind_exporter/fixtures"
+)
+
+func roundTrip(t *testing.T, s bind.Statistics) bind.Statistics {
+	t.Helper()
+	var b bytes.Buffer
+	if err := EncodeBinary(&b, s); err != nil {
+		t.Fatal(err)
+	}
+	got, err := DecodeBinary(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+// fixtureStats returns the statistics of the fixture file, see
+// bindtest.FixtureStatistics, without the monotonic clock reading of the
+// fetch time, which is not encoded.
+func fixtureStats(t *testing.T, file string) (bind.Statistics, bool) {
+	t.Helper()
+	s, ok := bindtest.FixtureStatistics(t, file)
+	s.Source.FetchTime = s.Source.FetchTime.Round(0)
+	return s, ok
+}
+
+func TestRoundTripFixtures(t *testing.T) {
+	files, err := fs.Glob(fixtures.FS, "*/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for _, file := range files {
+		s, ok := fixtureStats(t, file)
+		if !ok {
+			continue
+		}
+		n++
+		if got := roundTrip(t, s); !reflect.DeepEqual(s, got) {
+			t.Errorf("%s: want %+v, got %+v", file, s, got)
+		}
+	}
+	if n == 0 {
+		t.Error("want fixtures")
+	}
+}
+
+// fill sets every value reachable from v to a value other than its zero
+// value, with slices and maps of one element.
+func fill(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(time.Time{}) {
+			v.Set(reflect.ValueOf(time.Date(2024, 3, 15, 8, 12, 43, 118e6, time.UTC)))
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				fill(v.Field(i))
+			}
+		}
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		fill(v.Index(0))
+	case reflect.Map:
+		if v.Type().Elem().Kind() == reflect.Interface {
+			// Extensions are opaque and not encoded.
+			return
+		}
+		k := reflect.New(v.Type().Key()).Elem()
+		e := reflect.New(v.Type().Elem()).Elem()
+		fill(k)
+		fill(e)
+		v.Set(reflect.MakeMap(v.Type()))
+		v.SetMapIndex(k, e)
+	case reflect.String:
+		v.SetString("x")
+	case reflect.Uint64:
+		v.SetUint(math.MaxUint64)
+	case reflect.Int, reflect.Int64:
+		v.SetInt(math.MinInt64)
+	case reflect.Float64:
+		v.SetFloat(math.Inf(1))
+	default:
+		panic(fmt.Sprintf("unsupported kind %s", v.Kind()))
+	}
+}
+
+func TestRoundTripAllFields(t *testing.T) {
+	var s bind.Statistics
+	fill(reflect.ValueOf(&s).Elem())
+	if got := roundTrip(t, s); !reflect.DeepEqual(s, got) {
+		t.Errorf("want %+v, got %+v", s, got)
+	}
+
+	empty := bind.Statistics{Views: []bind.View{}, ZoneViews: []bind.ZoneView{{ZoneData: []bind.ZoneCounter{{ZoneStats: []bind.Counter{}}}}}}
+	if got := roundTrip(t, empty); !reflect.DeepEqual(empty, got) {
+		t.Errorf("want empty slices kept, got %+v", got)
+	}
+	if got := roundTrip(t, bind.Statistics{}); !reflect.DeepEqual(bind.Statistics{}, got) {
+		t.Errorf("want nil slices kept, got %+v", got)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	var b bytes.Buffer
+	if err := EncodeBinary(&b, largeStatistics(3)); err != nil {
+		t.Fatal(err)
+	}
+	enc := b.Bytes()
+
+	if _, err := DecodeBinary(bytes.NewReader(append([]byte{Version + 1}, enc[1:]...))); !errors.Is(err, ErrUnknownVersion) {
+		t.Errorf("want unknown version error, got %v", err)
+	}
+	if _, err := DecodeBinary(bytes.NewReader(enc[:len(enc)/2])); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("want unexpected EOF for truncated input, got %v", err)
+	}
+	if _, err := DecodeBinary(bytes.NewReader([]byte{Version, 0xff, 0xff, 0xff, 0xff, 0x0f})); err == nil {
+		t.Error("want error for excessive string length")
+	}
+}
+
+// largeStatistics returns statistics of a server with n zones.
+func largeStatistics(n int) bind.Statistics {
+	s := bind.Statistics{
+		Source: bind.Source{Format: bind.FormatJSONv1, FetchTime: time.Date(2024, 3, 15, 8, 0, 0, 0, time.UTC)},
+		Server: bind.Server{IncomingQueries: []bind.Counter{{Name: "A", Counter: 128417}}},
+	}
+	results := []string{"QrySuccess", "QryAuthAns", "QryNoauthAns", "QryReferral", "QryNxrrset", "QrySERVFAIL", "QryFORMERR", "QryNXDOMAIN"}
+	zones := make([]bind.ZoneCounter, n)
+	for i := range zones {
+		z := bind.ZoneCounter{Name: fmt.Sprintf("zone%d.example", i), Serial: "2024031501"}
+		for j, r := range results {
+			z.QueryResults = append(z.QueryResults, bind.Counter{Name: r, Counter: uint64(i * j)})
+		}
+		z.IncomingQueries = []bind.Counter{{Name: "A", Counter: uint64(i)}, {Name: "AAAA", Counter: uint64(i / 2)}}
+		zones[i] = z
+	}
+	s.ZoneViews = []bind.ZoneView{{Name: "_default", ZoneData: zones}}
+	return s
+}
+
+func BenchmarkEncode(b *testing.B) {
+	s := largeStatistics(100000)
+	for _, bc := range []struct {
+		name   string
+		encode func(io.Writer, bind.Statistics) error
+	}{
+		{"binary", EncodeBinary},
+		{"json", func(w io.Writer, s bind.Statistics) error { return json.NewEncoder(w).Encode(s) }},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			var buf bytes.Buffer
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				buf.Reset()
+				if err := bc.encode(&buf, s); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(buf.Len()), "bytes/op")
+		})
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	s := largeStatistics(100000)
+	var bin, js bytes.Buffer
+	if err := EncodeBinary(&bin, s); err != nil {
+		b.Fatal(err)
+	}
+	if err := json.NewEncoder(&js).Encode(s); err != nil {
+		b.Fatal(err)
+	}
+	b.Run("binary", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := DecodeBinary(bytes.NewReader(bin.Bytes())); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("json", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var s bind.Statistics
+			if err := json.Unmarshal(js.Bytes(), &s); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
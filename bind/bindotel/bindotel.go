@@ -0,0 +1,121 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bindotel provides OpenTelemetry tracing of bind clients. It is kept
+// separate so that the bind package does not depend on OpenTelemetry.
+package bindotel
+
+import (
+	"context"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/prometheus-community/bind_exporter/bind/bindotel"
+
+// Span names and attribute keys recorded by the Client.
+const (
+	StatsSpan  = "bind.Stats"
+	FetchSpan  = "bind.fetch"
+	DecodeSpan = "bind.decode"
+
+	GroupsKey     = attribute.Key("bind.groups")
+	GroupKey      = attribute.Key("bind.group")
+	EndpointKey   = attribute.Key("bind.endpoint")
+	StatusCodeKey = attribute.Key("http.status_code")
+	BytesKey      = attribute.Key("bind.bytes")
+	ZonesKey      = attribute.Key("bind.zones")
+	// SectionBytesPrefix prefixes the name of a section of the decoded
+	// document to form the key of its size, see bind.DecodeInfo.SectionBytes,
+	// e.g. "bind.section_bytes.taskmgr".
+	SectionBytesPrefix = "bind.section_bytes."
+)
+
+// Client implements bind.Client and records a span for every Stats call of
+// the wrapped client. Every document fetched during the call is recorded as
+// a child span, which in turn has a child span for decoding the document.
+type Client struct {
+	client bind.Client
+	tracer trace.Tracer
+}
+
+// NewClient returns a Client tracing c with a tracer from tp. The global
+// TracerProvider is used if tp is nil.
+func NewClient(c bind.Client, tp trace.TracerProvider) *Client {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return &Client{
+		client: c,
+		tracer: tp.Tracer(instrumentationName),
+	}
+}
+
+// Stats implements bind.Client.
+func (c *Client) Stats(ctx context.Context, groups ...bind.StatisticGroup) (bind.Statistics, error) {
+	names := make([]string, 0, len(groups))
+	for _, g := range groups {
+		names = append(names, string(g))
+	}
+	ctx, span := c.tracer.Start(ctx, StatsSpan, trace.WithAttributes(GroupsKey.StringSlice(names)))
+	defer span.End()
+
+	ctx = bind.WithClientTrace(ctx, &bind.ClientTrace{
+		GetStart: func(ctx context.Context, g bind.StatisticGroup, url string) context.Context {
+			ctx, _ = c.tracer.Start(ctx, FetchSpan, trace.WithAttributes(
+				GroupKey.String(string(g)),
+				EndpointKey.String(url),
+			))
+			return ctx
+		},
+		GetDone: func(ctx context.Context, g bind.StatisticGroup, info bind.RequestInfo, err error) {
+			span := trace.SpanFromContext(ctx)
+			if info.StatusCode != 0 {
+				span.SetAttributes(StatusCodeKey.Int(info.StatusCode))
+			}
+			span.SetAttributes(BytesKey.Int64(info.Bytes))
+			end(span, err)
+		},
+		DecodeStart: func(ctx context.Context, g bind.StatisticGroup) context.Context {
+			ctx, _ = c.tracer.Start(ctx, DecodeSpan, trace.WithAttributes(GroupKey.String(string(g))))
+			return ctx
+		},
+		DecodeDone: func(ctx context.Context, g bind.StatisticGroup, info bind.DecodeInfo, err error) {
+			span := trace.SpanFromContext(ctx)
+			span.SetAttributes(ZonesKey.Int(info.Zones))
+			for k, n := range info.SectionBytes {
+				span.SetAttributes(attribute.Int64(SectionBytesPrefix+k, n))
+			}
+			end(span, err)
+		},
+	})
+
+	s, err := c.client.Stats(ctx, groups...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return s, err
+}
+
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
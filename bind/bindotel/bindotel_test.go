@@ -0,0 +1,114 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindotel_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/bindotel"
+	"github.com/prometheus-community/bind_exporter/bind/json"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestClient(t *testing.T) {
+	m := map[string]string{
+		"/json/v1/server": "../../fixtures/json/server.json",
+		"/json/v1/tasks":  "../../fixtures/json/tasks.json",
+		"/json/v1/zones":  "../../fixtures/json/zones.json",
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if f, ok := m[r.URL.Path]; ok {
+			http.ServeFile(w, r, f)
+		} else {
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	c := bindotel.NewClient(json.NewClient(ts.URL, http.DefaultClient), tp)
+
+	if _, err := c.Stats(context.Background(), bind.ServerStats, bind.TaskStats); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := sr.Ended()
+	byID := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range spans {
+		byID[s.SpanContext().SpanID().String()] = s
+	}
+	// Render every span as "parent/name(group)" to assert the structure.
+	var got []string
+	zones := -1
+	for _, s := range spans {
+		name := s.Name()
+		if p, ok := byID[s.Parent().SpanID().String()]; ok {
+			name = p.Name() + "/" + name
+		}
+		for _, kv := range s.Attributes() {
+			switch kv.Key {
+			case bindotel.GroupKey:
+				name += "(" + kv.Value.AsString() + ")"
+			case bindotel.ZonesKey:
+				if s.Name() == bindotel.DecodeSpan && hasAttr(s, bindotel.GroupKey.String(string(bind.ViewStats))) {
+					zones = int(kv.Value.AsInt64())
+				}
+			case bindotel.StatusCodeKey:
+				if kv.Value.AsInt64() != http.StatusOK {
+					t.Errorf("unexpected status code %d on span %s", kv.Value.AsInt64(), s.Name())
+				}
+			}
+		}
+		got = append(got, name)
+	}
+
+	want := []string{
+		"bind.fetch/bind.decode(server)",
+		"bind.Stats/bind.fetch(server)",
+		"bind.fetch/bind.decode(view)",
+		"bind.Stats/bind.fetch(view)",
+		"bind.fetch/bind.decode(tasks)",
+		"bind.Stats/bind.fetch(tasks)",
+		"bind.Stats",
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want spans %q, got %q", want, got)
+	}
+	if zones != 1 {
+		t.Errorf("want 1 decoded zone, got %d", zones)
+	}
+}
+
+func hasAttr(s sdktrace.ReadOnlySpan, want attribute.KeyValue) bool {
+	for _, kv := range s.Attributes() {
+		if kv == want {
+			return true
+		}
+	}
+	return false
+}
+
+func ExampleNewClient() {
+	tp := sdktrace.NewTracerProvider()
+	c := bindotel.NewClient(json.NewClient("http://localhost:8053/", http.DefaultClient), tp)
+	c.Stats(context.Background(), bind.ServerStats, bind.ViewStats)
+}
@@ -0,0 +1,276 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bindrelay re-serves the statistics of BIND servers over HTTP, for
+// networks in which the monitoring system cannot reach the statistics
+// channels directly. A Relay runs next to named, polls it and serves the
+// latest statistics.
+package bindrelay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/exposition"
+)
+
+// TargetParam is the query parameter selecting the target of a request. It
+// may be omitted if the Relay has a single target.
+const TargetParam = "target"
+
+// Relay is an http.Handler serving the latest statistics of its targets:
+//
+//   - /stats serves the statistics as JSON,
+//   - /metrics serves them in the OpenMetrics text format,
+//   - /healthz answers 200 OK if the last poll succeeded and 503 Service
+//     Unavailable otherwise.
+//
+// /stats and /metrics answer 503 Service Unavailable until the first poll of
+// the target succeeded, and serve the statistics of the last successful poll
+// afterwards. /metrics includes the time every group has last been fetched
+// and the time of the request as the time it has been served, see
+// exposition.WriteOpenMetricsWithFreshness. /healthz reports all targets
+// unless a target is selected.
+type Relay struct {
+	targets map[string]*target
+	names   []string
+	mux     *http.ServeMux
+	clock   bind.Clock
+}
+
+type target struct {
+	poller    *bind.Poller
+	freshness *bind.FreshnessClient
+
+	mu     sync.Mutex
+	sample *bind.Sample
+	err    error
+	polled bool
+}
+
+// Target is a server polled by a Relay.
+type Target struct {
+	Client bind.Client
+	// Options configure the poller of the target, after the options common
+	// to all targets, e.g. with the state file or the seed of the target,
+	// see bind.WithStateFile and bind.WithPollSeed.
+	Options []bind.PollerOption
+}
+
+// New returns a Relay polling targets, keyed by target name, every interval.
+// The options configure the pollers of all targets. It returns an error if
+// targets share a state file, whose samples they would overwrite.
+func New(targets map[string]Target, interval time.Duration, opts ...bind.PollerOption) (*Relay, error) {
+	r := &Relay{targets: map[string]*target{}, mux: http.NewServeMux()}
+	// The freshness clients share the Clock set by opts, see
+	// bind.WithPollClock.
+	r.clock = bind.NewPoller(nil, interval, opts...).Clock()
+	for name := range targets {
+		r.names = append(r.names, name)
+	}
+	sort.Strings(r.names)
+	stateFiles := map[string]string{}
+	for _, name := range r.names {
+		t := targets[name]
+		fc := bind.NewFreshnessClient(t.Client, bind.WithClock(r.clock))
+		p := bind.NewPoller(fc, interval, append(append([]bind.PollerOption{}, opts...), t.Options...)...)
+		if f := p.StateFile(); f != "" {
+			if other, ok := stateFiles[f]; ok {
+				return nil, fmt.Errorf("targets %q and %q share the state file %s", other, name, f)
+			}
+			stateFiles[f] = name
+		}
+		r.targets[name] = &target{poller: p, freshness: fc}
+	}
+	r.mux.HandleFunc("/stats", r.serveStats)
+	r.mux.HandleFunc("/metrics", r.serveMetrics)
+	r.mux.HandleFunc("/healthz", r.serveHealth)
+	return r, nil
+}
+
+// Run polls the targets until ctx is done, see bind.Poller.Run, and returns
+// the error of ctx.
+func (r *Relay) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, t := range r.targets {
+		wg.Add(1)
+		go func(t *target) {
+			defer wg.Done()
+			t.poller.Run(ctx, t.record)
+		}(t)
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// Refresh polls all targets once and waits for the results.
+func (r *Relay) Refresh(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, t := range r.targets {
+		wg.Add(1)
+		go func(t *target) {
+			defer wg.Done()
+			t.record(t.poller.Poll(ctx))
+		}(t)
+	}
+	wg.Wait()
+}
+
+// ListenAndServe serves the Relay on addr and polls its targets until ctx is
+// done.
+func (r *Relay) ListenAndServe(ctx context.Context, addr string) error {
+	srv := &http.Server{Addr: addr, Handler: r, ReadHeaderTimeout: 10 * time.Second}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go r.Run(ctx)
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return ctx.Err()
+}
+
+func (t *target) record(s bind.Sample, err error) {
+	if errors.Is(err, bind.ErrPollSkipped) {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.polled = true
+	t.err = err
+	if err == nil {
+		t.sample = &s
+	}
+}
+
+// state returns the last successful sample, whether the target has been
+// polled and the error of the last poll.
+func (t *target) state() (*bind.Sample, bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.sample, t.polled, t.err
+}
+
+// ServeHTTP implements http.Handler.
+func (r *Relay) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mux.ServeHTTP(w, req)
+}
+
+// target returns the target selected by req, or writes an error and returns
+// nil.
+func (r *Relay) target(w http.ResponseWriter, req *http.Request) *target {
+	name := req.URL.Query().Get(TargetParam)
+	if name == "" && len(r.names) == 1 {
+		name = r.names[0]
+	}
+	t, ok := r.targets[name]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown target %q", name), http.StatusNotFound)
+		return nil
+	}
+	return t
+}
+
+// sample returns the target selected by req and its last sample, or writes
+// an error and returns a nil sample.
+func (r *Relay) sample(w http.ResponseWriter, req *http.Request) (*target, *bind.Sample) {
+	t := r.target(w, req)
+	if t == nil {
+		return nil, nil
+	}
+	s, _, err := t.state()
+	if s == nil {
+		msg := "statistics not yet fetched"
+		if err != nil {
+			msg += ": " + err.Error()
+		}
+		http.Error(w, msg, http.StatusServiceUnavailable)
+		return t, nil
+	}
+	w.Header().Set("Last-Modified", s.Time.UTC().Format(http.TimeFormat))
+	return t, s
+}
+
+func (r *Relay) serveStats(w http.ResponseWriter, req *http.Request) {
+	_, s := r.sample(w, req)
+	if s == nil {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.Stats)
+}
+
+func (r *Relay) serveMetrics(w http.ResponseWriter, req *http.Request) {
+	t, s := r.sample(w, req)
+	if s == nil {
+		return
+	}
+	// The relay serves the statistics of the last poll now, which may
+	// have been fetched long ago.
+	fr := t.freshness.Freshness()
+	now := r.clock.Now()
+	for g, f := range fr {
+		if !f.ServedAt.IsZero() {
+			f.ServedAt = now
+			fr[g] = f
+		}
+	}
+	w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+	exposition.WriteOpenMetricsWithFreshness(w, s.Stats, fr)
+}
+
+func (r *Relay) serveHealth(w http.ResponseWriter, req *http.Request) {
+	names := r.names
+	if req.URL.Query().Has(TargetParam) || len(names) == 1 {
+		t := r.target(w, req)
+		if t == nil {
+			return
+		}
+		for _, n := range names {
+			if r.targets[n] == t {
+				names = []string{n}
+			}
+		}
+	}
+
+	status := http.StatusOK
+	var lines []string
+	for _, n := range names {
+		_, polled, err := r.targets[n].state()
+		switch {
+		case !polled:
+			status = http.StatusServiceUnavailable
+			lines = append(lines, n+": not yet polled")
+		case err != nil:
+			status = http.StatusServiceUnavailable
+			lines = append(lines, n+": "+err.Error())
+		default:
+			lines = append(lines, n+": ok")
+		}
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	for _, l := range lines {
+		fmt.Fprintln(w, l)
+	}
+}
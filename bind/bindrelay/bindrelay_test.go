@@ -0,0 +1,164 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindrelay
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/bindtest"
+	bindjson "github.com/prometheus-community/bind_exporter/bind/json"
+	"github.com/prometheus-community/bind_exporter/bind/xml"
+)
+
+func get(t *testing.T, url string) (int, string, http.Header) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(b), resp.Header
+}
+
+func TestRelay(t *testing.T) {
+	named := bindtest.NewServer()
+	defer named.Close()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusBadGateway)
+	}))
+	defer down.Close()
+
+	dir := t.TempDir()
+	relay, err := New(map[string]Target{
+		"ns1":  {Client: xml.NewClient(named.URL, nil), Options: []bind.PollerOption{bind.WithStateFile(filepath.Join(dir, "ns1.json"))}},
+		"ns2":  {Client: bindjson.NewClient(named.URL, nil), Options: []bind.PollerOption{bind.WithStateFile(filepath.Join(dir, "ns2.json"))}},
+		"down": {Client: bindjson.NewClient(down.URL, nil)},
+	}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(relay)
+	defer ts.Close()
+
+	if code, _, _ := get(t, ts.URL+"/stats?target=ns1"); code != http.StatusServiceUnavailable {
+		t.Errorf("want 503 before the first poll, got %d", code)
+	}
+	relay.Refresh(context.Background())
+
+	code, body, header := get(t, ts.URL+"/stats?target=ns1")
+	if code != http.StatusOK {
+		t.Fatalf("want 200, got %d: %s", code, body)
+	}
+	var s bind.Statistics
+	if err := json.Unmarshal([]byte(body), &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Source.Format != bind.FormatXMLv3 || len(s.Server.IncomingQueries) == 0 {
+		t.Errorf("want server statistics of ns1, got %+v", s)
+	}
+	if header.Get("Last-Modified") == "" {
+		t.Error("want Last-Modified header")
+	}
+
+	code, body, header = get(t, ts.URL+"/metrics?target=ns2")
+	if code != http.StatusOK || !strings.Contains(body, "bind_incoming_queries_total{") || !strings.HasSuffix(body, "# EOF\n") {
+		t.Errorf("want OpenMetrics of ns2, got %d:\n%s", code, body)
+	}
+	if ct := header.Get("Content-Type"); !strings.HasPrefix(ct, "application/openmetrics-text") {
+		t.Errorf("want OpenMetrics content type, got %q", ct)
+	}
+	for _, name := range []string{`bind_group_fetched_timestamp_seconds{group="server"}`, `bind_group_served_timestamp_seconds{group="server"}`} {
+		if !strings.Contains(body, name+" ") {
+			t.Errorf("want freshness gauge %s, got:\n%s", name, body)
+		}
+	}
+
+	for _, tc := range []struct {
+		path string
+		code int
+		body string
+	}{
+		{path: "/healthz?target=ns1", code: http.StatusOK, body: "ns1: ok\n"},
+		{path: "/healthz?target=down", code: http.StatusServiceUnavailable, body: "502 Bad Gateway"},
+		{path: "/healthz", code: http.StatusServiceUnavailable, body: "ns1: ok\nns2: ok\n"},
+		{path: "/healthz?target=ns3", code: http.StatusNotFound},
+		{path: "/stats?target=down", code: http.StatusServiceUnavailable, body: "not yet fetched"},
+		{path: "/stats", code: http.StatusNotFound},
+	} {
+		code, body, _ := get(t, ts.URL+tc.path)
+		if code != tc.code || !strings.Contains(body, tc.body) {
+			t.Errorf("%s: want %d with %q, got %d: %s", tc.path, tc.code, tc.body, code, body)
+		}
+	}
+}
+
+func TestRelaySingleTarget(t *testing.T) {
+	named := bindtest.NewServer()
+	defer named.Close()
+	relay, err := New(map[string]Target{"ns1": {Client: bindjson.NewClient(named.URL, nil)}}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(relay)
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- relay.Run(ctx) }()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		code, body, _ := get(t, ts.URL+"/healthz")
+		if code == http.StatusOK {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("want target polled by Run, got %d: %s", code, body)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if code, _, _ := get(t, ts.URL+"/stats"); code != http.StatusOK {
+		t.Errorf("want single target selected by default, got %d", code)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("want context canceled, got %v", err)
+	}
+}
+
+func TestRelaySharedStateFile(t *testing.T) {
+	state := bind.WithStateFile(filepath.Join(t.TempDir(), "state.json"))
+	targets := map[string]Target{
+		"ns1": {Client: bindjson.NewClient("http://ns1:8053", nil)},
+		"ns2": {Client: bindjson.NewClient("http://ns2:8053", nil)},
+	}
+	if _, err := New(targets, time.Minute, state); err == nil {
+		t.Error("want error for targets sharing a state file")
+	}
+	if _, err := New(map[string]Target{"ns1": targets["ns1"]}, time.Minute, state); err != nil {
+		t.Errorf("want state file of single target, got %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindtest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"text/tabwriter"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+)
+
+// neighbors is the number of counters listed before and after the asserted
+// counter in failure messages.
+const neighbors = 3
+
+// AssertCounterDelta reports an error to t unless the server counter name of
+// section increased by want from before to after, e.g. by 100 after sending
+// 100 queries for nsstats QryUDP. Sections are named like those of
+// bind.IndexedStats, e.g. "nsstats" or "qtypes", and counters by their
+// normalized name, see bind.NormalizeCounterName. BIND omits counters which
+// are zero, so missing counters count as zero. The error lists the counters
+// of the section next to name. It returns whether the assertion held.
+func AssertCounterDelta(t testing.TB, before, after bind.Statistics, section, name string, want uint64) bool {
+	t.Helper()
+	return assertCounter(t, before, after, section, name, fmt.Sprint(want), func(d uint64) bool {
+		return d == want
+	})
+}
+
+// AssertCounterAtLeast is like AssertCounterDelta, but accepts any increase of
+// at least min, e.g. for counters also counting the queries of other clients.
+func AssertCounterAtLeast(t testing.TB, before, after bind.Statistics, section, name string, min uint64) bool {
+	t.Helper()
+	return assertCounter(t, before, after, section, name, fmt.Sprintf("at least %d", min), func(d uint64) bool {
+		return d >= min
+	})
+}
+
+// AssertCounterWithin is like AssertCounterDelta, but accepts an increase
+// differing from want by up to tolerance, e.g. for queries sent over UDP
+// which may be lost.
+func AssertCounterWithin(t testing.TB, before, after bind.Statistics, section, name string, want, tolerance uint64) bool {
+	t.Helper()
+	return assertCounter(t, before, after, section, name, fmt.Sprintf("%d±%d", want, tolerance), func(d uint64) bool {
+		if d > want {
+			return d-want <= tolerance
+		}
+		return want-d <= tolerance
+	})
+}
+
+// AssertSerialAdvanced reports an error to t unless the serial of zone in view
+// is greater in after than in before, compared with the serial number
+// arithmetic of RFC 1982 like bind.SerialDrift. It returns whether the
+// assertion held.
+func AssertSerialAdvanced(t testing.TB, before, after bind.Statistics, view, zone string) bool {
+	t.Helper()
+	from, ok := serial(before, view, zone)
+	if !ok {
+		t.Errorf("zone %s of view %s missing before", zone, view)
+		return false
+	}
+	to, ok := serial(after, view, zone)
+	if !ok {
+		t.Errorf("zone %s of view %s missing after", zone, view)
+		return false
+	}
+	r := bind.SerialDrift(before, map[string]bind.Statistics{"after": after})
+	for _, z := range r.Zones {
+		if z.View == view && bind.ZoneKey(z.Zone) == bind.ZoneKey(zone) && z.Secondaries[0].State == bind.SerialAhead {
+			return true
+		}
+	}
+	t.Errorf("serial of zone %s of view %s did not advance: %s before, %s after", zone, view, from, to)
+	return false
+}
+
+func serial(s bind.Statistics, view, zone string) (string, bool) {
+	for _, z := range s.Zone(zone) {
+		if z.View == view {
+			return z.Zone.Serial, true
+		}
+	}
+	return "", false
+}
+
+// assertCounter reports an error to t unless ok accepts the increase of the
+// server counter name of section, which is expected to be want.
+func assertCounter(t testing.TB, before, after bind.Statistics, section, name, want string, ok func(uint64) bool) bool {
+	t.Helper()
+	prev, cur := before.Indexed().Server[section], after.Indexed().Server[section]
+	var got string
+	if p, c := prev[name], cur[name]; c < p {
+		got = fmt.Sprintf("decreased by %d", p-c)
+	} else if ok(c - p) {
+		return true
+	} else {
+		got = fmt.Sprintf("increased by %d", c-p)
+	}
+	t.Errorf("%s %s %s, want increase of %s\n%s", section, name, got, want, neighborhood(prev, cur, name))
+	return false
+}
+
+// neighborhood formats the counters of a section next to name before and
+// after, marking name, which is listed even if missing.
+func neighborhood(prev, cur map[string]uint64, name string) string {
+	seen := map[string]bool{name: true}
+	names := []string{name}
+	for _, m := range []map[string]uint64{prev, cur} {
+		for n := range m {
+			if !seen[n] {
+				seen[n] = true
+				names = append(names, n)
+			}
+		}
+	}
+	sort.Strings(names)
+	i := sort.SearchStrings(names, name)
+	lo, hi := i-neighbors, i+neighbors+1
+	if lo < 0 {
+		lo = 0
+	}
+	if hi > len(names) {
+		hi = len(names)
+	}
+
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tcounter\tbefore\tafter\tdelta")
+	for _, n := range names[lo:hi] {
+		mark := ""
+		if n == name {
+			mark = ">"
+		}
+		p, c := prev[n], cur[n]
+		delta := fmt.Sprintf("+%d", c-p)
+		if c < p {
+			delta = fmt.Sprintf("-%d", p-c)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\n", mark, n, p, c, delta)
+	}
+	w.Flush()
+	return b.String()
+}
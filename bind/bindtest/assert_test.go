@@ -0,0 +1,144 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+)
+
+// recorder records the errors reported by the assertions instead of failing
+// the test.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func build(t *testing.T, nsstats map[string]uint64, serial string) bind.Statistics {
+	t.Helper()
+	s, err := bind.NewStatisticsBuilder().
+		Server("nsstats", nsstats).
+		View("_default").Zone("example.com", serial).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestAssertions(t *testing.T) {
+	before := build(t, map[string]uint64{
+		"ReqEdns0": 10, "Requestv4": 100, "Response": 100, "QryAuthAns": 90, "QrySuccess": 80,
+		"QryUDP": 95, "QryTCP": 5, "TruncatedResp": 1,
+	}, "2024031501")
+	after := build(t, map[string]uint64{
+		"ReqEdns0": 10, "Requestv4": 200, "Response": 200, "QryAuthAns": 190, "QrySuccess": 180,
+		"QryUDP": 193, "QryTCP": 7, "TruncatedResp": 1, "QryDropped": 2,
+	}, "2024031502")
+
+	for _, tc := range []struct {
+		name   string
+		assert func(t testing.TB) bool
+		want   string
+	}{
+		{
+			name:   "delta",
+			assert: func(t testing.TB) bool { return AssertCounterDelta(t, before, after, "nsstats", "QryUDP", 98) },
+		},
+		{
+			name:   "at least",
+			assert: func(t testing.TB) bool { return AssertCounterAtLeast(t, before, after, "nsstats", "Requestv4", 90) },
+		},
+		{
+			name:   "within",
+			assert: func(t testing.TB) bool { return AssertCounterWithin(t, before, after, "nsstats", "QryUDP", 100, 2) },
+		},
+		{
+			name:   "missing counter",
+			assert: func(t testing.TB) bool { return AssertCounterDelta(t, before, after, "nsstats", "QryNXDOMAIN", 0) },
+		},
+		{
+			name:   "serial",
+			assert: func(t testing.TB) bool { return AssertSerialAdvanced(t, before, after, "_default", "example.com.") },
+		},
+		{
+			name:   "delta failure",
+			assert: func(t testing.TB) bool { return AssertCounterDelta(t, before, after, "nsstats", "QryUDP", 100) },
+			want: "nsstats QryUDP increased by 98, want increase of 100\n" +
+				"   counter     before  after  delta\n" +
+				"   QryDropped  0       2      +2\n" +
+				"   QrySuccess  80      180    +100\n" +
+				"   QryTCP      5       7      +2\n" +
+				">  QryUDP      95      193    +98\n" +
+				"   ReqEdns0    10      10     +0\n" +
+				"   Requestv4   100     200    +100\n" +
+				"   Response    100     200    +100\n",
+		},
+		{
+			name:   "at least failure",
+			assert: func(t testing.TB) bool { return AssertCounterAtLeast(t, after, before, "nsstats", "TruncatedResp", 1) },
+			want: "nsstats TruncatedResp increased by 0, want increase of at least 1\n" +
+				"   counter        before  after  delta\n" +
+				"   ReqEdns0       10      10     +0\n" +
+				"   Requestv4      200     100    -100\n" +
+				"   Response       200     100    -100\n" +
+				">  TruncatedResp  1       1      +0\n",
+		},
+		{
+			name:   "within failure",
+			assert: func(t testing.TB) bool { return AssertCounterWithin(t, after, before, "nsstats", "QryTCP", 2, 1) },
+			want: "nsstats QryTCP decreased by 2, want increase of 2±1\n" +
+				"   counter     before  after  delta\n" +
+				"   QryAuthAns  190     90     -100\n" +
+				"   QryDropped  2       0      -2\n" +
+				"   QrySuccess  180     80     -100\n" +
+				">  QryTCP      7       5      -2\n" +
+				"   QryUDP      193     95     -98\n" +
+				"   ReqEdns0    10      10     +0\n" +
+				"   Requestv4   200     100    -100\n",
+		},
+		{
+			name:   "serial failure",
+			assert: func(t testing.TB) bool { return AssertSerialAdvanced(t, after, before, "_default", "example.com") },
+			want:   "serial of zone example.com of view _default did not advance: 2024031502 before, 2024031501 after",
+		},
+		{
+			name:   "missing zone",
+			assert: func(t testing.TB) bool { return AssertSerialAdvanced(t, before, after, "internal", "example.com") },
+			want:   "zone example.com of view internal missing before",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &recorder{TB: t}
+			ok := tc.assert(r)
+			if ok != (tc.want == "") {
+				t.Errorf("want assertion to hold %t, got %t", tc.want == "", ok)
+			}
+			switch {
+			case tc.want == "" && len(r.errors) != 0:
+				t.Errorf("want no error, got %q", r.errors)
+			case tc.want != "" && (len(r.errors) != 1 || r.errors[0] != tc.want):
+				t.Errorf("want error\n%s\ngot %q", tc.want, r.errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bindtest provides a fake BIND statistics channel for tests of code
+// using the bind packages, and assertions comparing the statistics of a server
+// taken before and after an action, for integration tests of named itself.
+package bindtest
+
+import (
+	"context"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/json"
+	"github.com/prometheus-community/bind_exporter/bind/xml"
+	"github.com/prometheus-community/bind_exporter/fixtures"
+)
+
+// documents maps the paths served by Handler to fixture files.
+var documents = map[string]string{
+	json.ServerPath: "json/server.json",
+	json.StatusPath: "json/status.json",
+	json.TasksPath:  "json/tasks.json",
+	json.ZonesPath:  "json/zones.json",
+	xml.ServerPath:  "xml/server.xml",
+	xml.StatusPath:  "xml/status.xml",
+	xml.TasksPath:   "xml/tasks.xml",
+	xml.ZonesPath:   "xml/zones.xml",
+}
+
+// Handler returns a handler serving the server, tasks and zones documents of
+// the JSON v1 and XML v3 APIs, plus their status documents. Other paths return
+// 404.
+func Handler() http.Handler {
+	return HandlerWith(nil)
+}
+
+// HandlerWith is like Handler, but serves the fixture files of overrides for
+// their paths, e.g. "xml/zones-hot.xml" for xml.ZonesPath.
+func HandlerWith(overrides map[string]string) http.Handler {
+	docs := make(map[string]string, len(documents)+len(overrides))
+	for p, f := range documents {
+		docs[p] = f
+	}
+	for p, f := range overrides {
+		docs[p] = f
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, ok := docs[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		b, err := fs.ReadFile(fixtures.FS, f)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if path.Ext(f) == ".json" {
+			w.Header().Set("Content-Type", "application/json")
+		} else {
+			w.Header().Set("Content-Type", "text/xml")
+		}
+		w.Write(b)
+	})
+}
+
+// NewServer returns a started server running Handler. The caller should call
+// Close when finished.
+func NewServer() *httptest.Server {
+	return httptest.NewServer(Handler())
+}
+
+// NewServerWith returns a started server running HandlerWith(overrides). The
+// caller should call Close when finished.
+func NewServerWith(overrides map[string]string) *httptest.Server {
+	return httptest.NewServer(HandlerWith(overrides))
+}
+
+// FixtureStatistics returns the statistics of the server, view and task
+// groups decoded from the fixture file by the client of its format, with file
+// served for the document of its kind, e.g. the server document for
+// "xml/server-paired.xml", and the default fixtures for the other documents.
+// It returns false for the fixtures of other documents, and reports an error
+// to t if the statistics cannot be decoded.
+func FixtureStatistics(t testing.TB, file string) (bind.Statistics, bool) {
+	t.Helper()
+	dir, name := path.Split(file)
+	var paths [2]string
+	switch {
+	case strings.HasPrefix(name, "server"):
+		paths = [2]string{xml.ServerPath, json.ServerPath}
+	case strings.HasPrefix(name, "zone"):
+		paths = [2]string{xml.ZonesPath, json.ZonesPath}
+	case strings.HasPrefix(name, "tasks"):
+		paths = [2]string{xml.TasksPath, json.TasksPath}
+	default:
+		return bind.Statistics{}, false
+	}
+	ts := NewServerWith(map[string]string{paths[0]: file, paths[1]: file})
+	defer ts.Close()
+
+	var c bind.Client = xml.NewClient(ts.URL, nil)
+	if dir == "json/" {
+		c = json.NewClient(ts.URL, nil)
+	}
+	s, err := c.Stats(context.Background(), bind.ServerStats, bind.ViewStats, bind.TaskStats)
+	if err != nil {
+		t.Errorf("%s: %s", file, err)
+		return bind.Statistics{}, false
+	}
+	return s, true
+}
@@ -0,0 +1,32 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindtest
+
+import (
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind/internal/clock"
+)
+
+// FakeClock is a bind.Clock whose time only changes with Advance, for tests
+// of time-dependent code without waiting, e.g. with bind.WithClock or
+// bind.WithPollClock. Its timers fire once the clock has been advanced past
+// their deadline; BlockUntil and BlockUntilDue wait for the code under test
+// to set a timer. It is safe for concurrent use by multiple goroutines.
+type FakeClock = clock.Fake
+
+// NewFakeClock returns a FakeClock at t.
+func NewFakeClock(t time.Time) *FakeClock {
+	return clock.NewFake(t)
+}
@@ -0,0 +1,188 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+// ErrCircuitOpen is returned by a CircuitBreakerClient while its circuit is
+// open, without querying the wrapped client.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// BreakerState is the state of a CircuitBreakerClient.
+type BreakerState int
+
+// States of a CircuitBreakerClient.
+const (
+	// BreakerClosed passes all calls to the wrapped client.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen fails all calls with ErrCircuitOpen.
+	BreakerOpen
+	// BreakerHalfOpen passes a single probe call to the wrapped client and
+	// fails the others with ErrCircuitOpen.
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// BreakerOpts configures a CircuitBreakerClient.
+type BreakerOpts struct {
+	// Failures is the number of consecutive failed calls which open the
+	// circuit. It defaults to 5.
+	Failures int
+	// CoolDown is the time the circuit stays open before a probe call is
+	// let through. It defaults to 30 seconds.
+	CoolDown time.Duration
+	// OnStateChange is called on every change of state, in order. It is
+	// called with the breaker locked and must not call the breaker. It may
+	// be nil.
+	OnStateChange func(from, to BreakerState)
+	// Logger receives a message for every change of state. Nothing is
+	// logged if nil.
+	Logger log.Logger
+	// Clock is the source of the time of the cool-down. It defaults to
+	// SystemClock.
+	Clock Clock
+}
+
+// CircuitBreakerClient is a Client which stops querying the wrapped client
+// after consecutive failures, so that a struggling statistics channel is not
+// flooded with requests. Once the circuit has been open for the cool-down
+// period, a single probe call is let through: the circuit closes if it
+// succeeds and opens again otherwise. Calls cancelled by their caller and
+// statistics truncated by the zone limit do not count as failures, whereas
+// calls exceeding the deadline of their context do, as the server has been
+// too slow to answer.
+type CircuitBreakerClient struct {
+	client Client
+	opts   BreakerOpts
+	now    func() time.Time
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	opened   time.Time
+	probing  bool
+}
+
+// NewCircuitBreakerClient returns a CircuitBreakerClient wrapping c.
+func NewCircuitBreakerClient(c Client, opts BreakerOpts) *CircuitBreakerClient {
+	if opts.Failures <= 0 {
+		opts.Failures = 5
+	}
+	if opts.CoolDown <= 0 {
+		opts.CoolDown = 30 * time.Second
+	}
+	if opts.Logger == nil {
+		opts.Logger = log.NewNopLogger()
+	}
+	if opts.Clock == nil {
+		opts.Clock = SystemClock()
+	}
+	return &CircuitBreakerClient{client: c, opts: opts, now: opts.Clock.Now}
+}
+
+// State returns the current state of the circuit.
+func (c *CircuitBreakerClient) State() BreakerState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state
+}
+
+// Stats implements Client. It returns ErrCircuitOpen while the circuit is
+// open.
+func (c *CircuitBreakerClient) Stats(ctx context.Context, groups ...StatisticGroup) (Statistics, error) {
+	probe, err := c.acquire()
+	if err != nil {
+		return Statistics{}, err
+	}
+	s, err := c.client.Stats(ctx, groups...)
+	c.release(ctx, probe, err)
+	return s, err
+}
+
+// acquire returns ErrCircuitOpen unless a call may pass, and reports whether
+// the call is the probe of a half-open circuit.
+func (c *CircuitBreakerClient) acquire() (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch c.state {
+	case BreakerOpen:
+		if c.now().Sub(c.opened) < c.opts.CoolDown {
+			return false, ErrCircuitOpen
+		}
+		c.setState(BreakerHalfOpen)
+	case BreakerHalfOpen:
+		if c.probing {
+			return false, ErrCircuitOpen
+		}
+	default:
+		return false, nil
+	}
+	c.probing = true
+	return true, nil
+}
+
+// release records the result of a call.
+func (c *CircuitBreakerClient) release(ctx context.Context, probe bool, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if probe {
+		c.probing = false
+	}
+	switch {
+	case err != nil && errors.Is(ctx.Err(), context.Canceled):
+		// Cancelled by the caller, which says nothing about the server.
+	case err != nil && !errors.Is(err, ErrTooManyZones):
+		if c.state == BreakerClosed {
+			c.failures++
+		}
+		if probe || c.state == BreakerClosed && c.failures >= c.opts.Failures {
+			c.opened = c.now()
+			c.setState(BreakerOpen)
+		}
+	default:
+		c.failures = 0
+		if probe {
+			c.setState(BreakerClosed)
+		}
+	}
+}
+
+// setState changes the state of the circuit, which must be locked.
+func (c *CircuitBreakerClient) setState(to BreakerState) {
+	from := c.state
+	c.state = to
+	level.Info(c.opts.Logger).Log("msg", "Circuit breaker changed state", "from", from, "to", to)
+	if c.opts.OnStateChange != nil {
+		c.opts.OnStateChange(from, to)
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyClient fails with err and counts its calls.
+type flakyClient struct {
+	mu    sync.Mutex
+	err   error
+	calls int
+	// block, if not nil, makes calls wait until it is closed.
+	block chan struct{}
+}
+
+func (c *flakyClient) Stats(ctx context.Context, groups ...StatisticGroup) (Statistics, error) {
+	c.mu.Lock()
+	c.calls++
+	err, block := c.err, c.block
+	c.mu.Unlock()
+	if block != nil {
+		<-block
+	}
+	return Statistics{}, err
+}
+
+func (c *flakyClient) set(err error, block chan struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err, c.block = err, block
+}
+
+func TestCircuitBreakerClient(t *testing.T) {
+	now := time.Unix(0, 0)
+	var transitions []string
+	inner := &flakyClient{err: errors.New("connection refused")}
+	c := NewCircuitBreakerClient(inner, BreakerOpts{
+		Failures: 3,
+		CoolDown: time.Minute,
+		OnStateChange: func(from, to BreakerState) {
+			transitions = append(transitions, from.String()+" -> "+to.String())
+		},
+	})
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if c.State() != BreakerClosed {
+			t.Fatalf("call %d: expected closed circuit, got %s", i, c.State())
+		}
+		if _, err := c.Stats(ctx); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("call %d: expected error of client, got %v", i, err)
+		}
+	}
+	if c.State() != BreakerOpen {
+		t.Fatalf("expected open circuit, got %s", c.State())
+	}
+
+	// The open circuit fails fast.
+	now = now.Add(59 * time.Second)
+	if _, err := c.Stats(ctx); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if inner.calls != 3 {
+		t.Fatalf("expected 3 calls of client, got %d", inner.calls)
+	}
+
+	// After the cool-down a single probe is let through.
+	now = now.Add(time.Second)
+	block := make(chan struct{})
+	inner.set(nil, block)
+	done := make(chan error)
+	go func() {
+		_, err := c.Stats(ctx)
+		done <- err
+	}()
+	for c.State() != BreakerHalfOpen {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := c.Stats(ctx); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen during probe, got %v", err)
+	}
+	close(block)
+	if err := <-done; err != nil {
+		t.Fatalf("probe failed: %v", err)
+	}
+	if c.State() != BreakerClosed {
+		t.Fatalf("expected closed circuit, got %s", c.State())
+	}
+	if inner.calls != 4 {
+		t.Fatalf("expected 4 calls of client, got %d", inner.calls)
+	}
+
+	expected := []string{"closed -> open", "open -> half-open", "half-open -> closed"}
+	if !reflect.DeepEqual(transitions, expected) {
+		t.Fatalf("expected transitions %q, got %q", expected, transitions)
+	}
+}
+
+func TestCircuitBreakerClientFailedProbe(t *testing.T) {
+	now := time.Unix(0, 0)
+	inner := &flakyClient{err: errors.New("connection refused")}
+	c := NewCircuitBreakerClient(inner, BreakerOpts{Failures: 1, CoolDown: time.Minute})
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	c.Stats(ctx)
+	if c.State() != BreakerOpen {
+		t.Fatalf("expected open circuit, got %s", c.State())
+	}
+	now = now.Add(time.Minute)
+	if _, err := c.Stats(ctx); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected error of probe, got %v", err)
+	}
+	if c.State() != BreakerOpen {
+		t.Fatalf("expected reopened circuit, got %s", c.State())
+	}
+	// The cool-down restarts with the failed probe.
+	now = now.Add(59 * time.Second)
+	if _, err := c.Stats(ctx); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+}
+
+func TestCircuitBreakerClientIgnoredErrors(t *testing.T) {
+	inner := &flakyClient{}
+	c := NewCircuitBreakerClient(inner, BreakerOpts{Failures: 2})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	inner.set(context.Canceled, nil)
+	for i := 0; i < 3; i++ {
+		c.Stats(ctx)
+	}
+	inner.set(&TruncatedError{Err: ErrTooManyZones}, nil)
+	for i := 0; i < 3; i++ {
+		c.Stats(context.Background())
+	}
+	if c.State() != BreakerClosed {
+		t.Fatalf("expected closed circuit, got %s", c.State())
+	}
+
+	// Calls exceeding their deadline are failures.
+	ctx, cancel = context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+	inner.set(context.DeadlineExceeded, nil)
+	c.Stats(ctx)
+	c.Stats(ctx)
+	if c.State() != BreakerOpen {
+		t.Fatalf("expected open circuit after deadlines exceeded, got %s", c.State())
+	}
+	c = NewCircuitBreakerClient(inner, BreakerOpts{Failures: 2})
+
+	// A success resets the consecutive failures.
+	inner.set(errors.New("connection refused"), nil)
+	c.Stats(context.Background())
+	inner.set(nil, nil)
+	c.Stats(context.Background())
+	inner.set(errors.New("connection refused"), nil)
+	c.Stats(context.Background())
+	if c.State() != BreakerClosed {
+		t.Fatalf("expected closed circuit, got %s", c.State())
+	}
+}
@@ -0,0 +1,355 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// StatisticsBuilder builds Statistics from maps of counters, e.g. for tests or
+// for adapters of other sources of statistics. Sections are named like those
+// of IndexedStats, e.g. "nsstats"; sections unknown to the package must be
+// added with the Extra methods, so that misspelled names are reported by
+// Build rather than silently moved to Extra. Like the JSON client, the
+// builder sorts counters, gauges and views by name, see Statistics; zones are
+// kept in the order they have been added.
+//
+// The methods return the builder for chaining. View selects the view the
+// following view and zone methods apply to, and Zone the zone the following
+// zone methods apply to.
+type StatisticsBuilder struct {
+	s    Statistics
+	view string
+	zone int
+	err  error
+}
+
+// NewStatisticsBuilder returns an empty StatisticsBuilder.
+func NewStatisticsBuilder() *StatisticsBuilder {
+	return &StatisticsBuilder{zone: -1}
+}
+
+// Source sets the Source of the statistics.
+func (b *StatisticsBuilder) Source(src Source) *StatisticsBuilder {
+	b.s.Source = src
+	return b
+}
+
+// Times sets the boot, reconfiguration and current time of the server.
+func (b *StatisticsBuilder) Times(boot, config, current time.Time) *StatisticsBuilder {
+	b.s.Server.BootTime, b.s.Server.ConfigTime, b.s.Server.CurrentTime = boot, config, current
+	return b
+}
+
+// Server adds the counters of the server section, which is one of "qtypes",
+// "opcodes", "nsstats", "zonestats" and "rcodes". Rcodes are normalized, see
+// NormalizeRcodeName.
+func (b *StatisticsBuilder) Server(section string, counters map[string]uint64) *StatisticsBuilder {
+	srv := &b.s.Server
+	switch section {
+	case "qtypes":
+		srv.IncomingQueries = addCounters(srv.IncomingQueries, counters, nil)
+	case "opcodes":
+		srv.IncomingRequests = addCounters(srv.IncomingRequests, counters, nil)
+	case "nsstats":
+		srv.NameServerStats = addCounters(srv.NameServerStats, counters, nil)
+	case "zonestats":
+		srv.ZoneMaintenance = addCounters(srv.ZoneMaintenance, counters, nil)
+		srv.ZoneStatistics = srv.ZoneMaintenance
+	case "rcodes":
+		srv.ServerRcodes = addCounters(srv.ServerRcodes, counters, NormalizeRcodeName)
+	default:
+		b.fail(fmt.Errorf("unknown server section %q", section))
+	}
+	return b
+}
+
+// ServerExtra adds the counters of a server section of type t unknown to the
+// package, see Extra.
+func (b *StatisticsBuilder) ServerExtra(t string, counters map[string]uint64) *StatisticsBuilder {
+	addExtra(&b.s.Server.Extra, t, counters)
+	return b
+}
+
+// View selects the view name, adding it if necessary.
+func (b *StatisticsBuilder) View(name string) *StatisticsBuilder {
+	b.view, b.zone = name, -1
+	if b.currentView() == nil {
+		b.s.Views = append(b.s.Views, View{Name: name})
+	}
+	return b
+}
+
+// Resolver adds the resolver statistics of the view, i.e. the section
+// "resstats", including the QryRTT counters of its QueryRTT histogram. Like
+// the clients, it adds the gauges of the section, e.g. NumFetch, to
+// ResolverGauges, see ResolverGaugeStat.
+func (b *StatisticsBuilder) Resolver(counters map[string]uint64) *StatisticsBuilder {
+	v := b.viewFor("resolver statistics")
+	if v == nil {
+		return b
+	}
+	stats, gauges := map[string]uint64{}, map[string]uint64{}
+	for name, val := range counters {
+		if ResolverGaugeStat(name) {
+			gauges[name] = val
+		} else {
+			stats[name] = val
+		}
+	}
+	v.ResolverStats = addCounters(v.ResolverStats, stats, nil)
+	v.ResolverGauges = addGauges(v.ResolverGauges, gauges)
+	return b
+}
+
+// ResolverQueries adds the outgoing queries of the view by type, i.e. the
+// section "resqtypes".
+func (b *StatisticsBuilder) ResolverQueries(counters map[string]uint64) *StatisticsBuilder {
+	if v := b.viewFor("resolver queries"); v != nil {
+		v.ResolverQueries = addCounters(v.ResolverQueries, counters, nil)
+	}
+	return b
+}
+
+// Cache adds the numbers of cached RRsets of the view by type.
+func (b *StatisticsBuilder) Cache(gauges map[string]uint64) *StatisticsBuilder {
+	if v := b.viewFor("cache"); v != nil {
+		v.Cache = addGauges(v.Cache, gauges)
+	}
+	return b
+}
+
+// CacheMemory adds the memory used by the cache of the view.
+func (b *StatisticsBuilder) CacheMemory(gauges map[string]uint64) *StatisticsBuilder {
+	if v := b.viewFor("cache memory"); v != nil {
+		v.CacheMemory = addGauges(v.CacheMemory, gauges)
+	}
+	return b
+}
+
+// CacheStats adds the cache counters of the view, i.e. the section
+// "cachestats" without the memory statistics, see CacheCounterStat.
+func (b *StatisticsBuilder) CacheStats(counters map[string]uint64) *StatisticsBuilder {
+	if v := b.viewFor("cache statistics"); v != nil {
+		v.CacheStats = addCounters(v.CacheStats, counters, nil)
+	}
+	return b
+}
+
+// ViewExtra adds the counters of a resolver section of type t unknown to the
+// package, see Extra.
+func (b *StatisticsBuilder) ViewExtra(t string, counters map[string]uint64) *StatisticsBuilder {
+	if v := b.viewFor("section " + t); v != nil {
+		addExtra(&v.Extra, t, counters)
+	}
+	return b
+}
+
+// Zone adds the zone name with serial to the view and selects it, see
+// ZoneCounter.SetName. A zone which has been added already, regardless of a
+// trailing dot, is selected again.
+func (b *StatisticsBuilder) Zone(name, serial string) *StatisticsBuilder {
+	if b.viewFor("zone "+name) == nil {
+		return b
+	}
+	zv := b.currentZoneView()
+	if zv == nil {
+		b.s.ZoneViews = append(b.s.ZoneViews, ZoneView{Name: b.view})
+		zv = &b.s.ZoneViews[len(b.s.ZoneViews)-1]
+	}
+	for i, z := range zv.ZoneData {
+		if z.Name == CanonicalZoneName(name) {
+			b.zone = i
+			zv.ZoneData[i].Serial = serial
+			return b
+		}
+	}
+	z := ZoneCounter{Serial: serial}
+	z.SetName(name)
+	zv.ZoneData = append(zv.ZoneData, z)
+	b.zone = len(zv.ZoneData) - 1
+	return b
+}
+
+// ZoneCounters adds the counters of the zone section, which is one of
+// "zonestats", "dnssec_sign", "dnssec_refresh", "query_results", "qtypes"
+// and "nsstats".
+func (b *StatisticsBuilder) ZoneCounters(section string, counters map[string]uint64) *StatisticsBuilder {
+	z := b.zoneFor("section " + section)
+	if z == nil {
+		return b
+	}
+	switch section {
+	case "zonestats":
+		z.ZoneStats = addCounters(z.ZoneStats, counters, nil)
+	case "dnssec_sign":
+		z.DNSSECSignStats = addCounters(z.DNSSECSignStats, counters, nil)
+	case "dnssec_refresh":
+		z.DNSSECRefreshStats = addCounters(z.DNSSECRefreshStats, counters, nil)
+	case "query_results":
+		z.QueryResults = addCounters(z.QueryResults, counters, nil)
+	case "qtypes":
+		z.IncomingQueries = addCounters(z.IncomingQueries, counters, nil)
+	case "nsstats":
+		z.NameServerStats = addCounters(z.NameServerStats, counters, nil)
+	default:
+		b.fail(fmt.Errorf("unknown zone section %q", section))
+	}
+	return b
+}
+
+// ZoneExtra adds the counters of a zone section of type t unknown to the
+// package, see Extra.
+func (b *StatisticsBuilder) ZoneExtra(t string, counters map[string]uint64) *StatisticsBuilder {
+	if z := b.zoneFor("section " + t); z != nil {
+		addExtra(&z.Extra, t, counters)
+	}
+	return b
+}
+
+// Build returns the statistics, with the warnings of Validate like those of
+// the clients, or the first error of the builder. Views are sorted by name,
+// and the QueryRTT histograms computed from the resolver statistics. The
+// builder must not be used afterwards.
+func (b *StatisticsBuilder) Build() (Statistics, error) {
+	if b.err != nil {
+		return Statistics{}, b.err
+	}
+	s := b.s
+	sort.SliceStable(s.Views, func(i, j int) bool { return s.Views[i].Name < s.Views[j].Name })
+	sort.SliceStable(s.ZoneViews, func(i, j int) bool { return s.ZoneViews[i].Name < s.ZoneViews[j].Name })
+	for i := range s.Views {
+		v := &s.Views[i]
+		var err error
+		if v.QueryRTT, err = QueryRTTHistogram(v.ResolverStats); err != nil {
+			return Statistics{}, fmt.Errorf("invalid resolver statistics of view %q: %s", v.Name, err)
+		}
+	}
+	s.AddWarnings(0, Validate(s)...)
+	return s, nil
+}
+
+func (b *StatisticsBuilder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+func (b *StatisticsBuilder) currentView() *View {
+	for i := range b.s.Views {
+		if b.s.Views[i].Name == b.view {
+			return &b.s.Views[i]
+		}
+	}
+	return nil
+}
+
+func (b *StatisticsBuilder) currentZoneView() *ZoneView {
+	for i := range b.s.ZoneViews {
+		if b.s.ZoneViews[i].Name == b.view {
+			return &b.s.ZoneViews[i]
+		}
+	}
+	return nil
+}
+
+// viewFor returns the selected view, or records an error for what if no view
+// has been selected.
+func (b *StatisticsBuilder) viewFor(what string) *View {
+	v := b.currentView()
+	if v == nil {
+		b.fail(errors.New("no view selected for " + what))
+	}
+	return v
+}
+
+// zoneFor returns the selected zone, or records an error for what if no zone
+// has been selected.
+func (b *StatisticsBuilder) zoneFor(what string) *ZoneCounter {
+	if b.viewFor(what) == nil {
+		return nil
+	}
+	zv := b.currentZoneView()
+	if zv == nil || b.zone < 0 {
+		b.fail(fmt.Errorf("no zone selected for %s in view %q", what, b.view))
+		return nil
+	}
+	return &zv.ZoneData[b.zone]
+}
+
+// addCounters returns cs with the counters of m added in the order of their
+// names, after renaming them with rename unless nil. Counters of the same
+// name are summed.
+func addCounters(cs []Counter, m map[string]uint64, rename func(string) string) []Counter {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		c := Counter{Name: name, Counter: m[name]}
+		if rename != nil {
+			c.Name = rename(name)
+		}
+		found := false
+		for i := range cs {
+			if cs[i].Name == c.Name {
+				cs[i].Counter, _ = AddCounters(cs[i].Counter, c.Counter)
+				found = true
+				break
+			}
+		}
+		if !found {
+			cs = append(cs, c)
+		}
+	}
+	sort.SliceStable(cs, func(i, j int) bool { return cs[i].Name < cs[j].Name })
+	return cs
+}
+
+// addExtra adds the counters of m to the section of type t of e, see
+// addCounters, creating e if it is nil.
+func addExtra(e *Extra, t string, m map[string]uint64) {
+	cs := addCounters((*e)[t], m, nil)
+	if len(cs) == 0 {
+		return
+	}
+	if *e == nil {
+		*e = Extra{}
+	}
+	(*e)[t] = cs
+}
+
+// addGauges returns gs with the gauges of m added, replacing gauges of the
+// same name, sorted by name.
+func addGauges(gs []Gauge, m map[string]uint64) []Gauge {
+	for name, val := range m {
+		found := false
+		for i := range gs {
+			if gs[i].Name == name {
+				gs[i].Gauge = val
+				found = true
+				break
+			}
+		}
+		if !found {
+			gs = append(gs, Gauge{Name: name, Gauge: val})
+		}
+	}
+	sort.SliceStable(gs, func(i, j int) bool { return gs[i].Name < gs[j].Name })
+	return gs
+}
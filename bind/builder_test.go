@@ -0,0 +1,120 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+)
+
+func ExampleStatisticsBuilder() {
+	s, err := bind.NewStatisticsBuilder().
+		Server("qtypes", map[string]uint64{"A": 120, "AAAA": 30}).
+		Server("nsstats", map[string]uint64{"Requestv4": 150, "QrySuccess": 140}).
+		View(bind.DefaultView).
+		Resolver(map[string]uint64{"Queryv4": 40, "QryRTT10": 25, "QryRTT100": 15}).
+		Cache(map[string]uint64{"A": 12}).
+		Zone("example.com", "2024031501").
+		ZoneCounters("qtypes", map[string]uint64{"A": 100}).
+		Build()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(s.Server.IncomingQueries, len(bind.Validate(s)), s.Views[0].QueryRTT.Count)
+	// Output: [{A 120} {AAAA 30}] 0 40
+}
+
+func TestStatisticsBuilder(t *testing.T) {
+	s, err := bind.NewStatisticsBuilder().
+		View("internal").
+		Zone("example.org", "1").
+		View(bind.DefaultView).
+		Resolver(map[string]uint64{"Lame": 2}).
+		Zone("example.net", "3").
+		Zone("example.com", "2").
+		ZoneCounters("query_results", map[string]uint64{"QrySuccess": 5}).
+		ZoneExtra("future", map[string]uint64{"Queued": 1}).
+		Zone("example.net", "4").
+		ZoneCounters("qtypes", map[string]uint64{"AAAA": 1, "A": 2}).
+		ZoneCounters("qtypes", map[string]uint64{"A": 3}).
+		Server("rcodes", map[string]uint64{"17": 1, "NOERROR": 9}).
+		ServerExtra("future", map[string]uint64{"X": 1}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var views []string
+	for _, v := range s.Views {
+		views = append(views, v.Name)
+	}
+	if want := []string{bind.DefaultView, "internal"}; !reflect.DeepEqual(views, want) {
+		t.Errorf("want views sorted %v, got %v", want, views)
+	}
+	zv := s.ZoneViews[0]
+	if zv.Name != bind.DefaultView || len(zv.ZoneData) != 2 {
+		t.Fatalf("want two zones in view %s, got %+v", bind.DefaultView, s.ZoneViews)
+	}
+	net, com := zv.ZoneData[0], zv.ZoneData[1]
+	if net.Name != "example.net" || net.Serial != "4" || com.Name != "example.com" {
+		t.Errorf("want zones in the order added with the last serial, got %+v", zv.ZoneData)
+	}
+	if want := []bind.Counter{{Name: "A", Counter: 5}, {Name: "AAAA", Counter: 1}}; !reflect.DeepEqual(net.IncomingQueries, want) {
+		t.Errorf("want summed counters sorted by name %v, got %v", want, net.IncomingQueries)
+	}
+	if got := com.Extra["future"]; len(got) != 1 || got[0].Counter != 1 {
+		t.Errorf("want extra section of zone, got %v", com.Extra)
+	}
+	if want := []bind.Counter{{Name: "NOERROR", Counter: 9}, {Name: "RCODE17", Counter: 1}}; !reflect.DeepEqual(s.Server.ServerRcodes, want) {
+		t.Errorf("want normalized rcodes %v, got %v", want, s.Server.ServerRcodes)
+	}
+	if len(s.Server.Extra["future"]) != 1 {
+		t.Errorf("want extra section of server, got %v", s.Server.Extra)
+	}
+}
+
+func TestStatisticsBuilderErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		b    *bind.StatisticsBuilder
+		want string
+	}{
+		"server section": {
+			bind.NewStatisticsBuilder().Server("nstats", nil),
+			`unknown server section "nstats"`,
+		},
+		"zone section": {
+			bind.NewStatisticsBuilder().View("v").Zone("z", "1").ZoneCounters("rcodes", nil),
+			`unknown zone section "rcodes"`,
+		},
+		"view": {
+			bind.NewStatisticsBuilder().Resolver(nil),
+			"no view selected",
+		},
+		"zone": {
+			bind.NewStatisticsBuilder().View("v").ZoneCounters("qtypes", nil),
+			"no zone selected",
+		},
+		"first error": {
+			bind.NewStatisticsBuilder().Server("a", nil).Server("b", nil),
+			`"a"`,
+		},
+	} {
+		if _, err := tc.b.Build(); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: want error containing %q, got %v", name, tc.want, err)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import "strings"
+
+// BuiltinZones lists the empty zones which named creates automatically in
+// every view of a resolver, see the empty-zones-enable option. On a resolver
+// which serves no zones of its own they are usually the only zones reported.
+// The names are lowercase and without trailing dot. The variable may be
+// extended before clients are constructed with WithoutBuiltinZones.
+var BuiltinZones = []string{
+	// RFC 6303 local IPv4 ranges.
+	"0.in-addr.arpa", "127.in-addr.arpa", "254.169.in-addr.arpa",
+	"255.255.255.255.in-addr.arpa",
+	// RFC 1918 private ranges.
+	"10.in-addr.arpa", "16.172.in-addr.arpa", "17.172.in-addr.arpa",
+	"18.172.in-addr.arpa", "19.172.in-addr.arpa", "20.172.in-addr.arpa",
+	"21.172.in-addr.arpa", "22.172.in-addr.arpa", "23.172.in-addr.arpa",
+	"24.172.in-addr.arpa", "25.172.in-addr.arpa", "26.172.in-addr.arpa",
+	"27.172.in-addr.arpa", "28.172.in-addr.arpa", "29.172.in-addr.arpa",
+	"30.172.in-addr.arpa", "31.172.in-addr.arpa", "168.192.in-addr.arpa",
+	// RFC 6598 shared address space.
+	"64.100.in-addr.arpa", "65.100.in-addr.arpa", "66.100.in-addr.arpa",
+	"67.100.in-addr.arpa", "68.100.in-addr.arpa", "69.100.in-addr.arpa",
+	"70.100.in-addr.arpa", "71.100.in-addr.arpa", "72.100.in-addr.arpa",
+	"73.100.in-addr.arpa", "74.100.in-addr.arpa", "75.100.in-addr.arpa",
+	"76.100.in-addr.arpa", "77.100.in-addr.arpa", "78.100.in-addr.arpa",
+	"79.100.in-addr.arpa", "80.100.in-addr.arpa", "81.100.in-addr.arpa",
+	"82.100.in-addr.arpa", "83.100.in-addr.arpa", "84.100.in-addr.arpa",
+	"85.100.in-addr.arpa", "86.100.in-addr.arpa", "87.100.in-addr.arpa",
+	"88.100.in-addr.arpa", "89.100.in-addr.arpa", "90.100.in-addr.arpa",
+	"91.100.in-addr.arpa", "92.100.in-addr.arpa", "93.100.in-addr.arpa",
+	"94.100.in-addr.arpa", "95.100.in-addr.arpa", "96.100.in-addr.arpa",
+	"97.100.in-addr.arpa", "98.100.in-addr.arpa", "99.100.in-addr.arpa",
+	"100.100.in-addr.arpa", "101.100.in-addr.arpa", "102.100.in-addr.arpa",
+	"103.100.in-addr.arpa", "104.100.in-addr.arpa", "105.100.in-addr.arpa",
+	"106.100.in-addr.arpa", "107.100.in-addr.arpa", "108.100.in-addr.arpa",
+	"109.100.in-addr.arpa", "110.100.in-addr.arpa", "111.100.in-addr.arpa",
+	"112.100.in-addr.arpa", "113.100.in-addr.arpa", "114.100.in-addr.arpa",
+	"115.100.in-addr.arpa", "116.100.in-addr.arpa", "117.100.in-addr.arpa",
+	"118.100.in-addr.arpa", "119.100.in-addr.arpa", "120.100.in-addr.arpa",
+	"121.100.in-addr.arpa", "122.100.in-addr.arpa", "123.100.in-addr.arpa",
+	"124.100.in-addr.arpa", "125.100.in-addr.arpa", "126.100.in-addr.arpa",
+	"127.100.in-addr.arpa",
+	// RFC 5737 documentation ranges.
+	"2.0.192.in-addr.arpa", "100.51.198.in-addr.arpa", "113.0.203.in-addr.arpa",
+	// RFC 6303 local IPv6 ranges and RFC 3849 documentation range.
+	"0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.ip6.arpa",
+	"1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.ip6.arpa",
+	"d.f.ip6.arpa", "8.e.f.ip6.arpa", "9.e.f.ip6.arpa", "a.e.f.ip6.arpa",
+	"b.e.f.ip6.arpa", "8.b.d.0.1.0.0.2.ip6.arpa",
+	// Special-use names of RFC 7534, RFC 8375 and RFC 9462.
+	"empty.as112.arpa", "home.arpa", "resolver.arpa",
+}
+
+// CanonicalZoneName returns the canonical form of the zone name, which has no
+// trailing dot except for the root zone ".". The clients report zone names in
+// this form, and the functions of the package taking zone names accept either
+// form. The case of the name is preserved, unlike by ZoneKey.
+func CanonicalZoneName(name string) string {
+	if name == "." {
+		return name
+	}
+	return strings.TrimSuffix(name, ".")
+}
+
+// SetName sets the Name of z to the canonical form of name, see
+// CanonicalZoneName, and its RawName to name if they differ.
+func (z *ZoneCounter) SetName(name string) {
+	z.Name = CanonicalZoneName(name)
+	z.RawName = ""
+	if z.Name != name {
+		z.RawName = name
+	}
+}
+
+// ReportedName returns the name of z as reported by named, i.e. RawName if
+// set and Name otherwise.
+func (z ZoneCounter) ReportedName() string {
+	if z.RawName != "" {
+		return z.RawName
+	}
+	return z.Name
+}
+
+// ZoneKey returns the key of the zone name in ClientOptions.ExcludedZones,
+// which is the lowercase canonical name, see CanonicalZoneName.
+func ZoneKey(name string) string {
+	return strings.ToLower(CanonicalZoneName(name))
+}
+
+// ExcludesZone reports whether the zone name of class class is excluded by
+// ExcludedZones. Zones of classes other than IN, such as version.bind, are
+// only excluded by WithoutBuiltinZones: clients do not report them as zones,
+// but count their queries in QueriesByClass.
+func (o ClientOptions) ExcludesZone(name, class string) bool {
+	if class != ClassIN {
+		return o.ExcludeBuiltinZones
+	}
+	return o.ExcludedZones[ZoneKey(name)]
+}
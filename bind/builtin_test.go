@@ -0,0 +1,149 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestBuiltinZonesNormalized(t *testing.T) {
+	seen := map[string]bool{}
+	for _, z := range BuiltinZones {
+		if ZoneKey(z) != z {
+			t.Errorf("builtin zone %q is not normalized", z)
+		}
+		if seen[z] {
+			t.Errorf("duplicate builtin zone %q", z)
+		}
+		seen[z] = true
+	}
+}
+
+func TestExcludesZone(t *testing.T) {
+	o := NewClientOptions(WithExcludedZones("Example.COM."))
+	for _, tc := range []struct {
+		name, class string
+		want        bool
+	}{
+		{name: "example.com", class: "IN", want: true},
+		{name: "EXAMPLE.com.", class: "IN", want: true},
+		{name: "example.net", class: "IN"},
+		{name: "version.bind", class: "CH"},
+	} {
+		if got := o.ExcludesZone(tc.name, tc.class); got != tc.want {
+			t.Errorf("ExcludesZone(%q, %q): want %t, got %t", tc.name, tc.class, tc.want, got)
+		}
+	}
+	if o := NewClientOptions(WithoutBuiltinZones()); !o.ExcludesZone("version.bind", "CH") {
+		t.Error("want zones of class CH excluded without builtin zones")
+	}
+	if (ClientOptions{}).ExcludesZone("version.bind", "CH") {
+		t.Error("want no zones excluded by default")
+	}
+}
+
+func TestCanonicalZoneName(t *testing.T) {
+	for name, want := range map[string]string{
+		".":                ".",
+		"example.com":      "example.com",
+		"Example.COM.":     "Example.COM",
+		"10.in-addr.arpa.": "10.in-addr.arpa",
+	} {
+		if got := CanonicalZoneName(name); got != want {
+			t.Errorf("CanonicalZoneName(%q): want %q, got %q", name, want, got)
+		}
+	}
+	if got := ZoneKey("."); got != "." {
+		t.Errorf("want root zone key \".\", got %q", got)
+	}
+}
+
+// TestZoneNameForms passes the root zone, a zone named with trailing dot
+// and an in-addr.arpa zone through the functions taking zone names, in
+// either form.
+func TestZoneNameForms(t *testing.T) {
+	build := func(serial string) Statistics {
+		s, err := NewStatisticsBuilder().
+			Source(Source{Format: FormatJSONv1}).
+			Times(time.Unix(1, 0), time.Unix(2, 0), time.Time{}).
+			View(DefaultView).
+			Zone(".", serial).ZoneCounters("qtypes", map[string]uint64{"NS": 1}).
+			Zone("example.com.", serial).ZoneCounters("qtypes", map[string]uint64{"A": 1}).
+			Zone("10.in-addr.arpa", serial).ZoneCounters("qtypes", map[string]uint64{"PTR": 1}).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	s := build("1")
+	zones := s.ZoneViews[0].ZoneData
+	if got := []string{zones[0].Name, zones[1].Name, zones[2].Name}; !reflect.DeepEqual(got, []string{".", "example.com", "10.in-addr.arpa"}) {
+		t.Fatalf("want canonical zone names, got %v", got)
+	}
+	if zones[1].RawName != "example.com." || zones[1].ReportedName() != "example.com." || zones[0].RawName != "" || zones[0].ReportedName() != "." {
+		t.Errorf("want raw names kept, got %+v", zones)
+	}
+
+	// The other form of every name, as users may pass them.
+	others := map[string]string{".": ".", "example.com": "example.com.", "10.in-addr.arpa": "10.in-addr.arpa."}
+	is := s.Indexed()
+	o := NewClientOptions(WithExcludedZones(".", "example.com", "10.in-addr.arpa."))
+	for name, other := range others {
+		for _, n := range []string{name, other} {
+			if zs := s.Zone(n); len(zs) != 1 || zs[0].Zone.Name != name {
+				t.Errorf("Zone(%q): want zone %s, got %v", n, name, zs)
+			}
+			if _, ok := is.Zones[DefaultView][ZoneKey(n)]; !ok {
+				t.Errorf("want indexed zone %q", n)
+			}
+			if !o.ExcludesZone(n, "IN") {
+				t.Errorf("want zone %q excluded", n)
+			}
+		}
+	}
+
+	// Statistics of the same zones named in the other form.
+	b := NewStatisticsBuilder().
+		Source(Source{Format: FormatJSONv1}).
+		Times(time.Unix(1, 0), time.Unix(3, 0), time.Time{}).
+		View(DefaultView)
+	for _, other := range others {
+		b.Zone(other, "2").ZoneCounters("qtypes", map[string]uint64{"A": 5})
+	}
+	later, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := Reloaded(s, later); r == nil || len(r.AddedZones) != 0 || len(r.RemovedZones) != 0 {
+		t.Errorf("want no zones added or removed, got %+v", r)
+	}
+	report := SerialDrift(s, map[string]Statistics{"secondary": later})
+	var drifted []string
+	for _, d := range report.Zones {
+		drifted = append(drifted, d.Zone)
+	}
+	sort.Strings(drifted)
+	if want := []string{".", "10.in-addr.arpa", "example.com"}; !reflect.DeepEqual(drifted, want) {
+		t.Errorf("want drift of %v, got %v", want, drifted)
+	}
+	for _, a := range HotZones(s, later, 1) {
+		if a.Added || a.Removed || others[a.Zone] == "" {
+			t.Errorf("want zones matched by canonical name, got %+v", a)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"strings"
+	"unicode"
+)
+
+//go:generate go run ./internal/gencatalog -in counters.tsv -out catalog_table.go
+
+// CounterGroup identifies a group of counters of the server or a view.
+type CounterGroup string
+
+// Counter groups, named after the counter types of the XML statistics.
+const (
+	// NameServerCounters are reported in Server.NameServerStats.
+	NameServerCounters CounterGroup = "nsstat"
+	// OpcodeCounters are reported in Server.IncomingRequests.
+	OpcodeCounters CounterGroup = "opcode"
+	// RcodeCounters are reported in Server.ServerRcodes.
+	RcodeCounters CounterGroup = "rcode"
+	// ZoneMaintenanceCounters are reported in Server.ZoneMaintenance and
+	// ZoneCounter.ZoneStats.
+	ZoneMaintenanceCounters CounterGroup = "zonestat"
+	// ResolverCounters are reported in View.ResolverStats, and those of
+	// KindGauge in View.ResolverGauges.
+	ResolverCounters CounterGroup = "resstats"
+	// CacheCounters are the cache statistics of views. Those of KindCounter
+	// are reported in View.CacheStats and the memory statistics in
+	// View.CacheMemory.
+	CacheCounters CounterGroup = "cachestats"
+	// SocketCounters are the socket I/O statistics, which are not decoded by
+	// the clients.
+	SocketCounters CounterGroup = "sockstat"
+	// MemoryCounters are the memory summary of named, reported in
+	// Memory.Summary.
+	MemoryCounters CounterGroup = "memory"
+)
+
+// CounterKind tells whether a statistic only increases or reports a current
+// value.
+type CounterKind string
+
+// Kinds of statistics.
+const (
+	KindCounter CounterKind = "counter"
+	KindGauge   CounterKind = "gauge"
+)
+
+// Unit is the unit of a statistic which is not a plain number of events or
+// items.
+type Unit string
+
+// Units of statistics.
+const (
+	UnitBytes   Unit = "bytes"
+	UnitSeconds Unit = "seconds"
+)
+
+// CounterInfo describes a well-known statistic of BIND.
+type CounterInfo struct {
+	Group CounterGroup `json:"group" yaml:"group"`
+	// Name is the name used by current BIND versions, see
+	// NormalizeCounterName. Every name is declared as a constant, e.g.
+	// CounterQrySuccess, with a trailing + spelled Plus.
+	Name string      `json:"name" yaml:"name"`
+	Kind CounterKind `json:"kind" yaml:"kind"`
+	// Unit is the unit of the statistic, or empty for counts.
+	Unit Unit `json:"unit,omitempty" yaml:"unit,omitempty"`
+	// Help is a one sentence description of the statistic.
+	Help string `json:"help" yaml:"help"`
+	// Since is the first BIND release reporting the statistic, e.g. "9.18",
+	// or empty if it is reported by all supported releases.
+	Since string `json:"since,omitempty" yaml:"since,omitempty"`
+	// Until is the first BIND release no longer reporting the statistic, or
+	// empty if it is still reported.
+	Until string `json:"until,omitempty" yaml:"until,omitempty"`
+}
+
+// ReportedBy reports whether the statistic is reported by the given BIND
+// version. Statistics bound to a release range are not reported by unknown
+// versions.
+func (i CounterInfo) ReportedBy(version string) bool {
+	if i.Since == "" && i.Until == "" {
+		return true
+	}
+	v, ok := parseVersion(version)
+	if !ok {
+		return false
+	}
+	if since, ok := parseVersion(i.Since); ok && versionLess(v, since) {
+		return false
+	}
+	if until, ok := parseVersion(i.Until); ok && !versionLess(v, until) {
+		return false
+	}
+	return true
+}
+
+// MetricName returns the name of the statistic in snake case followed by its
+// unit, if any, following the conventions of Prometheus, e.g.
+// "total_use_bytes" for TotalUse.
+func (i CounterInfo) MetricName() string {
+	var b strings.Builder
+	for j, r := range i.Name {
+		if j > 0 && unicode.IsUpper(r) && !unicode.IsUpper(rune(i.Name[j-1])) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	if i.Unit != "" {
+		b.WriteString("_" + string(i.Unit))
+	}
+	return b.String()
+}
+
+var catalogIndex = func() map[CounterGroup]map[string]int {
+	idx := map[CounterGroup]map[string]int{}
+	for i, c := range catalog {
+		if idx[c.Group] == nil {
+			idx[c.Group] = map[string]int{}
+		}
+		idx[c.Group][c.Name] = i
+	}
+	return idx
+}()
+
+// Describe returns the catalog entry of the statistic name, which may be a
+// name used by an older BIND version. Names used by several groups, e.g.
+// NXDOMAIN, are described by the entry of the first group in the catalog;
+// use DescribeIn to describe the statistic of a particular group.
+func Describe(name string) (CounterInfo, bool) {
+	name = NormalizeCounterName(name)
+	for _, c := range catalog {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return CounterInfo{}, false
+}
+
+// DescribeIn returns the catalog entry of the statistic name of group g.
+func DescribeIn(g CounterGroup, name string) (CounterInfo, bool) {
+	i, ok := catalogIndex[g][NormalizeCounterName(name)]
+	if !ok {
+		return CounterInfo{}, false
+	}
+	return catalog[i], true
+}
+
+// CounterCatalog returns all well-known statistics. The returned slice may be
+// modified by the caller.
+func CounterCatalog() []CounterInfo {
+	return append([]CounterInfo(nil), catalog...)
+}
+
+// FillZeroCounters adds the counters of the catalog which are missing from the
+// server and view statistics of s with a value of zero, as BIND omits counters
+// which have not been incremented. Only counters reported by
+// s.Source.BINDVersion are added, and gauges are never added. The QueryRTT
+// histograms of views are recomputed.
+func FillZeroCounters(s *Statistics) error {
+	version := s.Source.BINDVersion
+	s.Server.NameServerStats = fillZero(s.Server.NameServerStats, NameServerCounters, version)
+	s.Server.IncomingRequests = fillZero(s.Server.IncomingRequests, OpcodeCounters, version)
+	s.Server.ServerRcodes = fillZero(s.Server.ServerRcodes, RcodeCounters, version)
+	s.Server.ZoneMaintenance = fillZero(s.Server.ZoneMaintenance, ZoneMaintenanceCounters, version)
+	s.Server.ZoneStatistics = s.Server.ZoneMaintenance
+	for i := range s.Views {
+		v := &s.Views[i]
+		v.ResolverStats = fillZero(v.ResolverStats, ResolverCounters, version)
+		h, err := QueryRTTHistogram(v.ResolverStats)
+		if err != nil {
+			return err
+		}
+		v.QueryRTT = h
+	}
+	return nil
+}
+
+func fillZero(cs []Counter, g CounterGroup, version string) []Counter {
+	present := make(map[string]bool, len(cs))
+	for _, c := range cs {
+		present[NormalizeCounterName(c.Name)] = true
+	}
+	for _, c := range catalog {
+		if c.Group == g && c.Kind == KindCounter && !present[c.Name] && c.ReportedBy(version) {
+			cs = append(cs, Counter{Name: c.Name})
+		}
+	}
+	return cs
+}
@@ -0,0 +1,711 @@
+// Code generated by gencatalog from counters.tsv. DO NOT EDIT.
+
+package bind
+
+// Name server statistics, see NameServerCounters.
+const (
+	// Number of IPv4 requests received.
+	CounterRequestv4 = "Requestv4"
+	// Number of IPv6 requests received.
+	CounterRequestv6 = "Requestv6"
+	// Number of requests received with EDNS(0).
+	CounterReqEdns0 = "ReqEdns0"
+	// Number of requests received with an unsupported EDNS version.
+	CounterReqBadEDNSVer = "ReqBadEDNSVer"
+	// Number of requests received with TSIG.
+	CounterReqTSIG = "ReqTSIG"
+	// Number of requests received with SIG(0).
+	CounterReqSIG0 = "ReqSIG0"
+	// Number of requests received with an invalid TSIG or SIG(0) signature.
+	CounterReqBadSIG = "ReqBadSIG"
+	// Number of TCP requests received.
+	CounterReqTCP = "ReqTCP"
+	// Number of rejected authoritative queries.
+	CounterAuthQryRej = "AuthQryRej"
+	// Number of rejected recursive queries.
+	CounterRecQryRej = "RecQryRej"
+	// Number of rejected zone transfers.
+	CounterXfrRej = "XfrRej"
+	// Number of rejected dynamic update requests.
+	CounterUpdateRej = "UpdateRej"
+	// Number of responses sent.
+	CounterResponse = "Response"
+	// Number of truncated responses sent.
+	CounterTruncatedResp = "TruncatedResp"
+	// Number of responses sent with EDNS(0).
+	CounterRespEDNS0 = "RespEDNS0"
+	// Number of responses sent with TSIG.
+	CounterRespTSIG = "RespTSIG"
+	// Number of responses sent with SIG(0).
+	CounterRespSIG0 = "RespSIG0"
+	// Number of queries resulting in a successful answer.
+	CounterQrySuccess = "QrySuccess"
+	// Number of queries resulting in an authoritative answer.
+	CounterQryAuthAns = "QryAuthAns"
+	// Number of queries resulting in a non-authoritative answer.
+	CounterQryNoauthAns = "QryNoauthAns"
+	// Number of queries resulting in a referral answer.
+	CounterQryReferral = "QryReferral"
+	// Number of queries resulting in an NXRRSET answer.
+	CounterQryNxrrset = "QryNxrrset"
+	// Number of queries resulting in a SERVFAIL answer.
+	CounterQrySERVFAIL = "QrySERVFAIL"
+	// Number of queries resulting in a FORMERR answer.
+	CounterQryFORMERR = "QryFORMERR"
+	// Number of queries resulting in an NXDOMAIN answer.
+	CounterQryNXDOMAIN = "QryNXDOMAIN"
+	// Number of queries causing recursion.
+	CounterQryRecursion = "QryRecursion"
+	// Number of duplicated queries received.
+	CounterQryDuplicate = "QryDuplicate"
+	// Number of recursive queries dropped due to the recursive client limit.
+	CounterQryDropped = "QryDropped"
+	// Number of queries failing for other reasons.
+	CounterQryFailure = "QryFailure"
+	// Number of queries resulting in an NXDOMAIN answer which were redirected.
+	CounterQryNXRedir = "QryNXRedir"
+	// Number of queries resulting in an NXDOMAIN answer which were redirected and resulted in a successful remote lookup.
+	CounterQryNXRedirRLookup = "QryNXRedirRLookup"
+	// Number of queries answered with BADCOOKIE.
+	CounterQryBADCOOKIE = "QryBADCOOKIE"
+	// Number of UDP queries received.
+	CounterQryUDP = "QryUDP"
+	// Number of TCP queries received.
+	CounterQryTCP = "QryTCP"
+	// Number of queries answered with stale data.
+	CounterQryUsedStale = "QryUsedStale"
+	// Number of queries for which stale data was tried after stale-answer-client-timeout expired.
+	CounterQryTryStale = "QryTryStale"
+	// Number of requested zone transfers completed.
+	CounterXfrReqDone = "XfrReqDone"
+	// Number of dynamic update requests forwarded.
+	CounterUpdateReqFwd = "UpdateReqFwd"
+	// Number of dynamic update responses forwarded.
+	CounterUpdateRespFwd = "UpdateRespFwd"
+	// Number of failed dynamic update forwards.
+	CounterUpdateFwdFail = "UpdateFwdFail"
+	// Number of dynamic updates completed.
+	CounterUpdateDone = "UpdateDone"
+	// Number of failed dynamic updates.
+	CounterUpdateFail = "UpdateFail"
+	// Number of dynamic updates rejected due to a prerequisite failure.
+	CounterUpdateBadPrereq = "UpdateBadPrereq"
+	// Number of current recursive clients.
+	CounterRecursClients = "RecursClients"
+	// Number of queries answered with DNS64 synthesized data.
+	CounterDNS64 = "DNS64"
+	// Number of responses dropped by response rate limiting.
+	CounterRateDropped = "RateDropped"
+	// Number of responses truncated by response rate limiting.
+	CounterRateSlipped = "RateSlipped"
+	// Number of responses rewritten by response policy zones.
+	CounterRPZRewrites = "RPZRewrites"
+	// Number of queries dropped due to the per-client recursion limit.
+	CounterRecLimitDropped = "RecLimitDropped"
+	// Number of requests received with the NSID option.
+	CounterNSIDOpt = "NSIDOpt"
+	// Number of requests received with the EXPIRE option.
+	CounterExpireOpt = "ExpireOpt"
+	// Number of requests received with an unknown EDNS option.
+	CounterOtherOpt = "OtherOpt"
+	// Number of requests received with the EDNS Client Subnet option.
+	CounterECSOpt = "ECSOpt"
+	// Number of requests received with the EDNS KEY-TAG option.
+	CounterKeyTagOpt = "KeyTagOpt"
+	// Number of requests received with a COOKIE option.
+	CounterCookieIn = "CookieIn"
+	// Number of requests received with a COOKIE option with only a client cookie.
+	CounterCookieNew = "CookieNew"
+	// Number of requests received with a COOKIE option of invalid size.
+	CounterCookieBadSize = "CookieBadSize"
+	// Number of requests received with a COOKIE option with a timestamp out of range.
+	CounterCookieBadTime = "CookieBadTime"
+	// Number of requests received with a COOKIE option not matching the server cookie.
+	CounterCookieNoMatch = "CookieNoMatch"
+	// Number of requests received with a COOKIE option matching the server cookie.
+	CounterCookieMatch = "CookieMatch"
+)
+
+// Zone maintenance statistics, see ZoneMaintenanceCounters.
+const (
+	// Number of IPv4 NOTIFY messages sent.
+	CounterNotifyOutv4 = "NotifyOutv4"
+	// Number of IPv6 NOTIFY messages sent.
+	CounterNotifyOutv6 = "NotifyOutv6"
+	// Number of IPv4 NOTIFY messages received.
+	CounterNotifyInv4 = "NotifyInv4"
+	// Number of IPv6 NOTIFY messages received.
+	CounterNotifyInv6 = "NotifyInv6"
+	// Number of rejected incoming NOTIFY messages.
+	CounterNotifyRej = "NotifyRej"
+	// Number of IPv4 SOA queries sent.
+	CounterSOAOutv4 = "SOAOutv4"
+	// Number of IPv6 SOA queries sent.
+	CounterSOAOutv6 = "SOAOutv6"
+	// Number of IPv4 AXFR requests sent.
+	CounterAXFRReqv4 = "AXFRReqv4"
+	// Number of IPv6 AXFR requests sent.
+	CounterAXFRReqv6 = "AXFRReqv6"
+	// Number of IPv4 IXFR requests sent.
+	CounterIXFRReqv4 = "IXFRReqv4"
+	// Number of IPv6 IXFR requests sent.
+	CounterIXFRReqv6 = "IXFRReqv6"
+	// Number of successful zone transfers.
+	CounterXfrSuccess = "XfrSuccess"
+	// Number of failed zone transfers.
+	CounterXfrFail = "XfrFail"
+)
+
+// Resolver statistics, see ResolverCounters.
+const (
+	// Number of IPv4 queries sent.
+	CounterQueryv4 = "Queryv4"
+	// Number of IPv6 queries sent.
+	CounterQueryv6 = "Queryv6"
+	// Number of IPv4 responses received.
+	CounterResponsev4 = "Responsev4"
+	// Number of IPv6 responses received.
+	CounterResponsev6 = "Responsev6"
+	// Number of NXDOMAIN responses received.
+	CounterNXDOMAIN = "NXDOMAIN"
+	// Number of SERVFAIL responses received.
+	CounterSERVFAIL = "SERVFAIL"
+	// Number of FORMERR responses received.
+	CounterFORMERR = "FORMERR"
+	// Number of REFUSED responses received.
+	CounterREFUSED = "REFUSED"
+	// Number of responses received with other errors.
+	CounterOtherError = "OtherError"
+	// Number of EDNS(0) query errors.
+	CounterEDNS0Fail = "EDNS0Fail"
+	// Number of mismatch responses received.
+	CounterMismatch = "Mismatch"
+	// Number of truncated responses received.
+	CounterTruncated = "Truncated"
+	// Number of lame delegation responses received.
+	CounterLame = "Lame"
+	// Number of resolver query retries.
+	CounterRetry = "Retry"
+	// Number of queries aborted due to quota control.
+	CounterQueryAbort = "QueryAbort"
+	// Number of failures in opening query sockets.
+	CounterQuerySockFail = "QuerySockFail"
+	// Number of UDP queries in progress.
+	CounterQueryCurUDP = "QueryCurUDP"
+	// Number of TCP queries in progress.
+	CounterQueryCurTCP = "QueryCurTCP"
+	// Number of query timeouts.
+	CounterQueryTimeout = "QueryTimeout"
+	// Number of IPv4 NS address fetches invoked.
+	CounterGlueFetchv4 = "GlueFetchv4"
+	// Number of IPv6 NS address fetches invoked.
+	CounterGlueFetchv6 = "GlueFetchv6"
+	// Number of failed IPv4 NS address fetches.
+	CounterGlueFetchv4Fail = "GlueFetchv4Fail"
+	// Number of failed IPv6 NS address fetches.
+	CounterGlueFetchv6Fail = "GlueFetchv6Fail"
+	// Number of DNSSEC validation attempts.
+	CounterValAttempt = "ValAttempt"
+	// Number of successful DNSSEC validations.
+	CounterValOk = "ValOk"
+	// Number of successful DNSSEC validations of negative responses.
+	CounterValNegOk = "ValNegOk"
+	// Number of DNSSEC validation attempt errors.
+	CounterValFail = "ValFail"
+	// Number of queries answered within 10ms.
+	CounterQryRTT10 = "QryRTT10"
+	// Number of queries answered within 100ms.
+	CounterQryRTT100 = "QryRTT100"
+	// Number of queries answered within 500ms.
+	CounterQryRTT500 = "QryRTT500"
+	// Number of queries answered within 800ms.
+	CounterQryRTT800 = "QryRTT800"
+	// Number of queries answered within 1600ms.
+	CounterQryRTT1600 = "QryRTT1600"
+	// Number of queries answered after more than 1600ms.
+	CounterQryRTT1600Plus = "QryRTT1600+"
+	// Number of active fetches.
+	CounterNumFetch = "NumFetch"
+	// Number of buckets of the resolver.
+	CounterBucketSize = "BucketSize"
+	// Number of queries spilled due to the fetches-per-zone limit.
+	CounterZoneQuota = "ZoneQuota"
+	// Number of queries spilled due to the fetches-per-server limit.
+	CounterServerQuota = "ServerQuota"
+	// Number of responses received with an unsupported EDNS version.
+	CounterBadEDNSVersion = "BadEDNSVersion"
+	// Number of times the resolver waited for the next item after receiving an invalid response.
+	CounterNextItem = "NextItem"
+	// Number of queries sent with only a client cookie.
+	CounterClientCookieOut = "ClientCookieOut"
+	// Number of queries sent with a client and a server cookie.
+	CounterServerCookieOut = "ServerCookieOut"
+	// Number of responses received with a valid client cookie.
+	CounterCookieClientOk = "CookieClientOk"
+	// Number of BADCOOKIE responses received.
+	CounterBadCookieRcode = "BadCookieRcode"
+)
+
+// Cache statistics of views, see CacheCounters.
+const (
+	// Number of lookups of the cache which found data.
+	CounterCacheHits = "CacheHits"
+	// Number of lookups of the cache which found no data.
+	CounterCacheMisses = "CacheMisses"
+	// Number of queries answered from the cache.
+	CounterQueryHits = "QueryHits"
+	// Number of queries which could not be answered from the cache.
+	CounterQueryMisses = "QueryMisses"
+	// Number of cache entries deleted to free memory.
+	CounterDeleteLRU = "DeleteLRU"
+	// Number of cache entries deleted after their TTL expired.
+	CounterDeleteTTL = "DeleteTTL"
+	// Memory allocated for the cache database in bytes.
+	CounterTreeMemTotal = "TreeMemTotal"
+	// Memory in use by the cache database in bytes.
+	CounterTreeMemInUse = "TreeMemInUse"
+	// Maximum memory in use by the cache database in bytes.
+	CounterTreeMemMax = "TreeMemMax"
+	// Memory allocated for the cache heap in bytes.
+	CounterHeapMemTotal = "HeapMemTotal"
+	// Memory in use by the cache heap in bytes.
+	CounterHeapMemInUse = "HeapMemInUse"
+	// Maximum memory in use by the cache heap in bytes.
+	CounterHeapMemMax = "HeapMemMax"
+)
+
+// Incoming requests by opcode, see OpcodeCounters.
+const (
+	// Number of QUERY requests received.
+	CounterQUERY = "QUERY"
+	// Number of IQUERY requests received.
+	CounterIQUERY = "IQUERY"
+	// Number of STATUS requests received.
+	CounterSTATUS = "STATUS"
+	// Number of requests received with reserved opcode 3.
+	CounterRESERVED3 = "RESERVED3"
+	// Number of NOTIFY requests received.
+	CounterNOTIFY = "NOTIFY"
+	// Number of UPDATE requests received.
+	CounterUPDATE = "UPDATE"
+	// Number of requests received with reserved opcode 6.
+	CounterRESERVED6 = "RESERVED6"
+	// Number of requests received with reserved opcode 7.
+	CounterRESERVED7 = "RESERVED7"
+	// Number of requests received with reserved opcode 8.
+	CounterRESERVED8 = "RESERVED8"
+	// Number of requests received with reserved opcode 9.
+	CounterRESERVED9 = "RESERVED9"
+	// Number of requests received with reserved opcode 10.
+	CounterRESERVED10 = "RESERVED10"
+	// Number of requests received with reserved opcode 11.
+	CounterRESERVED11 = "RESERVED11"
+	// Number of requests received with reserved opcode 12.
+	CounterRESERVED12 = "RESERVED12"
+	// Number of requests received with reserved opcode 13.
+	CounterRESERVED13 = "RESERVED13"
+	// Number of requests received with reserved opcode 14.
+	CounterRESERVED14 = "RESERVED14"
+	// Number of requests received with reserved opcode 15.
+	CounterRESERVED15 = "RESERVED15"
+)
+
+// Responses sent by rcode, see RcodeCounters.
+const (
+	// Number of NOERROR responses sent.
+	CounterNOERROR = "NOERROR"
+	// Number of NOTIMP responses sent.
+	CounterNOTIMP = "NOTIMP"
+	// Number of YXDOMAIN responses sent.
+	CounterYXDOMAIN = "YXDOMAIN"
+	// Number of YXRRSET responses sent.
+	CounterYXRRSET = "YXRRSET"
+	// Number of NXRRSET responses sent.
+	CounterNXRRSET = "NXRRSET"
+	// Number of NOTAUTH responses sent.
+	CounterNOTAUTH = "NOTAUTH"
+	// Number of NOTZONE responses sent.
+	CounterNOTZONE = "NOTZONE"
+	// Number of BADVERS responses sent.
+	CounterBADVERS = "BADVERS"
+	// Number of BADCOOKIE responses sent.
+	CounterBADCOOKIE = "BADCOOKIE"
+)
+
+// Socket I/O statistics, see SocketCounters.
+const (
+	// Number of IPv4 UDP sockets opened.
+	CounterUDP4Open = "UDP4Open"
+	// Number of failures to open IPv4 UDP sockets.
+	CounterUDP4OpenFail = "UDP4OpenFail"
+	// Number of IPv4 UDP sockets closed.
+	CounterUDP4Close = "UDP4Close"
+	// Number of failures to bind IPv4 UDP sockets.
+	CounterUDP4BindFail = "UDP4BindFail"
+	// Number of failures to connect IPv4 UDP sockets.
+	CounterUDP4ConnFail = "UDP4ConnFail"
+	// Number of IPv4 UDP connections established.
+	CounterUDP4Conn = "UDP4Conn"
+	// Number of errors in IPv4 UDP socket send operations.
+	CounterUDP4SendErr = "UDP4SendErr"
+	// Number of errors in IPv4 UDP socket receive operations.
+	CounterUDP4RecvErr = "UDP4RecvErr"
+	// Number of active IPv4 UDP sockets.
+	CounterUDP4Active = "UDP4Active"
+	// Number of IPv6 UDP sockets opened.
+	CounterUDP6Open = "UDP6Open"
+	// Number of failures to open IPv6 UDP sockets.
+	CounterUDP6OpenFail = "UDP6OpenFail"
+	// Number of IPv6 UDP sockets closed.
+	CounterUDP6Close = "UDP6Close"
+	// Number of failures to bind IPv6 UDP sockets.
+	CounterUDP6BindFail = "UDP6BindFail"
+	// Number of failures to connect IPv6 UDP sockets.
+	CounterUDP6ConnFail = "UDP6ConnFail"
+	// Number of IPv6 UDP connections established.
+	CounterUDP6Conn = "UDP6Conn"
+	// Number of errors in IPv6 UDP socket send operations.
+	CounterUDP6SendErr = "UDP6SendErr"
+	// Number of errors in IPv6 UDP socket receive operations.
+	CounterUDP6RecvErr = "UDP6RecvErr"
+	// Number of active IPv6 UDP sockets.
+	CounterUDP6Active = "UDP6Active"
+	// Number of IPv4 TCP sockets opened.
+	CounterTCP4Open = "TCP4Open"
+	// Number of failures to open IPv4 TCP sockets.
+	CounterTCP4OpenFail = "TCP4OpenFail"
+	// Number of IPv4 TCP sockets closed.
+	CounterTCP4Close = "TCP4Close"
+	// Number of failures to bind IPv4 TCP sockets.
+	CounterTCP4BindFail = "TCP4BindFail"
+	// Number of failures to connect IPv4 TCP sockets.
+	CounterTCP4ConnFail = "TCP4ConnFail"
+	// Number of IPv4 TCP connections established.
+	CounterTCP4Conn = "TCP4Conn"
+	// Number of failures to accept incoming IPv4 TCP connections.
+	CounterTCP4AcceptFail = "TCP4AcceptFail"
+	// Number of incoming IPv4 TCP connections accepted.
+	CounterTCP4Accept = "TCP4Accept"
+	// Number of errors in IPv4 TCP socket send operations.
+	CounterTCP4SendErr = "TCP4SendErr"
+	// Number of errors in IPv4 TCP socket receive operations.
+	CounterTCP4RecvErr = "TCP4RecvErr"
+	// Number of active IPv4 TCP sockets.
+	CounterTCP4Active = "TCP4Active"
+	// Number of IPv6 TCP sockets opened.
+	CounterTCP6Open = "TCP6Open"
+	// Number of failures to open IPv6 TCP sockets.
+	CounterTCP6OpenFail = "TCP6OpenFail"
+	// Number of IPv6 TCP sockets closed.
+	CounterTCP6Close = "TCP6Close"
+	// Number of failures to bind IPv6 TCP sockets.
+	CounterTCP6BindFail = "TCP6BindFail"
+	// Number of failures to connect IPv6 TCP sockets.
+	CounterTCP6ConnFail = "TCP6ConnFail"
+	// Number of IPv6 TCP connections established.
+	CounterTCP6Conn = "TCP6Conn"
+	// Number of failures to accept incoming IPv6 TCP connections.
+	CounterTCP6AcceptFail = "TCP6AcceptFail"
+	// Number of incoming IPv6 TCP connections accepted.
+	CounterTCP6Accept = "TCP6Accept"
+	// Number of errors in IPv6 TCP socket send operations.
+	CounterTCP6SendErr = "TCP6SendErr"
+	// Number of errors in IPv6 TCP socket receive operations.
+	CounterTCP6RecvErr = "TCP6RecvErr"
+	// Number of active IPv6 TCP sockets.
+	CounterTCP6Active = "TCP6Active"
+	// Number of Unix domain sockets opened.
+	CounterUnixOpen = "UnixOpen"
+	// Number of failures to open Unix domain sockets.
+	CounterUnixOpenFail = "UnixOpenFail"
+	// Number of Unix domain sockets closed.
+	CounterUnixClose = "UnixClose"
+	// Number of failures to bind Unix domain sockets.
+	CounterUnixBindFail = "UnixBindFail"
+	// Number of failures to connect Unix domain sockets.
+	CounterUnixConnFail = "UnixConnFail"
+	// Number of Unix domain connections established.
+	CounterUnixConn = "UnixConn"
+	// Number of failures to accept incoming Unix domain connections.
+	CounterUnixAcceptFail = "UnixAcceptFail"
+	// Number of incoming Unix domain connections accepted.
+	CounterUnixAccept = "UnixAccept"
+	// Number of errors in Unix domain socket send operations.
+	CounterUnixSendErr = "UnixSendErr"
+	// Number of errors in Unix domain socket receive operations.
+	CounterUnixRecvErr = "UnixRecvErr"
+	// Number of active Unix domain sockets.
+	CounterUnixActive = "UnixActive"
+	// Number of file descriptor watch sockets closed.
+	CounterFDWatchClose = "FDWatchClose"
+	// Number of failures to bind file descriptor watch sockets.
+	CounterFdwatchBindFail = "FdwatchBindFail"
+	// Number of failures to connect file descriptor watch sockets.
+	CounterFDwatchConnFail = "FDwatchConnFail"
+	// Number of file descriptor watch connections established.
+	CounterFDwatchConn = "FDwatchConn"
+	// Number of errors in file descriptor watch socket send operations.
+	CounterFDwatchSendErr = "FDwatchSendErr"
+	// Number of errors in file descriptor watch socket receive operations.
+	CounterFDwatchRecvErr = "FDwatchRecvErr"
+	// Number of raw sockets opened.
+	CounterRawOpen = "RawOpen"
+	// Number of failures to open raw sockets.
+	CounterRawOpenFail = "RawOpenFail"
+	// Number of raw sockets closed.
+	CounterRawClose = "RawClose"
+	// Number of errors in raw socket receive operations.
+	CounterRawRecvErr = "RawRecvErr"
+	// Number of active raw sockets.
+	CounterRawActive = "RawActive"
+)
+
+// Memory summary, see MemoryCounters.
+const (
+	// Total memory allocated by the memory contexts of named in bytes.
+	CounterTotalUse = "TotalUse"
+	// Memory in use by the memory contexts of named in bytes.
+	CounterInUse = "InUse"
+	// Memory allocated from the operating system by named in bytes.
+	CounterMalloced = "Malloced"
+	// Memory held in blocks by the memory contexts of named in bytes.
+	CounterBlockSize = "BlockSize"
+	// Memory used by the memory contexts themselves in bytes.
+	CounterContextSize = "ContextSize"
+	// Memory lost by the memory contexts of named in bytes.
+	CounterLost = "Lost"
+)
+
+var catalog = []CounterInfo{
+	{Group: NameServerCounters, Name: CounterRequestv4, Kind: KindCounter, Help: "Number of IPv4 requests received."},
+	{Group: NameServerCounters, Name: CounterRequestv6, Kind: KindCounter, Help: "Number of IPv6 requests received."},
+	{Group: NameServerCounters, Name: CounterReqEdns0, Kind: KindCounter, Help: "Number of requests received with EDNS(0)."},
+	{Group: NameServerCounters, Name: CounterReqBadEDNSVer, Kind: KindCounter, Help: "Number of requests received with an unsupported EDNS version."},
+	{Group: NameServerCounters, Name: CounterReqTSIG, Kind: KindCounter, Help: "Number of requests received with TSIG."},
+	{Group: NameServerCounters, Name: CounterReqSIG0, Kind: KindCounter, Help: "Number of requests received with SIG(0)."},
+	{Group: NameServerCounters, Name: CounterReqBadSIG, Kind: KindCounter, Help: "Number of requests received with an invalid TSIG or SIG(0) signature."},
+	{Group: NameServerCounters, Name: CounterReqTCP, Kind: KindCounter, Help: "Number of TCP requests received."},
+	{Group: NameServerCounters, Name: CounterAuthQryRej, Kind: KindCounter, Help: "Number of rejected authoritative queries."},
+	{Group: NameServerCounters, Name: CounterRecQryRej, Kind: KindCounter, Help: "Number of rejected recursive queries."},
+	{Group: NameServerCounters, Name: CounterXfrRej, Kind: KindCounter, Help: "Number of rejected zone transfers."},
+	{Group: NameServerCounters, Name: CounterUpdateRej, Kind: KindCounter, Help: "Number of rejected dynamic update requests."},
+	{Group: NameServerCounters, Name: CounterResponse, Kind: KindCounter, Help: "Number of responses sent."},
+	{Group: NameServerCounters, Name: CounterTruncatedResp, Kind: KindCounter, Help: "Number of truncated responses sent."},
+	{Group: NameServerCounters, Name: CounterRespEDNS0, Kind: KindCounter, Help: "Number of responses sent with EDNS(0)."},
+	{Group: NameServerCounters, Name: CounterRespTSIG, Kind: KindCounter, Help: "Number of responses sent with TSIG."},
+	{Group: NameServerCounters, Name: CounterRespSIG0, Kind: KindCounter, Help: "Number of responses sent with SIG(0)."},
+	{Group: NameServerCounters, Name: CounterQrySuccess, Kind: KindCounter, Help: "Number of queries resulting in a successful answer."},
+	{Group: NameServerCounters, Name: CounterQryAuthAns, Kind: KindCounter, Help: "Number of queries resulting in an authoritative answer."},
+	{Group: NameServerCounters, Name: CounterQryNoauthAns, Kind: KindCounter, Help: "Number of queries resulting in a non-authoritative answer."},
+	{Group: NameServerCounters, Name: CounterQryReferral, Kind: KindCounter, Help: "Number of queries resulting in a referral answer."},
+	{Group: NameServerCounters, Name: CounterQryNxrrset, Kind: KindCounter, Help: "Number of queries resulting in an NXRRSET answer."},
+	{Group: NameServerCounters, Name: CounterQrySERVFAIL, Kind: KindCounter, Help: "Number of queries resulting in a SERVFAIL answer."},
+	{Group: NameServerCounters, Name: CounterQryFORMERR, Kind: KindCounter, Help: "Number of queries resulting in a FORMERR answer."},
+	{Group: NameServerCounters, Name: CounterQryNXDOMAIN, Kind: KindCounter, Help: "Number of queries resulting in an NXDOMAIN answer."},
+	{Group: NameServerCounters, Name: CounterQryRecursion, Kind: KindCounter, Help: "Number of queries causing recursion."},
+	{Group: NameServerCounters, Name: CounterQryDuplicate, Kind: KindCounter, Help: "Number of duplicated queries received."},
+	{Group: NameServerCounters, Name: CounterQryDropped, Kind: KindCounter, Help: "Number of recursive queries dropped due to the recursive client limit."},
+	{Group: NameServerCounters, Name: CounterQryFailure, Kind: KindCounter, Help: "Number of queries failing for other reasons."},
+	{Group: NameServerCounters, Name: CounterQryNXRedir, Kind: KindCounter, Help: "Number of queries resulting in an NXDOMAIN answer which were redirected."},
+	{Group: NameServerCounters, Name: CounterQryNXRedirRLookup, Kind: KindCounter, Help: "Number of queries resulting in an NXDOMAIN answer which were redirected and resulted in a successful remote lookup."},
+	{Group: NameServerCounters, Name: CounterQryBADCOOKIE, Kind: KindCounter, Since: "9.11", Help: "Number of queries answered with BADCOOKIE."},
+	{Group: NameServerCounters, Name: CounterQryUDP, Kind: KindCounter, Since: "9.11", Help: "Number of UDP queries received."},
+	{Group: NameServerCounters, Name: CounterQryTCP, Kind: KindCounter, Since: "9.11", Help: "Number of TCP queries received."},
+	{Group: NameServerCounters, Name: CounterQryUsedStale, Kind: KindCounter, Since: "9.18", Help: "Number of queries answered with stale data."},
+	{Group: NameServerCounters, Name: CounterQryTryStale, Kind: KindCounter, Since: "9.18", Help: "Number of queries for which stale data was tried after stale-answer-client-timeout expired."},
+	{Group: NameServerCounters, Name: CounterXfrReqDone, Kind: KindCounter, Help: "Number of requested zone transfers completed."},
+	{Group: NameServerCounters, Name: CounterUpdateReqFwd, Kind: KindCounter, Help: "Number of dynamic update requests forwarded."},
+	{Group: NameServerCounters, Name: CounterUpdateRespFwd, Kind: KindCounter, Help: "Number of dynamic update responses forwarded."},
+	{Group: NameServerCounters, Name: CounterUpdateFwdFail, Kind: KindCounter, Help: "Number of failed dynamic update forwards."},
+	{Group: NameServerCounters, Name: CounterUpdateDone, Kind: KindCounter, Help: "Number of dynamic updates completed."},
+	{Group: NameServerCounters, Name: CounterUpdateFail, Kind: KindCounter, Help: "Number of failed dynamic updates."},
+	{Group: NameServerCounters, Name: CounterUpdateBadPrereq, Kind: KindCounter, Help: "Number of dynamic updates rejected due to a prerequisite failure."},
+	{Group: NameServerCounters, Name: CounterRecursClients, Kind: KindGauge, Help: "Number of current recursive clients."},
+	{Group: NameServerCounters, Name: CounterDNS64, Kind: KindCounter, Help: "Number of queries answered with DNS64 synthesized data."},
+	{Group: NameServerCounters, Name: CounterRateDropped, Kind: KindCounter, Help: "Number of responses dropped by response rate limiting."},
+	{Group: NameServerCounters, Name: CounterRateSlipped, Kind: KindCounter, Help: "Number of responses truncated by response rate limiting."},
+	{Group: NameServerCounters, Name: CounterRPZRewrites, Kind: KindCounter, Help: "Number of responses rewritten by response policy zones."},
+	{Group: NameServerCounters, Name: CounterRecLimitDropped, Kind: KindCounter, Help: "Number of queries dropped due to the per-client recursion limit."},
+	{Group: NameServerCounters, Name: CounterNSIDOpt, Kind: KindCounter, Help: "Number of requests received with the NSID option."},
+	{Group: NameServerCounters, Name: CounterExpireOpt, Kind: KindCounter, Help: "Number of requests received with the EXPIRE option."},
+	{Group: NameServerCounters, Name: CounterOtherOpt, Kind: KindCounter, Help: "Number of requests received with an unknown EDNS option."},
+	{Group: NameServerCounters, Name: CounterECSOpt, Kind: KindCounter, Since: "9.11", Help: "Number of requests received with the EDNS Client Subnet option."},
+	{Group: NameServerCounters, Name: CounterKeyTagOpt, Kind: KindCounter, Since: "9.11", Help: "Number of requests received with the EDNS KEY-TAG option."},
+	{Group: NameServerCounters, Name: CounterCookieIn, Kind: KindCounter, Since: "9.11", Help: "Number of requests received with a COOKIE option."},
+	{Group: NameServerCounters, Name: CounterCookieNew, Kind: KindCounter, Since: "9.11", Help: "Number of requests received with a COOKIE option with only a client cookie."},
+	{Group: NameServerCounters, Name: CounterCookieBadSize, Kind: KindCounter, Since: "9.11", Help: "Number of requests received with a COOKIE option of invalid size."},
+	{Group: NameServerCounters, Name: CounterCookieBadTime, Kind: KindCounter, Since: "9.11", Help: "Number of requests received with a COOKIE option with a timestamp out of range."},
+	{Group: NameServerCounters, Name: CounterCookieNoMatch, Kind: KindCounter, Since: "9.11", Help: "Number of requests received with a COOKIE option not matching the server cookie."},
+	{Group: NameServerCounters, Name: CounterCookieMatch, Kind: KindCounter, Since: "9.11", Help: "Number of requests received with a COOKIE option matching the server cookie."},
+	{Group: ZoneMaintenanceCounters, Name: CounterNotifyOutv4, Kind: KindCounter, Help: "Number of IPv4 NOTIFY messages sent."},
+	{Group: ZoneMaintenanceCounters, Name: CounterNotifyOutv6, Kind: KindCounter, Help: "Number of IPv6 NOTIFY messages sent."},
+	{Group: ZoneMaintenanceCounters, Name: CounterNotifyInv4, Kind: KindCounter, Help: "Number of IPv4 NOTIFY messages received."},
+	{Group: ZoneMaintenanceCounters, Name: CounterNotifyInv6, Kind: KindCounter, Help: "Number of IPv6 NOTIFY messages received."},
+	{Group: ZoneMaintenanceCounters, Name: CounterNotifyRej, Kind: KindCounter, Help: "Number of rejected incoming NOTIFY messages."},
+	{Group: ZoneMaintenanceCounters, Name: CounterSOAOutv4, Kind: KindCounter, Help: "Number of IPv4 SOA queries sent."},
+	{Group: ZoneMaintenanceCounters, Name: CounterSOAOutv6, Kind: KindCounter, Help: "Number of IPv6 SOA queries sent."},
+	{Group: ZoneMaintenanceCounters, Name: CounterAXFRReqv4, Kind: KindCounter, Help: "Number of IPv4 AXFR requests sent."},
+	{Group: ZoneMaintenanceCounters, Name: CounterAXFRReqv6, Kind: KindCounter, Help: "Number of IPv6 AXFR requests sent."},
+	{Group: ZoneMaintenanceCounters, Name: CounterIXFRReqv4, Kind: KindCounter, Help: "Number of IPv4 IXFR requests sent."},
+	{Group: ZoneMaintenanceCounters, Name: CounterIXFRReqv6, Kind: KindCounter, Help: "Number of IPv6 IXFR requests sent."},
+	{Group: ZoneMaintenanceCounters, Name: CounterXfrSuccess, Kind: KindCounter, Help: "Number of successful zone transfers."},
+	{Group: ZoneMaintenanceCounters, Name: CounterXfrFail, Kind: KindCounter, Help: "Number of failed zone transfers."},
+	{Group: ResolverCounters, Name: CounterQueryv4, Kind: KindCounter, Help: "Number of IPv4 queries sent."},
+	{Group: ResolverCounters, Name: CounterQueryv6, Kind: KindCounter, Help: "Number of IPv6 queries sent."},
+	{Group: ResolverCounters, Name: CounterResponsev4, Kind: KindCounter, Help: "Number of IPv4 responses received."},
+	{Group: ResolverCounters, Name: CounterResponsev6, Kind: KindCounter, Help: "Number of IPv6 responses received."},
+	{Group: ResolverCounters, Name: CounterNXDOMAIN, Kind: KindCounter, Help: "Number of NXDOMAIN responses received."},
+	{Group: ResolverCounters, Name: CounterSERVFAIL, Kind: KindCounter, Help: "Number of SERVFAIL responses received."},
+	{Group: ResolverCounters, Name: CounterFORMERR, Kind: KindCounter, Help: "Number of FORMERR responses received."},
+	{Group: ResolverCounters, Name: CounterREFUSED, Kind: KindCounter, Help: "Number of REFUSED responses received."},
+	{Group: ResolverCounters, Name: CounterOtherError, Kind: KindCounter, Help: "Number of responses received with other errors."},
+	{Group: ResolverCounters, Name: CounterEDNS0Fail, Kind: KindCounter, Help: "Number of EDNS(0) query errors."},
+	{Group: ResolverCounters, Name: CounterMismatch, Kind: KindCounter, Help: "Number of mismatch responses received."},
+	{Group: ResolverCounters, Name: CounterTruncated, Kind: KindCounter, Help: "Number of truncated responses received."},
+	{Group: ResolverCounters, Name: CounterLame, Kind: KindCounter, Help: "Number of lame delegation responses received."},
+	{Group: ResolverCounters, Name: CounterRetry, Kind: KindCounter, Help: "Number of resolver query retries."},
+	{Group: ResolverCounters, Name: CounterQueryAbort, Kind: KindCounter, Help: "Number of queries aborted due to quota control."},
+	{Group: ResolverCounters, Name: CounterQuerySockFail, Kind: KindCounter, Help: "Number of failures in opening query sockets."},
+	{Group: ResolverCounters, Name: CounterQueryCurUDP, Kind: KindGauge, Help: "Number of UDP queries in progress."},
+	{Group: ResolverCounters, Name: CounterQueryCurTCP, Kind: KindGauge, Help: "Number of TCP queries in progress."},
+	{Group: ResolverCounters, Name: CounterQueryTimeout, Kind: KindCounter, Help: "Number of query timeouts."},
+	{Group: ResolverCounters, Name: CounterGlueFetchv4, Kind: KindCounter, Help: "Number of IPv4 NS address fetches invoked."},
+	{Group: ResolverCounters, Name: CounterGlueFetchv6, Kind: KindCounter, Help: "Number of IPv6 NS address fetches invoked."},
+	{Group: ResolverCounters, Name: CounterGlueFetchv4Fail, Kind: KindCounter, Help: "Number of failed IPv4 NS address fetches."},
+	{Group: ResolverCounters, Name: CounterGlueFetchv6Fail, Kind: KindCounter, Help: "Number of failed IPv6 NS address fetches."},
+	{Group: ResolverCounters, Name: CounterValAttempt, Kind: KindCounter, Help: "Number of DNSSEC validation attempts."},
+	{Group: ResolverCounters, Name: CounterValOk, Kind: KindCounter, Help: "Number of successful DNSSEC validations."},
+	{Group: ResolverCounters, Name: CounterValNegOk, Kind: KindCounter, Help: "Number of successful DNSSEC validations of negative responses."},
+	{Group: ResolverCounters, Name: CounterValFail, Kind: KindCounter, Help: "Number of DNSSEC validation attempt errors."},
+	{Group: ResolverCounters, Name: CounterQryRTT10, Kind: KindCounter, Help: "Number of queries answered within 10ms."},
+	{Group: ResolverCounters, Name: CounterQryRTT100, Kind: KindCounter, Help: "Number of queries answered within 100ms."},
+	{Group: ResolverCounters, Name: CounterQryRTT500, Kind: KindCounter, Help: "Number of queries answered within 500ms."},
+	{Group: ResolverCounters, Name: CounterQryRTT800, Kind: KindCounter, Help: "Number of queries answered within 800ms."},
+	{Group: ResolverCounters, Name: CounterQryRTT1600, Kind: KindCounter, Help: "Number of queries answered within 1600ms."},
+	{Group: ResolverCounters, Name: CounterQryRTT1600Plus, Kind: KindCounter, Help: "Number of queries answered after more than 1600ms."},
+	{Group: ResolverCounters, Name: CounterNumFetch, Kind: KindGauge, Help: "Number of active fetches."},
+	{Group: ResolverCounters, Name: CounterBucketSize, Kind: KindGauge, Help: "Number of buckets of the resolver."},
+	{Group: ResolverCounters, Name: CounterZoneQuota, Kind: KindCounter, Help: "Number of queries spilled due to the fetches-per-zone limit."},
+	{Group: ResolverCounters, Name: CounterServerQuota, Kind: KindCounter, Help: "Number of queries spilled due to the fetches-per-server limit."},
+	{Group: ResolverCounters, Name: CounterBadEDNSVersion, Kind: KindCounter, Help: "Number of responses received with an unsupported EDNS version."},
+	{Group: ResolverCounters, Name: CounterNextItem, Kind: KindCounter, Help: "Number of times the resolver waited for the next item after receiving an invalid response."},
+	{Group: ResolverCounters, Name: CounterClientCookieOut, Kind: KindCounter, Since: "9.11", Help: "Number of queries sent with only a client cookie."},
+	{Group: ResolverCounters, Name: CounterServerCookieOut, Kind: KindCounter, Since: "9.11", Help: "Number of queries sent with a client and a server cookie."},
+	{Group: ResolverCounters, Name: CounterCookieIn, Kind: KindCounter, Since: "9.11", Help: "Number of responses received with a COOKIE option."},
+	{Group: ResolverCounters, Name: CounterCookieClientOk, Kind: KindCounter, Since: "9.11", Help: "Number of responses received with a valid client cookie."},
+	{Group: ResolverCounters, Name: CounterBadCookieRcode, Kind: KindCounter, Since: "9.11", Help: "Number of BADCOOKIE responses received."},
+	{Group: CacheCounters, Name: CounterCacheHits, Kind: KindCounter, Help: "Number of lookups of the cache which found data."},
+	{Group: CacheCounters, Name: CounterCacheMisses, Kind: KindCounter, Help: "Number of lookups of the cache which found no data."},
+	{Group: CacheCounters, Name: CounterQueryHits, Kind: KindCounter, Help: "Number of queries answered from the cache."},
+	{Group: CacheCounters, Name: CounterQueryMisses, Kind: KindCounter, Help: "Number of queries which could not be answered from the cache."},
+	{Group: CacheCounters, Name: CounterDeleteLRU, Kind: KindCounter, Help: "Number of cache entries deleted to free memory."},
+	{Group: CacheCounters, Name: CounterDeleteTTL, Kind: KindCounter, Help: "Number of cache entries deleted after their TTL expired."},
+	{Group: CacheCounters, Name: CounterTreeMemTotal, Kind: KindGauge, Unit: UnitBytes, Help: "Memory allocated for the cache database in bytes."},
+	{Group: CacheCounters, Name: CounterTreeMemInUse, Kind: KindGauge, Unit: UnitBytes, Help: "Memory in use by the cache database in bytes."},
+	{Group: CacheCounters, Name: CounterTreeMemMax, Kind: KindGauge, Unit: UnitBytes, Help: "Maximum memory in use by the cache database in bytes."},
+	{Group: CacheCounters, Name: CounterHeapMemTotal, Kind: KindGauge, Unit: UnitBytes, Help: "Memory allocated for the cache heap in bytes."},
+	{Group: CacheCounters, Name: CounterHeapMemInUse, Kind: KindGauge, Unit: UnitBytes, Help: "Memory in use by the cache heap in bytes."},
+	{Group: CacheCounters, Name: CounterHeapMemMax, Kind: KindGauge, Unit: UnitBytes, Help: "Maximum memory in use by the cache heap in bytes."},
+	{Group: OpcodeCounters, Name: CounterQUERY, Kind: KindCounter, Help: "Number of QUERY requests received."},
+	{Group: OpcodeCounters, Name: CounterIQUERY, Kind: KindCounter, Help: "Number of IQUERY requests received."},
+	{Group: OpcodeCounters, Name: CounterSTATUS, Kind: KindCounter, Help: "Number of STATUS requests received."},
+	{Group: OpcodeCounters, Name: CounterRESERVED3, Kind: KindCounter, Help: "Number of requests received with reserved opcode 3."},
+	{Group: OpcodeCounters, Name: CounterNOTIFY, Kind: KindCounter, Help: "Number of NOTIFY requests received."},
+	{Group: OpcodeCounters, Name: CounterUPDATE, Kind: KindCounter, Help: "Number of UPDATE requests received."},
+	{Group: OpcodeCounters, Name: CounterRESERVED6, Kind: KindCounter, Help: "Number of requests received with reserved opcode 6."},
+	{Group: OpcodeCounters, Name: CounterRESERVED7, Kind: KindCounter, Help: "Number of requests received with reserved opcode 7."},
+	{Group: OpcodeCounters, Name: CounterRESERVED8, Kind: KindCounter, Help: "Number of requests received with reserved opcode 8."},
+	{Group: OpcodeCounters, Name: CounterRESERVED9, Kind: KindCounter, Help: "Number of requests received with reserved opcode 9."},
+	{Group: OpcodeCounters, Name: CounterRESERVED10, Kind: KindCounter, Help: "Number of requests received with reserved opcode 10."},
+	{Group: OpcodeCounters, Name: CounterRESERVED11, Kind: KindCounter, Help: "Number of requests received with reserved opcode 11."},
+	{Group: OpcodeCounters, Name: CounterRESERVED12, Kind: KindCounter, Help: "Number of requests received with reserved opcode 12."},
+	{Group: OpcodeCounters, Name: CounterRESERVED13, Kind: KindCounter, Help: "Number of requests received with reserved opcode 13."},
+	{Group: OpcodeCounters, Name: CounterRESERVED14, Kind: KindCounter, Help: "Number of requests received with reserved opcode 14."},
+	{Group: OpcodeCounters, Name: CounterRESERVED15, Kind: KindCounter, Help: "Number of requests received with reserved opcode 15."},
+	{Group: RcodeCounters, Name: CounterNOERROR, Kind: KindCounter, Help: "Number of NOERROR responses sent."},
+	{Group: RcodeCounters, Name: CounterFORMERR, Kind: KindCounter, Help: "Number of FORMERR responses sent."},
+	{Group: RcodeCounters, Name: CounterSERVFAIL, Kind: KindCounter, Help: "Number of SERVFAIL responses sent."},
+	{Group: RcodeCounters, Name: CounterNXDOMAIN, Kind: KindCounter, Help: "Number of NXDOMAIN responses sent."},
+	{Group: RcodeCounters, Name: CounterNOTIMP, Kind: KindCounter, Help: "Number of NOTIMP responses sent."},
+	{Group: RcodeCounters, Name: CounterREFUSED, Kind: KindCounter, Help: "Number of REFUSED responses sent."},
+	{Group: RcodeCounters, Name: CounterYXDOMAIN, Kind: KindCounter, Help: "Number of YXDOMAIN responses sent."},
+	{Group: RcodeCounters, Name: CounterYXRRSET, Kind: KindCounter, Help: "Number of YXRRSET responses sent."},
+	{Group: RcodeCounters, Name: CounterNXRRSET, Kind: KindCounter, Help: "Number of NXRRSET responses sent."},
+	{Group: RcodeCounters, Name: CounterNOTAUTH, Kind: KindCounter, Help: "Number of NOTAUTH responses sent."},
+	{Group: RcodeCounters, Name: CounterNOTZONE, Kind: KindCounter, Help: "Number of NOTZONE responses sent."},
+	{Group: RcodeCounters, Name: CounterRESERVED11, Kind: KindCounter, Help: "Number of responses sent with reserved rcode 11."},
+	{Group: RcodeCounters, Name: CounterRESERVED12, Kind: KindCounter, Help: "Number of responses sent with reserved rcode 12."},
+	{Group: RcodeCounters, Name: CounterRESERVED13, Kind: KindCounter, Help: "Number of responses sent with reserved rcode 13."},
+	{Group: RcodeCounters, Name: CounterRESERVED14, Kind: KindCounter, Help: "Number of responses sent with reserved rcode 14."},
+	{Group: RcodeCounters, Name: CounterRESERVED15, Kind: KindCounter, Help: "Number of responses sent with reserved rcode 15."},
+	{Group: RcodeCounters, Name: CounterBADVERS, Kind: KindCounter, Help: "Number of BADVERS responses sent."},
+	{Group: RcodeCounters, Name: CounterBADCOOKIE, Kind: KindCounter, Since: "9.11", Help: "Number of BADCOOKIE responses sent."},
+	{Group: SocketCounters, Name: CounterUDP4Open, Kind: KindCounter, Help: "Number of IPv4 UDP sockets opened."},
+	{Group: SocketCounters, Name: CounterUDP4OpenFail, Kind: KindCounter, Help: "Number of failures to open IPv4 UDP sockets."},
+	{Group: SocketCounters, Name: CounterUDP4Close, Kind: KindCounter, Help: "Number of IPv4 UDP sockets closed."},
+	{Group: SocketCounters, Name: CounterUDP4BindFail, Kind: KindCounter, Help: "Number of failures to bind IPv4 UDP sockets."},
+	{Group: SocketCounters, Name: CounterUDP4ConnFail, Kind: KindCounter, Help: "Number of failures to connect IPv4 UDP sockets."},
+	{Group: SocketCounters, Name: CounterUDP4Conn, Kind: KindCounter, Help: "Number of IPv4 UDP connections established."},
+	{Group: SocketCounters, Name: CounterUDP4SendErr, Kind: KindCounter, Help: "Number of errors in IPv4 UDP socket send operations."},
+	{Group: SocketCounters, Name: CounterUDP4RecvErr, Kind: KindCounter, Help: "Number of errors in IPv4 UDP socket receive operations."},
+	{Group: SocketCounters, Name: CounterUDP4Active, Kind: KindGauge, Help: "Number of active IPv4 UDP sockets."},
+	{Group: SocketCounters, Name: CounterUDP6Open, Kind: KindCounter, Help: "Number of IPv6 UDP sockets opened."},
+	{Group: SocketCounters, Name: CounterUDP6OpenFail, Kind: KindCounter, Help: "Number of failures to open IPv6 UDP sockets."},
+	{Group: SocketCounters, Name: CounterUDP6Close, Kind: KindCounter, Help: "Number of IPv6 UDP sockets closed."},
+	{Group: SocketCounters, Name: CounterUDP6BindFail, Kind: KindCounter, Help: "Number of failures to bind IPv6 UDP sockets."},
+	{Group: SocketCounters, Name: CounterUDP6ConnFail, Kind: KindCounter, Help: "Number of failures to connect IPv6 UDP sockets."},
+	{Group: SocketCounters, Name: CounterUDP6Conn, Kind: KindCounter, Help: "Number of IPv6 UDP connections established."},
+	{Group: SocketCounters, Name: CounterUDP6SendErr, Kind: KindCounter, Help: "Number of errors in IPv6 UDP socket send operations."},
+	{Group: SocketCounters, Name: CounterUDP6RecvErr, Kind: KindCounter, Help: "Number of errors in IPv6 UDP socket receive operations."},
+	{Group: SocketCounters, Name: CounterUDP6Active, Kind: KindGauge, Help: "Number of active IPv6 UDP sockets."},
+	{Group: SocketCounters, Name: CounterTCP4Open, Kind: KindCounter, Help: "Number of IPv4 TCP sockets opened."},
+	{Group: SocketCounters, Name: CounterTCP4OpenFail, Kind: KindCounter, Help: "Number of failures to open IPv4 TCP sockets."},
+	{Group: SocketCounters, Name: CounterTCP4Close, Kind: KindCounter, Help: "Number of IPv4 TCP sockets closed."},
+	{Group: SocketCounters, Name: CounterTCP4BindFail, Kind: KindCounter, Help: "Number of failures to bind IPv4 TCP sockets."},
+	{Group: SocketCounters, Name: CounterTCP4ConnFail, Kind: KindCounter, Help: "Number of failures to connect IPv4 TCP sockets."},
+	{Group: SocketCounters, Name: CounterTCP4Conn, Kind: KindCounter, Help: "Number of IPv4 TCP connections established."},
+	{Group: SocketCounters, Name: CounterTCP4AcceptFail, Kind: KindCounter, Help: "Number of failures to accept incoming IPv4 TCP connections."},
+	{Group: SocketCounters, Name: CounterTCP4Accept, Kind: KindCounter, Help: "Number of incoming IPv4 TCP connections accepted."},
+	{Group: SocketCounters, Name: CounterTCP4SendErr, Kind: KindCounter, Help: "Number of errors in IPv4 TCP socket send operations."},
+	{Group: SocketCounters, Name: CounterTCP4RecvErr, Kind: KindCounter, Help: "Number of errors in IPv4 TCP socket receive operations."},
+	{Group: SocketCounters, Name: CounterTCP4Active, Kind: KindGauge, Help: "Number of active IPv4 TCP sockets."},
+	{Group: SocketCounters, Name: CounterTCP6Open, Kind: KindCounter, Help: "Number of IPv6 TCP sockets opened."},
+	{Group: SocketCounters, Name: CounterTCP6OpenFail, Kind: KindCounter, Help: "Number of failures to open IPv6 TCP sockets."},
+	{Group: SocketCounters, Name: CounterTCP6Close, Kind: KindCounter, Help: "Number of IPv6 TCP sockets closed."},
+	{Group: SocketCounters, Name: CounterTCP6BindFail, Kind: KindCounter, Help: "Number of failures to bind IPv6 TCP sockets."},
+	{Group: SocketCounters, Name: CounterTCP6ConnFail, Kind: KindCounter, Help: "Number of failures to connect IPv6 TCP sockets."},
+	{Group: SocketCounters, Name: CounterTCP6Conn, Kind: KindCounter, Help: "Number of IPv6 TCP connections established."},
+	{Group: SocketCounters, Name: CounterTCP6AcceptFail, Kind: KindCounter, Help: "Number of failures to accept incoming IPv6 TCP connections."},
+	{Group: SocketCounters, Name: CounterTCP6Accept, Kind: KindCounter, Help: "Number of incoming IPv6 TCP connections accepted."},
+	{Group: SocketCounters, Name: CounterTCP6SendErr, Kind: KindCounter, Help: "Number of errors in IPv6 TCP socket send operations."},
+	{Group: SocketCounters, Name: CounterTCP6RecvErr, Kind: KindCounter, Help: "Number of errors in IPv6 TCP socket receive operations."},
+	{Group: SocketCounters, Name: CounterTCP6Active, Kind: KindGauge, Help: "Number of active IPv6 TCP sockets."},
+	{Group: SocketCounters, Name: CounterUnixOpen, Kind: KindCounter, Help: "Number of Unix domain sockets opened."},
+	{Group: SocketCounters, Name: CounterUnixOpenFail, Kind: KindCounter, Help: "Number of failures to open Unix domain sockets."},
+	{Group: SocketCounters, Name: CounterUnixClose, Kind: KindCounter, Help: "Number of Unix domain sockets closed."},
+	{Group: SocketCounters, Name: CounterUnixBindFail, Kind: KindCounter, Help: "Number of failures to bind Unix domain sockets."},
+	{Group: SocketCounters, Name: CounterUnixConnFail, Kind: KindCounter, Help: "Number of failures to connect Unix domain sockets."},
+	{Group: SocketCounters, Name: CounterUnixConn, Kind: KindCounter, Help: "Number of Unix domain connections established."},
+	{Group: SocketCounters, Name: CounterUnixAcceptFail, Kind: KindCounter, Help: "Number of failures to accept incoming Unix domain connections."},
+	{Group: SocketCounters, Name: CounterUnixAccept, Kind: KindCounter, Help: "Number of incoming Unix domain connections accepted."},
+	{Group: SocketCounters, Name: CounterUnixSendErr, Kind: KindCounter, Help: "Number of errors in Unix domain socket send operations."},
+	{Group: SocketCounters, Name: CounterUnixRecvErr, Kind: KindCounter, Help: "Number of errors in Unix domain socket receive operations."},
+	{Group: SocketCounters, Name: CounterUnixActive, Kind: KindGauge, Help: "Number of active Unix domain sockets."},
+	{Group: SocketCounters, Name: CounterFDWatchClose, Kind: KindCounter, Help: "Number of file descriptor watch sockets closed."},
+	{Group: SocketCounters, Name: CounterFdwatchBindFail, Kind: KindCounter, Help: "Number of failures to bind file descriptor watch sockets."},
+	{Group: SocketCounters, Name: CounterFDwatchConnFail, Kind: KindCounter, Help: "Number of failures to connect file descriptor watch sockets."},
+	{Group: SocketCounters, Name: CounterFDwatchConn, Kind: KindCounter, Help: "Number of file descriptor watch connections established."},
+	{Group: SocketCounters, Name: CounterFDwatchSendErr, Kind: KindCounter, Help: "Number of errors in file descriptor watch socket send operations."},
+	{Group: SocketCounters, Name: CounterFDwatchRecvErr, Kind: KindCounter, Help: "Number of errors in file descriptor watch socket receive operations."},
+	{Group: SocketCounters, Name: CounterRawOpen, Kind: KindCounter, Help: "Number of raw sockets opened."},
+	{Group: SocketCounters, Name: CounterRawOpenFail, Kind: KindCounter, Help: "Number of failures to open raw sockets."},
+	{Group: SocketCounters, Name: CounterRawClose, Kind: KindCounter, Help: "Number of raw sockets closed."},
+	{Group: SocketCounters, Name: CounterRawRecvErr, Kind: KindCounter, Help: "Number of errors in raw socket receive operations."},
+	{Group: SocketCounters, Name: CounterRawActive, Kind: KindGauge, Help: "Number of active raw sockets."},
+	{Group: MemoryCounters, Name: CounterTotalUse, Kind: KindGauge, Unit: UnitBytes, Help: "Total memory allocated by the memory contexts of named in bytes."},
+	{Group: MemoryCounters, Name: CounterInUse, Kind: KindGauge, Unit: UnitBytes, Help: "Memory in use by the memory contexts of named in bytes."},
+	{Group: MemoryCounters, Name: CounterMalloced, Kind: KindGauge, Unit: UnitBytes, Help: "Memory allocated from the operating system by named in bytes."},
+	{Group: MemoryCounters, Name: CounterBlockSize, Kind: KindGauge, Unit: UnitBytes, Help: "Memory held in blocks by the memory contexts of named in bytes."},
+	{Group: MemoryCounters, Name: CounterContextSize, Kind: KindGauge, Unit: UnitBytes, Help: "Memory used by the memory contexts themselves in bytes."},
+	{Group: MemoryCounters, Name: CounterLost, Kind: KindGauge, Unit: UnitBytes, Help: "Memory lost by the memory contexts of named in bytes."},
+}
@@ -0,0 +1,301 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/prometheus-community/bind_exporter/fixtures"
+)
+
+func TestCounterInfoReportedBy(t *testing.T) {
+	tests := []struct {
+		since, version string
+		want           bool
+	}{
+		{since: "", version: "", want: true},
+		{since: "", version: "9.11.31", want: true},
+		{since: "9.18", version: "9.18.24", want: true},
+		{since: "9.18", version: "9.20.0", want: true},
+		{since: "9.18", version: "9.16.48", want: false},
+		{since: "9.18", version: "", want: false},
+	}
+	for _, tt := range tests {
+		e := CounterInfo{Group: NameServerCounters, Name: "QryUsedStale", Since: tt.since}
+		if got := e.ReportedBy(tt.version); got != tt.want {
+			t.Errorf("since %q, version %q: want %t, got %t", tt.since, tt.version, tt.want, got)
+		}
+	}
+}
+
+func TestCounterCatalog(t *testing.T) {
+	seen := map[[2]string]bool{}
+	for _, e := range CounterCatalog() {
+		key := [2]string{string(e.Group), e.Name}
+		if seen[key] {
+			t.Errorf("duplicated catalog entry %+v", e)
+		}
+		seen[key] = true
+		if e.Help == "" {
+			t.Errorf("want help text for %+v", e)
+		}
+		if n := NormalizeCounterName(e.Name); n != e.Name {
+			t.Errorf("want catalog to use current name %q instead of %q", n, e.Name)
+		}
+	}
+}
+
+func TestMetricName(t *testing.T) {
+	for name, want := range map[string]string{
+		CounterTotalUse:    "total_use_bytes",
+		CounterContextSize: "context_size_bytes",
+		CounterMalloced:    "malloced_bytes",
+	} {
+		info, ok := DescribeIn(MemoryCounters, name)
+		if got := info.MetricName(); !ok || got != want {
+			t.Errorf("%s: want metric name %q, got %q", name, want, got)
+		}
+	}
+	if got := (CounterInfo{Name: "QryRTT1600Plus"}).MetricName(); got != "qry_rtt1600_plus" {
+		t.Errorf("want metric name without unit, got %q", got)
+	}
+}
+
+func TestFillZeroCountersUnknownVersion(t *testing.T) {
+	s := Statistics{Server: Server{NameServerStats: []Counter{{Name: "RespTruncated", Counter: 3}}}}
+	if err := FillZeroCounters(&s); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range s.Server.NameServerStats {
+		switch c.Name {
+		case "TruncatedResp":
+			t.Error("want renamed counter not to be filled")
+		case "QryUDP":
+			t.Error("want counters of later releases not to be filled for unknown versions")
+		}
+	}
+	if len(s.Server.NameServerStats) < 10 {
+		t.Errorf("want counters to be filled, got %v", s.Server.NameServerStats)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	info, ok := Describe("GlueFetchv4Fail")
+	if !ok || info.Group != ResolverCounters || info.Kind != KindCounter || info.Help == "" {
+		t.Errorf("want resolver counter, got %+v (found %t)", info, ok)
+	}
+	if info, ok := Describe("UsedStale"); !ok || info.Name != "QryUsedStale" {
+		t.Errorf("want old names to be normalized, got %+v (found %t)", info, ok)
+	}
+	if info, ok := DescribeIn(ResolverCounters, "ServFail"); !ok || info.Name != CounterSERVFAIL {
+		t.Errorf("want mixed-case names to be normalized, got %+v (found %t)", info, ok)
+	}
+	if info, ok := DescribeIn(RcodeCounters, "NXDOMAIN"); !ok || info.Group != RcodeCounters {
+		t.Errorf("want rcode NXDOMAIN, got %+v (found %t)", info, ok)
+	}
+	if info, ok := DescribeIn(ResolverCounters, "NXDOMAIN"); !ok || info.Group != ResolverCounters {
+		t.Errorf("want resolver NXDOMAIN, got %+v (found %t)", info, ok)
+	}
+	if info, ok := DescribeIn(SocketCounters, "TCP4Active"); !ok || info.Kind != KindGauge {
+		t.Errorf("want socket gauge, got %+v (found %t)", info, ok)
+	}
+	if _, ok := Describe("NoSuchCounter"); ok {
+		t.Error("want unknown counter not to be found")
+	}
+}
+
+// uncatalogued lists the statistics of the fixtures which are deliberately
+// missing from the catalog.
+var uncatalogued = map[[2]string]bool{
+	// Unassigned rcodes are reported by number.
+	{"rcode", "17"}: true,
+	{"rcode", "18"}: true,
+	{"rcode", "19"}: true,
+	{"rcode", "20"}: true,
+	{"rcode", "21"}: true,
+	{"rcode", "22"}: true,
+	// The sizes of the cache database are not decoded by the clients.
+	{"cachestats", "CacheNodes"}:   true,
+	{"cachestats", "CacheBuckets"}: true,
+}
+
+// jsonGroups maps the objects of the JSON server document to counter groups.
+var jsonGroups = map[string]CounterGroup{
+	"nsstats":   NameServerCounters,
+	"opcodes":   OpcodeCounters,
+	"rcodes":    RcodeCounters,
+	"zonestats": ZoneMaintenanceCounters,
+	"sockstats": SocketCounters,
+}
+
+func TestCatalogCoversFixtures(t *testing.T) {
+	check := func(file string, g CounterGroup, name string) {
+		if _, ok := DescribeIn(g, name); !ok && !uncatalogued[[2]string{string(g), name}] {
+			t.Errorf("%s: %s counter %q is not in the catalog", file, g, name)
+		}
+	}
+
+	files, err := fs.Glob(fixtures.FS, "*/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		b, err := fs.ReadFile(fixtures.FS, file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch path.Ext(file) {
+		case ".xml":
+			var doc struct {
+				XMLName xml.Name
+				Server  struct {
+					Counters []xmlCounters `xml:"counters"`
+				} `xml:"server"`
+				Views []struct {
+					Counters []xmlCounters `xml:"counters"`
+				} `xml:"views>view"`
+			}
+			if err := xml.Unmarshal(b, &doc); err != nil {
+				t.Fatalf("%s: %s", file, err)
+			}
+			// The XML v2 schema reports counters as elements.
+			if doc.XMLName.Local != "statistics" {
+				continue
+			}
+			counters := doc.Server.Counters
+			for _, v := range doc.Views {
+				counters = append(counters, v.Counters...)
+			}
+			for _, cs := range counters {
+				g := CounterGroup(cs.Type)
+				if _, ok := catalogIndex[g]; !ok {
+					continue
+				}
+				for _, c := range cs.Counters {
+					check(file, g, c.Name)
+				}
+			}
+		case ".json":
+			var doc map[string]json.RawMessage
+			if err := json.Unmarshal(b, &doc); err != nil {
+				t.Fatalf("%s: %s", file, err)
+			}
+			for key, g := range jsonGroups {
+				var cs map[string]json.RawMessage
+				if err := json.Unmarshal(doc[key], &cs); err != nil && doc[key] != nil {
+					t.Fatalf("%s: %s: %s", file, key, err)
+				}
+				for name := range cs {
+					check(file, g, name)
+				}
+			}
+			var views map[string]struct {
+				Resolver struct {
+					Stats      map[string]json.RawMessage `json:"stats"`
+					CacheStats map[string]json.RawMessage `json:"cachestats"`
+				} `json:"resolver"`
+			}
+			if doc["views"] != nil && !strings.HasPrefix(file, "json/zones") {
+				if err := json.Unmarshal(doc["views"], &views); err != nil {
+					t.Fatalf("%s: views: %s", file, err)
+				}
+			}
+			for _, v := range views {
+				for name := range v.Resolver.Stats {
+					check(file, ResolverCounters, name)
+				}
+				for name := range v.Resolver.CacheStats {
+					check(file, CacheCounters, name)
+				}
+			}
+		}
+	}
+}
+
+type xmlCounters struct {
+	Type     string `xml:"type,attr"`
+	Counters []struct {
+		Name string `xml:"name,attr"`
+	} `xml:"counter"`
+}
+
+func TestCounterConstantsInFixtures(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), "catalog_table.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var docs [][]byte
+	files, err := fs.Glob(fixtures.FS, "*/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		b, err := fs.ReadFile(fixtures.FS, file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		docs = append(docs, b)
+	}
+	reported := func(name string) bool {
+		for _, b := range docs {
+			// Counters are attribute values or JSON keys, or elements in
+			// the XML v2 schema.
+			if strings.Contains(string(b), strconv.Quote(name)) || strings.Contains(string(b), "<"+name+">") {
+				return true
+			}
+		}
+		return false
+	}
+	// versionGated reports whether all catalog entries of name are bound to
+	// a release range, which the fixtures need not cover.
+	versionGated := func(name string) bool {
+		for _, e := range catalog {
+			if e.Name == name && e.Since == "" && e.Until == "" {
+				return false
+			}
+		}
+		return true
+	}
+
+	n := 0
+	for _, decl := range f.Decls {
+		d, ok := decl.(*ast.GenDecl)
+		if !ok || d.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range d.Specs {
+			v := spec.(*ast.ValueSpec)
+			name, err := strconv.Unquote(v.Values[0].(*ast.BasicLit).Value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			n++
+			if !reported(name) && !versionGated(name) {
+				t.Errorf("%s: counter %q is neither in a fixture nor version-gated", v.Names[0], name)
+			}
+		}
+	}
+	if n == 0 {
+		t.Error("want counter constants in catalog_table.go")
+	}
+}
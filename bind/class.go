@@ -0,0 +1,86 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+// Classes of zones.
+const (
+	ClassIN = "IN"
+	// ClassCH is the class of the zones of the built-in _bind view, e.g.
+	// version.bind, which answer version probes.
+	ClassCH = "CH"
+)
+
+// QueriesByClass holds the incoming queries of the zones of a view by type,
+// summed by the class of the zones, e.g. ClassIN and ClassCH. Unlike
+// ZoneViews, which only hold IN zones, it includes the zones of all classes,
+// so that e.g. the TXT queries of version probes, which the server counts
+// among its IncomingQueries, can be told apart from those of real traffic.
+//
+// The classes of zones are reported by the XML v3 and JSON v1 statistics of
+// BIND 9.10 and later, and their queries by type only with zone-statistics
+// set to full. A class is listed if any of its zones reported a query type
+// section, even an empty one, and missing otherwise; the queries of such a
+// class are unknown rather than zero. Neither the XML v2 schema nor the server
+// and view sections of any version separate queries by class, and queries of
+// class ANY or for names outside of the zones of the server are not counted
+// by any zone. Zones omitted by WithExcludedZones or the zone limits are not
+// counted either.
+type QueriesByClass map[string][]Counter
+
+// Add adds the counters cs of a zone of class to q, summing counters of the
+// same name and creating q if it is nil. The class is added even if cs is
+// empty.
+func (q *QueriesByClass) Add(class string, cs []Counter) {
+	if *q == nil {
+		*q = QueriesByClass{}
+	}
+	sum := (*q)[class]
+	for _, c := range cs {
+		found := false
+		for i := range sum {
+			if sum[i].Name == c.Name {
+				sum[i].Counter, _ = AddCounters(sum[i].Counter, c.Counter)
+				found = true
+				break
+			}
+		}
+		if !found {
+			sum = append(sum, c)
+		}
+	}
+	(*q)[class] = sum
+}
+
+// Total returns the number of queries of class, and whether q reports the
+// class.
+func (q QueriesByClass) Total(class string) (uint64, bool) {
+	cs, ok := q[class]
+	var n uint64
+	for _, c := range cs {
+		n, _ = AddCounters(n, c.Counter)
+	}
+	return n, ok
+}
+
+// QueriesByClass returns the QueriesByClass of all ZoneViews of s summed. It
+// is nil if no view reports queries by class.
+func (s *Statistics) QueriesByClass() QueriesByClass {
+	var q QueriesByClass
+	for _, v := range s.ZoneViews {
+		for class, cs := range v.QueriesByClass {
+			q.Add(class, cs)
+		}
+	}
+	return q
+}
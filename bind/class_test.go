@@ -0,0 +1,60 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQueriesByClass(t *testing.T) {
+	var q QueriesByClass
+	q.Add(ClassIN, []Counter{{Name: "A", Counter: 3}, {Name: "TXT", Counter: 1}})
+	q.Add(ClassIN, []Counter{{Name: "TXT", Counter: 2}})
+	q.Add(ClassCH, nil)
+	if want := (QueriesByClass{ClassIN: {{Name: "A", Counter: 3}, {Name: "TXT", Counter: 3}}, ClassCH: nil}); !reflect.DeepEqual(q, want) {
+		t.Errorf("want %v, got %v", want, q)
+	}
+	if n, ok := q.Total(ClassIN); n != 6 || !ok {
+		t.Errorf("want 6 IN queries, got %d (ok %t)", n, ok)
+	}
+	if n, ok := q.Total(ClassCH); n != 0 || !ok {
+		t.Errorf("want CH class reported without queries, got %d (ok %t)", n, ok)
+	}
+	if _, ok := q.Total("HS"); ok {
+		t.Error("want HS class not reported")
+	}
+
+	prev := Statistics{ZoneViews: []ZoneView{{Name: "_bind", QueriesByClass: QueriesByClass{ClassCH: {{Name: "TXT", Counter: 10}}}}}}
+	cur := prev.Clone()
+	cur.ZoneViews[0].QueriesByClass[ClassCH][0].Counter = 15
+	if prev.ZoneViews[0].QueriesByClass[ClassCH][0].Counter != 10 {
+		t.Error("want clone to copy the queries by class")
+	}
+	d, err := Delta(prev, cur)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := d.ZoneViews[0].QueriesByClass.Total(ClassCH); n != 5 {
+		t.Errorf("want 5 CH queries since prev, got %d", n)
+	}
+	// Removing a zone decreases the sum of its class.
+	cur.ZoneViews[0].QueriesByClass[ClassCH][0].Counter = 4
+	if d, err = Delta(prev, cur); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := d.ZoneViews[0].QueriesByClass.Total(ClassCH); n != 4 {
+		t.Errorf("want CH queries to start at zero, got %d", n)
+	}
+}
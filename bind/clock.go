@@ -0,0 +1,51 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"github.com/prometheus-community/bind_exporter/bind/internal/clock"
+)
+
+// Clock is the source of the local time of the package. The clients, the
+// client wrappers and the Poller read the time and wait for retries, rate
+// limits, read timeouts and intervals through it, see WithClock, so that
+// tests can control time with a fake clock like bindtest.FakeClock. Its
+// methods are NewTimer and AfterFunc, which work like their counterparts of
+// the time package, and Now.
+type Clock = clock.Clock
+
+// Timer is a timer created by a Clock, see time.Timer. Its channel is
+// returned by C.
+type Timer = clock.Timer
+
+// SystemClock returns the Clock of the system, which is used unless another
+// clock is set. It calls the time package directly.
+func SystemClock() Clock {
+	return clock.System{}
+}
+
+// WithClock makes clients read the local time and wait through c, e.g. for
+// the timing of requests, the clock skew and retries. Wrappers like
+// NewFreshnessClient and NewRateLimitedClient accept it as well. It defaults
+// to SystemClock.
+func WithClock(c Clock) ClientOption {
+	return func(o *ClientOptions) {
+		o.Clock = c
+	}
+}
+
+// clockOf returns the Clock set by opts.
+func clockOf(opts []ClientOption) Clock {
+	return NewClientOptions(opts...).Clock
+}
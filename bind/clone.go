@@ -0,0 +1,161 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+// Clone returns a deep copy of s, which shares no memory with s and may thus
+// be modified freely, e.g. statistics shared by WithCoalescing. Only the values
+// of the Extensions, which are opaque to the package, are shared.
+func (s Statistics) Clone() Statistics {
+	c := s
+	c.zones = nil
+	c.indexed = nil
+	c.Server = s.Server.clone()
+	if s.Views != nil {
+		c.Views = make([]View, len(s.Views))
+		for i, v := range s.Views {
+			c.Views[i] = v.clone()
+		}
+	}
+	if s.ZoneViews != nil {
+		c.ZoneViews = make([]ZoneView, len(s.ZoneViews))
+		for i, v := range s.ZoneViews {
+			c.ZoneViews[i] = v.clone()
+		}
+	}
+	c.TaskManager.Tasks = cloneSlice(s.TaskManager.Tasks)
+	c.Memory.Contexts = cloneSlice(s.Memory.Contexts)
+	c.MissingGroups = cloneSlice(s.MissingGroups)
+	c.Warnings = cloneSlice(s.Warnings)
+	if s.Decode != nil {
+		c.Decode = make(map[StatisticGroup]DecodeInfo, len(s.Decode))
+		for g, i := range s.Decode {
+			if i.SectionBytes != nil {
+				sections := make(map[string]int64, len(i.SectionBytes))
+				for k, n := range i.SectionBytes {
+					sections[k] = n
+				}
+				i.SectionBytes = sections
+			}
+			c.Decode[g] = i
+		}
+	}
+	if s.Extensions != nil {
+		c.Extensions = make(map[string]any, len(s.Extensions))
+		c.AddExtensions(s.Extensions)
+	}
+	return c
+}
+
+func (s Server) clone() Server {
+	s.IncomingQueries = cloneSlice(s.IncomingQueries)
+	s.IncomingRequests = cloneSlice(s.IncomingRequests)
+	s.NameServerStats = cloneSlice(s.NameServerStats)
+	s.ZoneMaintenance = cloneSlice(s.ZoneMaintenance)
+	s.ZoneStatistics = cloneSlice(s.ZoneStatistics)
+	s.ServerRcodes = cloneSlice(s.ServerRcodes)
+	s.Extra = s.Extra.clone()
+	return s
+}
+
+func (v View) clone() View {
+	v.Cache = cloneSlice(v.Cache)
+	v.CacheMemory = cloneSlice(v.CacheMemory)
+	v.CacheStats = cloneSlice(v.CacheStats)
+	v.ResolverStats = cloneSlice(v.ResolverStats)
+	v.ResolverGauges = cloneSlice(v.ResolverGauges)
+	v.ResolverQueries = cloneSlice(v.ResolverQueries)
+	v.QueryRTT.Buckets = cloneSlice(v.QueryRTT.Buckets)
+	v.Extra = v.Extra.clone()
+	return v
+}
+
+func (v ZoneView) clone() ZoneView {
+	v.QueriesByClass = QueriesByClass(Extra(v.QueriesByClass).clone())
+	if v.ZoneData == nil {
+		return v
+	}
+	zones := make([]ZoneCounter, len(v.ZoneData))
+	for i, z := range v.ZoneData {
+		z.ZoneStats = cloneSlice(z.ZoneStats)
+		z.DNSSECSignStats = cloneSlice(z.DNSSECSignStats)
+		z.DNSSECRefreshStats = cloneSlice(z.DNSSECRefreshStats)
+		z.QueryResults = cloneSlice(z.QueryResults)
+		z.IncomingQueries = cloneSlice(z.IncomingQueries)
+		z.NameServerStats = cloneSlice(z.NameServerStats)
+		z.Extra = z.Extra.clone()
+		zones[i] = z
+	}
+	v.ZoneData = zones
+	return v
+}
+
+// cloneSlice returns a copy of s, which is nil if s is nil.
+func cloneSlice[T any](s []T) []T {
+	if s == nil {
+		return nil
+	}
+	return append(make([]T, 0, len(s)), s...)
+}
+
+// TrimZero returns a deep copy of s, see Clone, without the counters whose
+// value is zero, e.g. to archive statistics compactly. Gauges, histograms and
+// tasks are kept as zero is a meaningful value for them. Counter lists left
+// empty are nil.
+func (s Statistics) TrimZero() Statistics {
+	c := s.Clone()
+	c.Server.IncomingQueries = trimZero(c.Server.IncomingQueries)
+	c.Server.IncomingRequests = trimZero(c.Server.IncomingRequests)
+	c.Server.NameServerStats = trimZero(c.Server.NameServerStats)
+	c.Server.ZoneMaintenance = trimZero(c.Server.ZoneMaintenance)
+	c.Server.ZoneStatistics = trimZero(c.Server.ZoneStatistics)
+	c.Server.ServerRcodes = trimZero(c.Server.ServerRcodes)
+	c.Server.Extra = c.Server.Extra.trimZero()
+	for i := range c.Views {
+		v := &c.Views[i]
+		v.CacheStats = trimZero(v.CacheStats)
+		v.ResolverStats = trimZero(v.ResolverStats)
+		v.ResolverQueries = trimZero(v.ResolverQueries)
+		v.Extra = v.Extra.trimZero()
+	}
+	for i := range c.ZoneViews {
+		for j := range c.ZoneViews[i].ZoneData {
+			z := &c.ZoneViews[i].ZoneData[j]
+			z.ZoneStats = trimZero(z.ZoneStats)
+			z.DNSSECSignStats = trimZero(z.DNSSECSignStats)
+			z.DNSSECRefreshStats = trimZero(z.DNSSECRefreshStats)
+			z.QueryResults = trimZero(z.QueryResults)
+			z.IncomingQueries = trimZero(z.IncomingQueries)
+			z.NameServerStats = trimZero(z.NameServerStats)
+			z.Extra = z.Extra.trimZero()
+		}
+		v := &c.ZoneViews[i]
+		v.QueriesByClass = QueriesByClass(Extra(v.QueriesByClass).trimZero())
+	}
+	return c
+}
+
+// trimZero removes the zero counters of cs in place.
+func trimZero(cs []Counter) []Counter {
+	n := 0
+	for _, c := range cs {
+		if c.Counter != 0 {
+			cs[n] = c
+			n++
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+	return cs[:n]
+}
@@ -0,0 +1,196 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fullStatistics returns statistics with every slice holding an element,
+// including zero counters and gauges.
+func fullStatistics() Statistics {
+	cs := func() []Counter { return []Counter{{Name: "A", Counter: 1}, {Name: "B"}} }
+	return Statistics{
+		Source: Source{Format: FormatXMLv3, FetchTime: time.Unix(10, 0)},
+		Server: Server{
+			BootTime:         time.Unix(1, 0),
+			IncomingQueries:  cs(),
+			IncomingRequests: cs(),
+			NameServerStats:  cs(),
+			ZoneMaintenance:  cs(),
+			ZoneStatistics:   cs(),
+			ServerRcodes:     cs(),
+			Extra:            Extra{"future": cs()},
+		},
+		Views: []View{{
+			Name:            "_default",
+			Cache:           []Gauge{{Name: "A", Gauge: 1}, {Name: "B"}},
+			CacheMemory:     []Gauge{{Name: "TreeMemInUse"}},
+			CacheStats:      []Counter{{Name: "QueryHits", Counter: 1}},
+			ResolverStats:   cs(),
+			ResolverGauges:  []Gauge{{Name: "NumFetch"}},
+			ResolverQueries: cs(),
+			QueryRTT:        Histogram{Buckets: []Bucket{{UpperBound: 0.01}}},
+			Extra:           Extra{"future": cs()},
+		}},
+		ZoneViews: []ZoneView{{Name: "_default", ZoneData: []ZoneCounter{{
+			Name:               "example.com",
+			Serial:             "1",
+			ZoneStats:          cs(),
+			DNSSECSignStats:    cs(),
+			DNSSECRefreshStats: cs(),
+			QueryResults:       cs(),
+			IncomingQueries:    cs(),
+			NameServerStats:    cs(),
+			Extra:              Extra{"future": cs()},
+		}}, QueriesByClass: QueriesByClass{ClassIN: cs()}}},
+		TaskManager:   TaskManager{Tasks: []Task{{ID: "0x1", Name: "res"}}},
+		Memory:        Memory{Summary: MemorySummary{InUse: 1}, Contexts: []MemoryContext{{ID: "0x1", Name: "main", InUse: 1}}},
+		MissingGroups: []StatisticGroup{TaskStats},
+		Warnings:      []Warning{{Code: "code"}},
+		Decode:        map[StatisticGroup]DecodeInfo{ServerStats: {Views: 1, SectionBytes: map[string]int64{"views": 1}}},
+		Extensions:    map[string]any{"custom": 1},
+	}
+}
+
+// walk calls f for every value reachable from v, which must be addressable.
+// Map values are not addressable, but the elements of slices held by maps
+// are.
+func walk(v reflect.Value, path string, f func(reflect.Value, string)) {
+	f(v, path)
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(time.Time{}) {
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			// Unexported fields only hold caches, e.g. the zone index.
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			walk(v.Field(i), path+"."+v.Type().Field(i).Name, f)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			walk(v.Index(i), fmt.Sprintf("%s[%d]", path, i), f)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			walk(iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key()), f)
+		}
+	}
+}
+
+func TestFullStatistics(t *testing.T) {
+	s := fullStatistics()
+	walk(reflect.ValueOf(&s).Elem(), "s", func(v reflect.Value, path string) {
+		if (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0 {
+			t.Errorf("want %s to be populated", path)
+		}
+	})
+}
+
+func TestClone(t *testing.T) {
+	orig := fullStatistics()
+	c := orig.Clone()
+	if !reflect.DeepEqual(orig, c) {
+		t.Fatalf("want clone equal to original")
+	}
+
+	// Modify every value reachable from the clone. Struct values held by
+	// maps cannot be modified in place and are copied anyway.
+	walk(reflect.ValueOf(&c).Elem(), "c", func(v reflect.Value, _ string) {
+		if !v.CanSet() {
+			return
+		}
+		switch v.Kind() {
+		case reflect.String:
+			v.SetString(v.String() + "x")
+		case reflect.Uint64:
+			v.SetUint(v.Uint() + 1)
+		case reflect.Int64:
+			v.SetInt(v.Int() + 1)
+		case reflect.Float64:
+			v.SetFloat(v.Float() + 1)
+		}
+	})
+	if want := fullStatistics(); !reflect.DeepEqual(want, orig) {
+		t.Errorf("want original unchanged by modifying clone, got %+v", orig)
+	}
+
+	if c := (Statistics{}).Clone(); !reflect.DeepEqual(Statistics{}, c) {
+		t.Errorf("want nil slices to stay nil, got %+v", c)
+	}
+}
+
+func TestTrimZero(t *testing.T) {
+	orig := fullStatistics()
+	trimmed := orig.TrimZero()
+	if want := fullStatistics(); !reflect.DeepEqual(want, orig) {
+		t.Errorf("want original unchanged by TrimZero")
+	}
+	walk(reflect.ValueOf(&trimmed).Elem(), "s", func(v reflect.Value, path string) {
+		if c, ok := v.Interface().(Counter); ok && c.Counter == 0 {
+			t.Errorf("want zero counter %s removed", path)
+		}
+	})
+	if got := trimmed.Views[0].Cache; len(got) != 2 {
+		t.Errorf("want zero gauges kept, got %v", got)
+	}
+	if got := trimmed.Server.IncomingQueries; len(got) != 1 || got[0].Name != "A" {
+		t.Errorf("want nonzero counters kept, got %v", got)
+	}
+	if s := (Statistics{Server: Server{IncomingQueries: []Counter{{Name: "A"}}}}).TrimZero(); s.Server.IncomingQueries != nil {
+		t.Errorf("want empty counter list nil, got %v", s.Server.IncomingQueries)
+	}
+}
+
+// largeStatistics returns statistics of a server with n zones.
+func largeStatistics(n int) Statistics {
+	s := fullStatistics()
+	zones := make([]ZoneCounter, n)
+	for i := range zones {
+		zones[i] = s.ZoneViews[0].ZoneData[0]
+		zones[i].Name = fmt.Sprintf("zone%d.example", i)
+		zones[i].QueryResults = make([]Counter, 12)
+		zones[i].IncomingQueries = make([]Counter, 8)
+		for j := range zones[i].QueryResults {
+			zones[i].QueryResults[j] = Counter{Name: "Qry", Counter: uint64(j % 2)}
+		}
+	}
+	s.ZoneViews[0].ZoneData = zones
+	return s
+}
+
+func BenchmarkClone(b *testing.B) {
+	s := largeStatistics(10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Clone()
+	}
+}
+
+func BenchmarkTrimZero(b *testing.B) {
+	s := largeStatistics(10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.TrimZero()
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Report compares the counters reported by a server with the catalog, see
+// CompatibilityReport.
+type Report struct {
+	// BINDVersion is the version of the server.
+	BINDVersion string
+	// Sections holds a section per counter group of the catalog, followed
+	// by the sections unknown to the catalog, e.g. Extra sections of a new
+	// BIND release, in the order of their names.
+	Sections []SectionReport
+}
+
+// SectionReport compares the counters of a section with the catalog. The
+// lists are sorted and never nil.
+type SectionReport struct {
+	Group CounterGroup
+	// Present lists the counters of the catalog reported by the server,
+	// with their catalog names, see NormalizeCounterName.
+	Present []string
+	// Missing lists the counters of the catalog which the server version
+	// should report but did not. BIND omits counters which have never been
+	// incremented, so an idle server misses counters it does support.
+	Missing []string
+	// Unknown lists the counters reported by the server which are not in the
+	// catalog. All counters of sections unknown to the catalog are unknown.
+	Unknown []string
+}
+
+// CompatibilityReport fetches the server and view statistics from c and
+// compares their counters with the catalog, see CompatibilityReportOf.
+func CompatibilityReport(ctx context.Context, c Client) (Report, error) {
+	s, err := c.Stats(ctx, ServerStats, ViewStats)
+	if err != nil {
+		return Report{}, err
+	}
+	return CompatibilityReportOf(s), nil
+}
+
+// CompatibilityReportOf compares the counters of the server and the views of s
+// with the catalog. The counters of all views are combined. Socket counters
+// are not decoded by the clients and thus not reported, neither is the memory
+// summary, which is decoded into the fields of MemorySummary, and the
+// counters of query types, which the catalog does not list, are ignored.
+func CompatibilityReportOf(s Statistics) Report {
+	names := map[CounterGroup]map[string]bool{}
+	add := func(g CounterGroup, cs []Counter) {
+		if names[g] == nil {
+			names[g] = map[string]bool{}
+		}
+		for _, c := range cs {
+			names[g][c.Name] = true
+		}
+	}
+	add(NameServerCounters, s.Server.NameServerStats)
+	add(OpcodeCounters, s.Server.IncomingRequests)
+	add(RcodeCounters, s.Server.ServerRcodes)
+	add(ZoneMaintenanceCounters, s.Server.ZoneMaintenance)
+	for t, cs := range s.Server.Extra {
+		add(CounterGroup(t), cs)
+	}
+	add(ResolverCounters, nil)
+	add(CacheCounters, nil)
+	for _, v := range s.Views {
+		add(CacheCounters, v.CacheStats)
+		for _, g := range v.CacheMemory {
+			add(CacheCounters, []Counter{{Name: g.Name}})
+		}
+		add(ResolverCounters, v.ResolverStats)
+		for _, g := range v.ResolverGauges {
+			add(ResolverCounters, []Counter{{Name: g.Name}})
+		}
+		for t, cs := range v.Extra {
+			add(CounterGroup(t), cs)
+		}
+	}
+
+	r := Report{BINDVersion: s.Source.BINDVersion}
+	var unknown []SectionReport
+	for g, ns := range names {
+		sec := SectionReport{Group: g, Present: []string{}, Missing: []string{}, Unknown: []string{}}
+		present := map[string]bool{}
+		for n := range ns {
+			if info, ok := DescribeIn(g, n); ok {
+				if !present[info.Name] {
+					present[info.Name] = true
+					sec.Present = append(sec.Present, info.Name)
+				}
+			} else {
+				sec.Unknown = append(sec.Unknown, n)
+			}
+		}
+		sort.Strings(sec.Present)
+		sort.Strings(sec.Unknown)
+		if _, ok := catalogIndex[g]; !ok {
+			unknown = append(unknown, sec)
+			continue
+		}
+		for _, c := range catalog {
+			if c.Group == g && !present[c.Name] && c.ReportedBy(s.Source.BINDVersion) {
+				sec.Missing = append(sec.Missing, c.Name)
+			}
+		}
+		sort.Strings(sec.Missing)
+		r.Sections = append(r.Sections, sec)
+	}
+	// The catalog groups keep the order of the catalog.
+	order := map[CounterGroup]int{}
+	for _, c := range catalog {
+		if _, ok := order[c.Group]; !ok {
+			order[c.Group] = len(order)
+		}
+	}
+	sort.Slice(r.Sections, func(i, j int) bool { return order[r.Sections[i].Group] < order[r.Sections[j].Group] })
+	sort.Slice(unknown, func(i, j int) bool { return unknown[i].Group < unknown[j].Group })
+	r.Sections = append(r.Sections, unknown...)
+	return r
+}
+
+// String returns the report as text with a line per section, followed by
+// indented lines listing the missing and unknown counters.
+func (r Report) String() string {
+	var b strings.Builder
+	version := r.BINDVersion
+	if version == "" {
+		version = "unknown version"
+	}
+	fmt.Fprintf(&b, "BIND %s\n", version)
+	for _, sec := range r.Sections {
+		fmt.Fprintf(&b, "%s: %d present, %d missing, %d unknown\n", sec.Group, len(sec.Present), len(sec.Missing), len(sec.Unknown))
+		if len(sec.Missing) > 0 {
+			fmt.Fprintf(&b, "  missing: %s\n", strings.Join(sec.Missing, ", "))
+		}
+		if len(sec.Unknown) > 0 {
+			fmt.Fprintf(&b, "  unknown: %s\n", strings.Join(sec.Unknown, ", "))
+		}
+	}
+	return b.String()
+}
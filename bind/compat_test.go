@@ -0,0 +1,145 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/bindtest"
+	bindjson "github.com/prometheus-community/bind_exporter/bind/json"
+	"github.com/prometheus-community/bind_exporter/bind/xml"
+	"github.com/prometheus-community/bind_exporter/fixtures"
+)
+
+func TestCompatibilityReport(t *testing.T) {
+	// compatibility returns the report for the server document file,
+	// served together with the default fixtures of the other documents.
+	compatibility := func(file string) bind.Report {
+		ts := bindtest.NewServerWith(map[string]string{xml.ServerPath: file, bindjson.ServerPath: file})
+		defer ts.Close()
+		var c bind.Client = xml.NewClient(ts.URL, nil)
+		if strings.HasPrefix(file, "json/") {
+			c = bindjson.NewClient(ts.URL, nil)
+		}
+		r, err := bind.CompatibilityReport(context.Background(), c)
+		if err != nil {
+			t.Fatalf("%s: %s", file, err)
+		}
+		return r
+	}
+	section := func(r bind.Report, g bind.CounterGroup) bind.SectionReport {
+		for _, s := range r.Sections {
+			if s.Group == g {
+				return s
+			}
+		}
+		return bind.SectionReport{}
+	}
+
+	// groups are the decoded catalog groups in the order of the catalog.
+	var groups []bind.CounterGroup
+	for _, c := range bind.CounterCatalog() {
+		if c.Group != bind.SocketCounters && c.Group != bind.MemoryCounters && (len(groups) == 0 || groups[len(groups)-1] != c.Group) {
+			groups = append(groups, c.Group)
+		}
+	}
+
+	files, err := fs.Glob(fixtures.FS, "*/server*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		if file == "xml/server-blank.xml" {
+			continue
+		}
+		r := compatibility(file)
+		if r.BINDVersion == "" {
+			t.Errorf("%s: want BIND version", file)
+		}
+		catalogued := 0
+		for _, s := range r.Sections {
+			switch s.Group {
+			case bind.NameServerCounters, bind.OpcodeCounters, bind.RcodeCounters, bind.ZoneMaintenanceCounters, bind.ResolverCounters, bind.CacheCounters:
+				catalogued++
+				// Only unassigned rcodes are reported by number.
+				for _, n := range s.Unknown {
+					if s.Group != bind.RcodeCounters || strings.Trim(strings.TrimPrefix(n, "RCODE"), "0123456789") != "" {
+						t.Errorf("%s: unknown %s counter %q", file, s.Group, n)
+					}
+				}
+			}
+		}
+		if catalogued != 6 {
+			t.Errorf("%s: want the 6 decoded catalog groups, got %v", file, r.Sections)
+		}
+		for i, g := range groups {
+			if r.Sections[i].Group != g {
+				t.Errorf("%s: want section %d to be %s, got %s", file, i, g, r.Sections[i].Group)
+			}
+		}
+	}
+
+	for _, file := range []string{"xml/server-future.xml", "json/server-future.json"} {
+		r := compatibility(file)
+		if got, want := section(r, "quic").Unknown, []string{"QUICConnFail", "QUICConnIn"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: want unknown quic counters %v, got %v", file, want, got)
+		}
+		if got, want := section(r, "resquic").Unknown, []string{"QUICQueryv4"}; !reflect.DeepEqual(got[:1], want) {
+			t.Errorf("%s: want unknown resquic counters %v, got %v", file, want, got)
+		}
+		if n := len(r.Sections); r.Sections[n-2].Group != "quic" || r.Sections[n-1].Group != "resquic" {
+			t.Errorf("%s: want unknown sections last, got %v", file, r.Sections)
+		}
+	}
+
+	r := compatibility("xml/server.xml")
+	ns := section(r, bind.NameServerCounters)
+	if !contains(ns.Present, bind.CounterQrySuccess) || contains(ns.Missing, bind.CounterQrySuccess) {
+		t.Errorf("want QrySuccess present, got %+v", ns)
+	}
+	for _, n := range ns.Missing {
+		if info, _ := bind.DescribeIn(bind.NameServerCounters, n); !info.ReportedBy(r.BINDVersion) {
+			t.Errorf("want only counters of %s missing, got %s", r.BINDVersion, n)
+		}
+	}
+	if !strings.HasPrefix(r.String(), "BIND 9.11.31\nnsstat: ") {
+		t.Errorf("unexpected report:\n%s", r)
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded bind.Report
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, r) {
+		t.Errorf("want report to survive JSON, got %s", b)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,223 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ConsistencyTolerance tells how much the counters of the XML and JSON
+// statistics of a server may differ to be taken as consistent, see
+// VerifyFormatConsistency. The zero value requires equal counters.
+type ConsistencyTolerance struct {
+	// Absolute is the largest difference of the values of a counter taken
+	// as consistent.
+	Absolute uint64
+	// Monotonic takes a counter as consistent if its value in the
+	// statistics fetched last is not smaller than in those fetched first,
+	// as the counters keep increasing between the fetches.
+	Monotonic bool
+}
+
+// DefaultConsistencyTolerance is the tolerance of VerifyFormatConsistency.
+var DefaultConsistencyTolerance = ConsistencyTolerance{Monotonic: true}
+
+// ConsistencyReport lists the counters which differ between the XML and JSON
+// statistics of a server. Counters are identified by paths like those of the
+// errors of Delta, e.g. "server/nsstats/QrySuccess",
+// "views/_default/resstats/Queryv4" and "zones/_default/example.com/qtypes/A",
+// with names normalized like IndexedStats does.
+type ConsistencyReport struct {
+	// BINDVersion is the version of the server.
+	BINDVersion string
+	// Compared is the number of counters reported in both formats.
+	Compared int
+	// Differences lists the counters whose values differ beyond the
+	// tolerance, sorted by path.
+	Differences []CounterDifference
+	// OnlyXML and OnlyJSON list the counters of sections which only one of
+	// the formats reports, sorted by path, with a value of zero for the
+	// other format. Counters of zero are not listed, as BIND omits counters
+	// which have never been incremented; for the same reason a counter
+	// missing from a section reported by both formats is compared as zero.
+	OnlyXML  []CounterDifference
+	OnlyJSON []CounterDifference
+}
+
+// CounterDifference is a counter of a ConsistencyReport with its values in
+// both formats.
+type CounterDifference struct {
+	Path string
+	XML  uint64
+	JSON uint64
+}
+
+// Consistent reports whether the formats reported the same counters within
+// the tolerance.
+func (r ConsistencyReport) Consistent() bool {
+	return len(r.Differences) == 0 && len(r.OnlyXML) == 0 && len(r.OnlyJSON) == 0
+}
+
+// String returns the report as text with a line per difference.
+func (r ConsistencyReport) String() string {
+	var b strings.Builder
+	version := r.BINDVersion
+	if version == "" {
+		version = "unknown version"
+	}
+	fmt.Fprintf(&b, "BIND %s: %d counters compared, %d differ, %d only in XML, %d only in JSON\n", version, r.Compared, len(r.Differences), len(r.OnlyXML), len(r.OnlyJSON))
+	for _, d := range r.Differences {
+		fmt.Fprintf(&b, "  %s: XML %d, JSON %d\n", d.Path, d.XML, d.JSON)
+	}
+	for _, d := range r.OnlyXML {
+		fmt.Fprintf(&b, "  %s: XML %d, not in JSON\n", d.Path, d.XML)
+	}
+	for _, d := range r.OnlyJSON {
+		fmt.Fprintf(&b, "  %s: JSON %d, not in XML\n", d.Path, d.JSON)
+	}
+	return b.String()
+}
+
+// VerifyFormatConsistency fetches the groups, by default ServerStats and
+// ViewStats, from the XML client first and then from the JSON client of the
+// same server and compares their counters with DefaultConsistencyTolerance,
+// see ConsistencyTolerance.Verify. It tells whether moving from one client to
+// the other changes the numbers, and serves as integration test of the
+// clients against a real named.
+func VerifyFormatConsistency(ctx context.Context, xmlClient, jsonClient Client, groups ...StatisticGroup) (ConsistencyReport, error) {
+	return DefaultConsistencyTolerance.Verify(ctx, xmlClient, jsonClient, groups...)
+}
+
+// Verify is VerifyFormatConsistency with the tolerance t. It returns an error
+// if a fetch fails, if the clients do not return the XML and JSON formats, or
+// if their statistics report different versions of BIND.
+func (t ConsistencyTolerance) Verify(ctx context.Context, xmlClient, jsonClient Client, groups ...StatisticGroup) (ConsistencyReport, error) {
+	if len(groups) == 0 {
+		groups = []StatisticGroup{ServerStats, ViewStats}
+	}
+	x, err := xmlClient.Stats(ctx, groups...)
+	if err != nil {
+		return ConsistencyReport{}, fmt.Errorf("fetching XML statistics: %w", err)
+	}
+	j, err := jsonClient.Stats(ctx, groups...)
+	if err != nil {
+		return ConsistencyReport{}, fmt.Errorf("fetching JSON statistics: %w", err)
+	}
+	if x.Source.Format != FormatXMLv3 || j.Source.Format != FormatJSONv1 {
+		return ConsistencyReport{}, fmt.Errorf("cannot compare %q statistics to %q statistics, want %q and %q", x.Source.Format, j.Source.Format, FormatXMLv3, FormatJSONv1)
+	}
+	if x.Source.BINDVersion != j.Source.BINDVersion {
+		return ConsistencyReport{}, fmt.Errorf("XML statistics of BIND %s, JSON statistics of BIND %s", x.Source.BINDVersion, j.Source.BINDVersion)
+	}
+	return t.Compare(x, j), nil
+}
+
+// Compare compares the counters of the XML statistics x to those of the JSON
+// statistics j of the same server. Unless j has been fetched before x, see
+// Source.FetchTime, x is taken to have been fetched first.
+func (t ConsistencyTolerance) Compare(x, j Statistics) ConsistencyReport {
+	r := ConsistencyReport{BINDVersion: x.Source.BINDVersion}
+	if r.BINDVersion == "" {
+		r.BINDVersion = j.Source.BINDVersion
+	}
+	jsonFirst := j.Source.FetchTime.Before(x.Source.FetchTime)
+	xs, js := consistencySections(&x), consistencySections(&j)
+	for path, xc := range xs {
+		jc, ok := js[path]
+		if !ok {
+			r.OnlyXML = appendNonZero(r.OnlyXML, path, xc, func(v uint64) CounterDifference { return CounterDifference{XML: v} })
+			continue
+		}
+		for name := range jc {
+			if _, ok := xc[name]; !ok {
+				xc[name] = 0
+			}
+		}
+		for name, xv := range xc {
+			jv := jc[name]
+			r.Compared++
+			first, last := xv, jv
+			if jsonFirst {
+				first, last = jv, xv
+			}
+			if !t.consistent(first, last) {
+				r.Differences = append(r.Differences, CounterDifference{Path: path + "/" + name, XML: xv, JSON: jv})
+			}
+		}
+	}
+	for path, jc := range js {
+		if _, ok := xs[path]; !ok {
+			r.OnlyJSON = appendNonZero(r.OnlyJSON, path, jc, func(v uint64) CounterDifference { return CounterDifference{JSON: v} })
+		}
+	}
+	for _, ds := range [][]CounterDifference{r.Differences, r.OnlyXML, r.OnlyJSON} {
+		sort.Slice(ds, func(a, b int) bool { return ds[a].Path < ds[b].Path })
+	}
+	return r
+}
+
+// consistent reports whether the value last of a counter fetched after first
+// is within the tolerance.
+func (t ConsistencyTolerance) consistent(first, last uint64) bool {
+	if t.Monotonic && last >= first {
+		return true
+	}
+	if last < first {
+		first, last = last, first
+	}
+	return last-first <= t.Absolute
+}
+
+// consistencySections returns the counters of s by the path of their section
+// and their normalized name, see IndexedStats.
+func consistencySections(s *Statistics) map[string]map[string]uint64 {
+	is := s.Indexed()
+	m := map[string]map[string]uint64{}
+	add := func(prefix string, sections map[string]map[string]uint64) {
+		for sec, cs := range sections {
+			c := make(map[string]uint64, len(cs))
+			for name, v := range cs {
+				c[name] = v
+			}
+			m[prefix+sec] = c
+		}
+	}
+	add("server/", is.Server)
+	for name, v := range is.Views {
+		add("views/"+name+"/", v.Counters)
+	}
+	for view, zones := range is.Zones {
+		for name, z := range zones {
+			add("zones/"+view+"/"+name+"/", z.Counters)
+		}
+	}
+	return m
+}
+
+// appendNonZero appends the counters of cs which are not zero to ds, with the
+// value set by diff, under the path of their section.
+func appendNonZero(ds []CounterDifference, path string, cs map[string]uint64, diff func(uint64) CounterDifference) []CounterDifference {
+	for name, v := range cs {
+		if v == 0 {
+			continue
+		}
+		d := diff(v)
+		d.Path = path + "/" + name
+		ds = append(ds, d)
+	}
+	return ds
+}
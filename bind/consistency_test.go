@@ -0,0 +1,155 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/bindtest"
+	bindjson "github.com/prometheus-community/bind_exporter/bind/json"
+	"github.com/prometheus-community/bind_exporter/bind/xml"
+)
+
+// pairedServer serves the XML and JSON documents captured from the same
+// server at the same instant.
+func pairedServer() *httptest.Server {
+	return bindtest.NewServerWith(map[string]string{
+		xml.ServerPath:      "xml/server-paired.xml",
+		xml.ZonesPath:       "xml/zones-paired.xml",
+		bindjson.ServerPath: "json/server-paired.json",
+		bindjson.ZonesPath:  "json/zones-paired.json",
+	})
+}
+
+func TestVerifyFormatConsistency(t *testing.T) {
+	ts := pairedServer()
+	defer ts.Close()
+	x, j := xml.NewClient(ts.URL, nil), bindjson.NewClient(ts.URL, nil)
+
+	r, err := bind.VerifyFormatConsistency(context.Background(), x, j)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Consistent() || r.BINDVersion != "9.18.24" || r.Compared < 50 {
+		t.Errorf("want consistent report of BIND 9.18.24, got %s", r)
+	}
+
+	// The JSON document has been produced 41ms later, during which a few
+	// counters increased by up to 5.
+	exact, err := bind.ConsistencyTolerance{}.Verify(context.Background(), x, j)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exact.Differences) != 13 || len(exact.OnlyXML)+len(exact.OnlyJSON) != 0 {
+		t.Errorf("want 13 differences of the counters increased, got %s", exact)
+	}
+	if got, want := exact.Differences[0], (bind.CounterDifference{Path: "server/nsstats/QryNoauthAns", XML: 3986, JSON: 3988}); got != want {
+		t.Errorf("want first difference %+v, got %+v", want, got)
+	}
+	for _, tc := range []struct {
+		tolerance bind.ConsistencyTolerance
+		want      []string
+	}{
+		{bind.ConsistencyTolerance{Absolute: 5}, nil},
+		{bind.ConsistencyTolerance{Absolute: 4}, []string{"views/_default/cachestats/CacheHits"}},
+	} {
+		r, err := tc.tolerance.Verify(context.Background(), x, j)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, d := range r.Differences {
+			got = append(got, d.Path)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("tolerance %+v: want differences %v, got %v", tc.tolerance, tc.want, got)
+		}
+	}
+
+	if _, err := bind.VerifyFormatConsistency(context.Background(), x, x); err == nil {
+		t.Error("want error comparing XML statistics to XML statistics")
+	}
+}
+
+func TestConsistencyCompare(t *testing.T) {
+	ts := pairedServer()
+	defer ts.Close()
+	x, err := xml.NewClient(ts.URL, nil).Stats(context.Background(), bind.ServerStats, bind.ViewStats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j, err := bindjson.NewClient(ts.URL, nil).Stats(context.Background(), bind.ServerStats, bind.ViewStats)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A counter decreasing after the first fetch is inconsistent.
+	j = j.Clone()
+	j.Source.FetchTime = x.Source.FetchTime.Add(1)
+	for i, c := range j.Server.IncomingRequests {
+		if c.Name == bind.CounterQUERY {
+			j.Server.IncomingRequests[i].Counter = 5000
+		}
+	}
+	// The cache statistics are only reported by XML, apart from the
+	// DeleteLRU counter which is zero.
+	j.Views[0].CacheStats = nil
+	r := bind.DefaultConsistencyTolerance.Compare(x, j)
+	if want := []bind.CounterDifference{{Path: "server/opcodes/QUERY", XML: 5231, JSON: 5000}}; !reflect.DeepEqual(r.Differences, want) {
+		t.Errorf("want differences %+v, got %+v", want, r.Differences)
+	}
+	var only []string
+	for _, d := range r.OnlyXML {
+		only = append(only, d.Path)
+	}
+	want := []string{
+		"views/_default/cachestats/CacheHits",
+		"views/_default/cachestats/CacheMisses",
+		"views/_default/cachestats/DeleteTTL",
+		"views/_default/cachestats/QueryHits",
+		"views/_default/cachestats/QueryMisses",
+	}
+	if !reflect.DeepEqual(only, want) || r.OnlyJSON != nil {
+		t.Errorf("want counters only in XML %v, got %v and only in JSON %v", want, only, r.OnlyJSON)
+	}
+
+	// Fetched the other way round, the JSON counters are the earlier ones:
+	// QUERY is consistent, and the other counters which increased are not.
+	j.Source.FetchTime = x.Source.FetchTime.Add(-1)
+	if r := bind.DefaultConsistencyTolerance.Compare(x, j); len(r.Differences) != 10 {
+		t.Errorf("want the counters of XML which increased to differ, got %+v", r.Differences)
+	}
+}
+
+// TestVerifyFormatConsistencyNamed compares the formats of the statistics
+// channel of a running named at BIND_STATS_URL, e.g. http://localhost:8053,
+// which must serve both formats.
+func TestVerifyFormatConsistencyNamed(t *testing.T) {
+	url := os.Getenv("BIND_STATS_URL")
+	if url == "" {
+		t.Skip("BIND_STATS_URL not set")
+	}
+	r, err := bind.VerifyFormatConsistency(context.Background(), xml.NewClient(url, nil), bindjson.NewClient(url, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Consistent() {
+		t.Error(r)
+	}
+}
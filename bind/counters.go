@@ -0,0 +1,421 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// counterNames maps counter names which differ between BIND versions and
+// statistics formats to the name used by current versions.
+var counterNames = map[string]string{
+	"RespTruncated": CounterTruncatedResp,
+	"RequestTCP":    CounterReqTCP,
+	"TCPRequest":    CounterReqTCP,
+	// Serve-stale counters of BIND 9.16 lack the prefix used since 9.18.
+	"UsedStale": CounterQryUsedStale,
+	"TryStale":  CounterQryTryStale,
+	// Mixed-case spellings of the resolver counters of responses by rcode.
+	"ServFail": CounterSERVFAIL,
+	"FormErr":  CounterFORMERR,
+}
+
+// NormalizeCounterName returns the name current BIND versions use for the
+// counter name, which is name itself unless the counter has been renamed.
+func NormalizeCounterName(name string) string {
+	if n, ok := counterNames[name]; ok {
+		return n
+	}
+	return name
+}
+
+// ParseCounter parses the value of a counter or gauge, a decimal number
+// optionally surrounded by whitespace, as left by pretty-printers of the
+// documents. Signs and hexadecimal numbers, which BIND never emits, are
+// rejected with an error wrapping ErrInvalidCounter, as is an empty value.
+func ParseCounter(s string) (uint64, error) {
+	v := strings.TrimSpace(s)
+	switch {
+	case v == "":
+		return 0, fmt.Errorf("%w: empty value", ErrInvalidCounter)
+	case v[0] == '+' || v[0] == '-':
+		return 0, fmt.Errorf("%w %q: signs are not allowed", ErrInvalidCounter, v)
+	case len(v) > 1 && v[0] == '0' && (v[1] == 'x' || v[1] == 'X'):
+		return 0, fmt.Errorf("%w %q: hexadecimal numbers are not allowed", ErrInvalidCounter, v)
+	}
+	n, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w %q: %s", ErrInvalidCounter, v, err.(*strconv.NumError).Err)
+	}
+	return n, nil
+}
+
+// AddCounters returns the sum of the counter values a and b, saturating at
+// math.MaxUint64 instead of wrapping around. It reports whether the sum
+// overflowed.
+func AddCounters(a, b uint64) (uint64, bool) {
+	sum, carry := bits.Add64(a, b, 0)
+	if carry != 0 {
+		return math.MaxUint64, true
+	}
+	return sum, false
+}
+
+// CacheMemoryStat reports whether the cache statistic name reports memory in
+// bytes, e.g. HeapMemInUse, rather than a count.
+func CacheMemoryStat(name string) bool {
+	return strings.HasPrefix(name, "HeapMem") || strings.HasPrefix(name, "TreeMem")
+}
+
+// CacheCounterStat reports whether the cache statistic name is a counter of
+// the catalog, e.g. QueryHits. The clients decode these statistics into
+// View.CacheStats.
+func CacheCounterStat(name string) bool {
+	info, ok := DescribeIn(CacheCounters, name)
+	return ok && info.Kind == KindCounter
+}
+
+// ResolverGaugeStat reports whether the resolver statistic name reports a
+// current value rather than a count, e.g. NumFetch, according to the catalog.
+// The clients decode these statistics into View.ResolverGauges.
+func ResolverGaugeStat(name string) bool {
+	info, ok := DescribeIn(ResolverCounters, name)
+	return ok && info.Kind == KindGauge
+}
+
+// ServerCounters holds commonly used name server and rcode counters. Counters
+// not reported by the server are zero.
+type ServerCounters struct {
+	// Requestv4 and Requestv6 count the requests received over IPv4 and
+	// IPv6.
+	Requestv4 uint64
+	Requestv6 uint64
+	// ReqTCP counts the requests received over TCP.
+	ReqTCP uint64
+	// QryUDP and QryTCP count the queries received over UDP and TCP. They
+	// are reported by BIND 9.12 and later.
+	QryUDP uint64
+	QryTCP uint64
+	// Response counts the responses sent.
+	Response uint64
+	// TruncatedResp counts the responses sent with the TC bit set.
+	TruncatedResp uint64
+	// QryUsedStale counts the queries answered with stale data, see RFC
+	// 8767. It is zero unless stale-answer-enable is set.
+	QryUsedStale uint64
+	// QryTryStale counts the queries for which stale data has been tried
+	// after stale-answer-client-timeout expired.
+	QryTryStale uint64
+	// BadVers and BadCookie count the responses sent with the extended
+	// rcodes BADVERS and BADCOOKIE.
+	BadVers   uint64
+	BadCookie uint64
+}
+
+// Counters returns the typed name server and rcode counters of s.
+func (s Server) Counters() ServerCounters {
+	c := ServerCounters{}
+	for _, n := range s.NameServerStats {
+		switch NormalizeCounterName(n.Name) {
+		case CounterRequestv4:
+			c.Requestv4 = n.Counter
+		case CounterRequestv6:
+			c.Requestv6 = n.Counter
+		case CounterReqTCP:
+			c.ReqTCP = n.Counter
+		case CounterQryUDP:
+			c.QryUDP = n.Counter
+		case CounterQryTCP:
+			c.QryTCP = n.Counter
+		case CounterResponse:
+			c.Response = n.Counter
+		case CounterTruncatedResp:
+			c.TruncatedResp = n.Counter
+		case CounterQryUsedStale:
+			c.QryUsedStale = n.Counter
+		case CounterQryTryStale:
+			c.QryTryStale = n.Counter
+		}
+	}
+	for _, n := range s.ServerRcodes {
+		switch NormalizeRcodeName(n.Name) {
+		case CounterBADVERS:
+			c.BadVers = n.Counter
+		case CounterBADCOOKIE:
+			c.BadCookie = n.Counter
+		}
+	}
+	return c
+}
+
+// MaintenanceCounters holds the zone maintenance counters, i.e. all counters
+// of ZoneMaintenanceCounters, of the server or of a zone. Counters not
+// reported by the server are zero.
+type MaintenanceCounters struct {
+	// NotifyOutv4 and NotifyOutv6 count the NOTIFY messages sent over IPv4
+	// and IPv6.
+	NotifyOutv4 uint64
+	NotifyOutv6 uint64
+	// NotifyInv4 and NotifyInv6 count the NOTIFY messages received over
+	// IPv4 and IPv6, and NotifyRej those rejected.
+	NotifyInv4 uint64
+	NotifyInv6 uint64
+	NotifyRej  uint64
+	// SOAOutv4 and SOAOutv6 count the SOA queries sent by secondary zones
+	// over IPv4 and IPv6.
+	SOAOutv4 uint64
+	SOAOutv6 uint64
+	// AXFRReqv4, AXFRReqv6, IXFRReqv4 and IXFRReqv6 count the zone
+	// transfers requested over IPv4 and IPv6.
+	AXFRReqv4 uint64
+	AXFRReqv6 uint64
+	IXFRReqv4 uint64
+	IXFRReqv6 uint64
+	// XfrSuccess and XfrFail count the zone transfers which succeeded and
+	// failed.
+	XfrSuccess uint64
+	XfrFail    uint64
+}
+
+// Maintenance returns the typed zone maintenance counters of s, the totals
+// over its zones.
+func (s Server) Maintenance() MaintenanceCounters {
+	return maintenanceCounters(s.ZoneMaintenance)
+}
+
+// Maintenance returns the typed zone maintenance counters of z.
+func (z ZoneCounter) Maintenance() MaintenanceCounters {
+	return maintenanceCounters(z.ZoneStats)
+}
+
+func maintenanceCounters(cs []Counter) MaintenanceCounters {
+	c := MaintenanceCounters{}
+	for _, n := range cs {
+		switch n.Name {
+		case CounterNotifyOutv4:
+			c.NotifyOutv4 = n.Counter
+		case CounterNotifyOutv6:
+			c.NotifyOutv6 = n.Counter
+		case CounterNotifyInv4:
+			c.NotifyInv4 = n.Counter
+		case CounterNotifyInv6:
+			c.NotifyInv6 = n.Counter
+		case CounterNotifyRej:
+			c.NotifyRej = n.Counter
+		case CounterSOAOutv4:
+			c.SOAOutv4 = n.Counter
+		case CounterSOAOutv6:
+			c.SOAOutv6 = n.Counter
+		case CounterAXFRReqv4:
+			c.AXFRReqv4 = n.Counter
+		case CounterAXFRReqv6:
+			c.AXFRReqv6 = n.Counter
+		case CounterIXFRReqv4:
+			c.IXFRReqv4 = n.Counter
+		case CounterIXFRReqv6:
+			c.IXFRReqv6 = n.Counter
+		case CounterXfrSuccess:
+			c.XfrSuccess = n.Counter
+		case CounterXfrFail:
+			c.XfrFail = n.Counter
+		}
+	}
+	return c
+}
+
+// ViewCounters holds the resolver counters of a view describing the
+// transport of outgoing queries. Counters not reported by the server are
+// zero.
+//
+// A response received with the TC bit set is counted by Truncated, and the
+// resolver resends the query over TCP, so Truncated is the number of TCP
+// fallbacks. The resent queries count towards QueryCurTCP while they are in
+// progress. Retry counts all queries resent by the resolver, also those after
+// a timeout or a failed EDNS query, see EDNS0Fail. A surge of Truncated
+// relative to the responses usually means that large responses, e.g. DNSSEC
+// signed ones, no longer fit the EDNS buffer size.
+//
+// A middlebox dropping or mangling queries with EDNS makes them fail with
+// FORMERR responses or time out, which counts towards EDNS0Fail, FORMERR and
+// QueryTimeout. The resolver then retries without EDNS, so a high
+// EDNSFallbackFraction along with many FORMERR responses points to a broken
+// path rather than to broken upstream servers.
+type ViewCounters struct {
+	// Queryv4 and Queryv6 count the queries sent over IPv4 and IPv6.
+	Queryv4 uint64
+	Queryv6 uint64
+	// Responsev4 and Responsev6 count the responses received over IPv4
+	// and IPv6.
+	Responsev4 uint64
+	Responsev6 uint64
+	// Truncated counts the truncated responses received.
+	Truncated uint64
+	// Retry counts the queries resent.
+	Retry uint64
+	// EDNS0Fail counts the queries with EDNS which failed.
+	EDNS0Fail uint64
+	// BadEDNSVersion counts the responses received with an unsupported
+	// EDNS version.
+	BadEDNSVersion uint64
+	// SERVFAIL and FORMERR count the responses received with these rcodes.
+	SERVFAIL uint64
+	FORMERR  uint64
+	// QueryTimeout counts the queries which timed out.
+	QueryTimeout uint64
+	// QueryCurUDP and QueryCurTCP are the numbers of queries in progress
+	// over UDP and TCP, see ViewGauges.
+	QueryCurUDP uint64
+	QueryCurTCP uint64
+}
+
+// Counters returns the typed resolver counters of v.
+func (v View) Counters() ViewCounters {
+	c := ViewCounters{}
+	g := v.Gauges()
+	c.QueryCurUDP, c.QueryCurTCP = g.QueryCurUDP, g.QueryCurTCP
+	for _, n := range v.ResolverStats {
+		switch NormalizeCounterName(n.Name) {
+		case CounterQueryv4:
+			c.Queryv4 = n.Counter
+		case CounterQueryv6:
+			c.Queryv6 = n.Counter
+		case CounterResponsev4:
+			c.Responsev4 = n.Counter
+		case CounterResponsev6:
+			c.Responsev6 = n.Counter
+		case CounterTruncated:
+			c.Truncated = n.Counter
+		case CounterRetry:
+			c.Retry = n.Counter
+		case CounterEDNS0Fail:
+			c.EDNS0Fail = n.Counter
+		case CounterBadEDNSVersion:
+			c.BadEDNSVersion = n.Counter
+		case CounterSERVFAIL:
+			c.SERVFAIL = n.Counter
+		case CounterFORMERR:
+			c.FORMERR = n.Counter
+		case CounterQueryTimeout:
+			c.QueryTimeout = n.Counter
+		}
+	}
+	return c
+}
+
+// ViewGauges holds the resolver gauges of a view, which tell the current load
+// of the resolver. A growing number of fetches usually precedes SERVFAIL
+// responses, as the fetches of a saturated resolver time out. Reported is
+// false if the server does not report the gauges, like older versions of
+// BIND, which is not the same as an idle resolver.
+type ViewGauges struct {
+	// Reported tells whether any of the gauges has been reported.
+	Reported bool
+	// NumFetch is the number of fetches in progress.
+	NumFetch uint64
+	// BucketSize is the number of buckets of the resolver.
+	BucketSize uint64
+	// QueryCurUDP and QueryCurTCP are the numbers of queries in progress
+	// over UDP and TCP.
+	QueryCurUDP uint64
+	QueryCurTCP uint64
+}
+
+// Gauges returns the typed resolver gauges of v, read from ResolverGauges. For
+// statistics built by hand, gauges in ResolverStats are read as well.
+func (v View) Gauges() ViewGauges {
+	g := ViewGauges{}
+	set := func(name string, val uint64) {
+		switch NormalizeCounterName(name) {
+		case CounterNumFetch:
+			g.NumFetch = val
+		case CounterBucketSize:
+			g.BucketSize = val
+		case CounterQueryCurUDP:
+			g.QueryCurUDP = val
+		case CounterQueryCurTCP:
+			g.QueryCurTCP = val
+		default:
+			return
+		}
+		g.Reported = true
+	}
+	for _, n := range v.ResolverStats {
+		set(n.Name, n.Counter)
+	}
+	for _, n := range v.ResolverGauges {
+		set(n.Name, n.Gauge)
+	}
+	return g
+}
+
+// TCPFallbackFraction returns the fraction of responses received truncated,
+// which made the resolver fall back to TCP, or NaN if no responses have been
+// received.
+func (c ViewCounters) TCPFallbackFraction() float64 {
+	return ratio(c.Truncated, c.Responsev4+c.Responsev6)
+}
+
+// EDNSFallbackFraction returns the fraction of queries sent whose EDNS query
+// failed, which made the resolver retry without EDNS, or NaN if no queries
+// have been sent.
+func (c ViewCounters) EDNSFallbackFraction() float64 {
+	return ratio(c.EDNS0Fail, c.Queryv4+c.Queryv6)
+}
+
+// TimeoutFraction returns the fraction of queries sent which timed out, or NaN
+// if no queries have been sent.
+func (c ViewCounters) TimeoutFraction() float64 {
+	return ratio(c.QueryTimeout, c.Queryv4+c.Queryv6)
+}
+
+// IPv6Fraction returns the fraction of queries sent over IPv6, or NaN if no
+// queries have been sent.
+func (c ViewCounters) IPv6Fraction() float64 {
+	return ratio(c.Queryv6, c.Queryv4+c.Queryv6)
+}
+
+// RetryFraction returns the ratio of queries resent to queries sent, or NaN
+// if no queries have been sent.
+func (c ViewCounters) RetryFraction() float64 {
+	return ratio(c.Retry, c.Queryv4+c.Queryv6)
+}
+
+// TCPFraction returns the fraction of requests received over TCP, or NaN if
+// no requests have been received.
+func (c ServerCounters) TCPFraction() float64 {
+	return ratio(c.ReqTCP, c.Requestv4+c.Requestv6)
+}
+
+// IPv6Fraction returns the fraction of requests received over IPv6, or NaN if
+// no requests have been received.
+func (c ServerCounters) IPv6Fraction() float64 {
+	return ratio(c.Requestv6, c.Requestv4+c.Requestv6)
+}
+
+// TruncatedFraction returns the fraction of responses sent truncated, or NaN
+// if no responses have been sent.
+func (c ServerCounters) TruncatedFraction() float64 {
+	return ratio(c.TruncatedResp, c.Response)
+}
+
+func ratio(n, d uint64) float64 {
+	if d == 0 {
+		return math.NaN()
+	}
+	return float64(n) / float64(d)
+}
@@ -0,0 +1,132 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestServerCounters(t *testing.T) {
+	s := Server{NameServerStats: []Counter{
+		{Name: "Requestv4", Counter: 60},
+		{Name: "Requestv6", Counter: 20},
+		{Name: "RequestTCP", Counter: 8},
+		{Name: "Response", Counter: 80},
+		{Name: "RespTruncated", Counter: 4},
+		{Name: "ReqEdns0", Counter: 70},
+	}}
+	want := ServerCounters{Requestv4: 60, Requestv6: 20, ReqTCP: 8, Response: 80, TruncatedResp: 4}
+	c := s.Counters()
+	if c != want {
+		t.Fatalf("want counters %+v, got %+v", want, c)
+	}
+	if got := c.TCPFraction(); got != 0.1 {
+		t.Errorf("want TCP fraction 0.1, got %v", got)
+	}
+	if got := c.IPv6Fraction(); got != 0.25 {
+		t.Errorf("want IPv6 fraction 0.25, got %v", got)
+	}
+	if got := c.TruncatedFraction(); got != 0.05 {
+		t.Errorf("want truncated fraction 0.05, got %v", got)
+	}
+}
+
+func TestServerCountersZeroDenominator(t *testing.T) {
+	c := Server{}.Counters()
+	if c != (ServerCounters{}) {
+		t.Fatalf("want zero counters, got %+v", c)
+	}
+	for name, f := range map[string]float64{
+		"TCP":       c.TCPFraction(),
+		"IPv6":      c.IPv6Fraction(),
+		"truncated": c.TruncatedFraction(),
+	} {
+		if !math.IsNaN(f) {
+			t.Errorf("want NaN %s fraction without requests, got %v", name, f)
+		}
+	}
+}
+
+func TestViewCounters(t *testing.T) {
+	v := View{ResolverStats: []Counter{
+		{Name: "Queryv4", Counter: 75},
+		{Name: "Queryv6", Counter: 25},
+		{Name: "Responsev4", Counter: 70},
+		{Name: "Responsev6", Counter: 10},
+		{Name: "Truncated", Counter: 8},
+		{Name: "Retry", Counter: 20},
+		{Name: "QueryCurTCP", Counter: 3},
+		{Name: "QryRTT10", Counter: 50},
+	}}
+	want := ViewCounters{Queryv4: 75, Queryv6: 25, Responsev4: 70, Responsev6: 10, Truncated: 8, Retry: 20, QueryCurTCP: 3}
+	c := v.Counters()
+	if c != want {
+		t.Fatalf("want counters %+v, got %+v", want, c)
+	}
+	if got := c.TCPFallbackFraction(); got != 0.1 {
+		t.Errorf("want TCP fallback fraction 0.1, got %v", got)
+	}
+	if got := c.IPv6Fraction(); got != 0.25 {
+		t.Errorf("want IPv6 fraction 0.25, got %v", got)
+	}
+	if got := c.RetryFraction(); got != 0.2 {
+		t.Errorf("want retry fraction 0.2, got %v", got)
+	}
+	for name, f := range map[string]float64{
+		"TCP fallback": View{}.Counters().TCPFallbackFraction(),
+		"IPv6":         View{}.Counters().IPv6Fraction(),
+		"retry":        View{}.Counters().RetryFraction(),
+	} {
+		if !math.IsNaN(f) {
+			t.Errorf("want NaN %s fraction without queries, got %v", name, f)
+		}
+	}
+}
+
+func TestAddCounters(t *testing.T) {
+	for _, tc := range []struct {
+		a, b     uint64
+		want     uint64
+		overflow bool
+	}{
+		{a: 1, b: 2, want: 3},
+		{a: math.MaxUint64 - 1, b: 1, want: math.MaxUint64},
+		{a: math.MaxUint64, b: 1, want: math.MaxUint64, overflow: true},
+		{a: math.MaxUint64, b: math.MaxUint64, want: math.MaxUint64, overflow: true},
+	} {
+		if got, overflow := AddCounters(tc.a, tc.b); got != tc.want || overflow != tc.overflow {
+			t.Errorf("%d+%d: want %d (overflow %t), got %d (overflow %t)", tc.a, tc.b, tc.want, tc.overflow, got, overflow)
+		}
+	}
+}
+
+func TestParseCounter(t *testing.T) {
+	for s, want := range map[string]uint64{
+		"12345":                12345,
+		"\n      12345\n    ":  12345,
+		"\t0 ":                 0,
+		"18446744073709551615": math.MaxUint64,
+	} {
+		if got, err := ParseCounter(s); err != nil || got != want {
+			t.Errorf("%q: want %d, got %d, %v", s, want, got, err)
+		}
+	}
+	for _, s := range []string{"", " ", "+1", "-1", "0x10", "0X10", "1.5", "1e3", "18446744073709551616", "n/a"} {
+		if _, err := ParseCounter(s); !errors.Is(err, ErrInvalidCounter) {
+			t.Errorf("%q: want ErrInvalidCounter, got %v", s, err)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrCounterReset is returned by Delta if the counters of the later
+// statistics cannot be compared to the earlier ones, because the server has
+// been restarted or a counter decreased.
+var ErrCounterReset = errors.New("counter reset")
+
+// BootTimeTolerance is the difference of boot times below which SameBoot
+// takes them to describe the same boot of named. The formats report boot
+// times with different precision.
+const BootTimeTolerance = time.Second
+
+// SameBoot reports whether the boot times a and b, which may have been
+// reported by different formats, describe the same boot of named.
+func SameBoot(a, b time.Time) bool {
+	if a.IsZero() || b.IsZero() {
+		return a.IsZero() == b.IsZero()
+	}
+	d := a.Sub(b)
+	return d < BootTimeTolerance && d > -BootTimeTolerance
+}
+
+// Delta returns the increase of every counter of cur since prev. Counters
+// absent from prev are taken to start at zero. Gauges, times and the task
+// manager statistics are those of cur, and the QueryRTT histograms are
+// derived from the resulting resolver counters. It returns an error wrapping
+// ErrCounterReset if the boot times differ, see SameBoot, or a counter
+// decreased, and an error if the statistics have been produced by different
+// formats, whose counters are not comparable. A decrease is never taken for a
+// wraparound of the counter, which would yield an increase of nearly 2^64.
+//
+// Views and zones are matched by name. If named has been reconfigured since
+// prev, i.e. the config times differ, a view or zone whose counters decreased
+// is taken to have been recreated and starts at zero, and a zone which left
+// exactly one view is taken to have moved to its new view.
+func Delta(prev, cur Statistics) (Statistics, error) {
+	if prev.Source.Format != cur.Source.Format {
+		return Statistics{}, fmt.Errorf("cannot compare %q statistics to %q statistics", cur.Source.Format, prev.Source.Format)
+	}
+	if !SameBoot(prev.Server.BootTime, cur.Server.BootTime) {
+		return Statistics{}, fmt.Errorf("%w: server booted at %s, previously at %s", ErrCounterReset, cur.Server.BootTime, prev.Server.BootTime)
+	}
+
+	d := cur
+	var ds deltas
+	d.Server.IncomingQueries = ds.delta("server/qtypes", prev.Server.IncomingQueries, cur.Server.IncomingQueries)
+	d.Server.IncomingRequests = ds.delta("server/opcodes", prev.Server.IncomingRequests, cur.Server.IncomingRequests)
+	d.Server.NameServerStats = ds.delta("server/nsstats", prev.Server.NameServerStats, cur.Server.NameServerStats)
+	d.Server.ZoneMaintenance = ds.delta("server/zonestats", prev.Server.ZoneMaintenance, cur.Server.ZoneMaintenance)
+	d.Server.ZoneStatistics = d.Server.ZoneMaintenance
+	d.Server.ServerRcodes = ds.delta("server/rcodes", prev.Server.ServerRcodes, cur.Server.ServerRcodes)
+	d.Server.Extra = deltaExtra(ds.delta, "server/extra/", prev.Server.Extra, cur.Server.Extra)
+	if ds.err != nil {
+		return Statistics{}, ds.err
+	}
+
+	// A reconfiguration recreates the views and zones whose configuration
+	// changed, so their counters may restart at zero.
+	reloaded := !prev.Server.ConfigTime.Equal(cur.Server.ConfigTime)
+
+	prevViews := map[string]View{}
+	for _, v := range prev.Views {
+		prevViews[v.Name] = v
+	}
+	d.Views = make([]View, len(cur.Views))
+	for i, v := range cur.Views {
+		dv, err := deltaView(prevViews[v.Name], v)
+		if reloaded && errors.Is(err, ErrCounterReset) {
+			dv, err = deltaView(View{}, v)
+		}
+		if err != nil {
+			return Statistics{}, err
+		}
+		d.Views[i] = dv
+	}
+
+	prevZones := map[string]ZoneCounter{}
+	prevClasses := map[string]QueriesByClass{}
+	for _, v := range prev.ZoneViews {
+		prevClasses[v.Name] = v.QueriesByClass
+		for _, z := range v.ZoneData {
+			prevZones[v.Name+"/"+z.Name] = z
+		}
+	}
+	var moved map[string]ZoneCounter
+	if reloaded {
+		moved = movedZones(prev, cur)
+	}
+	d.ZoneViews = make([]ZoneView, len(cur.ZoneViews))
+	for i, v := range cur.ZoneViews {
+		zv := ZoneView{Name: v.Name, ZoneData: make([]ZoneCounter, len(v.ZoneData))}
+		// Zones removed from the view decrease the sums of their class,
+		// which then start at zero.
+		var err error
+		if zv.QueriesByClass, err = deltaClasses(v.Name, prevClasses[v.Name], v.QueriesByClass); err != nil {
+			zv.QueriesByClass, _ = deltaClasses(v.Name, nil, v.QueriesByClass)
+		}
+		for j, z := range v.ZoneData {
+			path := "zones/" + v.Name + "/" + z.Name
+			p, ok := prevZones[v.Name+"/"+z.Name]
+			if !ok {
+				p = moved[z.Name]
+			}
+			dz, err := deltaZone(path, p, z)
+			if reloaded && errors.Is(err, ErrCounterReset) {
+				dz, err = deltaZone(path, ZoneCounter{}, z)
+			}
+			if err != nil {
+				return Statistics{}, err
+			}
+			zv.ZoneData[j] = dz
+		}
+		d.ZoneViews[i] = zv
+	}
+	return d, nil
+}
+
+// deltas computes the increase of counters, keeping the first error.
+type deltas struct {
+	err error
+}
+
+func (ds *deltas) delta(path string, prev, cur []Counter) []Counter {
+	if ds.err != nil {
+		return nil
+	}
+	var c []Counter
+	c, ds.err = deltaCounters(path, prev, cur)
+	return c
+}
+
+func deltaView(p, v View) (View, error) {
+	var ds deltas
+	v.CacheStats = ds.delta("views/"+v.Name+"/cachestats", p.CacheStats, v.CacheStats)
+	v.ResolverStats = ds.delta("views/"+v.Name+"/resolver", p.ResolverStats, v.ResolverStats)
+	v.ResolverQueries = ds.delta("views/"+v.Name+"/resqtypes", p.ResolverQueries, v.ResolverQueries)
+	v.Extra = deltaExtra(ds.delta, "views/"+v.Name+"/extra/", p.Extra, v.Extra)
+	if ds.err != nil {
+		return View{}, ds.err
+	}
+	var err error
+	v.QueryRTT, err = QueryRTTHistogram(v.ResolverStats)
+	return v, err
+}
+
+func deltaZone(path string, p, z ZoneCounter) (ZoneCounter, error) {
+	var ds deltas
+	z.ZoneStats = ds.delta(path+"/zonestats", p.ZoneStats, z.ZoneStats)
+	z.DNSSECSignStats = ds.delta(path+"/dnssec-sign", p.DNSSECSignStats, z.DNSSECSignStats)
+	z.DNSSECRefreshStats = ds.delta(path+"/dnssec-refresh", p.DNSSECRefreshStats, z.DNSSECRefreshStats)
+	z.QueryResults = ds.delta(path+"/rcodes", p.QueryResults, z.QueryResults)
+	z.IncomingQueries = ds.delta(path+"/qtypes", p.IncomingQueries, z.IncomingQueries)
+	z.NameServerStats = ds.delta(path+"/nsstats", p.NameServerStats, z.NameServerStats)
+	z.Extra = deltaExtra(ds.delta, path+"/extra/", p.Extra, z.Extra)
+	return z, ds.err
+}
+
+// movedZones returns the previous statistics of the zones of cur which have
+// moved to another view since prev, by zone name. Zones which left several
+// views are omitted, as their origin is ambiguous.
+func movedZones(prev, cur Statistics) map[string]ZoneCounter {
+	present := map[string]bool{}
+	for _, v := range cur.ZoneViews {
+		for _, z := range v.ZoneData {
+			present[v.Name+"/"+z.Name] = true
+		}
+	}
+	left := map[string][]ZoneCounter{}
+	for _, v := range prev.ZoneViews {
+		for _, z := range v.ZoneData {
+			if !present[v.Name+"/"+z.Name] {
+				left[z.Name] = append(left[z.Name], z)
+			}
+		}
+	}
+	moved := make(map[string]ZoneCounter, len(left))
+	for name, zs := range left {
+		if len(zs) == 1 {
+			moved[name] = zs[0]
+		}
+	}
+	return moved
+}
+
+// deltaClasses returns the increase of the queries of every class of cur.
+func deltaClasses(view string, prev, cur QueriesByClass) (QueriesByClass, error) {
+	var ds deltas
+	d := deltaExtra(ds.delta, "zones/"+view+"/classes/", Extra(prev), Extra(cur))
+	return QueriesByClass(d), ds.err
+}
+
+// deltaExtra applies delta to every section of cur.
+func deltaExtra(delta func(path string, prev, cur []Counter) []Counter, path string, prev, cur Extra) Extra {
+	if cur == nil {
+		return nil
+	}
+	d := make(Extra, len(cur))
+	for t, cs := range cur {
+		d[t] = delta(path+t, prev[t], cs)
+	}
+	return d
+}
+
+func deltaCounters(path string, prev, cur []Counter) ([]Counter, error) {
+	if cur == nil {
+		return nil, nil
+	}
+	p := make(map[string]uint64, len(prev))
+	for _, c := range prev {
+		p[c.Name] = c.Counter
+	}
+	d := make([]Counter, len(cur))
+	for i, c := range cur {
+		if c.Counter < p[c.Name] {
+			return nil, fmt.Errorf("%w: %s/%s decreased from %d to %d", ErrCounterReset, path, c.Name, p[c.Name], c.Counter)
+		}
+		d[i] = Counter{Name: c.Name, Counter: c.Counter - p[c.Name]}
+	}
+	return d, nil
+}
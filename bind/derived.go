@@ -0,0 +1,170 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"math"
+	"time"
+)
+
+// Derived holds rates and ratios derived from the increase of the counters
+// between two snapshots of the statistics, see Derive. Values the server does
+// not report the counters of, e.g. because its version predates them, are
+// omitted, which is not the same as a value of zero: their Has field is
+// false and the value is NaN.
+type Derived struct {
+	// Interval is the time the rates are measured over.
+	Interval time.Duration
+	// QPS is the number of queries received per second, see QPS.
+	QPS    float64
+	HasQPS bool
+	// Views holds the values of the views in the order of Statistics.Views.
+	Views []DerivedView
+}
+
+// DerivedView holds the derived values of a view, see Derived.
+type DerivedView struct {
+	Name string
+	// RecursionRate is the number of queries sent by the resolver of the
+	// view per second, see RecursionRate.
+	RecursionRate    float64
+	HasRecursionRate bool
+	// CacheHitRatio is the fraction of queries answered from the cache of
+	// the view, see CacheHitRatio.
+	CacheHitRatio    float64
+	HasCacheHitRatio bool
+}
+
+// View returns the derived values of the view name, and false if there is no
+// such view.
+func (d Derived) View(name string) (DerivedView, bool) {
+	for _, v := range d.Views {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return DerivedView{}, false
+}
+
+// QPS returns the number of queries received per second, computed from the
+// increase d of the counters during interval, e.g. the Delta and Interval of
+// a Sample:
+//
+//	QPS = QUERY / interval
+//
+// where QUERY is the counter of QUERY requests in Server.IncomingRequests.
+// It returns NaN if interval is not positive, and false if d holds no opcode
+// counters, e.g. because the server statistics have not been fetched.
+func QPS(d Statistics, interval time.Duration) (float64, bool) {
+	if d.Server.IncomingRequests == nil {
+		return math.NaN(), false
+	}
+	var n uint64
+	for _, c := range d.Server.IncomingRequests {
+		if c.Name == CounterQUERY {
+			n = c.Counter
+		}
+	}
+	return perSecond(n, interval), true
+}
+
+// RecursionRate returns the number of queries sent by the resolver of the view
+// per second, computed from the increase d of its counters during interval:
+//
+//	RecursionRate = (Queryv4 + Queryv6) / interval
+//
+// It returns NaN if interval is not positive, and false if d holds no
+// resolver statistics, e.g. for views without recursion on older versions of
+// BIND.
+func RecursionRate(d View, interval time.Duration) (float64, bool) {
+	if d.ResolverStats == nil {
+		return math.NaN(), false
+	}
+	c := d.Counters()
+	return perSecond(c.Queryv4+c.Queryv6, interval), true
+}
+
+// CacheHitRatio returns the fraction of queries answered from the cache of
+// the view, computed from the increase d of its counters:
+//
+//	CacheHitRatio = QueryHits / (QueryHits + QueryMisses)
+//
+// It returns NaN if no query has been looked up in the cache, and false if d
+// holds neither counter, e.g. because the server does not report them.
+// CacheHits and CacheMisses are not used: they count the lookups of the cache
+// database by the resolver itself, of which a single query may need many.
+func CacheHitRatio(d View) (float64, bool) {
+	var hits, misses uint64
+	reported := false
+	for _, c := range d.CacheStats {
+		switch NormalizeCounterName(c.Name) {
+		case CounterQueryHits:
+			hits, reported = c.Counter, true
+		case CounterQueryMisses:
+			misses, reported = c.Counter, true
+		}
+	}
+	if !reported {
+		return math.NaN(), false
+	}
+	return ratio(hits, hits+misses), true
+}
+
+// DeriveDelta returns the derived values of the increase d of the counters
+// during interval, see QPS, RecursionRate and CacheHitRatio.
+func DeriveDelta(d Statistics, interval time.Duration) Derived {
+	dv := Derived{Interval: interval}
+	dv.QPS, dv.HasQPS = QPS(d, interval)
+	for _, v := range d.Views {
+		w := DerivedView{Name: v.Name}
+		w.RecursionRate, w.HasRecursionRate = RecursionRate(v, interval)
+		w.CacheHitRatio, w.HasCacheHitRatio = CacheHitRatio(v)
+		dv.Views = append(dv.Views, w)
+	}
+	return dv
+}
+
+// Derive returns the derived values of the increase of the counters from prev
+// to cur, see DeriveDelta. The rates are measured over the time between the
+// current times reported by the server, or between the fetch times if the
+// server did not report them. If named has been restarted in between, see
+// SameBoot, the counters of cur are measured from the boot time instead, like
+// the Delta of a Sample. It returns the errors of Delta otherwise, e.g. for a
+// counter reset.
+func Derive(prev, cur Statistics) (Derived, error) {
+	interval := cur.Server.CurrentTime.Sub(prev.Server.CurrentTime)
+	if cur.Server.CurrentTime.IsZero() || prev.Server.CurrentTime.IsZero() {
+		interval = cur.Source.FetchTime.Sub(prev.Source.FetchTime)
+	}
+	if !SameBoot(prev.Server.BootTime, cur.Server.BootTime) {
+		if up := cur.Server.CurrentTime.Sub(cur.Server.BootTime); up > 0 && up < interval {
+			interval = up
+		}
+		prev = Statistics{Source: cur.Source, Server: Server{BootTime: cur.Server.BootTime}}
+	}
+	d, err := Delta(prev, cur)
+	if err != nil {
+		return Derived{}, err
+	}
+	return DeriveDelta(d, interval), nil
+}
+
+// perSecond returns n per second of interval, or NaN if interval is not
+// positive.
+func perSecond(n uint64, interval time.Duration) float64 {
+	if interval <= 0 {
+		return math.NaN()
+	}
+	return float64(n) / interval.Seconds()
+}
@@ -0,0 +1,189 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SerialState describes the serial of a zone on a secondary relative to the
+// primary, see SerialDrift.
+type SerialState string
+
+const (
+	// SerialEqual is the state of a serial equal to the one of the primary.
+	SerialEqual SerialState = "equal"
+	// SerialBehind is the state of a serial older than the one of the
+	// primary, usually a transfer not done yet.
+	SerialBehind SerialState = "behind"
+	// SerialAhead is the state of a serial newer than the one of the
+	// primary. Secondaries never get ahead by transfers, so it is alarming:
+	// the serial of the primary went backwards or the secondary is fed by
+	// another source.
+	SerialAhead SerialState = "ahead"
+	// SerialMissing is the state of a zone not present on the secondary.
+	SerialMissing SerialState = "missing"
+	// SerialUnloaded is the state of a zone present on the secondary
+	// without a serial, e.g. "-" before the first transfer.
+	SerialUnloaded SerialState = "unloaded"
+	// SerialUnknown is the state of a serial which cannot be compared,
+	// because the primary has no serial of the zone or the serials are
+	// 2^31 apart, for which RFC 1982 defines no order.
+	SerialUnknown SerialState = "unknown"
+)
+
+// DriftReport lists the zones whose serials differ between a primary and its
+// secondaries, see SerialDrift.
+type DriftReport struct {
+	// Zones is sorted by view and zone.
+	Zones []ZoneDrift
+}
+
+// ZoneDrift describes the serials of a zone on the primary and the
+// secondaries.
+type ZoneDrift struct {
+	ZoneRef
+	// Primary is the serial on the primary, empty if PrimaryMissing.
+	Primary        string
+	PrimaryMissing bool
+	// Secondaries is sorted by target.
+	Secondaries []SecondarySerial
+}
+
+// SecondarySerial is the serial of a zone on a secondary.
+type SecondarySerial struct {
+	Target string
+	// Serial is empty if the zone is missing on the secondary.
+	Serial string
+	State  SerialState
+}
+
+// SerialDrift compares the serials of the zones of primary to those of the
+// same zones in the same views of the secondaries, keyed by target. Serials
+// are compared with the serial number arithmetic of RFC 1982 and zone names
+// like ZoneKey does. The report lists the zones which are missing on any
+// target or whose serial differs on any secondary, including zones present
+// only on secondaries.
+func SerialDrift(primary Statistics, secondaries map[string]Statistics) DriftReport {
+	type key struct{ view, zone string }
+	zones := map[key]*ZoneDrift{}
+	// serials holds the serials of the zones on the secondaries by target.
+	serials := map[key]map[string]string{}
+	zone := func(view, name string) key {
+		k := key{view, ZoneKey(name)}
+		if _, ok := zones[k]; !ok {
+			zones[k] = &ZoneDrift{ZoneRef: ZoneRef{View: view, Zone: CanonicalZoneName(name)}, PrimaryMissing: true}
+			serials[k] = map[string]string{}
+		}
+		return k
+	}
+	for _, v := range primary.ZoneViews {
+		for _, z := range v.ZoneData {
+			d := zones[zone(v.Name, z.Name)]
+			d.Primary, d.PrimaryMissing = z.Serial, false
+		}
+	}
+	// The targets are sorted for zones present only on secondaries to be
+	// named consistently.
+	targets := make([]string, 0, len(secondaries))
+	for t := range secondaries {
+		targets = append(targets, t)
+	}
+	sort.Strings(targets)
+	for _, t := range targets {
+		for _, v := range secondaries[t].ZoneViews {
+			for _, z := range v.ZoneData {
+				serials[zone(v.Name, z.Name)][t] = z.Serial
+			}
+		}
+	}
+
+	var r DriftReport
+	for k, d := range zones {
+		drift := d.PrimaryMissing
+		for _, t := range targets {
+			s, ok := serials[k][t]
+			st := SecondarySerial{Target: t, Serial: s, State: serialState(d.Primary, d.PrimaryMissing, s, ok)}
+			drift = drift || st.State != SerialEqual
+			d.Secondaries = append(d.Secondaries, st)
+		}
+		if drift {
+			r.Zones = append(r.Zones, *d)
+		}
+	}
+	sort.Slice(r.Zones, func(i, j int) bool {
+		a, b := r.Zones[i].ZoneRef, r.Zones[j].ZoneRef
+		if a.View != b.View {
+			return a.View < b.View
+		}
+		return ZoneKey(a.Zone) < ZoneKey(b.Zone)
+	})
+	return r
+}
+
+func serialState(primary string, primaryMissing bool, secondary string, ok bool) SerialState {
+	if !ok {
+		return SerialMissing
+	}
+	s, err := strconv.ParseUint(secondary, 10, 32)
+	if err != nil {
+		return SerialUnloaded
+	}
+	if primaryMissing {
+		return SerialUnknown
+	}
+	p, err := strconv.ParseUint(primary, 10, 32)
+	if err != nil {
+		return SerialUnknown
+	}
+	switch d := uint32(s) - uint32(p); {
+	case d == 0:
+		return SerialEqual
+	case d < 1<<31:
+		return SerialAhead
+	case d > 1<<31:
+		return SerialBehind
+	default:
+		return SerialUnknown
+	}
+}
+
+// String formats the report with a line per zone for command line use, e.g.
+//
+//	_default/example.com: primary 2024031802, ns2 2024031801 (behind), ns3 missing
+//
+// It returns an empty string if no zone drifted.
+func (r DriftReport) String() string {
+	var b strings.Builder
+	for _, z := range r.Zones {
+		primary := z.Primary
+		if z.PrimaryMissing {
+			primary = string(SerialMissing)
+		}
+		fmt.Fprintf(&b, "%s/%s: primary %s", z.View, z.Zone, primary)
+		for _, s := range z.Secondaries {
+			switch s.State {
+			case SerialMissing:
+				fmt.Fprintf(&b, ", %s missing", s.Target)
+			default:
+				fmt.Fprintf(&b, ", %s %s (%s)", s.Target, s.Serial, s.State)
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,123 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"reflect"
+	"testing"
+)
+
+func zoneStatistics(view string, zones ...ZoneCounter) Statistics {
+	return Statistics{ZoneViews: []ZoneView{{Name: view, ZoneData: zones}}}
+}
+
+func TestSerialDrift(t *testing.T) {
+	primary := zoneStatistics("_default",
+		ZoneCounter{Name: "equal.example", Serial: "2024031802"},
+		ZoneCounter{Name: "drift.example", Serial: "2024031802"},
+		ZoneCounter{Name: "wrapped.example", Serial: "5"},
+		ZoneCounter{Name: "unloaded.example", Serial: "-"},
+	)
+	secondaries := map[string]Statistics{
+		"ns2": zoneStatistics("_default",
+			ZoneCounter{Name: "equal.example", Serial: "2024031802"},
+			ZoneCounter{Name: "Drift.Example.", Serial: "2024031801"},
+			ZoneCounter{Name: "wrapped.example", Serial: "4294967290"},
+			ZoneCounter{Name: "unloaded.example", Serial: "1"},
+			ZoneCounter{Name: "orphan.example", Serial: "7"},
+		),
+		"ns3": zoneStatistics("_default",
+			ZoneCounter{Name: "equal.example", Serial: "2024031802"},
+			ZoneCounter{Name: "drift.example", Serial: "2024031803"},
+			ZoneCounter{Name: "wrapped.example", Serial: "-"},
+		),
+	}
+	r := SerialDrift(primary, secondaries)
+	want := []ZoneDrift{{
+		ZoneRef: ZoneRef{View: "_default", Zone: "drift.example"},
+		Primary: "2024031802",
+		Secondaries: []SecondarySerial{
+			{Target: "ns2", Serial: "2024031801", State: SerialBehind},
+			{Target: "ns3", Serial: "2024031803", State: SerialAhead},
+		},
+	}, {
+		ZoneRef:        ZoneRef{View: "_default", Zone: "orphan.example"},
+		PrimaryMissing: true,
+		Secondaries: []SecondarySerial{
+			{Target: "ns2", Serial: "7", State: SerialUnknown},
+			{Target: "ns3", State: SerialMissing},
+		},
+	}, {
+		ZoneRef: ZoneRef{View: "_default", Zone: "unloaded.example"},
+		Primary: "-",
+		Secondaries: []SecondarySerial{
+			{Target: "ns2", Serial: "1", State: SerialUnknown},
+			{Target: "ns3", State: SerialMissing},
+		},
+	}, {
+		// The serial of the primary wrapped around, so ns2 is behind.
+		ZoneRef: ZoneRef{View: "_default", Zone: "wrapped.example"},
+		Primary: "5",
+		Secondaries: []SecondarySerial{
+			{Target: "ns2", Serial: "4294967290", State: SerialBehind},
+			{Target: "ns3", Serial: "-", State: SerialUnloaded},
+		},
+	}}
+	if !reflect.DeepEqual(r.Zones, want) {
+		t.Fatalf("want zones %+v, got %+v", want, r.Zones)
+	}
+
+	wantString := `_default/drift.example: primary 2024031802, ns2 2024031801 (behind), ns3 2024031803 (ahead)
+_default/orphan.example: primary missing, ns2 7 (unknown), ns3 missing
+_default/unloaded.example: primary -, ns2 1 (unknown), ns3 missing
+_default/wrapped.example: primary 5, ns2 4294967290 (behind), ns3 - (unloaded)
+`
+	if got := r.String(); got != wantString {
+		t.Errorf("want report\n%s\ngot\n%s", wantString, got)
+	}
+}
+
+func TestSerialDriftNone(t *testing.T) {
+	s := zoneStatistics("_default", ZoneCounter{Name: "example.com", Serial: "1"})
+	r := SerialDrift(s, map[string]Statistics{"ns2": s, "ns3": s})
+	if r.Zones != nil || r.String() != "" {
+		t.Errorf("want no drift, got %+v", r)
+	}
+	// Views are compared separately.
+	r = SerialDrift(s, map[string]Statistics{"ns2": zoneStatistics("external", ZoneCounter{Name: "example.com", Serial: "1"})})
+	if len(r.Zones) != 2 {
+		t.Errorf("want zone missing in both views, got %+v", r.Zones)
+	}
+}
+
+func TestSerialState(t *testing.T) {
+	for _, tc := range []struct {
+		primary, secondary string
+		want               SerialState
+	}{
+		{"1", "1", SerialEqual},
+		{"1", "2", SerialAhead},
+		{"2", "1", SerialBehind},
+		{"4294967295", "0", SerialAhead},
+		{"0", "2147483648", SerialUnknown},
+		{"0", "2147483647", SerialAhead},
+		{"0", "2147483649", SerialBehind},
+		{"1", "-", SerialUnloaded},
+		{"-", "1", SerialUnknown},
+	} {
+		if got := serialState(tc.primary, false, tc.secondary, true); got != tc.want {
+			t.Errorf("%s on primary, %s on secondary: want %s, got %s", tc.primary, tc.secondary, tc.want, got)
+		}
+	}
+}
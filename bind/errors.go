@@ -0,0 +1,239 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RedirectError is returned when a request has been redirected, either by a
+// redirect response which was not followed or to a location serving HTML
+// instead of statistics.
+type RedirectError struct {
+	// URL is the requested URL.
+	URL string
+	// Location is the redirect target, if known.
+	Location string
+	// StatusCode is the status of the refused redirect response. It is zero
+	// if the redirect has been followed.
+	StatusCode int
+}
+
+func (e *RedirectError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("request to %q was redirected to %q which returned HTML instead of statistics", e.URL, e.Location)
+	}
+	if e.Location == "" {
+		return fmt.Sprintf("refusing redirect with status %d from %q", e.StatusCode, e.URL)
+	}
+	return fmt.Sprintf("refusing redirect with status %d from %q to %q", e.StatusCode, e.URL, e.Location)
+}
+
+// StatusPageError is returned when the server answers a request for
+// statistics with an HTML page meant for browsers, like the XHTML status page
+// which some builds of BIND 9.9 serve at "/". It usually means that the URL of
+// the client does not point at the root of the statistics channel.
+type StatusPageError struct {
+	// URL is the requested URL.
+	URL string
+	// Paths lists the paths of the documents the client queries below its
+	// URL, by format.
+	Paths map[Format][]string
+}
+
+func (e *StatusPageError) Error() string {
+	formats := make([]string, 0, len(e.Paths))
+	for f := range e.Paths {
+		formats = append(formats, string(f))
+	}
+	sort.Strings(formats)
+	for i, f := range formats {
+		formats[i] = f + " at " + strings.Join(e.Paths[Format(f)], ", ")
+	}
+	return fmt.Sprintf("%q returned an HTML status page instead of statistics, point the client at the root of the statistics channel, below which it queries %s", e.URL, strings.Join(formats, "; "))
+}
+
+// StatusError is returned when the server answers a request with a status
+// other than 200 OK.
+type StatusError struct {
+	URL        string
+	StatusCode int
+	// Status is the status line of the response, e.g. "404 Not Found".
+	Status string
+	// RetryAfter is the delay requested by the Retry-After header of a 503
+	// Service Unavailable response. It is zero if the header is missing.
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status for %q: %s", e.URL, e.Status)
+}
+
+// Is reports whether target is ErrServerBusy and the status is 503 Service
+// Unavailable.
+func (e *StatusError) Is(target error) bool {
+	return target == ErrServerBusy && e.StatusCode == http.StatusServiceUnavailable
+}
+
+// ErrServerBusy is matched by a StatusError with status 503 Service
+// Unavailable, which named returns while its statistics channel is busy. See
+// WithRetries.
+var ErrServerBusy = errors.New("server busy")
+
+// DecodeError is returned when a document cannot be decoded. It describes the
+// position of the decoder when the error occurred. With WithFailureCapture,
+// the errors of decoders which do not report their position, like the JSON
+// client, are wrapped into a DecodeError without Path to report the capture.
+type DecodeError struct {
+	// Path is the path of the element being decoded, with elements separated
+	// by ">" and the name or type attribute of an element in brackets, e.g.
+	// "statistics>views>view[internal]>zones>zone[example.com]". It is empty
+	// if the position is unknown.
+	Path string
+	// Offset is the byte offset of the decoder in the document.
+	Offset int64
+	// Text is the character data of the element being decoded.
+	Text string
+	// CapturePath is the file the response body has been written to, see
+	// WithFailureCapture. It is empty if the body has not been captured.
+	CapturePath string
+	Err         error
+}
+
+func (e *DecodeError) Error() string {
+	msg := e.Err.Error()
+	if e.Path != "" {
+		pos := fmt.Sprintf("at %s (offset %d", e.Path, e.Offset)
+		if e.Text != "" {
+			pos += fmt.Sprintf(", text %.40q", e.Text)
+		}
+		msg = pos + "): " + msg
+	}
+	if e.CapturePath != "" {
+		msg += " (response captured in " + e.CapturePath + ")"
+	}
+	return msg
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// GroupTimeoutError is returned when the timeout of a statistic group set with
+// WithGroupTimeout expires before a document of the group has been fetched. It
+// wraps the error of the request, which matches context.DeadlineExceeded, or
+// the TruncatedError of a partial zones document, see WithPartialZones.
+type GroupTimeoutError struct {
+	Group   StatisticGroup
+	Timeout time.Duration
+	Err     error
+}
+
+func (e *GroupTimeoutError) Error() string {
+	return fmt.Sprintf("timeout of %s statistics exceeded after %s: %s", e.Group, e.Timeout, e.Err)
+}
+
+func (e *GroupTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// ErrTruncatedResponse is matched by the errors of documents whose response
+// ended before the document was complete, e.g. because named has been
+// restarted while sending it. Unlike other decode errors, it does not mean
+// that the document is malformed, so the request is retried by WithRetries.
+// With WithPartialZones, a truncated zones document results in a
+// TruncatedError holding the zones decoded before the truncation, which also
+// matches ErrTruncatedResponse.
+var ErrTruncatedResponse = errors.New("truncated response")
+
+// ErrZoneNotFound is returned when a requested zone does not exist.
+var ErrZoneNotFound = errors.New("zone not found")
+
+// ErrInvalidCounter is wrapped by the errors of ParseCounter, which the
+// clients use to decode the values of counters.
+var ErrInvalidCounter = errors.New("invalid counter value")
+
+// ErrViewNotFound is returned when a requested view does not exist.
+var ErrViewNotFound = errors.New("view not found")
+
+// ErrUnknownItem is returned with WithStrictDecoding if a document contains
+// items unknown to the package. The error lists the unknown items by path.
+var ErrUnknownItem = errors.New("unknown items in document")
+
+// ErrSuspiciousDocument is returned if an XML document contains constructs
+// which BIND never emits and which may be used to exhaust the resources of the
+// decoder, such as DOCTYPE declarations or excessively nested elements. See
+// WithoutXMLHardening.
+var ErrSuspiciousDocument = errors.New("suspicious XML document")
+
+// ErrTooManyZones is wrapped by a TruncatedError if zones have been skipped
+// because of WithMaxZones or WithMaxZonesPerView.
+var ErrTooManyZones = errors.New("too many zones")
+
+// ErrReadIdleTimeout is returned when no data has been received from the
+// server within the timeout configured by WithReadIdleTimeout. It matches
+// context.DeadlineExceeded with errors.Is.
+var ErrReadIdleTimeout error = idleTimeoutError{}
+
+type idleTimeoutError struct{}
+
+func (idleTimeoutError) Error() string        { return "read idle timeout exceeded" }
+func (idleTimeoutError) Timeout() bool        { return true }
+func (idleTimeoutError) Is(target error) bool { return target == context.DeadlineExceeded }
+
+// TruncatedError is returned by Stats together with the statistics decoded so
+// far if not all zones of the zones document have been decoded. This happens
+// if reading the document has been cut short by the deadline of the context or
+// the end of a truncated response, provided partial zones are enabled with
+// WithPartialZones, in which case the error matches context.DeadlineExceeded
+// or ErrTruncatedResponse and other groups may be missing too.
+// It also happens if zones exceed the limit of WithMaxZones or
+// WithMaxZonesPerView, in which case the error matches ErrTooManyZones. The
+// ZoneViews of the statistics hold the zones decoded completely.
+type TruncatedError struct {
+	// Zones is the number of zones decoded, counted like DecodeInfo.Zones.
+	Zones int
+	// Skipped holds the number of zones skipped because of the zone limit
+	// by view. Zones omitted by WithExcludedZones are not counted.
+	Skipped map[string]int
+	Err     error
+}
+
+func (e *TruncatedError) Error() string {
+	msg := fmt.Sprintf("zones document truncated after %d zones", e.Zones)
+	if n := e.SkippedZones(); n > 0 {
+		msg += fmt.Sprintf(", skipped %d zones", n)
+	}
+	return msg + ": " + e.Err.Error()
+}
+
+func (e *TruncatedError) Unwrap() error {
+	return e.Err
+}
+
+// SkippedZones returns the total number of zones skipped because of the zone
+// limit.
+func (e *TruncatedError) SkippedZones() int {
+	n := 0
+	for _, s := range e.Skipped {
+		n += s
+	}
+	return n
+}
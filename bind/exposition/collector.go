@@ -0,0 +1,446 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exposition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CollectorOpts configures a Collector. The options cannot be changed once the
+// Collector has been created, in particular not after it has been registered,
+// as the registry relies on Describe always returning the same descriptors.
+type CollectorOpts struct {
+	// Namespace replaces the "bind" prefix of the metric names, e.g. to
+	// coexist with the metrics of the bind_exporter. It defaults to "bind".
+	Namespace string
+	// EnabledGroups lists the groups fetched from the client and exposed.
+	// All groups are enabled if empty.
+	EnabledGroups []bind.StatisticGroup
+	// DenyMetricPatterns lists shell patterns, see path.Match, of metric
+	// names which are not exposed. The patterns match the full name of the
+	// metric, including the namespace and the "_total" suffix of counters,
+	// e.g. "bind_zone_*".
+	DenyMetricPatterns []string
+	// ZoneMetrics enables the families with a series per zone, such as
+	// bind_zone_serial, which are disabled by default as a server hosting
+	// many zones produces a huge number of series. It requires MaxZones.
+	ZoneMetrics bool
+	// MaxZones limits the zones exposed by the zone families. Zones beyond
+	// the limit, in the order of view and zone names, are counted by the
+	// counter collector_zones_skipped_total.
+	MaxZones int
+	// ZoneFilter selects the zones exposed by the zone families, before
+	// MaxZones applies. It is called with the canonical zone names, see
+	// bind.CanonicalZoneName. All zones are selected if nil.
+	ZoneFilter func(view, zone string) bool
+	// Options configures the exposed metrics like those written by
+	// WriteOpenMetrics, e.g. WithDefaultViewAlias.
+	Options []Option
+	// Clock is the source of the time of scrapes and of the freshness of
+	// groups, unless the client is a bind.FreshnessClient already. It
+	// defaults to bind.SystemClock.
+	Clock bind.Clock
+}
+
+// Help texts of the families exposed only by a Collector.
+const (
+	upHelp             = "Whether the statistics of all enabled groups have been fetched."
+	scrapeDurationHelp = "Time taken to fetch the statistics in seconds."
+	scrapeErrorsHelp   = "Number of failed fetches by statistic group and class of error."
+	zonesSkippedHelp   = "Number of zones omitted from the zone metrics because of the limit of zones."
+	queryDurationHelp  = "Resolver query round-trip time in seconds."
+)
+
+// Collector is a prometheus.Collector exposing the metrics of
+// WriteOpenMetrics for the statistics fetched from a Client on every scrape.
+// It also exposes the gauge "up", which is 1 only if the statistics of all
+// enabled groups have been fetched, the duration of the scrape and the
+// counter "scrape_errors_total" of failed fetches by group and class of
+// error, see ErrorClass. Failed requests the client recovers from, e.g. by a
+// retry or by falling back to another format, do not count as failures, nor
+// do optional groups missing on the server, see bind.GroupOptional. The
+// metrics of the groups fetched before a failure are exposed regardless. The
+// gauges "group_fetched_timestamp_seconds" and
+// "group_served_timestamp_seconds" report the freshness of the groups, see
+// bind.FreshnessClient; the fetch time lags behind if the client serves
+// statistics without fetching them.
+type Collector struct {
+	client    bind.Client
+	freshness *bind.FreshnessClient
+	groups    []bind.StatisticGroup
+	now       func() time.Time
+
+	up             *prometheus.Desc
+	scrapeDuration *prometheus.Desc
+	scrapeErrors   *prometheus.Desc
+	fetched        *prometheus.Desc
+	served         *prometheus.Desc
+	// descs holds the descriptors of the exposed families by name in
+	// families.
+	descs map[string]*prometheus.Desc
+
+	// queryRTT is nil if the histogram of query round-trip times is not
+	// exposed.
+	queryRTT *prometheus.Desc
+
+	maxZones   int
+	zoneFilter func(view, zone string) bool
+	opts       options
+	// skipped is nil unless zone metrics are enabled.
+	skipped *prometheus.Desc
+
+	mu           sync.Mutex
+	skippedTotal float64
+	// errors counts the failed scrapes by group and error class.
+	errors map[[2]string]float64
+}
+
+// NewCollector returns a Collector for the statistics of c. It returns an
+// error if a pattern of opts is malformed, or if zone metrics are enabled
+// without a limit. Unless c is a bind.FreshnessClient, it is wrapped in one.
+func NewCollector(c bind.Client, opts CollectorOpts) (*Collector, error) {
+	ns := opts.Namespace
+	if ns == "" {
+		ns = "bind"
+	}
+	if opts.ZoneMetrics && opts.MaxZones <= 0 {
+		return nil, fmt.Errorf("zone metrics require a positive limit of zones")
+	}
+	for _, p := range opts.DenyMetricPatterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid metric pattern %q: %w", p, err)
+		}
+	}
+	groups := append([]bind.StatisticGroup(nil), opts.EnabledGroups...)
+	if len(groups) == 0 {
+		groups = []bind.StatisticGroup{bind.ServerStats, bind.ViewStats, bind.TaskStats}
+	}
+	enabled := map[bind.StatisticGroup]bool{}
+	for _, g := range groups {
+		enabled[g] = true
+	}
+
+	clock := opts.Clock
+	if clock == nil {
+		clock = bind.SystemClock()
+	}
+	fc, ok := c.(*bind.FreshnessClient)
+	if !ok {
+		fc = bind.NewFreshnessClient(c, bind.WithClock(clock))
+	}
+	col := &Collector{
+		client:    fc,
+		freshness: fc,
+		groups:    groups,
+		now:       clock.Now,
+
+		up:             prometheus.NewDesc(prometheus.BuildFQName(ns, "", "up"), upHelp, nil, nil),
+		scrapeDuration: prometheus.NewDesc(prometheus.BuildFQName(ns, "", "scrape_duration_seconds"), scrapeDurationHelp, nil, nil),
+		scrapeErrors: prometheus.NewDesc(prometheus.BuildFQName(ns, "", "scrape_errors_total"),
+			scrapeErrorsHelp, []string{"group", "class"}, nil),
+		fetched: prometheus.NewDesc(prometheus.BuildFQName(ns, "group", "fetched_timestamp_seconds"),
+			fetchedHelp, []string{"group"}, nil),
+		served: prometheus.NewDesc(prometheus.BuildFQName(ns, "group", "served_timestamp_seconds"),
+			servedHelp, []string{"group"}, nil),
+		descs:  map[string]*prometheus.Desc{},
+		errors: map[[2]string]float64{},
+
+		maxZones:   opts.MaxZones,
+		zoneFilter: opts.ZoneFilter,
+		opts:       newOptions(opts.Options),
+	}
+	if opts.ZoneMetrics && enabled[bind.ViewStats] {
+		col.skipped = prometheus.NewDesc(prometheus.BuildFQName(ns, "collector", "zones_skipped_total"), zonesSkippedHelp, nil, nil)
+	}
+	if name := prometheus.BuildFQName(ns, "resolver", "query_duration_seconds"); enabled[bind.ViewStats] && !denied(name, opts.DenyMetricPatterns) {
+		col.queryRTT = prometheus.NewDesc(name, queryDurationHelp, []string{"view"}, nil)
+	}
+	for _, f := range families(complete, col.opts) {
+		name := ns + strings.TrimPrefix(f.name, "bind")
+		if f.typ == counter {
+			name += "_total"
+		}
+		if f.group != "" && !enabled[f.group] || f.zone && !opts.ZoneMetrics || denied(name, opts.DenyMetricPatterns) {
+			continue
+		}
+		var labels []string
+		for _, l := range f.samples[0].labels {
+			labels = append(labels, l[0])
+		}
+		col.descs[f.name] = prometheus.NewDesc(name, f.help, labels, nil)
+	}
+	return col, nil
+}
+
+func denied(name string, patterns []string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// complete has a value in every field exposed by families, which thus
+// returns every family for it.
+var complete = bind.Statistics{
+	Server: bind.Server{
+		BootTime:         time.Unix(1, 0),
+		ConfigTime:       time.Unix(1, 0),
+		IncomingQueries:  []bind.Counter{{}},
+		IncomingRequests: []bind.Counter{{}},
+		NameServerStats:  []bind.Counter{{}},
+		ZoneMaintenance:  []bind.Counter{{}},
+		ServerRcodes:     []bind.Counter{{}},
+		Extra:            bind.Extra{"": {{}}},
+	},
+	Views: []bind.View{{
+		Cache:           []bind.Gauge{{}},
+		CacheMemory:     []bind.Gauge{{}},
+		CacheStats:      []bind.Counter{{}},
+		ResolverStats:   []bind.Counter{{}},
+		ResolverGauges:  []bind.Gauge{{}},
+		ResolverQueries: []bind.Counter{{}},
+		Extra:           bind.Extra{"": {{}}},
+	}},
+	ZoneViews: []bind.ZoneView{{ZoneData: []bind.ZoneCounter{{
+		Serial:          "1",
+		IncomingQueries: []bind.Counter{{}},
+		QueryResults:    []bind.Counter{{}},
+		Extra:           bind.Extra{"": {{}}},
+	}}}},
+	TaskManager: bind.TaskManager{ThreadModel: bind.ThreadModel{WorkerThreads: 1}},
+	Memory: bind.Memory{
+		Summary:  bind.MemorySummary{InUse: 1},
+		Contexts: []bind.MemoryContext{{}},
+	},
+	Warnings:        []bind.Warning{{}},
+	OmittedWarnings: 1,
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.up
+	ch <- c.scrapeDuration
+	ch <- c.scrapeErrors
+	ch <- c.fetched
+	ch <- c.served
+	if c.skipped != nil {
+		ch <- c.skipped
+	}
+	if c.queryRTT != nil {
+		ch <- c.queryRTT
+	}
+	for _, d := range c.descs {
+		ch <- d
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	// Only the outcome of the last request of a group counts: the client
+	// recovers from the failures of earlier ones, e.g. by retrying a
+	// truncated response or falling back to another format.
+	var (
+		mu   sync.Mutex
+		last = map[bind.StatisticGroup]error{}
+	)
+	ctx := bind.WithClientTrace(context.Background(), &bind.ClientTrace{
+		GetDone: func(_ context.Context, g bind.StatisticGroup, _ bind.RequestInfo, err error) {
+			if g != "" {
+				mu.Lock()
+				last[g] = err
+				mu.Unlock()
+			}
+		},
+	})
+	start := c.now()
+	s, err := c.client.Stats(ctx, c.groups...)
+	duration := c.now().Sub(start)
+	var failed [][2]string
+	if err != nil {
+		mu.Lock()
+		for g, gerr := range last {
+			// Optional groups the server does not provide are tolerated,
+			// like zones omitted by the zone limit.
+			if gerr != nil && !errors.Is(gerr, bind.ErrTooManyZones) && !containsGroup(s.MissingGroups, g) {
+				failed = append(failed, [2]string{string(g), ErrorClass(gerr)})
+			}
+		}
+		mu.Unlock()
+		if len(failed) == 0 && !errors.Is(err, bind.ErrTooManyZones) {
+			// The error did not occur in a request, e.g. while converting
+			// the documents.
+			failed = append(failed, [2]string{"unknown", ErrorClass(err)})
+		}
+	}
+
+	up := 1.
+	if len(failed) > 0 {
+		up = 0
+	}
+	ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, up)
+	ch <- prometheus.MustNewConstMetric(c.scrapeDuration, prometheus.GaugeValue, duration.Seconds())
+	c.mu.Lock()
+	for _, f := range failed {
+		c.errors[f]++
+	}
+	for f, n := range c.errors {
+		ch <- prometheus.MustNewConstMetric(c.scrapeErrors, prometheus.CounterValue, n, f[0], f[1])
+	}
+	c.mu.Unlock()
+	for _, f := range freshnessFamilies(c.freshness.Freshness()) {
+		d := c.fetched
+		if f.name == "bind_group_served_timestamp_seconds" {
+			d = c.served
+		}
+		for _, smp := range f.samples {
+			ch <- prometheus.MustNewConstMetric(d, prometheus.GaugeValue, smp.value, smp.labels[0][1])
+		}
+	}
+	if c.skipped != nil {
+		var skipped int
+		s.ZoneViews, skipped = c.limitZones(s.ZoneViews)
+		c.mu.Lock()
+		c.skippedTotal += float64(skipped)
+		total := c.skippedTotal
+		c.mu.Unlock()
+		ch <- prometheus.MustNewConstMetric(c.skipped, prometheus.CounterValue, total)
+	}
+	for _, f := range families(s, c.opts) {
+		d, ok := c.descs[f.name]
+		if !ok {
+			continue
+		}
+		typ := prometheus.GaugeValue
+		if f.typ == counter {
+			typ = prometheus.CounterValue
+		}
+		for _, smp := range f.samples {
+			if f.name == "bind_resolver" && strings.HasPrefix(smp.labels[1][1], bind.QryRTT) {
+				// Exposed as histogram.
+				continue
+			}
+			values := make([]string, len(smp.labels))
+			for i, l := range smp.labels {
+				values[i] = l[1]
+			}
+			ch <- prometheus.MustNewConstMetric(d, typ, smp.value, values...)
+		}
+	}
+	if c.queryRTT != nil {
+		for _, v := range s.Views {
+			c.collectQueryRTT(ch, v)
+		}
+	}
+}
+
+// collectQueryRTT sends the histogram of query round-trip times of v, unless
+// BIND did not report any. The sum of the round-trip times is unknown.
+func (c *Collector) collectQueryRTT(ch chan<- prometheus.Metric, v bind.View) {
+	if len(v.QueryRTT.Buckets) == 0 {
+		return
+	}
+	buckets := make(map[float64]uint64, len(v.QueryRTT.Buckets))
+	for _, b := range v.QueryRTT.Buckets {
+		if !math.IsInf(b.UpperBound, 1) {
+			buckets[b.UpperBound] = b.Count
+		}
+	}
+	ch <- prometheus.MustNewConstHistogram(c.queryRTT, v.QueryRTT.Count, math.NaN(), buckets, c.opts.view(v.Name))
+}
+
+// limitZones returns the zones of vs selected by the zone filter, up to the
+// limit of zones, sorted by view and zone name so that the same zones are
+// exposed on every scrape. It also returns the number of zones beyond the
+// limit.
+func (c *Collector) limitZones(vs []bind.ZoneView) ([]bind.ZoneView, int) {
+	sorted := append([]bind.ZoneView(nil), vs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	n, skipped := 0, 0
+	for i, v := range sorted {
+		var zones []bind.ZoneCounter
+		for _, z := range v.ZoneData {
+			if c.zoneFilter == nil || c.zoneFilter(v.Name, z.Name) {
+				zones = append(zones, z)
+			}
+		}
+		sort.Slice(zones, func(i, j int) bool { return zones[i].Name < zones[j].Name })
+		if keep := c.maxZones - n; len(zones) > keep {
+			skipped += len(zones) - keep
+			zones = zones[:keep]
+		}
+		n += len(zones)
+		sorted[i].ZoneData = zones
+	}
+	return sorted, skipped
+}
+
+// Classes of errors returned by ErrorClass.
+const (
+	ErrorClassConnection = "connection"
+	ErrorClassHTTPStatus = "http_status"
+	ErrorClassDecode     = "decode"
+	ErrorClassTimeout    = "timeout"
+	ErrorClassBusy       = "busy"
+)
+
+// ErrorClass classifies an error returned by a Client: ErrorClassTimeout for
+// expired deadlines, including bind.ErrReadIdleTimeout, ErrorClassBusy for
+// errors matching bind.ErrServerBusy, ErrorClassHTTPStatus for any other
+// bind.StatusError or bind.RedirectError, ErrorClassConnection for other errors
+// of the HTTP request, and ErrorClassDecode for all other errors, which occur
+// while decoding the documents.
+func ErrorClass(err error) string {
+	var (
+		nerr   net.Error
+		status *bind.StatusError
+		redir  *bind.RedirectError
+		uerr   *url.Error
+	)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &nerr) && nerr.Timeout():
+		return ErrorClassTimeout
+	case errors.Is(err, bind.ErrServerBusy):
+		return ErrorClassBusy
+	case errors.As(err, &status), errors.As(err, &redir):
+		return ErrorClassHTTPStatus
+	case errors.As(err, &uerr), errors.As(err, &nerr):
+		return ErrorClassConnection
+	}
+	return ErrorClassDecode
+}
+
+// containsGroup reports whether gs contains g.
+func containsGroup(gs []bind.StatisticGroup, g bind.StatisticGroup) bool {
+	for _, h := range gs {
+		if h == g {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,466 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exposition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/auto"
+	"github.com/prometheus-community/bind_exporter/bind/bindtest"
+	"github.com/prometheus-community/bind_exporter/bind/json"
+	"github.com/prometheus-community/bind_exporter/bind/xml"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	ts := bindtest.NewServer()
+	defer ts.Close()
+	client := json.NewClient(ts.URL, nil)
+
+	for _, tc := range []struct {
+		name    string
+		opts    CollectorOpts
+		present []string
+		absent  []string
+	}{
+		{
+			name:    "default",
+			present: []string{"bind_up", "bind_incoming_queries_total", "bind_resolver_total", "bind_worker_threads"},
+			absent:  []string{"bind_zone_serial", "bind_collector_zones_skipped_total"},
+		},
+		{
+			name:    "zones",
+			opts:    CollectorOpts{ZoneMetrics: true, MaxZones: 10},
+			present: []string{"bind_zone_serial", "bind_collector_zones_skipped_total"},
+		},
+		{
+			name:    "namespace",
+			opts:    CollectorOpts{Namespace: "named"},
+			present: []string{"named_up", "named_incoming_queries_total"},
+			absent:  []string{"bind_up", "bind_incoming_queries_total"},
+		},
+		{
+			name:    "server only",
+			opts:    CollectorOpts{EnabledGroups: []bind.StatisticGroup{bind.ServerStats}},
+			present: []string{"bind_up", "bind_incoming_queries_total"},
+			absent:  []string{"bind_resolver_total", "bind_zone_serial", "bind_worker_threads"},
+		},
+		{
+			name:    "without zones",
+			opts:    CollectorOpts{ZoneMetrics: true, MaxZones: 10, DenyMetricPatterns: []string{"bind_zone_*", "*_worker_threads"}},
+			present: []string{"bind_incoming_queries_total", "bind_resolver_total", "bind_tasks_running"},
+			absent:  []string{"bind_zone_serial", "bind_zone_maintenance_total", "bind_worker_threads"},
+		},
+	} {
+		c, err := NewCollector(client, tc.opts)
+		if err != nil {
+			t.Fatalf("%s: %s", tc.name, err)
+		}
+		problems, err := testutil.CollectAndLint(c)
+		if err != nil {
+			t.Fatalf("%s: %s", tc.name, err)
+		}
+		for _, p := range problems {
+			t.Errorf("%s: lint problem with %s: %s", tc.name, p.Metric, p.Text)
+		}
+
+		// The pedantic registry fails on metrics which have not been
+		// described.
+		reg := prometheus.NewPedanticRegistry()
+		if err := reg.Register(c); err != nil {
+			t.Fatalf("%s: %s", tc.name, err)
+		}
+		mfs, err := reg.Gather()
+		if err != nil {
+			t.Fatalf("%s: %s", tc.name, err)
+		}
+		got := map[string]bool{}
+		for _, mf := range mfs {
+			got[mf.GetName()] = true
+		}
+		for _, n := range tc.present {
+			if !got[n] {
+				t.Errorf("%s: want metric %s", tc.name, n)
+			}
+		}
+		for _, n := range tc.absent {
+			if got[n] {
+				t.Errorf("%s: want no metric %s", tc.name, n)
+			}
+		}
+	}
+}
+
+func TestCollectorDefaultViewAlias(t *testing.T) {
+	ts := bindtest.NewServer()
+	defer ts.Close()
+	c, err := NewCollector(json.NewClient(ts.URL, nil), CollectorOpts{
+		ZoneMetrics: true,
+		MaxZones:    10,
+		Options:     []Option{WithDefaultViewAlias("default")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(c); err != nil {
+		t.Fatal(err)
+	}
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	views := map[string]bool{}
+	for _, mf := range mfs {
+		for _, m := range mf.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "view" {
+					views[l.GetValue()] = true
+				}
+			}
+		}
+	}
+	if !views["default"] || views[bind.DefaultView] {
+		t.Errorf("want view %s exposed as default, got views %v", bind.DefaultView, views)
+	}
+}
+
+func TestCollectorMemory(t *testing.T) {
+	ts := bindtest.NewServer()
+	defer ts.Close()
+	c, err := NewCollector(xml.NewClient(ts.URL, nil), CollectorOpts{
+		EnabledGroups: []bind.StatisticGroup{bind.ServerStats},
+		Options:       []Option{WithMemoryContexts(2)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	problems, err := testutil.CollectAndLint(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range problems {
+		t.Errorf("lint problem with %s: %s", p.Metric, p.Text)
+	}
+
+	want := `
+# HELP bind_memory_in_use_bytes Memory in use by the memory contexts of named in bytes.
+# TYPE bind_memory_in_use_bytes gauge
+bind_memory_in_use_bytes 6.631824786e+09
+# HELP bind_memory_context_in_use_bytes Memory in use by the memory contexts of a name in bytes, for the names using most memory.
+# TYPE bind_memory_context_in_use_bytes gauge
+bind_memory_context_in_use_bytes{context="(other)"} 119250
+bind_memory_context_in_use_bytes{context="main"} 3.630424e+06
+bind_memory_context_in_use_bytes{context="zonemgr-pool"} 6.626239416e+09
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "bind_memory_in_use_bytes", "bind_memory_context_in_use_bytes"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollectorInvalidOpts(t *testing.T) {
+	if _, err := NewCollector(nil, CollectorOpts{DenyMetricPatterns: []string{"bind_["}}); err == nil {
+		t.Error("want error for malformed pattern")
+	}
+	if _, err := NewCollector(nil, CollectorOpts{ZoneMetrics: true}); err == nil {
+		t.Error("want error for zone metrics without limit")
+	}
+}
+
+type staticClient bind.Statistics
+
+func (c staticClient) Stats(context.Context, ...bind.StatisticGroup) (bind.Statistics, error) {
+	return bind.Statistics(c), nil
+}
+
+func TestCollectorZoneLimit(t *testing.T) {
+	v := bind.ZoneView{Name: "_default"}
+	for i := 0; i < 1000; i++ {
+		v.ZoneData = append(v.ZoneData, bind.ZoneCounter{
+			Name:            fmt.Sprintf("Zone%03d.Example.", 999-i),
+			Serial:          "1",
+			IncomingQueries: []bind.Counter{{Name: "A", Counter: 1}},
+		})
+	}
+	client := staticClient{ZoneViews: []bind.ZoneView{v}}
+
+	for _, tc := range []struct {
+		filter func(view, zone string) bool
+		first  string
+	}{
+		{first: "zone000.example"},
+		{filter: func(_, zone string) bool { return zone >= "Zone500" }, first: "zone500.example"},
+	} {
+		c, err := NewCollector(client, CollectorOpts{ZoneMetrics: true, MaxZones: 10, ZoneFilter: tc.filter})
+		if err != nil {
+			t.Fatal(err)
+		}
+		reg := prometheus.NewPedanticRegistry()
+		reg.MustRegister(c)
+		mfs, err := reg.Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]int{"bind_up": 1, "bind_scrape_duration_seconds": 1, "bind_group_served_timestamp_seconds": 3, "bind_collector_zones_skipped_total": 1, "bind_zone_serial": 10, "bind_zone_incoming_queries_total": 10}
+		got := map[string]int{}
+		var skipped float64
+		var first string
+		for _, mf := range mfs {
+			got[mf.GetName()] = len(mf.GetMetric())
+			switch mf.GetName() {
+			case "bind_collector_zones_skipped_total":
+				skipped = mf.GetMetric()[0].GetCounter().GetValue()
+			case "bind_zone_serial":
+				for _, l := range mf.GetMetric()[0].GetLabel() {
+					if l.GetName() == "zone_name" {
+						first = l.GetValue()
+					}
+				}
+			}
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("want series %v, got %v", want, got)
+		}
+		wantSkipped := 990.
+		if tc.filter != nil {
+			wantSkipped = 490
+		}
+		if skipped != wantSkipped || first != tc.first {
+			t.Errorf("want %v zones skipped and first zone %q, got %v and %q", wantSkipped, tc.first, skipped, first)
+		}
+	}
+}
+
+func TestCollectorQueryRTT(t *testing.T) {
+	ts := bindtest.NewServer()
+	defer ts.Close()
+	c, err := NewCollector(json.NewClient(ts.URL, nil), CollectorOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open("testdata/query-rtt.prom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := testutil.CollectAndCompare(c, f, "bind_resolver_query_duration_seconds"); err != nil {
+		t.Error(err)
+	}
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(c)
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mf := range mfs {
+		if mf.GetName() != "bind_resolver_total" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if strings.HasPrefix(l.GetValue(), bind.QryRTT) {
+					t.Errorf("want QryRTT counters only in histogram, got %s", l.GetValue())
+				}
+			}
+		}
+	}
+}
+
+func TestCollectorPartialFailure(t *testing.T) {
+	fixtures := bindtest.Handler()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == json.TasksPath {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		fixtures.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	c, err := NewCollector(json.NewClient(ts.URL, nil), CollectorOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(0, 0)
+	c.now = func() time.Time {
+		now = now.Add(250 * time.Millisecond)
+		return now
+	}
+
+	want := `# HELP bind_scrape_duration_seconds Time taken to fetch the statistics in seconds.
+# TYPE bind_scrape_duration_seconds gauge
+bind_scrape_duration_seconds 0.25
+# HELP bind_scrape_errors_total Number of failed fetches by statistic group and class of error.
+# TYPE bind_scrape_errors_total counter
+bind_scrape_errors_total{class="http_status",group="tasks"} 1
+# HELP bind_up Whether the statistics of all enabled groups have been fetched.
+# TYPE bind_up gauge
+bind_up 0
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want),
+		"bind_up", "bind_scrape_duration_seconds", "bind_scrape_errors_total", "bind_worker_threads"); err != nil {
+		t.Error(err)
+	}
+	if n := testutil.CollectAndCount(c, "bind_incoming_queries_total"); n == 0 {
+		t.Error("want metrics of the server statistics fetched before the failure")
+	}
+}
+
+func TestCollectorRecoveredFailures(t *testing.T) {
+	fixtures := bindtest.Handler()
+	clk := bindtest.NewFakeClock(time.Unix(0, 0))
+	for _, tc := range []struct {
+		name    string
+		handler func(n int, w http.ResponseWriter, r *http.Request) bool
+		client  func(url string) bind.Client
+	}{
+		{
+			// The truncated first response of the server document is
+			// retried.
+			name: "retry",
+			handler: func(n int, w http.ResponseWriter, r *http.Request) bool {
+				if r.URL.Path != json.ServerPath || n > 0 {
+					return false
+				}
+				rec := httptest.NewRecorder()
+				fixtures.ServeHTTP(rec, r)
+				b := rec.Body.Bytes()
+				w.Header().Set("Content-Length", fmt.Sprint(len(b)))
+				w.Write(b[:len(b)/2])
+				return true
+			},
+			client: func(url string) bind.Client {
+				return json.NewClient(url, nil, bind.WithRetries(1), bind.WithClock(clk))
+			},
+		},
+		{
+			// The auto client falls back to XML.
+			name: "fallback",
+			handler: func(_ int, w http.ResponseWriter, r *http.Request) bool {
+				if !strings.HasPrefix(r.URL.Path, "/json/") {
+					return false
+				}
+				http.NotFound(w, r)
+				return true
+			},
+			client: func(url string) bind.Client { return auto.NewClient(url, nil) },
+		},
+		{
+			// BIND 9.18 has no task statistics.
+			name: "missing",
+			handler: func(_ int, w http.ResponseWriter, r *http.Request) bool {
+				if r.URL.Path != json.TasksPath {
+					return false
+				}
+				http.NotFound(w, r)
+				return true
+			},
+			client: func(url string) bind.Client { return json.NewClient(url, nil) },
+		},
+	} {
+		requests := map[string]int{}
+		var mu sync.Mutex
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			n := requests[r.URL.Path]
+			requests[r.URL.Path]++
+			mu.Unlock()
+			if !tc.handler(n, w, r) {
+				fixtures.ServeHTTP(w, r)
+			}
+		}))
+		c, err := NewCollector(tc.client(ts.URL), CollectorOpts{})
+		if err != nil {
+			t.Fatalf("%s: %s", tc.name, err)
+		}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			if tc.name == "retry" {
+				clk.BlockUntilDue(bind.DefaultRetryDelay)
+				clk.Advance(bind.DefaultRetryDelay)
+			}
+		}()
+		want := `# HELP bind_up Whether the statistics of all enabled groups have been fetched.
+# TYPE bind_up gauge
+bind_up 1
+`
+		if err := testutil.CollectAndCompare(c, strings.NewReader(want), "bind_up", "bind_scrape_errors_total"); err != nil {
+			t.Errorf("%s: %s", tc.name, err)
+		}
+		<-done
+		ts.Close()
+	}
+}
+
+func TestErrorClass(t *testing.T) {
+	for err, want := range map[error]string{
+		&bind.StatusError{StatusCode: 500}:                           ErrorClassHTTPStatus,
+		&bind.StatusError{StatusCode: 503}:                           ErrorClassBusy,
+		&bind.RedirectError{StatusCode: 302}:                         ErrorClassHTTPStatus,
+		fmt.Errorf("reading: %w", bind.ErrReadIdleTimeout):           ErrorClassTimeout,
+		context.DeadlineExceeded:                                     ErrorClassTimeout,
+		&url.Error{Op: "Get", Err: errors.New("connection refused")}: ErrorClassConnection,
+		&bind.DecodeError{Err: errors.New("unexpected EOF")}:         ErrorClassDecode,
+	} {
+		if got := ErrorClass(err); got != want {
+			t.Errorf("%v: want class %s, got %s", err, want, got)
+		}
+	}
+}
+
+func TestSchemaCollector(t *testing.T) {
+	declared := declaredMetrics(t)
+	ts := bindtest.NewServer()
+	defer ts.Close()
+	c, err := NewCollector(xml.NewClient(ts.URL, nil), CollectorOpts{ZoneMetrics: true, MaxZones: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(c)
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mf := range mfs {
+		typ := strings.ToLower(mf.GetType().String())
+		name := mf.GetName()
+		if typ == "counter" {
+			name = strings.TrimSuffix(name, "_total")
+		}
+		for _, m := range mf.GetMetric() {
+			var labels []string
+			for _, l := range m.GetLabel() {
+				labels = append(labels, l.GetName())
+			}
+			checkDeclared(t, declared, name, typ, labels)
+		}
+	}
+}
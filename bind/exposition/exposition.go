@@ -0,0 +1,411 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package exposition renders Statistics in text formats for humans and
+// monitoring systems, and exposes them to Prometheus with Collector.
+package exposition
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+)
+
+type metricType string
+
+const (
+	counter   metricType = "counter"
+	gauge     metricType = "gauge"
+	histogram metricType = "histogram"
+)
+
+type family struct {
+	name string
+	typ  metricType
+	help string
+	// group is the statistic group the samples are taken from. It is empty
+	// for families about the statistics as a whole.
+	group bind.StatisticGroup
+	// zone is set for families with a sample per zone.
+	zone    bool
+	samples []sample
+}
+
+type sample struct {
+	labels [][2]string
+	value  float64
+}
+
+// counterSamples returns a sample per counter, labeled by labels and the
+// name of the counter as label.
+func counterSamples(label string, cs []bind.Counter, labels ...[2]string) []sample {
+	ss := make([]sample, 0, len(cs))
+	for _, c := range cs {
+		l := append([][2]string{}, labels...)
+		ss = append(ss, sample{labels: append(l, [2]string{label, c.Name}), value: float64(c.Counter)})
+	}
+	return ss
+}
+
+// gaugeSamples returns a sample per gauge, labeled by labels and the name of
+// the gauge as label.
+func gaugeSamples(label string, gs []bind.Gauge, labels ...[2]string) []sample {
+	ss := make([]sample, 0, len(gs))
+	for _, g := range gs {
+		l := append([][2]string{}, labels...)
+		ss = append(ss, sample{labels: append(l, [2]string{label, g.Name}), value: float64(g.Gauge)})
+	}
+	return ss
+}
+
+// extraSamples returns a sample per counter of the sections of e, labeled by
+// labels, the type of the section and the name of the counter.
+func extraSamples(e bind.Extra, labels ...[2]string) []sample {
+	var ss []sample
+	for _, t := range e.Sections() {
+		l := append(labels[:len(labels):len(labels)], [2]string{"section", t})
+		ss = append(ss, counterSamples("name", e[t], l...)...)
+	}
+	return ss
+}
+
+// zoneLabels returns the sanitized label values of the zones of v, keyed by
+// zone name.
+func zoneLabels(v bind.ZoneView) map[string]string {
+	names := make([]string, len(v.ZoneData))
+	for i, z := range v.ZoneData {
+		names[i] = z.Name
+	}
+	return bind.SanitizeZoneLabels(names)
+}
+
+func counters(name, help, label string, cs []bind.Counter) family {
+	return family{name: name, typ: counter, help: help, samples: counterSamples(label, cs)}
+}
+
+func single(name, help string, typ metricType, v float64) family {
+	return family{name: name, typ: typ, help: help, samples: []sample{{value: v}}}
+}
+
+// Option configures the metrics written by the functions of the package and
+// exposed by a Collector, see CollectorOpts.Options.
+type Option func(*options)
+
+type options struct {
+	// viewAlias replaces bind.DefaultView if aliased is set.
+	viewAlias string
+	aliased   bool
+	// memoryContexts is the number of memory contexts exposed, see
+	// bind.TopMemoryContexts.
+	memoryContexts int
+}
+
+func newOptions(opts []Option) options {
+	o := options{memoryContexts: bind.DefaultMemoryContexts}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// view returns the value of the view label of the view name.
+func (o options) view(name string) string {
+	if o.aliased {
+		return bind.ViewAlias(name, o.viewAlias)
+	}
+	return name
+}
+
+// WithDefaultViewAlias labels the metrics of the view bind.DefaultView with
+// the view alias instead, see bind.WithDefaultViewAlias. Prometheus takes an
+// empty alias for an absent view label. The statistics are not modified, and
+// CollectorOpts.ZoneFilter is still called with the names of the views.
+func WithDefaultViewAlias(alias string) Option {
+	return func(o *options) {
+		o.viewAlias = alias
+		o.aliased = true
+	}
+}
+
+// WithMemoryContexts exposes the n memory contexts using most memory, see
+// bind.TopMemoryContexts, instead of bind.DefaultMemoryContexts. A
+// non-positive n omits the family bind_memory_context_in_use_bytes.
+func WithMemoryContexts(n int) Option {
+	return func(o *options) {
+		o.memoryContexts = n
+	}
+}
+
+// families returns the metric families of s. Families without samples are
+// omitted.
+func families(s bind.Statistics, o options) []family {
+	var fs []family
+	add := func(g bind.StatisticGroup, f ...family) {
+		for i := range f {
+			f[i].group = g
+		}
+		fs = append(fs, f...)
+	}
+
+	if !s.Server.BootTime.IsZero() {
+		add(bind.ServerStats, single("bind_boot_time_seconds", "Start time of the BIND process since unix epoch in seconds.", gauge, float64(s.Server.BootTime.Unix())))
+	}
+	if !s.Server.ConfigTime.IsZero() {
+		add(bind.ServerStats, single("bind_config_time_seconds", "Time of the last reconfiguration since unix epoch in seconds.", gauge, float64(s.Server.ConfigTime.Unix())))
+	}
+	add(bind.ServerStats,
+		counters("bind_incoming_queries", "Number of incoming DNS queries.", "type", s.Server.IncomingQueries),
+		counters("bind_incoming_requests", "Number of incoming DNS requests.", "opcode", s.Server.IncomingRequests),
+		counters("bind_name_server", "Name server statistics.", "name", s.Server.NameServerStats),
+		counters("bind_response_rcodes", "Number of responses sent per RCODE.", "rcode", s.Server.ServerRcodes),
+		counters("bind_zone_maintenance", "Zone maintenance statistics.", "name", s.Server.ZoneMaintenance),
+		family{name: "bind_unknown_server", typ: counter, help: "Server counters of sections unknown to the exporter.", samples: extraSamples(s.Server.Extra)},
+	)
+	if s.Memory.Summary.Reported() {
+		for _, g := range s.Memory.Summary.Gauges() {
+			info, _ := bind.DescribeIn(bind.MemoryCounters, g.Name)
+			add(bind.ServerStats, single("bind_memory_"+info.MetricName(), info.Help, gauge, float64(g.Gauge)))
+		}
+	}
+	add(bind.ServerStats, family{
+		name:    "bind_memory_context_in_use_bytes",
+		typ:     gauge,
+		help:    "Memory in use by the memory contexts of a name in bytes, for the names using most memory.",
+		samples: gaugeSamples("context", bind.TopMemoryContexts(s.Memory.Contexts, o.memoryContexts)),
+	})
+
+	cache := family{name: "bind_resolver_cache_rrsets", typ: gauge, help: "Number of RRsets in cache database."}
+	memory := family{name: "bind_resolver_cache_memory_bytes", typ: gauge, help: "Memory used by the cache in bytes."}
+	cacheStats := family{name: "bind_resolver_cache", typ: counter, help: "Cache statistics, e.g. the queries answered from the cache."}
+	queries := family{name: "bind_resolver_queries", typ: counter, help: "Number of outgoing DNS queries."}
+	stats := family{name: "bind_resolver", typ: counter, help: "Resolver statistics."}
+	resGauges := family{name: "bind_resolver_current", typ: gauge, help: "Resolver statistics with a current value, e.g. the number of active fetches."}
+	viewExtra := family{name: "bind_unknown_view", typ: counter, help: "View counters of sections unknown to the exporter."}
+	for _, v := range s.Views {
+		view := [2]string{"view", o.view(v.Name)}
+		cache.samples = append(cache.samples, gaugeSamples("type", v.Cache, view)...)
+		memory.samples = append(memory.samples, gaugeSamples("name", v.CacheMemory, view)...)
+		cacheStats.samples = append(cacheStats.samples, counterSamples("name", v.CacheStats, view)...)
+		queries.samples = append(queries.samples, counterSamples("type", v.ResolverQueries, view)...)
+		stats.samples = append(stats.samples, counterSamples("name", v.ResolverStats, view)...)
+		resGauges.samples = append(resGauges.samples, gaugeSamples("name", v.ResolverGauges, view)...)
+		viewExtra.samples = append(viewExtra.samples, extraSamples(v.Extra, view)...)
+	}
+	add(bind.ViewStats, cache, memory, cacheStats, queries, stats, resGauges, viewExtra)
+
+	serial := family{name: "bind_zone_serial", typ: gauge, help: "Zone serial number.", zone: true}
+	zoneQueries := family{name: "bind_zone_incoming_queries", typ: counter, help: "Number of incoming DNS queries per zone.", zone: true}
+	zoneResults := family{name: "bind_zone_query_results", typ: counter, help: "Number of query results per zone.", zone: true}
+	zoneExtra := family{name: "bind_unknown_zone", typ: counter, help: "Zone counters of sections unknown to the exporter.", zone: true}
+	for _, v := range s.ZoneViews {
+		zones := zoneLabels(v)
+		for _, z := range v.ZoneData {
+			labels := [][2]string{{"view", o.view(v.Name)}, {"zone_name", zones[z.Name]}}
+			if n, err := strconv.ParseUint(z.Serial, 10, 32); err == nil {
+				serial.samples = append(serial.samples, sample{labels: labels, value: float64(n)})
+			}
+			zoneQueries.samples = append(zoneQueries.samples, counterSamples("type", z.IncomingQueries, labels...)...)
+			zoneResults.samples = append(zoneResults.samples, counterSamples("result", z.QueryResults, labels...)...)
+			zoneExtra.samples = append(zoneExtra.samples, extraSamples(z.Extra, labels...)...)
+		}
+	}
+	add(bind.ViewStats, serial, zoneQueries, zoneResults, zoneExtra)
+
+	if tm := s.TaskManager.ThreadModel; tm.WorkerThreads > 0 {
+		add(bind.TaskStats,
+			single("bind_tasks_running", "Number of running tasks.", gauge, float64(tm.TasksRunning)),
+			single("bind_worker_threads", "Total number of available worker threads.", gauge, float64(tm.WorkerThreads)),
+		)
+	}
+
+	warnings := family{name: "bind_warnings", typ: gauge, help: "Number of warnings about the statistics by code."}
+	codes := map[string]int{}
+	for _, w := range s.Warnings {
+		if codes[w.Code] == 0 {
+			warnings.samples = append(warnings.samples, sample{labels: [][2]string{{"code", w.Code}}})
+		}
+		codes[w.Code]++
+	}
+	for i := range warnings.samples {
+		warnings.samples[i].value = float64(codes[warnings.samples[i].labels[0][1]])
+	}
+	add("", warnings)
+	if s.OmittedWarnings > 0 {
+		add("", single("bind_warnings_omitted", "Number of warnings omitted because of the limit of warnings.", gauge, float64(s.OmittedWarnings)))
+	}
+
+	// The counters of the JSON documents are decoded from objects, so sort
+	// the samples to make the output stable.
+	n := 0
+	for _, f := range fs {
+		if len(f.samples) > 0 {
+			sort.SliceStable(f.samples, func(i, j int) bool {
+				return labels(f.samples[i].labels) < labels(f.samples[j].labels)
+			})
+			fs[n] = f
+			n++
+		}
+	}
+	return fs[:n]
+}
+
+// WriteOpenMetrics writes s to w in the OpenMetrics text format.
+func WriteOpenMetrics(w io.Writer, s bind.Statistics, opts ...Option) error {
+	return writeOpenMetrics(w, families(s, newOptions(opts)))
+}
+
+// WriteOpenMetricsWithFreshness writes s to w in the OpenMetrics text format
+// like WriteOpenMetrics, followed by the gauges
+// bind_group_fetched_timestamp_seconds and
+// bind_group_served_timestamp_seconds of the freshness fr of the groups, see
+// bind.FreshnessClient.
+func WriteOpenMetricsWithFreshness(w io.Writer, s bind.Statistics, fr map[bind.StatisticGroup]bind.Freshness, opts ...Option) error {
+	return writeOpenMetrics(w, append(families(s, newOptions(opts)), freshnessFamilies(fr)...))
+}
+
+// Schema returns bind.Schema with the metric families written by
+// WriteOpenMetrics and WriteOpenMetricsWithFreshness and exposed by a
+// Collector. The names are those of the default namespace "bind", which a
+// Collector replaces by CollectorOpts.Namespace.
+func Schema() bind.SchemaDoc {
+	doc := bind.Schema()
+	add := func(name string, typ metricType, help string, g bind.StatisticGroup, labels ...string) {
+		doc.Metrics = append(doc.Metrics, bind.MetricSchema{Name: name, Type: string(typ), Help: help, Group: g, Labels: labels})
+	}
+	fr := map[bind.StatisticGroup]bind.Freshness{"": {FetchedAt: time.Unix(1, 0), ServedAt: time.Unix(1, 0)}}
+	for _, f := range append(families(complete, newOptions(nil)), freshnessFamilies(fr)...) {
+		var labels []string
+		for _, l := range f.samples[0].labels {
+			labels = append(labels, l[0])
+		}
+		add(f.name, f.typ, f.help, f.group, labels...)
+	}
+	add("bind_up", gauge, upHelp, "")
+	add("bind_scrape_duration_seconds", gauge, scrapeDurationHelp, "")
+	add("bind_scrape_errors", counter, scrapeErrorsHelp, "", "group", "class")
+	add("bind_collector_zones_skipped", counter, zonesSkippedHelp, bind.ViewStats)
+	add("bind_resolver_query_duration_seconds", histogram, queryDurationHelp, bind.ViewStats, "view")
+	return doc
+}
+
+// Help texts of the freshness families.
+const (
+	fetchedHelp = "Time of the last successful fetch of the statistic group since unix epoch in seconds."
+	servedHelp  = "Time the statistics of the group have last been served since unix epoch in seconds."
+)
+
+// freshnessFamilies returns the families of the fetch and serve times of the
+// groups of fr, sorted by group. Groups never fetched or served are omitted.
+func freshnessFamilies(fr map[bind.StatisticGroup]bind.Freshness) []family {
+	groups := make([]string, 0, len(fr))
+	for g := range fr {
+		groups = append(groups, string(g))
+	}
+	sort.Strings(groups)
+	fetched := family{name: "bind_group_fetched_timestamp_seconds", typ: gauge, help: fetchedHelp}
+	served := family{name: "bind_group_served_timestamp_seconds", typ: gauge, help: servedHelp}
+	for _, g := range groups {
+		f := fr[bind.StatisticGroup(g)]
+		l := [][2]string{{"group", g}}
+		if !f.FetchedAt.IsZero() {
+			fetched.samples = append(fetched.samples, sample{labels: l, value: timestamp(f.FetchedAt)})
+		}
+		if !f.ServedAt.IsZero() {
+			served.samples = append(served.samples, sample{labels: l, value: timestamp(f.ServedAt)})
+		}
+	}
+	var fs []family
+	for _, f := range []family{fetched, served} {
+		if len(f.samples) > 0 {
+			fs = append(fs, f)
+		}
+	}
+	return fs
+}
+
+// timestamp returns t in seconds since the unix epoch.
+func timestamp(t time.Time) float64 {
+	return float64(t.UnixNano()) / 1e9
+}
+
+func writeOpenMetrics(w io.Writer, fs []family) error {
+	var b strings.Builder
+	for _, f := range fs {
+		fmt.Fprintf(&b, "# TYPE %s %s\n# HELP %s %s\n", f.name, f.typ, f.name, f.help)
+		name := f.name
+		if f.typ == counter {
+			name += "_total"
+		}
+		for _, s := range f.samples {
+			fmt.Fprintf(&b, "%s%s %s\n", name, labels(s.labels), strconv.FormatFloat(s.value, 'g', -1, 64))
+		}
+	}
+	b.WriteString("# EOF\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteTable writes s to w as a table with one line per value, intended for
+// humans.
+func WriteTable(w io.Writer, s bind.Statistics, opts ...Option) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "METRIC\tLABELS\tVALUE")
+	for _, f := range families(s, newOptions(opts)) {
+		for _, s := range f.samples {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", f.name, strings.Trim(labels(s.labels), "{}"), strconv.FormatFloat(s.value, 'f', -1, 64))
+		}
+	}
+	return tw.Flush()
+}
+
+// WriteRateTable writes the per second rates of the counters of the Delta of s
+// to w as a table, omitting counters which did not change. Nothing but the
+// header is written if s has no Delta.
+func WriteRateTable(w io.Writer, s bind.Sample, opts ...Option) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "METRIC\tLABELS\tRATE")
+	if s.Delta != nil {
+		for _, f := range families(*s.Delta, newOptions(opts)) {
+			if f.typ != counter {
+				continue
+			}
+			for _, smp := range f.samples {
+				if smp.value == 0 {
+					continue
+				}
+				rate := s.Rate(uint64(smp.value))
+				fmt.Fprintf(tw, "%s\t%s\t%s/s\n", f.name, strings.Trim(labels(smp.labels), "{}"), strconv.FormatFloat(rate, 'f', 3, 64))
+			}
+		}
+	}
+	return tw.Flush()
+}
+
+func labels(ls [][2]string) string {
+	if len(ls) == 0 {
+		return ""
+	}
+	s := make([]string, len(ls))
+	for i, l := range ls {
+		s[i] = l[0] + `="` + escaper.Replace(l[1]) + `"`
+	}
+	return "{" + strings.Join(s, ",") + "}"
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
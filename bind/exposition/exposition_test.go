@@ -0,0 +1,305 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exposition
+
+import (
+	"bytes"
+	"context"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/bindtest"
+	"github.com/prometheus-community/bind_exporter/bind/json"
+	"github.com/prometheus-community/bind_exporter/bind/xml"
+	"github.com/prometheus-community/bind_exporter/fixtures"
+)
+
+func TestWriteOpenMetrics(t *testing.T) {
+	s := bind.Statistics{
+		Server: bind.Server{
+			IncomingQueries: []bind.Counter{{Name: `A"B`, Counter: 3}},
+		},
+	}
+	var b bytes.Buffer
+	if err := WriteOpenMetrics(&b, s); err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range []string{
+		"# TYPE bind_incoming_queries counter\n",
+		`bind_incoming_queries_total{type="A\"B"} 3` + "\n",
+	} {
+		if !strings.Contains(b.String(), w) {
+			t.Errorf("want output to contain %q, got:\n%s", w, b.String())
+		}
+	}
+	if !strings.HasSuffix(b.String(), "# EOF\n") {
+		t.Errorf("want output to end with EOF, got:\n%s", b.String())
+	}
+}
+
+func TestWriteOpenMetricsResolverGauges(t *testing.T) {
+	s := bind.Statistics{Views: []bind.View{{
+		Name:           "_default",
+		ResolverStats:  []bind.Counter{{Name: "Queryv4", Counter: 5}},
+		ResolverGauges: []bind.Gauge{{Name: "NumFetch", Gauge: 1873}},
+	}}}
+	var b bytes.Buffer
+	if err := WriteOpenMetrics(&b, s); err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range []string{
+		"# TYPE bind_resolver counter\n",
+		`bind_resolver_total{view="_default",name="Queryv4"} 5` + "\n",
+		"# TYPE bind_resolver_current gauge\n",
+		`bind_resolver_current{view="_default",name="NumFetch"} 1873` + "\n",
+	} {
+		if !strings.Contains(b.String(), w) {
+			t.Errorf("want output to contain %q, got:\n%s", w, b.String())
+		}
+	}
+}
+
+func TestWriteRateTable(t *testing.T) {
+	delta := bind.Statistics{
+		Server: bind.Server{
+			IncomingQueries: []bind.Counter{{Name: "A", Counter: 20}, {Name: "MX", Counter: 0}},
+		},
+	}
+	var b bytes.Buffer
+	if err := WriteRateTable(&b, bind.Sample{Delta: &delta, Interval: 10 * time.Second}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "2.000/s") {
+		t.Errorf("want rate of 2/s, got:\n%s", b.String())
+	}
+	if strings.Contains(b.String(), "MX") {
+		t.Errorf("want unchanged counters to be omitted, got:\n%s", b.String())
+	}
+}
+
+func TestWriteOpenMetricsUnknownCounters(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, ok := map[string]string{
+			xml.ServerPath:  "xml/server-future.xml",
+			xml.ZonesPath:   "xml/zones-future.xml",
+			json.ServerPath: "json/server-future.json",
+			json.ZonesPath:  "json/zones-future.json",
+		}[r.URL.Path]
+		b, err := fs.ReadFile(fixtures.FS, f)
+		if !ok || err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(b)
+	}))
+	defer ts.Close()
+
+	for name, c := range map[string]bind.Client{
+		"xml":  xml.NewClient(ts.URL, nil),
+		"json": json.NewClient(ts.URL, nil),
+	} {
+		s, err := c.Stats(context.Background(), bind.ServerStats, bind.ViewStats)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		var b bytes.Buffer
+		if err := WriteOpenMetrics(&b, s); err != nil {
+			t.Fatal(err)
+		}
+		for _, w := range []string{
+			"# TYPE bind_unknown_server counter\n",
+			`bind_unknown_server_total{section="quic",name="QUICConnFail"} 1` + "\n",
+			`bind_unknown_server_total{section="quic",name="QUICConnIn"} 12` + "\n",
+			`bind_unknown_view_total{view="_default",section="resquic",name="QUICQueryv4"} 5` + "\n",
+			`bind_unknown_zone_total{view="_default",zone_name="example.com",section="quic",name="QUICQryIn"} 3` + "\n",
+			`bind_incoming_queries_total{type="A"} 812` + "\n",
+		} {
+			if !strings.Contains(b.String(), w) {
+				t.Errorf("%s: want output to contain %q, got:\n%s", name, w, b.String())
+			}
+		}
+	}
+}
+
+func TestWriteOpenMetricsWarnings(t *testing.T) {
+	s := bind.Statistics{
+		Warnings: []bind.Warning{
+			{Code: bind.WarnBlankCounter},
+			{Code: bind.WarnZoneStatisticsDisabled},
+			{Code: bind.WarnBlankCounter},
+		},
+		OmittedWarnings: 4,
+	}
+	var b bytes.Buffer
+	if err := WriteOpenMetrics(&b, s); err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range []string{
+		"# TYPE bind_warnings gauge\n",
+		`bind_warnings{code="blank-counter"} 2` + "\n",
+		`bind_warnings{code="zone-statistics-disabled"} 1` + "\n",
+		"bind_warnings_omitted 4\n",
+	} {
+		if !strings.Contains(b.String(), w) {
+			t.Errorf("want output to contain %q, got:\n%s", w, b.String())
+		}
+	}
+}
+
+func TestWriteOpenMetricsDefaultViewAlias(t *testing.T) {
+	// stats returns the statistics of the XML server and zones fixtures.
+	stats := func(server, zones string) bind.Statistics {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			f, ok := map[string]string{xml.ServerPath: server, xml.ZonesPath: zones}[r.URL.Path]
+			b, err := fs.ReadFile(fixtures.FS, f)
+			if !ok || err != nil {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write(b)
+		}))
+		defer ts.Close()
+		s, err := xml.NewClient(ts.URL, nil).Stats(context.Background(), bind.ServerStats, bind.ViewStats)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	single := stats("xml/server.xml", "xml/zones-full.xml")
+	multi := stats("xml/server-views.xml", "xml/zones-resolver.xml")
+
+	for _, tc := range []struct {
+		name    string
+		s       bind.Statistics
+		opts    []Option
+		present []string
+		absent  []string
+	}{
+		{name: "single view", s: single, present: []string{`view="_default"`, `view="_bind"`}},
+		{name: "single view aliased", s: single, opts: []Option{WithDefaultViewAlias("")}, present: []string{`view=""`, `view="_bind"`}, absent: []string{`view="_default"`}},
+		{name: "multiple views", s: multi, present: []string{`view="internal"`, `view="_default"`}},
+		{name: "multiple views aliased", s: multi, opts: []Option{WithDefaultViewAlias("default")}, present: []string{`view="internal"`, `view="default"`}, absent: []string{`view="_default"`}},
+	} {
+		var b bytes.Buffer
+		if err := WriteOpenMetrics(&b, tc.s, tc.opts...); err != nil {
+			t.Fatal(err)
+		}
+		for _, w := range tc.present {
+			if !strings.Contains(b.String(), w) {
+				t.Errorf("%s: want output to contain %s, got:\n%s", tc.name, w, b.String())
+			}
+		}
+		for _, w := range tc.absent {
+			if strings.Contains(b.String(), w) {
+				t.Errorf("%s: want output without %s, got:\n%s", tc.name, w, b.String())
+			}
+		}
+	}
+	if _, ok := single.View(bind.DefaultView); !ok {
+		t.Error("want statistics unchanged")
+	}
+}
+
+// checkDeclared reports an error unless the family name of type typ with the
+// given labels, in any order, is declared by the schema.
+func checkDeclared(t *testing.T, declared map[string]bind.MetricSchema, name, typ string, labels []string) {
+	t.Helper()
+	m, ok := declared[name]
+	if !ok {
+		t.Errorf("family %s not declared by the schema", name)
+		return
+	}
+	if m.Type != typ {
+		t.Errorf("family %s: want type %s, got %s", name, m.Type, typ)
+	}
+	want := append([]string(nil), m.Labels...)
+	sort.Strings(want)
+	sort.Strings(labels)
+	if !reflect.DeepEqual(want, labels) {
+		t.Errorf("family %s: want labels %v, got %v", name, m.Labels, labels)
+	}
+}
+
+// declaredMetrics returns the metric families of Schema by name.
+func declaredMetrics(t *testing.T) map[string]bind.MetricSchema {
+	doc := Schema()
+	labels := map[string]bool{}
+	for _, l := range doc.Labels {
+		labels[l.Name] = true
+	}
+	declared := map[string]bind.MetricSchema{}
+	for _, m := range doc.Metrics {
+		if _, ok := declared[m.Name]; ok {
+			t.Errorf("family %s declared twice", m.Name)
+		}
+		for _, l := range m.Labels {
+			if !labels[l] {
+				t.Errorf("family %s: label %s not declared by the schema", m.Name, l)
+			}
+		}
+		declared[m.Name] = m
+	}
+	return declared
+}
+
+func TestSchemaOpenMetrics(t *testing.T) {
+	declared := declaredMetrics(t)
+	ts := bindtest.NewServer()
+	defer ts.Close()
+	fr := map[bind.StatisticGroup]bind.Freshness{bind.ServerStats: {FetchedAt: time.Unix(1, 0), ServedAt: time.Unix(2, 0)}}
+	for name, c := range map[string]bind.Client{
+		"xml":  xml.NewClient(ts.URL, nil),
+		"json": json.NewClient(ts.URL, nil),
+	} {
+		s, err := c.Stats(context.Background(), bind.ServerStats, bind.ViewStats, bind.TaskStats)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		var b bytes.Buffer
+		if err := WriteOpenMetricsWithFreshness(&b, s, fr); err != nil {
+			t.Fatal(err)
+		}
+		types := map[string]string{}
+		for _, line := range strings.Split(strings.TrimSpace(b.String()), "\n") {
+			if f := strings.Fields(line); len(f) == 4 && f[1] == "TYPE" {
+				types[f[2]] = f[3]
+				continue
+			}
+			if strings.HasPrefix(line, "#") {
+				continue
+			}
+			// The label values of the fixtures contain neither spaces
+			// nor quoted commas.
+			series := line[:strings.LastIndexByte(line, ' ')]
+			family, ls, _ := strings.Cut(strings.TrimSuffix(series, "}"), "{")
+			var labels []string
+			for _, l := range strings.Split(ls, ",") {
+				if n, _, ok := strings.Cut(l, "="); ok {
+					labels = append(labels, n)
+				}
+			}
+			if types[family] == "" {
+				family = strings.TrimSuffix(family, "_total")
+			}
+			checkDeclared(t, declared, family, types[family], labels)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exposition
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+)
+
+// lineTags are the labels of bind.Flatten which identify the line of a
+// metric in the line protocol. The other label of a metric names its field.
+var lineTags = map[string]bool{"view": true, "zone": true}
+
+// WriteLineProtocol writes s to w in the InfluxDB line protocol. The metrics
+// are those of bind.Flatten, grouped into a line per part of the statistics
+// and view or zone: the measurement is measurementPrefix followed by the
+// part, e.g. "server", "view" or "zone", the view and zone are tags along
+// with tags, and every metric is a field named after its section and counter,
+// e.g. "nsstats_QryDropped" or "serial". Counters are written as integers
+// with their exact value, saturating at the largest integer of the protocol,
+// and gauges as floats, so that the type of a field never changes. Gauges
+// without a finite value are omitted, and so are tags with an empty value.
+// The lines have the timestamp ts in nanoseconds, or none if ts is zero, and
+// are sorted by measurement and tags, with sorted tags and fields.
+//
+// Names and values are escaped as required by the protocol. Those which
+// cannot be represented, i.e. with control characters, invalid UTF-8 or a
+// trailing backslash, are reported as errors before anything is written.
+func WriteLineProtocol(w io.Writer, s bind.Statistics, measurementPrefix string, tags map[string]string, ts time.Time) error {
+	lines := map[string]*line{}
+	for _, m := range bind.Flatten(s) {
+		segments := strings.SplitN(m.Name, ".", 3)
+		l := &line{measurement: measurementPrefix + segments[1], tags: map[string]string{}}
+		for k, v := range tags {
+			if v != "" {
+				l.tags[k] = v
+			}
+		}
+		field := ""
+		if len(segments) == 3 {
+			field = segments[2]
+		}
+		for k, v := range m.Labels {
+			if lineTags[k] {
+				l.tags[k] = v
+			} else if field == "" {
+				field = v
+			} else {
+				field += "_" + v
+			}
+		}
+		if err := l.validate(); err != nil {
+			return err
+		}
+		if err := validLineName("field key", field); err != nil {
+			return err
+		}
+		value, ok := fieldValue(m)
+		if !ok {
+			continue
+		}
+		key := l.key()
+		if existing, ok := lines[key]; ok {
+			l = existing
+		} else {
+			lines[key] = l
+		}
+		l.fields = append(l.fields, [2]string{field, value})
+	}
+
+	keys := make([]string, 0, len(lines))
+	for k := range lines {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	bw := bufio.NewWriter(w)
+	for _, k := range keys {
+		lines[k].write(bw, ts)
+	}
+	return bw.Flush()
+}
+
+// line is a line of the line protocol.
+type line struct {
+	measurement string
+	tags        map[string]string
+	fields      [][2]string
+}
+
+// sortedTags returns the keys of the tags of l in ascending order, as
+// recommended by the protocol.
+func (l *line) sortedTags() []string {
+	keys := make([]string, 0, len(l.tags))
+	for k := range l.tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// key returns the measurement and tags of l, which identify the line and
+// determine the order of lines.
+func (l *line) key() string {
+	var b strings.Builder
+	b.WriteString(l.measurement)
+	for _, k := range l.sortedTags() {
+		b.WriteString("\x00" + k + "\x00" + l.tags[k])
+	}
+	return b.String()
+}
+
+func (l *line) validate() error {
+	if err := validLineName("measurement", l.measurement); err != nil {
+		return err
+	}
+	for k, v := range l.tags {
+		if err := validLineName("tag key", k); err != nil {
+			return err
+		}
+		if err := validLineName("value of tag "+k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *line) write(w *bufio.Writer, ts time.Time) {
+	w.WriteString(measurementEscaper.Replace(l.measurement))
+	for _, k := range l.sortedTags() {
+		w.WriteString("," + keyEscaper.Replace(k) + "=" + keyEscaper.Replace(l.tags[k]))
+	}
+	sort.Slice(l.fields, func(i, j int) bool { return l.fields[i][0] < l.fields[j][0] })
+	for i, f := range l.fields {
+		sep := ","
+		if i == 0 {
+			sep = " "
+		}
+		w.WriteString(sep + keyEscaper.Replace(f[0]) + "=" + f[1])
+	}
+	if !ts.IsZero() {
+		w.WriteString(" " + strconv.FormatInt(ts.UnixNano(), 10))
+	}
+	w.WriteByte('\n')
+}
+
+// Escapers of the line protocol. Measurements escape commas and spaces, tag
+// keys, tag values and field keys also equals signs.
+var (
+	measurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	keyEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+)
+
+// validLineName returns an error if the name or tag value s of what cannot be
+// written in the line protocol.
+func validLineName(what, s string) error {
+	switch {
+	case s == "":
+		return fmt.Errorf("empty %s in line protocol", what)
+	case !utf8.ValidString(s):
+		return fmt.Errorf("invalid UTF-8 in %s %q", what, s)
+	case strings.HasSuffix(s, `\`):
+		return fmt.Errorf("trailing backslash in %s %q", what, s)
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] == 0x7f {
+			return fmt.Errorf("control character in %s %q", what, s)
+		}
+	}
+	return nil
+}
+
+// fieldValue returns the value of m in the syntax of the line protocol, or
+// false if it cannot be written.
+func fieldValue(m bind.FlatMetric) (string, bool) {
+	if m.Kind == bind.KindCounter {
+		if m.Counter > math.MaxInt64 {
+			return strconv.FormatInt(math.MaxInt64, 10) + "i", true
+		}
+		return strconv.FormatInt(int64(m.Counter), 10) + "i", true
+	}
+	if math.IsNaN(m.Value) || math.IsInf(m.Value, 0) {
+		return "", false
+	}
+	return strconv.FormatFloat(m.Value, 'f', -1, 64), true
+}
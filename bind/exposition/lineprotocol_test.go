@@ -0,0 +1,181 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exposition
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/line-protocol/v2/lineprotocol"
+	"github.com/prometheus-community/bind_exporter/bind"
+)
+
+// parsedLine is a line decoded by the official parser of the line protocol.
+type parsedLine struct {
+	measurement string
+	tags        map[string]string
+	fields      map[string]lineprotocol.Value
+	time        time.Time
+}
+
+func parseLineProtocol(t *testing.T, b []byte) []parsedLine {
+	t.Helper()
+	var lines []parsedLine
+	dec := lineprotocol.NewDecoderWithBytes(b)
+	for dec.Next() {
+		m, err := dec.Measurement()
+		if err != nil {
+			t.Fatalf("invalid measurement: %s\n%s", err, b)
+		}
+		l := parsedLine{measurement: string(m), tags: map[string]string{}, fields: map[string]lineprotocol.Value{}}
+		for {
+			k, v, err := dec.NextTag()
+			if err != nil {
+				t.Fatalf("invalid tag: %s\n%s", err, b)
+			}
+			if k == nil {
+				break
+			}
+			l.tags[string(k)] = string(v)
+		}
+		for {
+			k, v, err := dec.NextField()
+			if err != nil {
+				t.Fatalf("invalid field: %s\n%s", err, b)
+			}
+			if k == nil {
+				break
+			}
+			l.fields[string(k)] = v
+		}
+		if l.time, err = dec.Time(lineprotocol.Nanosecond, time.Time{}); err != nil {
+			t.Fatalf("invalid time: %s\n%s", err, b)
+		}
+		lines = append(lines, l)
+	}
+	return lines
+}
+
+func TestWriteLineProtocol(t *testing.T) {
+	s := bind.Statistics{
+		Server: bind.Server{
+			BootTime:        time.Unix(1700000000, 0),
+			NameServerStats: []bind.Counter{{Name: "QryDropped", Counter: 237}, {Name: "Requestv4", Counter: 9001}},
+		},
+		Views: []bind.View{{
+			Name:           "my view, internal=1",
+			ResolverStats:  []bind.Counter{{Name: "Queryv4", Counter: 5}},
+			ResolverGauges: []bind.Gauge{{Name: "NumFetch", Gauge: 12}},
+		}},
+		ZoneViews: []bind.ZoneView{{Name: "_default", ZoneData: []bind.ZoneCounter{
+			{Name: "a zone,with=specials.", Serial: "7", QueryResults: []bind.Counter{{Name: "QrySuccess", Counter: 3}}},
+			{Name: "example.com", Serial: "2024010101"},
+		}}},
+		TaskManager: bind.TaskManager{ThreadModel: bind.ThreadModel{WorkerThreads: 4, TasksRunning: 2}},
+	}
+	ts := time.Unix(1700000100, 5)
+	var b bytes.Buffer
+	if err := WriteLineProtocol(&b, s, "bind_", map[string]string{"host": "ns 1,dc=eu", "empty": ""}, ts); err != nil {
+		t.Fatal(err)
+	}
+	lines := parseLineProtocol(t, b.Bytes())
+
+	byKey := map[string]parsedLine{}
+	for _, l := range lines {
+		if l.tags["host"] != "ns 1,dc=eu" {
+			t.Errorf("want host tag on every line, got %v", l.tags)
+		}
+		if _, ok := l.tags["empty"]; ok {
+			t.Errorf("want empty tags omitted, got %v", l.tags)
+		}
+		if !l.time.Equal(ts) {
+			t.Errorf("want time %v, got %v", ts, l.time)
+		}
+		byKey[l.measurement+"/"+l.tags["view"]+"/"+l.tags["zone"]] = l
+	}
+	zone := bind.SanitizeZoneLabel("a zone,with=specials.")
+	for key, want := range map[string]map[string]any{
+		"bind_server//":                       {"boot_time_seconds": 1700000000.0, "nsstats_QryDropped": int64(237), "nsstats_Requestv4": int64(9001)},
+		"bind_view/my view, internal=1/":      {"resstats_Queryv4": int64(5), "resolver_gauges_NumFetch": 12.0},
+		"bind_zone/_default/" + zone:          {"serial": 7.0, "query_results_QrySuccess": int64(3)},
+		"bind_zone/_default/" + "example.com": {"serial": 2024010101.0},
+		"bind_tasks//":                        {"running": 2.0, "worker_threads": 4.0, "utilization": 0.5, "saturated": 0.0},
+	} {
+		l, ok := byKey[key]
+		if !ok {
+			t.Errorf("want line %s, got:\n%s", key, b.String())
+			continue
+		}
+		if len(l.fields) != len(want) {
+			t.Errorf("%s: want %d fields, got %v", key, len(want), l.fields)
+		}
+		for f, v := range want {
+			if got, ok := l.fields[f]; !ok || got.Interface() != v {
+				t.Errorf("%s: want field %s=%#v, got %#v", key, f, v, got.Interface())
+			}
+		}
+	}
+	if len(lines) != 5 {
+		t.Errorf("want a line per part, view and zone, got %d:\n%s", len(lines), b.String())
+	}
+}
+
+func TestWriteLineProtocolWithoutTime(t *testing.T) {
+	s := bind.Statistics{Server: bind.Server{NameServerStats: []bind.Counter{{Name: "Requestv4", Counter: 1}}}}
+	var b bytes.Buffer
+	if err := WriteLineProtocol(&b, s, "", nil, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := b.String(), "server nsstats_Requestv4=1i\n"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestWriteLineProtocolLargeCounters(t *testing.T) {
+	s := bind.Statistics{Server: bind.Server{NameServerStats: []bind.Counter{
+		{Name: "Requestv4", Counter: 1<<53 + 1},
+		{Name: "Requestv6", Counter: math.MaxUint64},
+	}}}
+	var b bytes.Buffer
+	if err := WriteLineProtocol(&b, s, "", nil, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	fields := parseLineProtocol(t, b.Bytes())[0].fields
+	for f, want := range map[string]int64{"nsstats_Requestv4": 1<<53 + 1, "nsstats_Requestv6": math.MaxInt64} {
+		if got := fields[f]; got.Kind() != lineprotocol.Int || got.IntV() != want {
+			t.Errorf("want field %s=%d, got %#v", f, want, got.Interface())
+		}
+	}
+}
+
+func TestWriteLineProtocolInvalid(t *testing.T) {
+	s := bind.Statistics{Server: bind.Server{NameServerStats: []bind.Counter{{Name: "Requestv4", Counter: 1}}}}
+	for name, tags := range map[string]map[string]string{
+		"trailing backslash": {"host": `ns1\`},
+		"newline":            {"host": "ns1\nns2"},
+		"invalid UTF-8":      {"host": "\xff"},
+	} {
+		var b bytes.Buffer
+		err := WriteLineProtocol(&b, s, "bind_", tags, time.Time{})
+		if err == nil || !strings.Contains(err.Error(), "tag host") {
+			t.Errorf("%s: want error about the tag, got %v", name, err)
+		}
+		if b.Len() > 0 {
+			t.Errorf("%s: want nothing written, got %q", name, b.String())
+		}
+	}
+}
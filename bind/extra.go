@@ -0,0 +1,65 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import "sort"
+
+// Sections returns the types of the sections of e in ascending order.
+func (e Extra) Sections() []string {
+	types := make([]string, 0, len(e))
+	for t := range e {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Add appends the counters cs to section t, creating e if it is nil. Empty
+// sections are not added.
+func (e *Extra) Add(t string, cs []Counter) {
+	if len(cs) == 0 {
+		return
+	}
+	if *e == nil {
+		*e = Extra{}
+	}
+	(*e)[t] = append((*e)[t], cs...)
+}
+
+func (e Extra) clone() Extra {
+	if e == nil {
+		return nil
+	}
+	c := make(Extra, len(e))
+	for t, cs := range e {
+		c[t] = cloneSlice(cs)
+	}
+	return c
+}
+
+// trimZero removes the zero counters of e in place, and the sections left
+// empty. It returns nil if no section is left.
+func (e Extra) trimZero() Extra {
+	for t, cs := range e {
+		if cs = trimZero(cs); cs == nil {
+			delete(e, t)
+		} else {
+			e[t] = cs
+		}
+	}
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,296 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FlatMetric is a single value of Statistics, for consumers which are not
+// interested in the structure of the statistics, e.g. to feed them into
+// InfluxDB or Graphite.
+type FlatMetric struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+	Kind   CounterKind
+	// Counter is the exact value of a counter, which Value approximates
+	// above 2^53. It is zero for gauges.
+	Counter uint64
+}
+
+// NameScheme determines how Flatten joins the segments of metric names.
+type NameScheme int
+
+const (
+	// NameDotted joins the segments with dots, e.g. "bind.server.rcodes".
+	NameDotted NameScheme = iota
+	// NameUnderscored joins the segments with underscores, e.g.
+	// "bind_server_rcodes".
+	NameUnderscored
+)
+
+// FlattenOption configures Flatten.
+type FlattenOption func(*flattenOptions)
+
+type flattenOptions struct {
+	scheme NameScheme
+	zones  bool
+	labels map[string]bool
+	// viewAlias replaces DefaultView if aliased is set.
+	viewAlias string
+	aliased   bool
+	// memoryContexts is the number of memory contexts reported, see
+	// TopMemoryContexts.
+	memoryContexts int
+}
+
+// view returns the label pair of the view name, which is empty if the view is
+// aliased to the empty name.
+func (o flattenOptions) view(name string) []string {
+	if o.aliased {
+		name = ViewAlias(name, o.viewAlias)
+	}
+	if name == "" {
+		return nil
+	}
+	return []string{"view", name}
+}
+
+// WithNameScheme sets the scheme of the metric names, NameDotted by default.
+func WithNameScheme(s NameScheme) FlattenOption {
+	return func(o *flattenOptions) {
+		o.scheme = s
+	}
+}
+
+// WithoutZoneMetrics omits the metrics of individual zones, whose number
+// grows with the number of zones served.
+func WithoutZoneMetrics() FlattenOption {
+	return func(o *flattenOptions) {
+		o.zones = false
+	}
+}
+
+// WithLabels restricts the labels of the metrics to the given names. Metrics
+// which no longer differ in their labels are summed, e.g. allowing only
+// "view" sums the zone metrics of every view.
+func WithLabels(names ...string) FlattenOption {
+	return func(o *flattenOptions) {
+		o.labels = map[string]bool{}
+		for _, n := range names {
+			o.labels[n] = true
+		}
+	}
+}
+
+// WithMemoryContexts reports the n memory contexts using most memory, see
+// TopMemoryContexts, instead of DefaultMemoryContexts. A non-positive n
+// omits the memory contexts.
+func WithMemoryContexts(n int) FlattenOption {
+	return func(o *flattenOptions) {
+		o.memoryContexts = n
+	}
+}
+
+// WithDefaultViewAlias reports the view DefaultView under the name alias,
+// e.g. to label the metrics of servers without explicit views like those of
+// unnamed views. An empty alias omits the view label of the metrics of
+// DefaultView. The statistics are not modified.
+func WithDefaultViewAlias(alias string) FlattenOption {
+	return func(o *flattenOptions) {
+		o.viewAlias = alias
+		o.aliased = true
+	}
+}
+
+// Flatten returns the values of s as a list of metrics sorted by name and
+// labels. Every metric name starts with "bind" followed by the part of the
+// statistics it belongs to, e.g. "server", "view" or "zone", so that names
+// are unique across groups, and the names of the counters are labels. Zone
+// names are sanitized by SanitizeZoneLabels. Times are reported as gauges in
+// seconds since the unix epoch. The QueryRTT histograms are omitted, they are
+// derived from the resolver counters, and so are the serials of zones which
+// have not been loaded. The task manager gauges, including the Utilization
+// and Saturated values of the thread model, are omitted for servers without
+// worker threads, e.g. BIND versions without task manager. The counters of
+// Extra sections are reported under an "unknown_" prefix. The memory summary
+// is reported as gauges named after the statistics of MemoryCounters, see
+// CounterInfo.MetricName, e.g. "bind.memory.in_use_bytes", and the memory
+// contexts by TopMemoryContexts.
+func Flatten(s Statistics, opts ...FlattenOption) []FlatMetric {
+	o := flattenOptions{zones: true, memoryContexts: DefaultMemoryContexts}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	f := flattener{opts: o, index: map[string]int{}}
+
+	for _, t := range []struct {
+		name string
+		v    int64
+		ok   bool
+	}{
+		{name: "boot_time_seconds", v: s.Server.BootTime.Unix(), ok: !s.Server.BootTime.IsZero()},
+		{name: "config_time_seconds", v: s.Server.ConfigTime.Unix(), ok: !s.Server.ConfigTime.IsZero()},
+		{name: "current_time_seconds", v: s.Server.CurrentTime.Unix(), ok: !s.Server.CurrentTime.IsZero()},
+	} {
+		if t.ok {
+			f.add([]string{"server", t.name}, nil, float64(t.v), KindGauge)
+		}
+	}
+	f.counters([]string{"server", "qtypes"}, "type", s.Server.IncomingQueries)
+	f.counters([]string{"server", "opcodes"}, "opcode", s.Server.IncomingRequests)
+	f.counters([]string{"server", "nsstats"}, "name", s.Server.NameServerStats)
+	f.counters([]string{"server", "zonestats"}, "name", s.Server.ZoneMaintenance)
+	f.counters([]string{"server", "rcodes"}, "rcode", s.Server.ServerRcodes)
+	f.extra("server", s.Server.Extra)
+	if s.Memory.Summary.Reported() {
+		for _, g := range s.Memory.Summary.Gauges() {
+			info, _ := DescribeIn(MemoryCounters, g.Name)
+			f.add([]string{"memory", info.MetricName()}, nil, float64(g.Gauge), KindGauge)
+		}
+	}
+	f.gauges([]string{"memory", "context_in_use_bytes"}, "context", TopMemoryContexts(s.Memory.Contexts, o.memoryContexts))
+
+	for _, v := range s.Views {
+		view := o.view(v.Name)
+		f.gauges([]string{"view", "cache_rrsets"}, "type", v.Cache, view...)
+		f.gauges([]string{"view", "cache_memory_bytes"}, "name", v.CacheMemory, view...)
+		f.counters([]string{"view", "cachestats"}, "name", v.CacheStats, view...)
+		f.counters([]string{"view", "resstats"}, "name", v.ResolverStats, view...)
+		f.gauges([]string{"view", "resolver_gauges"}, "name", v.ResolverGauges, view...)
+		f.counters([]string{"view", "resqtypes"}, "type", v.ResolverQueries, view...)
+		f.extra("view", v.Extra, view...)
+	}
+
+	if o.zones {
+		for _, v := range s.ZoneViews {
+			names := make([]string, len(v.ZoneData))
+			for i, z := range v.ZoneData {
+				names[i] = z.Name
+			}
+			labels := SanitizeZoneLabels(names)
+			for _, z := range v.ZoneData {
+				zone := append(o.view(v.Name), "zone", labels[z.Name])
+				if n, err := strconv.ParseUint(z.Serial, 10, 32); err == nil {
+					f.add([]string{"zone", "serial"}, zone, float64(n), KindGauge)
+				}
+				f.counters([]string{"zone", "zonestats"}, "name", z.ZoneStats, zone...)
+				f.counters([]string{"zone", "dnssec_sign"}, "key", z.DNSSECSignStats, zone...)
+				f.counters([]string{"zone", "dnssec_refresh"}, "key", z.DNSSECRefreshStats, zone...)
+				f.counters([]string{"zone", "query_results"}, "name", z.QueryResults, zone...)
+				f.counters([]string{"zone", "qtypes"}, "type", z.IncomingQueries, zone...)
+				f.counters([]string{"zone", "nsstats"}, "name", z.NameServerStats, zone...)
+				f.extra("zone", z.Extra, zone...)
+			}
+		}
+	}
+
+	if tm := s.TaskManager.ThreadModel; tm.WorkerThreads > 0 {
+		f.add([]string{"tasks", "running"}, nil, float64(tm.TasksRunning), KindGauge)
+		f.add([]string{"tasks", "worker_threads"}, nil, float64(tm.WorkerThreads), KindGauge)
+		f.add([]string{"tasks", "utilization"}, nil, tm.Utilization(), KindGauge)
+		saturated := 0.0
+		if tm.Saturated() {
+			saturated = 1
+		}
+		f.add([]string{"tasks", "saturated"}, nil, saturated, KindGauge)
+	}
+
+	sort.Slice(f.entries, func(i, j int) bool {
+		return f.entries[i].key < f.entries[j].key
+	})
+	ms := make([]FlatMetric, len(f.entries))
+	for i, e := range f.entries {
+		ms[i] = e.metric
+	}
+	return ms
+}
+
+type flattener struct {
+	opts    flattenOptions
+	entries []flatEntry
+	index   map[string]int
+}
+
+// flatEntry is a metric with its key, which is made of the name and labels of
+// the metric. It identifies the metric and determines the order of metrics.
+type flatEntry struct {
+	key    string
+	metric FlatMetric
+}
+
+func (f *flattener) counters(segments []string, label string, cs []Counter, labels ...string) {
+	for _, c := range cs {
+		m := f.add(segments, append(labels[:len(labels):len(labels)], label, c.Name), float64(c.Counter), KindCounter)
+		m.Counter, _ = AddCounters(m.Counter, c.Counter)
+	}
+}
+
+// extra adds the counters of the sections of e as metrics named after the
+// part and the sanitized type of the section, prefixed by "unknown_", e.g.
+// "bind.server.unknown_edns_option".
+func (f *flattener) extra(part string, e Extra, labels ...string) {
+	for t, cs := range e {
+		f.counters([]string{part, "unknown_" + SanitizeMetricName(t)}, "name", cs, labels...)
+	}
+}
+
+func (f *flattener) gauges(segments []string, label string, gs []Gauge, labels ...string) {
+	for _, g := range gs {
+		f.add(segments, append(labels[:len(labels):len(labels)], label, g.Name), float64(g.Gauge), KindGauge)
+	}
+}
+
+// add adds a metric with the given name segments and label pairs, or adds
+// value to an existing metric with the same name and labels, and returns the
+// metric, which is valid until the next call.
+func (f *flattener) add(segments, labels []string, value float64, kind CounterKind) *FlatMetric {
+	sep := "."
+	if f.opts.scheme == NameUnderscored {
+		sep = "_"
+	}
+	name := "bind" + sep + strings.Join(segments, sep)
+
+	ls := map[string]string{}
+	for i := 0; i+1 < len(labels); i += 2 {
+		if f.opts.labels == nil || f.opts.labels[labels[i]] {
+			ls[labels[i]] = labels[i+1]
+		}
+	}
+	key := flatKey(name, ls)
+	if i, ok := f.index[key]; ok {
+		f.entries[i].metric.Value += value
+		return &f.entries[i].metric
+	}
+	f.index[key] = len(f.entries)
+	f.entries = append(f.entries, flatEntry{key: key, metric: FlatMetric{Name: name, Labels: ls, Value: value, Kind: kind}})
+	return &f.entries[len(f.entries)-1].metric
+}
+
+func flatKey(name string, labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for n := range labels {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	b.WriteString(name)
+	for _, n := range names {
+		b.WriteString("\x00" + n + "\x00" + labels[n])
+	}
+	return b.String()
+}
@@ -0,0 +1,225 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind_test
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/bindtest"
+	"github.com/prometheus-community/bind_exporter/bind/xml"
+	"github.com/prometheus-community/bind_exporter/fixtures"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// fixtureStats returns the statistics of the XML v3 server fixture and the
+// zones fixture with zone-statistics set to full.
+func fixtureStats(t *testing.T) bind.Statistics {
+	ts := bindtest.NewServerWith(map[string]string{xml.ZonesPath: "xml/zones-full.xml"})
+	defer ts.Close()
+
+	s, err := xml.NewClient(ts.URL, nil).Stats(context.Background(), bind.ServerStats, bind.ViewStats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func formatFlat(ms []bind.FlatMetric) string {
+	var b strings.Builder
+	for _, m := range ms {
+		ls := make([]string, 0, len(m.Labels))
+		for k, v := range m.Labels {
+			ls = append(ls, k+"="+strconv.Quote(v))
+		}
+		sort.Strings(ls)
+		fmt.Fprintf(&b, "%s{%s} %s %s\n", m.Name, strings.Join(ls, ","), strconv.FormatFloat(m.Value, 'f', -1, 64), m.Kind)
+	}
+	return b.String()
+}
+
+func TestFlattenGolden(t *testing.T) {
+	s := fixtureStats(t)
+	for _, tc := range []struct {
+		file string
+		opts []bind.FlattenOption
+	}{
+		{file: "testdata/flatten-dotted.golden"},
+		{file: "testdata/flatten-underscored.golden", opts: []bind.FlattenOption{bind.WithNameScheme(bind.NameUnderscored), bind.WithoutZoneMetrics()}},
+	} {
+		got := formatFlat(bind.Flatten(s, tc.opts...))
+		if *update {
+			if err := os.WriteFile(tc.file, []byte(got), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := os.ReadFile(tc.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != string(want) {
+			t.Errorf("%s: output differs from the golden file, run go test -update to update it\n%s", tc.file, got)
+		}
+	}
+}
+
+func TestFlattenUnique(t *testing.T) {
+	s := fixtureStats(t)
+	for _, scheme := range []bind.NameScheme{bind.NameDotted, bind.NameUnderscored} {
+		ms := bind.Flatten(s, bind.WithNameScheme(scheme))
+		seen := map[string]bool{}
+		for _, m := range ms {
+			m.Value = 0
+			key := formatFlat([]bind.FlatMetric{m})
+			if seen[key] {
+				t.Errorf("scheme %d: duplicate metric %s", scheme, key)
+			}
+			seen[key] = true
+		}
+		if !sort.SliceIsSorted(ms, func(i, j int) bool { return ms[i].Name < ms[j].Name }) {
+			t.Errorf("scheme %d: metrics are not sorted by name", scheme)
+		}
+	}
+}
+
+func TestFlattenLabels(t *testing.T) {
+	s := bind.Statistics{ZoneViews: []bind.ZoneView{
+		{Name: "internal", ZoneData: []bind.ZoneCounter{
+			{Name: "a.example", QueryResults: []bind.Counter{{Name: "QrySuccess", Counter: 3}}},
+			{Name: "b.example", QueryResults: []bind.Counter{{Name: "QrySuccess", Counter: 4}}},
+		}},
+		{Name: "external", ZoneData: []bind.ZoneCounter{
+			{Name: "a.example", QueryResults: []bind.Counter{{Name: "QrySuccess", Counter: 5}}},
+		}},
+	}}
+	got := formatFlat(bind.Flatten(s, bind.WithLabels("view")))
+	want := "bind.zone.query_results{view=\"external\"} 5 counter\n" +
+		"bind.zone.query_results{view=\"internal\"} 7 counter\n"
+	if got != want {
+		t.Errorf("want\n%s\ngot\n%s", want, got)
+	}
+	if got := bind.Flatten(s, bind.WithoutZoneMetrics()); len(got) != 0 {
+		t.Errorf("want no metrics without zones, got %v", got)
+	}
+}
+
+func TestFlattenDefaultViewAlias(t *testing.T) {
+	// views returns the view labels of ms, "-" for metrics without.
+	views := func(ms []bind.FlatMetric) map[string]bool {
+		vs := map[string]bool{}
+		for _, m := range ms {
+			if strings.HasPrefix(m.Name, "bind.view.") || strings.HasPrefix(m.Name, "bind.zone.") {
+				if v, ok := m.Labels["view"]; ok {
+					vs[v] = true
+				} else {
+					vs["-"] = true
+				}
+			}
+		}
+		return vs
+	}
+
+	single := viewStats(t, "xml/server.xml", "xml/zones-full.xml")
+	multi := viewStats(t, "xml/server-views.xml", "xml/zones-resolver.xml")
+	for _, tc := range []struct {
+		name string
+		s    bind.Statistics
+		opts []bind.FlattenOption
+		want map[string]bool
+	}{
+		{name: "single view", s: single, want: map[string]bool{"_default": true, "_bind": true}},
+		{name: "single view aliased", s: single, opts: []bind.FlattenOption{bind.WithDefaultViewAlias("default")}, want: map[string]bool{"default": true, "_bind": true}},
+		{name: "single view unlabeled", s: single, opts: []bind.FlattenOption{bind.WithDefaultViewAlias("")}, want: map[string]bool{"-": true, "_bind": true}},
+		{name: "multiple views", s: multi, want: map[string]bool{"_default": true, "external": true, "internal": true}},
+		{name: "multiple views unlabeled", s: multi, opts: []bind.FlattenOption{bind.WithDefaultViewAlias("")}, want: map[string]bool{"-": true, "external": true, "internal": true}},
+	} {
+		if got := views(bind.Flatten(tc.s, tc.opts...)); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: want views %v, got %v", tc.name, tc.want, got)
+		}
+	}
+	if _, ok := single.View(bind.DefaultView); !ok {
+		t.Errorf("want statistics unchanged, got views %v", single.Views)
+	}
+}
+
+func TestFlattenTasks(t *testing.T) {
+	status := `<statistics version="3.14"><server><version>9.18.24</version></server></statistics>`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target, p, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		switch {
+		case target == "new" && p == "xml/v3/status":
+			w.Write([]byte(status))
+			return
+		case target == "new" && p == "xml/v3/tasks":
+			http.NotFound(w, r)
+			return
+		}
+		files := map[string]string{"xml/v3/zones": "xml/zones.xml", "xml/v3/tasks": "xml/" + target + ".xml"}
+		b, err := fs.ReadFile(fixtures.FS, files[p])
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(b)
+	}))
+	defer ts.Close()
+
+	for _, tc := range []struct {
+		target string
+		want   map[string]float64
+	}{
+		{target: "tasks", want: map[string]float64{"running": 8, "worker_threads": 16, "utilization": 0.5, "saturated": 0}},
+		{target: "tasks-busy", want: map[string]float64{"running": 7, "worker_threads": 4, "utilization": 1.75, "saturated": 1}},
+		// BIND 9.18 has no task manager, so the gauges are absent.
+		{target: "new", want: map[string]float64{}},
+	} {
+		s, err := xml.NewClient(ts.URL+"/"+tc.target, nil).Stats(context.Background(), bind.TaskStats)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.target, err)
+		}
+		got := map[string]float64{}
+		for _, m := range bind.Flatten(s) {
+			if name := strings.TrimPrefix(m.Name, "bind.tasks."); name != m.Name {
+				got[name] = m.Value
+			}
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+			t.Errorf("%s: want task gauges %v, got %v", tc.target, tc.want, got)
+		}
+	}
+}
+
+func TestFlattenExactCounters(t *testing.T) {
+	s := bind.Statistics{Server: bind.Server{NameServerStats: []bind.Counter{
+		{Name: bind.CounterRequestv4, Counter: 1<<53 + 1},
+		{Name: bind.CounterRequestv4, Counter: 2},
+	}}}
+	ms := bind.Flatten(s, bind.WithLabels())
+	if len(ms) != 1 || ms[0].Counter != 1<<53+3 {
+		t.Errorf("want exact sum %d of the counters, got %+v", uint64(1<<53+3), ms)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Freshness describes the age of the statistics of a group, see
+// FreshnessClient.
+type Freshness struct {
+	// FetchedAt is the local time the document of the group has last been
+	// fetched and decoded successfully.
+	FetchedAt time.Time
+	// ServedAt is the local time statistics of the group have last been
+	// returned by Stats. It is later than FetchedAt if the wrapped client
+	// served them without fetching, e.g. from a cache.
+	ServedAt time.Time
+	// LastError is the error of the last failed Stats call for the group,
+	// and LastErrorAt its local time. They are kept after later successes.
+	LastError   error
+	LastErrorAt time.Time
+}
+
+// FreshnessClient is a Client recording the freshness of every statistic
+// group fetched by the wrapped client. Its accessors are safe for use
+// concurrently with Stats.
+type FreshnessClient struct {
+	client Client
+	now    func() time.Time
+
+	mu     sync.RWMutex
+	groups map[StatisticGroup]Freshness
+}
+
+// NewFreshnessClient returns a FreshnessClient wrapping c. Of opts, only
+// WithClock applies.
+func NewFreshnessClient(c Client, opts ...ClientOption) *FreshnessClient {
+	return &FreshnessClient{client: c, now: clockOf(opts).Now, groups: map[StatisticGroup]Freshness{}}
+}
+
+// Stats implements Client. A group is fetched once the wrapped client
+// reports the successful request of its document, see ClientTrace, and served
+// if Stats succeeds and the group is not missing on the server. A failed
+// request for a group which has not been fetched otherwise, or the error of
+// Stats, is recorded as the last error of the group.
+func (c *FreshnessClient) Stats(ctx context.Context, groups ...StatisticGroup) (Statistics, error) {
+	var (
+		mu      sync.Mutex
+		fetched = map[StatisticGroup]bool{}
+		failed  = map[StatisticGroup]error{}
+	)
+	ctx = WithClientTrace(ctx, &ClientTrace{
+		GetDone: func(_ context.Context, g StatisticGroup, info RequestInfo, err error) {
+			if g == "" {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed[g] = err
+				return
+			}
+			fetched[g] = true
+			t := info.Received
+			if t.IsZero() {
+				t = c.now()
+			}
+			c.mu.Lock()
+			f := c.groups[g]
+			f.FetchedAt = t
+			c.groups[g] = f
+			c.mu.Unlock()
+		},
+	})
+	s, err := c.client.Stats(ctx, groups...)
+
+	now := c.now()
+	mu.Lock()
+	defer mu.Unlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, g := range groups {
+		f := c.groups[g]
+		switch {
+		case containsGroup(s.MissingGroups, g):
+		case failed[g] != nil && !fetched[g]:
+			f.LastError, f.LastErrorAt = failed[g], now
+		case err != nil && !fetched[g]:
+			f.LastError, f.LastErrorAt = err, now
+		case err == nil:
+			f.ServedAt = now
+		}
+		c.groups[g] = f
+	}
+	return s, err
+}
+
+// Freshness returns the freshness of the groups requested so far.
+func (c *FreshnessClient) Freshness() map[StatisticGroup]Freshness {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	m := make(map[StatisticGroup]Freshness, len(c.groups))
+	for g, f := range c.groups {
+		m[g] = f
+	}
+	return m
+}
+
+// LastSuccess returns the time of the last successful fetch of every group
+// which has been fetched, see Freshness.FetchedAt.
+func (c *FreshnessClient) LastSuccess() map[StatisticGroup]time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	m := map[StatisticGroup]time.Time{}
+	for g, f := range c.groups {
+		if !f.FetchedAt.IsZero() {
+			m[g] = f.FetchedAt
+		}
+	}
+	return m
+}
+
+// LastErrors returns the error of the last failed Stats call of every group
+// which failed, see Freshness.LastError.
+func (c *FreshnessClient) LastErrors() map[StatisticGroup]error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	m := map[StatisticGroup]error{}
+	for g, f := range c.groups {
+		if f.LastError != nil {
+			m[g] = f.LastError
+		}
+	}
+	return m
+}
@@ -0,0 +1,99 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// tracingClient reports a request for every requested group to the trace of the
+// context, failing those listed in failures, unless cached is set.
+type tracingClient struct {
+	cached   bool
+	failures map[StatisticGroup]error
+	missing  []StatisticGroup
+	received time.Time
+}
+
+func (c *tracingClient) Stats(ctx context.Context, groups ...StatisticGroup) (Statistics, error) {
+	s := Statistics{MissingGroups: c.missing}
+	if c.cached {
+		return s, nil
+	}
+	var err error
+	for _, g := range groups {
+		gerr := c.failures[g]
+		if t := ContextClientTrace(ctx); t != nil && t.GetDone != nil {
+			t.GetDone(ctx, g, RequestInfo{Received: c.received}, gerr)
+		}
+		if gerr != nil && !containsGroup(c.missing, g) {
+			err = gerr
+		}
+	}
+	return s, err
+}
+
+func TestFreshnessClient(t *testing.T) {
+	now := time.Date(2024, 3, 15, 8, 0, 0, 0, time.UTC)
+	inner := &tracingClient{received: now.Add(-time.Second)}
+	c := NewFreshnessClient(inner)
+	c.now = func() time.Time { return now }
+
+	if _, err := c.Stats(context.Background(), ServerStats, ViewStats); err != nil {
+		t.Fatal(err)
+	}
+	fetched := now.Add(-time.Second)
+	if got := c.LastSuccess(); len(got) != 2 || !got[ServerStats].Equal(fetched) || !got[ViewStats].Equal(fetched) {
+		t.Errorf("want groups fetched at %s, got %v", fetched, got)
+	}
+
+	// A cache hit is served, but not fetched.
+	now = now.Add(time.Minute)
+	inner.cached = true
+	if _, err := c.Stats(context.Background(), ServerStats); err != nil {
+		t.Fatal(err)
+	}
+	if f := c.Freshness()[ServerStats]; !f.FetchedAt.Equal(fetched) || !f.ServedAt.Equal(now) {
+		t.Errorf("want cache hit served at %s and fetched at %s, got %+v", now, fetched, f)
+	}
+
+	// Failures are recorded for the failed group only, and missing groups
+	// are neither served nor failed.
+	now = now.Add(time.Minute)
+	errBusy := errors.New("busy")
+	inner.cached = false
+	inner.received = now
+	inner.failures = map[StatisticGroup]error{ViewStats: errBusy, TaskStats: errors.New("not found")}
+	inner.missing = []StatisticGroup{TaskStats}
+	if _, err := c.Stats(context.Background(), ServerStats, ViewStats, TaskStats); !errors.Is(err, errBusy) {
+		t.Fatalf("want error of wrapped client, got %v", err)
+	}
+	errs := c.LastErrors()
+	if len(errs) != 1 || errs[ViewStats] != errBusy {
+		t.Errorf("want error of views only, got %v", errs)
+	}
+	fr := c.Freshness()
+	if f := fr[ServerStats]; !f.FetchedAt.Equal(now) || f.LastError != nil {
+		t.Errorf("want server statistics fetched despite failure of views, got %+v", f)
+	}
+	if f := fr[ViewStats]; !f.FetchedAt.Equal(fetched) || !f.LastErrorAt.Equal(now) {
+		t.Errorf("want views to keep fetch time of last success, got %+v", f)
+	}
+	if f := fr[TaskStats]; !f.FetchedAt.IsZero() || !f.ServedAt.IsZero() || f.LastError != nil {
+		t.Errorf("want missing tasks to be neither fetched nor failed, got %+v", f)
+	}
+}
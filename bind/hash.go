@@ -0,0 +1,114 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// ContentHash returns a hash of the statistics of group g in s. It covers the
+// counters, gauges and tasks of the group, including the Memory of
+// ServerStats, but not the times at which they have been fetched or rendered,
+// and does not depend on the order of views, zones or counters. Statistics of
+// the same group with equal content thus have equal hashes. The boot and
+// reconfiguration times of the server are covered by ServerStats and
+// StatusStats.
+func ContentHash(s Statistics, g StatisticGroup) uint64 {
+	var lines []string
+	add := func(parts ...string) {
+		lines = append(lines, strings.Join(parts, "\x00"))
+	}
+	counters := func(prefix []string, cs []Counter) {
+		for _, c := range cs {
+			add(append(prefix, c.Name, strconv.FormatUint(c.Counter, 10))...)
+		}
+	}
+	extra := func(prefix []string, e Extra) {
+		for t, cs := range e {
+			counters(append(prefix[:len(prefix):len(prefix)], t), cs)
+		}
+	}
+
+	switch g {
+	case ServerStats:
+		add("boot", s.Server.BootTime.UTC().Format(time.RFC3339Nano))
+		add("config", s.Server.ConfigTime.UTC().Format(time.RFC3339Nano))
+		counters([]string{"qtype"}, s.Server.IncomingQueries)
+		counters([]string{"opcode"}, s.Server.IncomingRequests)
+		counters([]string{"nsstat"}, s.Server.NameServerStats)
+		counters([]string{"zonestat"}, s.Server.ZoneMaintenance)
+		counters([]string{"rcode"}, s.Server.ServerRcodes)
+		extra([]string{"extra"}, s.Server.Extra)
+		for _, g := range s.Memory.Summary.Gauges() {
+			add("memory", g.Name, strconv.FormatUint(g.Gauge, 10))
+		}
+		for _, c := range s.Memory.Contexts {
+			add("memctx", c.ID, c.Name, strconv.FormatUint(c.References, 10), strconv.FormatUint(c.Total, 10),
+				strconv.FormatUint(c.InUse, 10), strconv.FormatUint(c.MaxInUse, 10))
+		}
+	case ViewStats:
+		for _, v := range s.Views {
+			for _, c := range v.Cache {
+				add("cache", v.Name, c.Name, strconv.FormatUint(c.Gauge, 10))
+			}
+			for _, c := range v.CacheMemory {
+				add("cachemem", v.Name, c.Name, strconv.FormatUint(c.Gauge, 10))
+			}
+			counters([]string{"cachestat", v.Name}, v.CacheStats)
+			counters([]string{"resstat", v.Name}, v.ResolverStats)
+			for _, c := range v.ResolverGauges {
+				add("resgauge", v.Name, c.Name, strconv.FormatUint(c.Gauge, 10))
+			}
+			counters([]string{"resqtype", v.Name}, v.ResolverQueries)
+			extra([]string{"extra", v.Name}, v.Extra)
+		}
+		for _, v := range s.ZoneViews {
+			for _, z := range v.ZoneData {
+				add("serial", v.Name, z.Name, z.Serial)
+				counters([]string{"zonestat", v.Name, z.Name}, z.ZoneStats)
+				counters([]string{"dnssec-sign", v.Name, z.Name}, z.DNSSECSignStats)
+				counters([]string{"dnssec-refresh", v.Name, z.Name}, z.DNSSECRefreshStats)
+				counters([]string{"rcode", v.Name, z.Name}, z.QueryResults)
+				counters([]string{"qtype", v.Name, z.Name}, z.IncomingQueries)
+				counters([]string{"nsstat", v.Name, z.Name}, z.NameServerStats)
+				extra([]string{"extra", v.Name, z.Name}, z.Extra)
+			}
+		}
+	case StatusStats:
+		add("boot", s.Server.BootTime.UTC().Format(time.RFC3339Nano))
+		add("config", s.Server.ConfigTime.UTC().Format(time.RFC3339Nano))
+		add("version", s.Source.BINDVersion)
+	case TaskStats:
+		tm := s.TaskManager.ThreadModel
+		add("thread-model", tm.Type, strconv.FormatUint(tm.WorkerThreads, 10),
+			strconv.FormatUint(tm.DefaultQuantum, 10), strconv.FormatUint(tm.TasksRunning, 10))
+		for _, t := range s.TaskManager.Tasks {
+			add("task", t.ID, t.Name, strconv.FormatInt(t.Quantum, 10),
+				strconv.FormatUint(t.References, 10), string(t.State))
+		}
+	}
+
+	sort.Strings(lines)
+	d := xxhash.New()
+	for _, l := range lines {
+		d.WriteString(l)
+		d.WriteString("\x00\n")
+	}
+	return d.Sum64()
+}
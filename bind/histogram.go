@@ -0,0 +1,106 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Histogram is a cumulative histogram.
+type Histogram struct {
+	// Buckets are sorted by ascending upper bound. The count of each bucket
+	// includes the counts of all buckets with a lower upper bound.
+	Buckets []Bucket
+	// Count is the total number of observations.
+	Count uint64
+}
+
+// Bucket is a single bucket of a Histogram.
+type Bucket struct {
+	// UpperBound is the inclusive upper bound of the bucket, +Inf for the
+	// last bucket of a complete histogram.
+	UpperBound float64
+	// Count is the cumulative number of observations.
+	Count uint64
+}
+
+type jsonBucket struct {
+	UpperBound string
+	Count      uint64
+}
+
+// MarshalJSON implements json.Marshaler. The upper bound is encoded as a
+// string, as JSON numbers cannot represent +Inf.
+func (b Bucket) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonBucket{
+		UpperBound: strconv.FormatFloat(b.UpperBound, 'g', -1, 64),
+		Count:      b.Count,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *Bucket) UnmarshalJSON(data []byte) error {
+	var j jsonBucket
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	ub, err := strconv.ParseFloat(j.UpperBound, 64)
+	if err != nil {
+		return fmt.Errorf("invalid bucket upper bound %q: %s", j.UpperBound, err)
+	}
+	*b = Bucket{UpperBound: ub, Count: j.Count}
+	return nil
+}
+
+// QueryRTTHistogram returns the histogram of query round-trip times in
+// seconds described by the QryRTT counters in stats. BIND reports the number
+// of queries per bucket, e.g. QryRTT10 for queries answered within 10ms and
+// QryRTT1600+ for queries taking longer than 1.6s. All other counters are
+// ignored, so stats without QryRTT counters result in an empty histogram.
+// Cumulative counts saturate at math.MaxUint64, which Validate reports with
+// WarnCounterOverflow.
+func QueryRTTHistogram(stats []Counter) (Histogram, error) {
+	var h Histogram
+	for _, s := range stats {
+		if !strings.HasPrefix(s.Name, QryRTT) {
+			continue
+		}
+		b := math.Inf(0)
+		if !strings.HasSuffix(s.Name, "+") {
+			rtt := strings.TrimPrefix(s.Name, QryRTT)
+			ms, err := strconv.ParseFloat(rtt, 32)
+			if err != nil {
+				return Histogram{}, fmt.Errorf("could not parse RTT: %s", rtt)
+			}
+			b = ms / 1000
+		}
+		h.Buckets = append(h.Buckets, Bucket{UpperBound: b, Count: s.Counter})
+	}
+
+	// Don't assume that QryRTT counters were in ascending order before summing them.
+	// JSON stats are unmarshaled into a map, which won't preserve the order that BIND renders.
+	sort.Slice(h.Buckets, func(i, j int) bool {
+		return h.Buckets[i].UpperBound < h.Buckets[j].UpperBound
+	})
+	for i := range h.Buckets {
+		h.Buckets[i].Count, _ = AddCounters(h.Buckets[i].Count, h.Count)
+		h.Count = h.Buckets[i].Count
+	}
+	return h, nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"errors"
+	"sort"
+)
+
+// ZoneActivity describes the activity of a zone between two statistics, see
+// HotZones.
+type ZoneActivity struct {
+	View string
+	Zone string
+	// Queries is the increase of the query type counters of the zone, i.e.
+	// ZoneCounter.IncomingQueries. It is zero for removed zones.
+	Queries uint64
+	// PrevSerial and Serial are the serials of the zone in the earlier and
+	// later statistics. PrevSerial is empty for added zones and Serial for
+	// removed zones.
+	PrevSerial string
+	Serial     string
+	// Added is set for zones absent from the earlier statistics, Removed
+	// for zones absent from the later statistics.
+	Added   bool
+	Removed bool
+}
+
+// SerialChanged reports whether the zone is present in both statistics and
+// its serial changed.
+func (a ZoneActivity) SerialChanged() bool {
+	return !a.Added && !a.Removed && a.PrevSerial != a.Serial
+}
+
+// HotZones returns the zones of cur which received at least minDelta queries
+// since prev, and at least one, or whose serial changed, as well as the zones
+// added to or removed from cur since prev. Zones are matched by view and name,
+// see ZoneKey. A zone whose counters decreased, e.g. because it has been
+// recreated by a reconfiguration or named has been restarted, is taken to
+// start at zero. The zones are sorted by descending Queries, then by view and
+// zone name.
+func HotZones(prev, cur Statistics, minDelta uint64) []ZoneActivity {
+	prevZones := map[[2]string]ZoneCounter{}
+	for _, v := range prev.ZoneViews {
+		for _, z := range v.ZoneData {
+			prevZones[[2]string{v.Name, ZoneKey(z.Name)}] = z
+		}
+	}
+
+	var as []ZoneActivity
+	for _, v := range cur.ZoneViews {
+		for _, z := range v.ZoneData {
+			k := [2]string{v.Name, ZoneKey(z.Name)}
+			p, ok := prevZones[k]
+			delete(prevZones, k)
+			a := ZoneActivity{View: v.Name, Zone: CanonicalZoneName(z.Name), PrevSerial: p.Serial, Serial: z.Serial, Added: !ok}
+			d, err := deltaCounters("", p.IncomingQueries, z.IncomingQueries)
+			if errors.Is(err, ErrCounterReset) {
+				d = z.IncomingQueries
+			}
+			for _, c := range d {
+				a.Queries, _ = AddCounters(a.Queries, c.Counter)
+			}
+			if a.Added || a.SerialChanged() || a.Queries > 0 && a.Queries >= minDelta {
+				as = append(as, a)
+			}
+		}
+	}
+	for k, z := range prevZones {
+		as = append(as, ZoneActivity{View: k[0], Zone: CanonicalZoneName(z.Name), PrevSerial: z.Serial, Removed: true})
+	}
+
+	sort.Slice(as, func(i, j int) bool {
+		if as[i].Queries != as[j].Queries {
+			return as[i].Queries > as[j].Queries
+		}
+		if as[i].View != as[j].View {
+			return as[i].View < as[j].View
+		}
+		return as[i].Zone < as[j].Zone
+	})
+	return as
+}
@@ -0,0 +1,74 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/bindtest"
+	"github.com/prometheus-community/bind_exporter/bind/xml"
+)
+
+// viewStats returns the view statistics of the given XML v3 server and zones
+// fixtures.
+func viewStats(t *testing.T, server, zones string) bind.Statistics {
+	ts := bindtest.NewServerWith(map[string]string{
+		xml.ServerPath: server,
+		xml.ZonesPath:  zones,
+	})
+	defer ts.Close()
+
+	s, err := xml.NewClient(ts.URL, nil).Stats(context.Background(), bind.ViewStats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestHotZones(t *testing.T) {
+	prev := viewStats(t, "xml/server.xml", "xml/zones-idle.xml")
+	cur := viewStats(t, "xml/server.xml", "xml/zones-hot.xml")
+
+	com := bind.ZoneActivity{View: "_default", Zone: "example.com", Queries: 500, PrevSerial: "2024031500", Serial: "2024031500"}
+	org := bind.ZoneActivity{View: "_default", Zone: "example.org", Queries: 40, Serial: "1", Added: true}
+	net := bind.ZoneActivity{View: "_default", Zone: "example.net", Queries: 3, PrevSerial: "2024031501", Serial: "2024031601"}
+	arpa := bind.ZoneActivity{View: "_default", Zone: "0.168.192.in-addr.arpa", PrevSerial: "2024031502", Removed: true}
+	for _, tc := range []struct {
+		minDelta uint64
+		want     []bind.ZoneActivity
+	}{
+		{minDelta: 10, want: []bind.ZoneActivity{com, org, net, arpa}},
+		{minDelta: 1000, want: []bind.ZoneActivity{org, net, arpa}},
+	} {
+		if got := bind.HotZones(prev, cur, tc.minDelta); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("min delta %d: want %+v, got %+v", tc.minDelta, tc.want, got)
+		}
+	}
+	if !net.SerialChanged() || com.SerialChanged() || org.SerialChanged() {
+		t.Error("want only the serial of example.net changed")
+	}
+
+	// Zones whose counters decreased count their queries since the restart.
+	zone := func(n uint64) bind.Statistics {
+		return bind.Statistics{ZoneViews: []bind.ZoneView{{Name: "_default", ZoneData: []bind.ZoneCounter{
+			{Name: "example.com", Serial: "1", IncomingQueries: []bind.Counter{{Name: "A", Counter: n}}},
+		}}}}
+	}
+	if got := bind.HotZones(zone(500), zone(20), 1); len(got) != 1 || got[0].Queries != 20 {
+		t.Errorf("want 20 queries since the restart, got %+v", got)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// DefaultTimeout is the overall request timeout of DefaultHTTPClient.
+const DefaultTimeout = 10 * time.Second
+
+// DefaultRetryDelay is the delay of a retry, see WithRetries, if the response
+// has no Retry-After header.
+const DefaultRetryDelay = time.Second
+
+// DefaultHTTPClient returns the http.Client used by clients which are given a
+// nil http.Client. It is tuned for repeatedly querying a single statistics
+// channel:
+//
+//   - requests time out after DefaultTimeout, including reading the body,
+//   - redirects are not followed,
+//   - up to 4 idle connections to the statistics channel are kept open for
+//     90s, so that the documents of a Stats call reuse connections,
+//   - connecting and TLS handshakes time out after 5s.
+//
+// Every call returns a new http.Client which may be adjusted freely.
+func DefaultHTTPClient() *http.Client {
+	return &http.Client{
+		Timeout:   DefaultTimeout,
+		Transport: defaultTransport(),
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func defaultTransport() *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          4,
+		MaxIdleConnsPerHost:   4,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+}
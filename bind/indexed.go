@@ -0,0 +1,172 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+// IndexedStats holds the counters of Statistics in nested maps, e.g. for
+// text/template, where s.Server.nsstats.QrySuccess is easier to write than a
+// search of a slice of counters. Sections are named like the segments of the
+// metrics of Flatten, e.g. "nsstats" or "qtypes", and sections unknown to the
+// package by their type, see Extra. Counters are keyed by their normalized
+// name, see NormalizeCounterName, summing counters which only differed by
+// their names. Sections without counters are omitted.
+type IndexedStats struct {
+	// Server holds the counters of the server by section and name.
+	Server map[string]map[string]uint64
+	// Views holds the views by name.
+	Views map[string]IndexedView
+	// Zones holds the zones by view and zone name, see ZoneKey.
+	Zones map[string]map[string]IndexedZone
+}
+
+// IndexedView holds the statistics of a view of IndexedStats.
+type IndexedView struct {
+	// Cache holds the number of cached RRsets by type.
+	Cache map[string]uint64
+	// CacheMemory holds the memory used by the cache in bytes by name.
+	CacheMemory map[string]uint64
+	// ResolverGauges holds the resolver gauges by name, see
+	// View.ResolverGauges.
+	ResolverGauges map[string]uint64
+	// Counters holds the resolver counters by section and name, i.e.
+	// "cachestats", "resstats", "resqtypes" and the sections unknown to the
+	// package.
+	Counters map[string]map[string]uint64
+}
+
+// IndexedZone holds the statistics of a zone of IndexedStats.
+type IndexedZone struct {
+	Serial string
+	// Counters holds the counters of the zone by section and name, i.e.
+	// "zonestats", "dnssec_sign", "dnssec_refresh", "query_results",
+	// "qtypes", "nsstats" and the sections unknown to the package.
+	Counters map[string]map[string]uint64
+}
+
+// indexedCache is the IndexedStats built for the Server, Views and ZoneViews
+// of statistics.
+type indexedCache struct {
+	server    Server
+	views     []View
+	zoneViews []ZoneView
+	stats     IndexedStats
+}
+
+// valid reports whether the cache has been built for s. Replacing the
+// Server, Views or ZoneViews invalidates it, see zoneIndex.valid.
+func (c *indexedCache) valid(s *Statistics) bool {
+	return c != nil && sameSlice(c.views, s.Views) && sameSlice(c.zoneViews, s.ZoneViews) &&
+		sameSlice(c.server.IncomingQueries, s.Server.IncomingQueries) &&
+		sameSlice(c.server.IncomingRequests, s.Server.IncomingRequests) &&
+		sameSlice(c.server.NameServerStats, s.Server.NameServerStats) &&
+		sameSlice(c.server.ZoneMaintenance, s.Server.ZoneMaintenance) &&
+		sameSlice(c.server.ServerRcodes, s.Server.ServerRcodes) &&
+		len(c.server.Extra) == len(s.Server.Extra)
+}
+
+func sameSlice[T any](a, b []T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	return len(a) == 0 || &a[0] == &b[0]
+}
+
+// Indexed returns the counters of s in nested maps. The maps are built on the
+// first call and reused until the Server, Views or ZoneViews are replaced,
+// e.g. by Merge, so the result must not be modified and Indexed must not be
+// called concurrently on the same statistics. Counters modified in place
+// require calling Merge or Clone to be indexed.
+func (s *Statistics) Indexed() IndexedStats {
+	if !s.indexed.valid(s) {
+		s.indexed = &indexedCache{server: s.Server, views: s.Views, zoneViews: s.ZoneViews, stats: newIndexedStats(s)}
+	}
+	return s.indexed.stats
+}
+
+func newIndexedStats(s *Statistics) IndexedStats {
+	is := IndexedStats{
+		Server: sections{}.
+			add("qtypes", s.Server.IncomingQueries).
+			add("opcodes", s.Server.IncomingRequests).
+			add("nsstats", s.Server.NameServerStats).
+			add("zonestats", s.Server.ZoneMaintenance).
+			add("rcodes", s.Server.ServerRcodes).
+			addExtra(s.Server.Extra),
+		Views: make(map[string]IndexedView, len(s.Views)),
+		Zones: make(map[string]map[string]IndexedZone, len(s.ZoneViews)),
+	}
+	for _, v := range s.Views {
+		is.Views[v.Name] = IndexedView{
+			Cache:          indexGauges(v.Cache),
+			CacheMemory:    indexGauges(v.CacheMemory),
+			ResolverGauges: indexGauges(v.ResolverGauges),
+			Counters: sections{}.
+				add("cachestats", v.CacheStats).
+				add("resstats", v.ResolverStats).
+				add("resqtypes", v.ResolverQueries).
+				addExtra(v.Extra),
+		}
+	}
+	for _, v := range s.ZoneViews {
+		zones := make(map[string]IndexedZone, len(v.ZoneData))
+		for _, z := range v.ZoneData {
+			zones[ZoneKey(z.Name)] = IndexedZone{
+				Serial: z.Serial,
+				Counters: sections{}.
+					add("zonestats", z.ZoneStats).
+					add("dnssec_sign", z.DNSSECSignStats).
+					add("dnssec_refresh", z.DNSSECRefreshStats).
+					add("query_results", z.QueryResults).
+					add("qtypes", z.IncomingQueries).
+					add("nsstats", z.NameServerStats).
+					addExtra(z.Extra),
+			}
+		}
+		is.Zones[v.Name] = zones
+	}
+	return is
+}
+
+// sections builds the counters of IndexedStats by section and name.
+type sections map[string]map[string]uint64
+
+func (m sections) add(section string, cs []Counter) sections {
+	if len(cs) == 0 {
+		return m
+	}
+	names := m[section]
+	if names == nil {
+		names = make(map[string]uint64, len(cs))
+		m[section] = names
+	}
+	for _, c := range cs {
+		name := NormalizeCounterName(c.Name)
+		names[name], _ = AddCounters(names[name], c.Counter)
+	}
+	return m
+}
+
+func (m sections) addExtra(e Extra) sections {
+	for t, cs := range e {
+		m.add(t, cs)
+	}
+	return m
+}
+
+func indexGauges(gs []Gauge) map[string]uint64 {
+	m := make(map[string]uint64, len(gs))
+	for _, g := range gs {
+		m[g.Name] = g.Gauge
+	}
+	return m
+}
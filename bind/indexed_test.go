@@ -0,0 +1,75 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+)
+
+func TestIndexed(t *testing.T) {
+	s := fixtureStats(t)
+	is := s.Indexed()
+
+	if got := is.Server["nsstats"][bind.CounterQrySuccess]; got != 29313 {
+		t.Errorf("want 29313 successful queries, got %d", got)
+	}
+	if got := is.Views["_default"].Counters["resstats"]["Queryv4"]; got != 1574 {
+		t.Errorf("want 1574 IPv4 resolver queries, got %d", got)
+	}
+	if _, ok := is.Zones["_default"]["example.com"]; !ok {
+		t.Errorf("want zone example.com, got %v", is.Zones["_default"])
+	}
+	if reflect.ValueOf(s.Indexed().Server).Pointer() != reflect.ValueOf(is.Server).Pointer() {
+		t.Error("want cached index")
+	}
+
+	b, err := json.Marshal(is)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded bind.IndexedStats
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, is) {
+		t.Errorf("want index to survive JSON, got %s", b)
+	}
+
+	tmpl := template.Must(template.New("report").Parse(
+		`{{range $name, $v := .Views}}{{$name}}: {{index $v.Counters.resstats "Queryv4"}} IPv4 queries, {{index $v.Counters.resstats "NXDOMAIN"}} NXDOMAIN
+{{end}}`))
+	var out strings.Builder
+	if err := tmpl.Execute(&out, is); err != nil {
+		t.Fatal(err)
+	}
+	if want := "_bind: 0 IPv4 queries, 0 NXDOMAIN\n_default: 1574 IPv4 queries, 16707 NXDOMAIN\n"; out.String() != want {
+		t.Errorf("want report\n%s\ngot\n%s", want, out.String())
+	}
+
+	// Counters renamed by BIND are indexed by their current name.
+	s = bind.Statistics{Server: bind.Server{NameServerStats: []bind.Counter{{Name: "RespTruncated", Counter: 3}, {Name: bind.CounterTruncatedResp, Counter: 4}}}}
+	if got := s.Indexed().Server["nsstats"][bind.CounterTruncatedResp]; got != 7 {
+		t.Errorf("want 7 truncated responses, got %d", got)
+	}
+	s.Merge(bind.Statistics{Server: bind.Server{NameServerStats: []bind.Counter{{Name: bind.CounterTruncatedResp, Counter: 1}}}}, bind.ServerStats)
+	if got := s.Indexed().Server["nsstats"][bind.CounterTruncatedResp]; got != 1 {
+		t.Errorf("want index rebuilt after merge, got %d", got)
+	}
+}
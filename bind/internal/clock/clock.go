@@ -0,0 +1,226 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package clock implements the sources of time of the bind packages, see
+// bind.Clock. It does not depend on them, so that the tests of every package
+// can use Fake.
+package clock
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock tells the local time and creates timers.
+type Clock interface {
+	Now() time.Time
+	// NewTimer returns a Timer sending the time on its channel once d has
+	// elapsed.
+	NewTimer(d time.Duration) Timer
+	// AfterFunc returns a Timer calling f in its own goroutine once d has
+	// elapsed. Its channel is nil.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a timer of a Clock, see time.Timer.
+type Timer interface {
+	C() <-chan time.Time
+	// Stop prevents the timer from firing and reports whether it was
+	// pending.
+	Stop() bool
+	// Reset makes the timer fire once d has elapsed and reports whether it
+	// was pending.
+	Reset(d time.Duration) bool
+}
+
+// System is the Clock of the system, based on the time package.
+type System struct{}
+
+// Now returns time.Now().
+func (System) Now() time.Time { return time.Now() }
+
+// NewTimer returns a Timer based on time.NewTimer.
+func (System) NewTimer(d time.Duration) Timer { return systemTimer{time.NewTimer(d)} }
+
+// AfterFunc returns a Timer based on time.AfterFunc.
+func (System) AfterFunc(d time.Duration, f func()) Timer {
+	return systemTimer{time.AfterFunc(d, f)}
+}
+
+type systemTimer struct{ *time.Timer }
+
+func (t systemTimer) C() <-chan time.Time { return t.Timer.C }
+
+// Sleep waits for d on c and returns early with the error of ctx once ctx is
+// done.
+func Sleep(ctx context.Context, c Clock, d time.Duration) error {
+	t := c.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Fake is a Clock whose time only changes with Advance. It is safe for
+// concurrent use by multiple goroutines.
+type Fake struct {
+	mu      sync.Mutex
+	changed *sync.Cond
+	now     time.Time
+	timers  []*fakeTimer
+}
+
+// NewFake returns a Fake clock at t.
+func NewFake(t time.Time) *Fake {
+	c := &Fake{now: t}
+	c.changed = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now returns the time of c.
+func (c *Fake) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer returns a Timer firing once c has been advanced by d. Like a timer
+// of the time package, its channel holds at most one time.
+func (c *Fake) NewTimer(d time.Duration) Timer {
+	t := &fakeTimer{clock: c, c: make(chan time.Time, 1)}
+	t.Reset(d)
+	return t
+}
+
+// AfterFunc returns a Timer calling f in its own goroutine once c has been
+// advanced by d.
+func (c *Fake) AfterFunc(d time.Duration, f func()) Timer {
+	t := &fakeTimer{clock: c, f: f}
+	t.Reset(d)
+	return t
+}
+
+// Advance moves the time of c forward by d and fires the timers due, in the
+// order of their deadlines.
+func (c *Fake) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	var due []*fakeTimer
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.at.After(c.now) {
+			pending = append(pending, t)
+		} else {
+			due = append(due, t)
+		}
+	}
+	c.timers = pending
+	c.changed.Broadcast()
+	c.mu.Unlock()
+
+	sort.SliceStable(due, func(i, j int) bool { return due[i].at.Before(due[j].at) })
+	for _, t := range due {
+		t.fire()
+	}
+}
+
+// BlockUntil waits until at least n timers of c are pending, e.g. until the
+// code under test waits for c.
+func (c *Fake) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.timers) < n {
+		c.changed.Wait()
+	}
+}
+
+// BlockUntilDue waits until a timer of c is pending which fires once c has
+// been advanced by d, e.g. until the code under test waits for d.
+func (c *Fake) BlockUntilDue(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for !c.due(d) {
+		c.changed.Wait()
+	}
+}
+
+// due reports whether a timer fires after d. The clock must be locked.
+func (c *Fake) due(d time.Duration) bool {
+	for _, t := range c.timers {
+		if t.at.Sub(c.now) == d {
+			return true
+		}
+	}
+	return false
+}
+
+type fakeTimer struct {
+	clock *Fake
+	c     chan time.Time
+	f     func()
+	at    time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.c }
+
+func (t *fakeTimer) Stop() bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return t.remove()
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	c := t.clock
+	c.mu.Lock()
+	pending := t.remove()
+	t.at = c.now.Add(d)
+	if d > 0 {
+		c.timers = append(c.timers, t)
+		c.changed.Broadcast()
+		c.mu.Unlock()
+		return pending
+	}
+	c.mu.Unlock()
+	t.fire()
+	return pending
+}
+
+// remove removes t from the pending timers of its clock, which must be
+// locked, and reports whether it was pending.
+func (t *fakeTimer) remove() bool {
+	c := t.clock
+	for i, p := range c.timers {
+		if p == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (t *fakeTimer) fire() {
+	if t.f != nil {
+		go t.f()
+		return
+	}
+	select {
+	case t.c <- t.at:
+	default:
+	}
+}
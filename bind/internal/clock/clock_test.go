@@ -0,0 +1,81 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clock
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFake(t *testing.T) {
+	start := time.Date(2024, 3, 15, 8, 0, 0, 0, time.UTC)
+	c := NewFake(start)
+	a := c.NewTimer(time.Minute)
+	b := c.NewTimer(2 * time.Minute)
+	fired := make(chan struct{})
+	c.AfterFunc(30*time.Second, func() { close(fired) })
+	stopped := c.NewTimer(time.Second)
+	if !stopped.Stop() || stopped.Stop() {
+		t.Error("want Stop to report only the pending timer")
+	}
+
+	c.Advance(time.Minute)
+	if got, want := c.Now(), start.Add(time.Minute); !got.Equal(want) {
+		t.Errorf("want time %s, got %s", want, got)
+	}
+	<-fired
+	select {
+	case got := <-a.C():
+		if want := start.Add(time.Minute); !got.Equal(want) {
+			t.Errorf("want timer fired at %s, got %s", want, got)
+		}
+	default:
+		t.Error("want due timer fired")
+	}
+	select {
+	case <-b.C():
+		t.Error("want timer not yet due pending")
+	case <-stopped.C():
+		t.Error("want stopped timer not fired")
+	default:
+	}
+
+	if !b.Reset(time.Second) {
+		t.Error("want Reset to report the pending timer")
+	}
+	c.BlockUntil(1)
+	c.BlockUntilDue(time.Second)
+	c.Advance(time.Second)
+	<-b.C()
+}
+
+func TestFakeSleep(t *testing.T) {
+	c := NewFake(time.Unix(0, 0))
+	done := make(chan error)
+	go func() { done <- Sleep(context.Background(), c, time.Hour) }()
+	c.BlockUntilDue(time.Hour)
+	c.Advance(time.Hour)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { done <- Sleep(ctx, c, time.Hour) }()
+	c.BlockUntil(1)
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("want context canceled, got %v", err)
+	}
+}
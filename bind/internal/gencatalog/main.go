@@ -0,0 +1,175 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command gencatalog generates the Go table of the counter catalog of the
+// bind package from counters.tsv.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// groups maps the group column to the constant of the bind package.
+var groups = map[string]string{
+	"cachestats": "CacheCounters",
+	"memory":     "MemoryCounters",
+	"nsstat":     "NameServerCounters",
+	"opcode":     "OpcodeCounters",
+	"rcode":      "RcodeCounters",
+	"resstats":   "ResolverCounters",
+	"sockstat":   "SocketCounters",
+	"zonestat":   "ZoneMaintenanceCounters",
+}
+
+var kinds = map[string]string{
+	"counter": "KindCounter",
+	"gauge":   "KindGauge",
+}
+
+var units = map[string]string{
+	"bytes": "UnitBytes",
+}
+
+func main() {
+	in := flag.String("in", "counters.tsv", "Catalog source file")
+	out := flag.String("out", "catalog_table.go", "Generated Go file")
+	flag.Parse()
+
+	f, err := os.Open(*in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	b, err := generate(f, *in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, b, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// groupDocs are the comments of the constant blocks of the counter names.
+var groupDocs = map[string]string{
+	"cachestats": "Cache statistics of views, see CacheCounters.",
+	"memory":     "Memory summary, see MemoryCounters.",
+	"nsstat":     "Name server statistics, see NameServerCounters.",
+	"opcode":     "Incoming requests by opcode, see OpcodeCounters.",
+	"rcode":      "Responses sent by rcode, see RcodeCounters.",
+	"resstats":   "Resolver statistics, see ResolverCounters.",
+	"sockstat":   "Socket I/O statistics, see SocketCounters.",
+	"zonestat":   "Zone maintenance statistics, see ZoneMaintenanceCounters.",
+}
+
+type entry struct {
+	group, name, kind, unit, since, until, help string
+}
+
+// generate returns the formatted Go source of the catalog described by r,
+// which has been read from the file named name.
+func generate(r io.Reader, name string) ([]byte, error) {
+	var entries []entry
+	seen := map[[2]string]bool{}
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		text := s.Text()
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Split(text, "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("%s:%d: want 7 fields, got %d", name, line, len(fields))
+		}
+		if _, ok := groups[fields[0]]; !ok {
+			return nil, fmt.Errorf("%s:%d: unknown group %q", name, line, fields[0])
+		}
+		if _, ok := kinds[fields[2]]; !ok {
+			return nil, fmt.Errorf("%s:%d: unknown kind %q", name, line, fields[2])
+		}
+		if _, ok := units[fields[3]]; !ok && fields[3] != "-" {
+			return nil, fmt.Errorf("%s:%d: unknown unit %q", name, line, fields[3])
+		}
+		if !token.IsIdentifier(constName(fields[1])) {
+			return nil, fmt.Errorf("%s:%d: counter name %q is no valid identifier", name, line, fields[1])
+		}
+		key := [2]string{fields[0], fields[1]}
+		if seen[key] {
+			return nil, fmt.Errorf("%s:%d: duplicated counter %s/%s", name, line, fields[0], fields[1])
+		}
+		seen[key] = true
+		entries = append(entries, entry{fields[0], fields[1], fields[2], fields[3], fields[4], fields[5], fields[6]})
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by gencatalog from %s. DO NOT EDIT.\n\npackage bind\n", name)
+
+	// Names reported by several groups, e.g. NXDOMAIN, are declared in the
+	// block of the first group.
+	declared := map[string]string{}
+	for i, e := range entries {
+		if i > 0 && e.group == entries[i-1].group {
+			continue
+		}
+		fmt.Fprintf(&b, "\n// %s\nconst (\n", groupDocs[e.group])
+		for _, c := range entries[i:] {
+			if c.group != e.group {
+				break
+			}
+			id := constName(c.name)
+			if n, ok := declared[id]; ok {
+				if n != c.name {
+					return nil, fmt.Errorf("%s: counter names %q and %q are both declared as %s", name, n, c.name, id)
+				}
+				continue
+			}
+			declared[id] = c.name
+			fmt.Fprintf(&b, "\t// %s\n\t%s = %q\n", c.help, id, c.name)
+		}
+		b.WriteString(")\n")
+	}
+
+	b.WriteString("\nvar catalog = []CounterInfo{\n")
+	for _, e := range entries {
+		fmt.Fprintf(&b, "\t{Group: %s, Name: %s, Kind: %s", groups[e.group], constName(e.name), kinds[e.kind])
+		if e.unit != "-" {
+			fmt.Fprintf(&b, ", Unit: %s", units[e.unit])
+		}
+		if e.since != "-" {
+			fmt.Fprintf(&b, ", Since: %q", e.since)
+		}
+		if e.until != "-" {
+			fmt.Fprintf(&b, ", Until: %q", e.until)
+		}
+		fmt.Fprintf(&b, ", Help: %q},\n", e.help)
+	}
+	b.WriteString("}\n")
+	return format.Source(b.Bytes())
+}
+
+// constName returns the name of the constant of the counter name, e.g.
+// CounterQryRTT1600Plus for QryRTT1600+.
+func constName(name string) string {
+	return "Counter" + strings.ReplaceAll(name, "+", "Plus")
+}
@@ -0,0 +1,56 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGeneratedTableUpToDate(t *testing.T) {
+	f, err := os.Open("../../counters.tsv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	got, err := generate(f, "counters.tsv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile("../../catalog_table.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("catalog_table.go is out of date, run go generate in the bind directory")
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	for _, tc := range []struct{ in, err string }{
+		{in: "nsstat\tA\tcounter\t-\t-\t-", err: "want 7 fields"},
+		{in: "bogus\tA\tcounter\t-\t-\t-\tHelp.", err: `unknown group "bogus"`},
+		{in: "nsstat\tA\trate\t-\t-\t-\tHelp.", err: `unknown kind "rate"`},
+		{in: "nsstat\tA\tgauge\tseconds\t-\t-\tHelp.", err: `unknown unit "seconds"`},
+		{in: "nsstat\tA\tcounter\t-\t-\t-\tHelp.\nnsstat\tA\tgauge\t-\t-\t-\tHelp.", err: "duplicated counter nsstat/A"},
+		{in: "nsstat\tA-B\tcounter\t-\t-\t-\tHelp.", err: `counter name "A-B" is no valid identifier`},
+		{in: "nsstat\tA+\tcounter\t-\t-\t-\tHelp.\nrcode\tAPlus\tcounter\t-\t-\t-\tHelp.", err: "both declared as CounterAPlus"},
+	} {
+		if _, err := generate(strings.NewReader(tc.in), "test.tsv"); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%q: want error containing %q, got %v", tc.in, tc.err, err)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus-community/bind_exporter/bind"
+)
+
+const (
+	// capturePrefix starts the names of the files written by captures, so
+	// that rotation leaves other files of the directory alone.
+	capturePrefix = "bind-capture-"
+	// maxCaptureBytes limits the bytes of a response body kept for a
+	// capture, see bind.WithFailureCapture.
+	maxCaptureBytes = 64 << 20
+)
+
+// captureReader keeps the first maxCaptureBytes read from r, and the first
+// error of r other than io.EOF, which tells that the body could not be read
+// rather than decoded.
+type captureReader struct {
+	r   io.Reader
+	buf bytes.Buffer
+	err error
+}
+
+func (r *captureReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if room := maxCaptureBytes - r.buf.Len(); room < n {
+		r.buf.Write(p[:room])
+	} else {
+		r.buf.Write(p[:n])
+	}
+	if err != nil && err != io.EOF && r.err == nil {
+		r.err = err
+	}
+	return n, err
+}
+
+// capture writes the body read by r, including its unread rest, to a new
+// file of the capture directory, removes the oldest captures and returns err
+// with the path of the file in its bind.DecodeError, wrapping err into one if
+// necessary. err is returned unchanged if the body could not be read or
+// written.
+func (c *Client) capture(u, contentType string, r *captureReader, err error) error {
+	if r.err != nil {
+		return err
+	}
+	io.Copy(io.Discard, io.LimitReader(r, int64(maxCaptureBytes-r.buf.Len())))
+	if r.err != nil {
+		return err
+	}
+
+	c.captureMu.Lock()
+	defer c.captureMu.Unlock()
+	p, werr := c.writeCapture(u, contentType, r.buf.Bytes())
+	if werr != nil {
+		level.Warn(c.logger()).Log("msg", "Failed to capture response", "url", u, "err", werr)
+		return err
+	}
+	if rerr := c.rotateCaptures(); rerr != nil {
+		level.Warn(c.logger()).Log("msg", "Failed to remove old captures", "dir", c.Options.FailureCaptureDir, "err", rerr)
+	}
+
+	// The DecodeError has just been created by the decoder, so it may be
+	// modified. The messages of the errors wrapping it have already been
+	// formatted, so the path is added to them.
+	var derr *bind.DecodeError
+	if !errors.As(err, &derr) {
+		return &bind.DecodeError{CapturePath: p, Err: err}
+	}
+	derr.CapturePath = p
+	if err == error(derr) {
+		return err
+	}
+	return fmt.Errorf("%w (response captured in %s)", err, p)
+}
+
+// writeCapture writes body to a new file named after the current time and the
+// document of u, and returns its path.
+func (c *Client) writeCapture(u, contentType string, body []byte) (string, error) {
+	doc := "body"
+	if pu, err := url.Parse(u); err == nil && path.Base(pu.Path) != "/" && path.Base(pu.Path) != "." {
+		doc = path.Base(pu.Path)
+	}
+	ext := ".body"
+	if mt, _, err := mime.ParseMediaType(contentType); err == nil {
+		switch {
+		case strings.HasSuffix(mt, "json"):
+			ext = ".json"
+		case strings.HasSuffix(mt, "xml"):
+			ext = ".xml"
+		}
+	}
+	name := capturePrefix + c.now().UTC().Format("20060102T150405.000000000Z") + "-" + doc
+	for i := 1; ; i++ {
+		p := filepath.Join(c.Options.FailureCaptureDir, name+ext)
+		if i > 1 {
+			p = filepath.Join(c.Options.FailureCaptureDir, fmt.Sprintf("%s-%d%s", name, i, ext))
+		}
+		f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if _, err := f.Write(body); err != nil {
+			f.Close()
+			return "", err
+		}
+		return p, f.Close()
+	}
+}
+
+// rotateCaptures removes the oldest captures of the capture directory beyond
+// the number of files to keep. The names of captures sort by their time.
+func (c *Client) rotateCaptures() error {
+	entries, err := os.ReadDir(c.Options.FailureCaptureDir)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), capturePrefix) && e.Type().IsRegular() {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	keep := c.Options.FailureCaptureFiles
+	if keep < 1 {
+		keep = 1
+	}
+	for len(names) > keep {
+		if err := os.Remove(filepath.Join(c.Options.FailureCaptureDir, names[0])); err != nil {
+			return err
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+func (c *Client) logger() log.Logger {
+	if c.Options.Logger == nil {
+		return log.NewNopLogger()
+	}
+	return c.Options.Logger
+}
@@ -0,0 +1,171 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+)
+
+// flights tracks the documents in flight by URL and the type they are
+// decoded into.
+type flights struct {
+	mu    sync.Mutex
+	calls map[flightKey]*call
+}
+
+// flightKey identifies a shared request. Documents of the same URL decoded
+// into different types, e.g. because the overrides of two groups point to
+// the same path, are requested separately.
+type flightKey struct {
+	url string
+	typ reflect.Type
+}
+
+// call is a request shared by concurrent callers. Its fields are set before
+// done is closed.
+type call struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+
+	// v points to the decoded value.
+	v    reflect.Value
+	info bind.RequestInfo
+	err  error
+	// decoded is set if the response body has been decoded, with the
+	// result decodeInfo and decodeErr.
+	decoded    bool
+	decodeInfo bind.DecodeInfo
+	decodeErr  error
+}
+
+// GetShared is like Get, but concurrent calls for the same URL and type of v
+// share a single request, regardless of their group. The first caller starts
+// the request, which decodes the response body with the function returned by
+// decoder for a new value of the type v points to. The decoded value is then
+// copied to v of every caller, also if the request failed, e.g. with a
+// TruncatedError. The copy is shallow, so the slices and maps of v are shared
+// and must not be modified.
+//
+// The request runs with a context which carries the values of the context of
+// the first caller but is only cancelled once all callers waiting for its
+// result have given up. A caller whose context is done returns its error
+// right away. The WaitRequest hook of the first caller applies to the
+// request, while the other hooks of every caller are run with the shared
+// result, so that errors are attributed to the groups of all callers. The
+// timeout of g, see bind.WithGroupTimeout, bounds the wait of the caller but
+// not the shared request.
+func (c *Client) GetShared(ctx context.Context, g bind.StatisticGroup, p string, v interface{}, decoder func(interface{}) DecodeFunc) (bind.RequestInfo, error) {
+	u, err := c.URL(p)
+	if err != nil {
+		return bind.RequestInfo{}, err
+	}
+	ctx, cancel, expired := c.groupContext(ctx, g)
+	defer cancel()
+	trace := bind.ContextClientTrace(ctx)
+	if trace != nil && trace.GetStart != nil {
+		ctx = trace.GetStart(ctx, g, u)
+	}
+
+	key := flightKey{url: u, typ: reflect.TypeOf(v).Elem()}
+	f := &c.flights
+	f.mu.Lock()
+	cl, ok := f.calls[key]
+	if !ok {
+		cl = c.startCall(ctx, trace, g, key, decoder)
+	}
+	cl.waiters++
+	f.mu.Unlock()
+
+	var info bind.RequestInfo
+	select {
+	case <-cl.done:
+		reflect.ValueOf(v).Elem().Set(cl.v.Elem())
+		info, err = cl.info, cl.err
+		if cl.decoded && trace != nil && trace.DecodeDone != nil {
+			dctx := ctx
+			if trace.DecodeStart != nil {
+				dctx = trace.DecodeStart(ctx, g)
+			}
+			trace.DecodeDone(dctx, g, cl.decodeInfo, cl.decodeErr)
+		}
+	case <-ctx.Done():
+		f.mu.Lock()
+		cl.waiters--
+		if cl.waiters == 0 {
+			// Nobody is interested in the result anymore, so later
+			// callers have to start a new request.
+			cl.cancel()
+			if f.calls[key] == cl {
+				delete(f.calls, key)
+			}
+		}
+		f.mu.Unlock()
+		info, err = bind.RequestInfo{URL: u}, expired(ctx.Err())
+	}
+	if trace != nil && trace.GetDone != nil {
+		trace.GetDone(ctx, g, info, err)
+	}
+	return info, err
+}
+
+// startCall starts the shared request of key. It is called with the lock of
+// the flights held.
+func (c *Client) startCall(ctx context.Context, trace *bind.ClientTrace, g bind.StatisticGroup, key flightKey, decoder func(interface{}) DecodeFunc) *call {
+	f := &c.flights
+	fctx, cancel := context.WithCancel(detachedContext{ctx})
+	cl := &call{done: make(chan struct{}), cancel: cancel}
+	if f.calls == nil {
+		f.calls = map[flightKey]*call{}
+	}
+	f.calls[key] = cl
+
+	// The decoding is reported to the callers once the request is done.
+	t := &bind.ClientTrace{
+		DecodeDone: func(_ context.Context, _ bind.StatisticGroup, info bind.DecodeInfo, err error) {
+			cl.decoded, cl.decodeInfo, cl.decodeErr = true, info, err
+		},
+	}
+	if trace != nil {
+		t.WaitRequest = trace.WaitRequest
+	}
+	go func() {
+		cl.v = reflect.New(key.typ)
+		cl.info, cl.err = c.get(fctx, t, g, key.url, decoder(cl.v.Interface()))
+		cancel()
+		f.mu.Lock()
+		if f.calls[key] == cl {
+			delete(f.calls, key)
+		}
+		f.mu.Unlock()
+		close(cl.done)
+	}()
+	return cl
+}
+
+// detachedContext carries the values of a context without its deadline and
+// cancellation.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool)         { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}               { return nil }
+func (detachedContext) Err() error                          { return nil }
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
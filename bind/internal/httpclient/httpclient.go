@@ -0,0 +1,688 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package httpclient implements the HTTP plumbing shared by the XML and JSON
+// clients.
+package httpclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/internal/clock"
+)
+
+// Client issues requests against a BIND statistics channel. It is safe for
+// concurrent use by multiple goroutines. Its configuration, including the
+// exported fields, must not be modified once it is in use; state changing
+// between requests lives in synchronized fields.
+type Client struct {
+	base    *url.URL
+	http    *http.Client
+	Options bind.ClientOptions
+	// Now returns the local time used for timing requests. It defaults to
+	// the Now of the Clock of the options.
+	Now func() time.Time
+	// Sleep waits for the delay of a retry and returns early with the error
+	// of ctx once ctx is done. It defaults to waiting for a timer of the
+	// Clock of the options.
+	Sleep func(ctx context.Context, d time.Duration) error
+
+	sem     chan struct{}
+	flights flights
+	// captureMu serializes the writing and rotation of captures.
+	captureMu sync.Mutex
+}
+
+// New returns an initialized Client. Unless an http.Client is given with
+// bind.WithHTTPClient, bind.DefaultHTTPClient adjusted to the options is used.
+// The URL must be an absolute http or https URL.
+func New(rawURL string, opts ...bind.ClientOption) (*Client, error) {
+	base, err := parseBaseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	o := bind.NewClientOptions(opts...)
+	if o.HTTPClient != nil && o.RoundTripper != nil {
+		return nil, fmt.Errorf("options WithHTTPClient and WithRoundTripper are mutually exclusive")
+	}
+	if o.ProxyURL != "" && o.NoProxy {
+		return nil, fmt.Errorf("options WithProxyURL and WithNoProxy are mutually exclusive")
+	}
+	if o.ProxyURL != "" || o.NoProxy {
+		if o.HTTPClient != nil {
+			return nil, fmt.Errorf("proxy options have no effect with WithHTTPClient")
+		}
+		if o.RoundTripper != nil {
+			return nil, fmt.Errorf("proxy options have no effect with WithRoundTripper")
+		}
+	}
+	c := o.HTTPClient
+	if c == nil {
+		if c, err = newHTTPClient(o, base); err != nil {
+			return nil, err
+		}
+	}
+	client := &Client{
+		base:    base,
+		http:    c,
+		Options: o,
+	}
+	if o.MaxInFlight > 0 {
+		client.sem = make(chan struct{}, o.MaxInFlight)
+	}
+	return client, nil
+}
+
+func parseBaseURL(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %q: %s", s, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid URL %q: scheme must be http or https", s)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid URL %q: missing host", s)
+	}
+	return u, nil
+}
+
+func newHTTPClient(o bind.ClientOptions, base *url.URL) (*http.Client, error) {
+	c := bind.DefaultHTTPClient()
+	c.CheckRedirect = checkRedirect(o.MaxRedirects)
+	if o.RoundTripper != nil {
+		c.Transport = o.RoundTripper
+		return c, nil
+	}
+	t, ok := c.Transport.(*http.Transport)
+	if !ok {
+		return c, nil
+	}
+	if o.TLSConfig != nil {
+		t.TLSClientConfig = o.TLSConfig.Clone()
+	}
+	switch {
+	case o.NoProxy:
+		t.Proxy = nil
+	case o.ProxyURL != "":
+		u, err := parseProxyURL(o.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		t.Proxy = http.ProxyURL(u)
+	}
+	if o.DialContext != nil {
+		t.DialContext = o.DialContext
+	}
+	if o.HostOverride != "" {
+		dial, err := overrideHost(t.DialContext, base, o.HostOverride)
+		if err != nil {
+			return nil, err
+		}
+		t.DialContext = dial
+	}
+	return c, nil
+}
+
+// parseProxyURL parses the URL of a proxy. The errors omit the password the
+// URL may contain.
+func parseProxyURL(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return nil, fmt.Errorf("invalid proxy URL: %s", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid proxy URL %q: scheme must be http, https or socks5", u.Redacted())
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: missing host", u.Redacted())
+	}
+	return u, nil
+}
+
+// overrideHost returns a dial function connecting to addr instead of the
+// host of base, and passing other addresses, such as that of a proxy, to
+// dial unchanged. The transport verifies the TLS server name against the
+// host of the request, so the certificate of base is still expected.
+func overrideHost(dial func(context.Context, string, string) (net.Conn, error), base *url.URL, addr string) (func(context.Context, string, string) (net.Conn, error), error) {
+	port := base.Port()
+	if port == "" {
+		port = "80"
+		if base.Scheme == "https" {
+			port = "443"
+		}
+	}
+	target := net.JoinHostPort(base.Hostname(), port)
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(strings.Trim(addr, "[]"), port)
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return nil, fmt.Errorf("invalid host override %q: %s", addr, err)
+	}
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		if strings.EqualFold(address, target) {
+			address = addr
+		}
+		return dial(ctx, network, address)
+	}, nil
+}
+
+// checkRedirect follows up to n redirects and returns the last redirect
+// response afterwards, which is then turned into a bind.RedirectError.
+func checkRedirect(n int) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) > n {
+			return http.ErrUseLastResponse
+		}
+		return nil
+	}
+}
+
+// HTTPClient returns the underlying http.Client.
+func (c *Client) HTTPClient() *http.Client {
+	return c.http
+}
+
+// URL resolves p against the base URL of the client. The path of p is joined
+// with the path of the base URL and its query parameters are merged into the
+// query of the base URL, replacing parameters of the same name. Escaped
+// characters in the path of p, such as "%2F", are preserved.
+func (c *Client) URL(p string) (string, error) {
+	u := *c.base
+	ref, err := url.Parse(p)
+	if err != nil {
+		return "", fmt.Errorf("invalid path %q: %s", p, err)
+	}
+	raw := path.Join(u.EscapedPath(), ref.EscapedPath())
+	if u.Path, err = url.PathUnescape(raw); err != nil {
+		return "", fmt.Errorf("invalid path %q: %s", p, err)
+	}
+	u.RawPath = raw
+	if ref.RawQuery != "" {
+		q := u.Query()
+		for k, v := range ref.Query() {
+			q[k] = v
+		}
+		u.RawQuery = q.Encode()
+	}
+	return u.String(), nil
+}
+
+// JoinPath appends the elements elem, each escaped as a single path segment,
+// to the path of p, which may carry a query string, e.g. that of an endpoint
+// override. The query string is kept.
+func JoinPath(p string, elem ...string) (string, error) {
+	u, err := url.Parse(p)
+	if err != nil {
+		return "", fmt.Errorf("invalid path %q: %s", p, err)
+	}
+	raw := strings.TrimRight(u.EscapedPath(), "/")
+	for _, e := range elem {
+		raw += "/" + url.PathEscape(e)
+	}
+	if u.Path, err = url.PathUnescape(raw); err != nil {
+		return "", fmt.Errorf("invalid path %q: %s", p, err)
+	}
+	u.RawPath = raw
+	return u.String(), nil
+}
+
+// NewRequest returns a GET request for u carrying the configured headers.
+func (c *Client) NewRequest(ctx context.Context, u string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %s", err)
+	}
+	for k, v := range c.Options.Header {
+		req.Header[k] = append([]string(nil), v...)
+	}
+	if host := req.Header.Get("Host"); host != "" {
+		req.Host = host
+	}
+	ua := c.Options.UserAgent
+	if ua == "" {
+		ua = bind.DefaultUserAgent()
+	}
+	req.Header.Set("User-Agent", ua)
+	return req, nil
+}
+
+// DecodeFunc decodes a response body. It is called again with the body of a
+// retry if the response has been truncated, and must then discard the result
+// of the previous call.
+type DecodeFunc func(io.Reader) (bind.DecodeInfo, error)
+
+// Get queries the given path for group g and passes the response body to
+// decode. The hooks of the ClientTrace associated with ctx are run around the
+// request and the decoding of the body. The request is bounded by the timeout
+// of g, see bind.WithGroupTimeout.
+func (c *Client) Get(ctx context.Context, g bind.StatisticGroup, p string, decode DecodeFunc) (bind.RequestInfo, error) {
+	u, err := c.URL(p)
+	if err != nil {
+		return bind.RequestInfo{}, err
+	}
+	ctx, cancel, expired := c.groupContext(ctx, g)
+	defer cancel()
+	info, err := c.get(ctx, bind.ContextClientTrace(ctx), g, u, decode)
+	return info, expired(err)
+}
+
+// groupContext returns a context based on ctx which is bounded by the timeout
+// of group g, and a function wrapping an error into a bind.GroupTimeoutError
+// if the timeout has expired before the deadline of ctx. Retries are only
+// attempted before the deadline of the returned context, so every attempt
+// stays within the timeout.
+func (c *Client) groupContext(ctx context.Context, g bind.StatisticGroup) (context.Context, context.CancelFunc, func(error) error) {
+	d := c.Options.GroupTimeouts[g]
+	if g == "" || d <= 0 {
+		return ctx, func() {}, func(err error) error { return err }
+	}
+	gctx, cancel := context.WithTimeout(ctx, d)
+	return gctx, cancel, func(err error) error {
+		if err == nil || ctx.Err() != nil || gctx.Err() != context.DeadlineExceeded {
+			return err
+		}
+		return &bind.GroupTimeoutError{Group: g, Timeout: d, Err: err}
+	}
+}
+
+// get requests u and decodes the response body, running the hooks of trace
+// for every attempt. A truncated response is retried like a 503 response,
+// see bind.WithRetries.
+func (c *Client) get(ctx context.Context, trace *bind.ClientTrace, g bind.StatisticGroup, u string, decode DecodeFunc) (bind.RequestInfo, error) {
+	for retries := 0; ; retries++ {
+		info, err := c.attempt(ctx, trace, g, u, decode)
+		if !errors.Is(err, bind.ErrTruncatedResponse) || retries >= c.Options.Retries {
+			return info, err
+		}
+		d := bind.DefaultRetryDelay
+		if deadline, ok := ctx.Deadline(); ok && c.now().Add(d).After(deadline) {
+			return info, err
+		}
+		if err := c.sleep(ctx, d); err != nil {
+			return info, err
+		}
+	}
+}
+
+// attempt requests u once, apart from the retries of 503 responses, and
+// decodes the response body.
+func (c *Client) attempt(ctx context.Context, trace *bind.ClientTrace, g bind.StatisticGroup, u string, decode DecodeFunc) (info bind.RequestInfo, err error) {
+	info.URL = u
+	if trace != nil && trace.GetStart != nil {
+		ctx = trace.GetStart(ctx, g, u)
+	}
+	if trace != nil && trace.GetDone != nil {
+		defer func() { trace.GetDone(ctx, g, info, err) }()
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	req, err := c.NewRequest(ctx, u)
+	if err != nil {
+		return info, err
+	}
+
+	var (
+		resp    *http.Response
+		release func()
+	)
+	for retries := 0; ; retries++ {
+		resp, release, err = c.send(ctx, req, trace, &info, g != bind.StatusStats)
+		if err != nil {
+			return info, err
+		}
+		if resp.StatusCode != http.StatusServiceUnavailable || retries >= c.Options.Retries {
+			break
+		}
+		d, ok := RetryAfter(resp.Header.Get("Retry-After"), c.now())
+		if !ok {
+			d = bind.DefaultRetryDelay
+		}
+		if deadline, ok := ctx.Deadline(); ok && c.now().Add(d).After(deadline) {
+			break
+		}
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		release()
+		if err := c.sleep(ctx, d); err != nil {
+			return info, err
+		}
+	}
+	defer release()
+	defer resp.Body.Close()
+
+	info.StatusCode = resp.StatusCode
+	if err := redirectError(u, resp); err != nil {
+		return info, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		serr := &bind.StatusError{URL: u, StatusCode: resp.StatusCode, Status: resp.Status}
+		if resp.StatusCode == http.StatusServiceUnavailable {
+			serr.RetryAfter, _ = RetryAfter(resp.Header.Get("Retry-After"), c.now())
+		}
+		return info, serr
+	}
+
+	var r io.Reader = &contextReader{
+		ctx:    ctx,
+		r:      resp.Body,
+		idle:   c.Options.ReadIdleTimeout,
+		clock:  c.Options.Clock,
+		cancel: cancel,
+	}
+	var capture *captureReader
+	if c.Options.FailureCaptureDir != "" {
+		capture = &captureReader{r: r}
+		r = capture
+	}
+	body := &countingReader{now: c.now, r: r}
+	defer func() { info.Bytes = body.n }()
+
+	dctx := ctx
+	if trace != nil && trace.DecodeStart != nil {
+		dctx = trace.DecodeStart(ctx, g)
+	}
+	start := c.now()
+	di, err := decode(body)
+	if d := c.now().Sub(start) - body.wait; d > 0 {
+		di.Duration = d
+	}
+	if err != nil {
+		err = TruncatedResponse(err, body.n)
+	}
+	var perr *bind.StatusPageError
+	if errors.As(err, &perr) && perr.URL == "" {
+		perr.URL = u
+	}
+	if err != nil && capture != nil {
+		err = c.capture(u, resp.Header.Get("Content-Type"), capture, err)
+	}
+	if trace != nil && trace.DecodeDone != nil {
+		trace.DecodeDone(dctx, g, di, err)
+	}
+	return info, err
+}
+
+// send waits for the hooks of trace and, if limited, a free request slot and
+// sends req. The returned function releases the slot.
+func (c *Client) send(ctx context.Context, req *http.Request, trace *bind.ClientTrace, info *bind.RequestInfo, limited bool) (*http.Response, func(), error) {
+	if trace != nil && trace.WaitRequest != nil {
+		if err := trace.WaitRequest(ctx); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	release := func() {}
+	if c.sem != nil && limited {
+		select {
+		case c.sem <- struct{}{}:
+			release = func() { <-c.sem }
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+
+	info.Sent = c.now()
+	resp, err := c.http.Do(req)
+	if err != nil {
+		release()
+		return nil, nil, fmt.Errorf("error querying stats: %w", err)
+	}
+	info.Received = c.now()
+	return resp, release, nil
+}
+
+// RetryAfter parses the value of a Retry-After header, which is either a
+// number of seconds or an HTTP date, into the delay relative to now. A date in
+// the past results in a delay of zero.
+func RetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.ParseUint(v, 10, 32); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	if d := t.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
+func (c *Client) sleep(ctx context.Context, d time.Duration) error {
+	if c.Sleep != nil {
+		return c.Sleep(ctx, d)
+	}
+	return clock.Sleep(ctx, c.Options.Clock, d)
+}
+
+// TruncatedResponse wraps err, the error of decoding a response after n bytes,
+// into an error matching bind.ErrTruncatedResponse if it reports the end of
+// the response within the document, i.e. io.ErrUnexpectedEOF, which the
+// transport also reports for a body shorter than its Content-Length. Errors
+// of empty responses and errors already matching bind.ErrTruncatedResponse are
+// returned unchanged.
+func TruncatedResponse(err error, n int64) error {
+	if n == 0 || !errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, bind.ErrTruncatedResponse) {
+		return err
+	}
+	return fmt.Errorf("%w after %d bytes: %w", bind.ErrTruncatedResponse, n, err)
+}
+
+// Reset sets the value v points to to its zero value, e.g. to discard the
+// result of a previous call of a DecodeFunc. Other values are left alone.
+func Reset(v interface{}) {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv.Elem().SetZero()
+	}
+}
+
+// IsNotFound reports whether err has been caused by a 404 response.
+func IsNotFound(err error) bool {
+	var serr *bind.StatusError
+	return errors.As(err, &serr) && serr.StatusCode == http.StatusNotFound
+}
+
+// IsStatusPage reports whether err has been caused by an HTML page served
+// instead of statistics, see bind.StatusPageError.
+func IsStatusPage(err error) bool {
+	var perr *bind.StatusPageError
+	return errors.As(err, &perr)
+}
+
+// IsHTML reports whether the response body r, as returned by SkipPreamble,
+// starts like an HTML page. It only peeks at the body.
+func IsHTML(r io.Reader) bool {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		return false
+	}
+	b, _ := br.Peek(512)
+	if len(b) == 0 || b[0] != '<' {
+		return false
+	}
+	b = bytes.ToLower(b)
+	return bytes.Contains(b, []byte("<html")) || bytes.Contains(b, []byte("<!doctype html"))
+}
+
+func (c *Client) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return c.Options.Clock.Now()
+}
+
+// ClockSkew estimates the offset of the server clock, which reported the time
+// current while rendering a response, relative to the local clock. The
+// server is assumed to have rendered the response halfway through the round
+// trip of the request. It returns zero if current is unknown.
+func ClockSkew(current time.Time, info bind.RequestInfo) time.Duration {
+	if current.IsZero() || info.Sent.IsZero() || info.Received.IsZero() {
+		return 0
+	}
+	rtt := info.Received.Sub(info.Sent)
+	return current.Sub(info.Received.Add(-rtt / 2))
+}
+
+// SkipPreamble returns a reader skipping an optional UTF-8 byte order mark
+// and any whitespace at the start of r. Some proxies and capture tools
+// prepend them to documents, which the decoders would reject.
+func SkipPreamble(r io.Reader) io.Reader {
+	br := bufio.NewReader(r)
+	if b, err := br.Peek(3); err == nil && string(b) == "\xef\xbb\xbf" {
+		br.Discard(3)
+	}
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return br
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			br.Discard(1)
+		default:
+			return br
+		}
+	}
+}
+
+// countingReader counts the bytes read and the time spent waiting for them.
+type countingReader struct {
+	r    io.Reader
+	n    int64
+	now  func() time.Time
+	wait time.Duration
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	start := r.now()
+	n, err := r.r.Read(p)
+	r.wait += r.now().Sub(start)
+	r.n += int64(n)
+	return n, err
+}
+
+// DecodeRecorder sums the DecodeInfo of the documents decoded by a Stats call
+// by group, see bind.Statistics.Decode. It is safe for concurrent use.
+type DecodeRecorder struct {
+	mu sync.Mutex
+	m  map[bind.StatisticGroup]bind.DecodeInfo
+}
+
+// Context returns a context based on ctx whose requests are recorded by r.
+func (r *DecodeRecorder) Context(ctx context.Context) context.Context {
+	return bind.WithClientTrace(ctx, &bind.ClientTrace{DecodeDone: r.done})
+}
+
+func (r *DecodeRecorder) done(_ context.Context, g bind.StatisticGroup, info bind.DecodeInfo, _ error) {
+	// Documents fetched for no group, e.g. the status document, are not
+	// recorded.
+	if g == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.m == nil {
+		r.m = map[bind.StatisticGroup]bind.DecodeInfo{}
+	}
+	r.m[g] = r.m[g].Add(info)
+}
+
+// Result returns the recorded DecodeInfo by group, or nil if no document has
+// been decoded.
+func (r *DecodeRecorder) Result() map[bind.StatisticGroup]bind.DecodeInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.m
+}
+
+// contextReader aborts reading once ctx is done, or when a single read blocks
+// for longer than the idle timeout. Cancelling ctx makes the transport unblock
+// a pending read of the response body.
+type contextReader struct {
+	ctx    context.Context
+	r      io.Reader
+	idle   time.Duration
+	cancel context.CancelCauseFunc
+	clock  bind.Clock
+	timer  bind.Timer
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if r.ctx.Err() != nil {
+		return 0, context.Cause(r.ctx)
+	}
+	if r.idle > 0 {
+		if r.timer == nil {
+			r.timer = r.clock.AfterFunc(r.idle, func() { r.cancel(bind.ErrReadIdleTimeout) })
+		} else {
+			r.timer.Reset(r.idle)
+		}
+	}
+	n, err := r.r.Read(p)
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	if err != nil && err != io.EOF && r.ctx.Err() != nil {
+		return n, context.Cause(r.ctx)
+	}
+	return n, err
+}
+
+// redirectError returns a bind.RedirectError if resp is a redirect which has
+// not been followed, or if u has been redirected to an HTML page.
+func redirectError(u string, resp *http.Response) error {
+	switch resp.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		loc, err := resp.Location()
+		if err != nil {
+			return &bind.RedirectError{URL: u, StatusCode: resp.StatusCode}
+		}
+		return &bind.RedirectError{URL: u, Location: loc.String(), StatusCode: resp.StatusCode}
+	}
+	if resp.Request == nil || resp.Request.URL.String() == u {
+		return nil
+	}
+	if mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mt == "text/html" {
+		return &bind.RedirectError{URL: u, Location: resp.Request.URL.String()}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+)
+
+// ZoneLimit enforces the limits of bind.WithMaxZones and
+// bind.WithMaxZonesPerView while a zones document is decoded. A nil ZoneLimit
+// admits every zone.
+type ZoneLimit struct {
+	max, perView int
+	total        int
+	views        map[string]int
+	// Skipped holds the number of zones refused by Admit by view.
+	Skipped map[string]int
+}
+
+// NewZoneLimit returns a ZoneLimit for the limits of o, or nil if there are
+// none.
+func NewZoneLimit(o bind.ClientOptions) *ZoneLimit {
+	if o.MaxZones <= 0 && o.MaxZonesPerView <= 0 {
+		return nil
+	}
+	return &ZoneLimit{max: o.MaxZones, perView: o.MaxZonesPerView, views: map[string]int{}}
+}
+
+// Admit reports whether another zone of view may be decoded, and counts it as
+// decoded or skipped.
+func (l *ZoneLimit) Admit(view string) bool {
+	if l == nil {
+		return true
+	}
+	if (l.max > 0 && l.total >= l.max) || (l.perView > 0 && l.views[view] >= l.perView) {
+		if l.Skipped == nil {
+			l.Skipped = map[string]int{}
+		}
+		l.Skipped[view]++
+		return false
+	}
+	l.total++
+	l.views[view]++
+	return true
+}
+
+// Truncated returns the error for the zones refused by Admit, given the
+// number of zones decoded and the error cutting the document short, if any. It
+// returns nil if the document is complete.
+func (l *ZoneLimit) Truncated(zones int, cut error) error {
+	var skipped map[string]int
+	if l != nil {
+		skipped = l.Skipped
+	}
+	if cut == nil && skipped == nil {
+		return nil
+	}
+	if cut == nil {
+		cut = bind.ErrTooManyZones
+	}
+	return &bind.TruncatedError{Zones: zones, Skipped: skipped, Err: cut}
+}
+
+// GetZones fetches the zones document at path p with get. With bind.WithViews,
+// get is called with the path of the document of every selected view below p
+// instead, see ViewPath. If the server has no document of a view, get is
+// called once more with p, and the results of the views must be discarded. It
+// returns the RequestInfo of the first document fetched, how the zones have
+// been fetched, and the error of the last call of get.
+func (c *Client) GetZones(p string, get func(p string) (bind.RequestInfo, error)) (bind.RequestInfo, bind.ZonesStrategy, error) {
+	if c.Options.Views == nil {
+		info, err := get(p)
+		return info, "", err
+	}
+	var first bind.RequestInfo
+	for i, v := range c.Options.Views {
+		info, err := get(ViewPath(p, v))
+		if IsNotFound(err) {
+			info, err := get(p)
+			return info, bind.ZonesFiltered, err
+		}
+		if i == 0 {
+			first = info
+		}
+		if err != nil {
+			return first, bind.ZonesPerView, err
+		}
+	}
+	return first, bind.ZonesPerView, nil
+}
+
+// ViewPath returns the path of the zones document of view below the path p of
+// the zones document, keeping the query of p. The name of the view is escaped.
+func ViewPath(p, view string) string {
+	u, err := url.Parse(p)
+	if err != nil {
+		// The path is reported as invalid once it is requested.
+		return p
+	}
+	u.RawPath = strings.TrimSuffix(u.EscapedPath(), "/") + "/" + url.PathEscape(view)
+	u.Path, _ = url.PathUnescape(u.RawPath)
+	return u.String()
+}
@@ -14,20 +14,26 @@
 package json
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
-	"net/url"
-	"path"
+	"sort"
 	"strconv"
 	"time"
 
 	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/internal/httpclient"
 )
 
 const (
 	// ServerPath is the HTTP path of the JSON v1 server resource.
 	ServerPath = "/json/v1/server"
+	// StatusPath is the HTTP path of the JSON v1 status resource.
+	StatusPath = "/json/v1/status"
 	// TasksPath is the HTTP path of the JSON v1 tasks resource.
 	TasksPath = "/json/v1/tasks"
 	// ZonesPath is the HTTP path of the JSON v1 zones resource.
@@ -37,31 +43,171 @@ const (
 type Gauges map[string]uint64
 type Counters map[string]uint64
 
+// UnmarshalJSON decodes the gauges of an object, see Counters.UnmarshalJSON.
+func (g *Gauges) UnmarshalJSON(b []byte) error {
+	return unmarshalCounters(b, (*map[string]uint64)(g))
+}
+
+// UnmarshalJSON decodes the counters of an object. Besides numbers, it
+// accepts strings holding numbers, as written by some converters of the
+// documents, see bind.ParseCounter.
+func (c *Counters) UnmarshalJSON(b []byte) error {
+	return unmarshalCounters(b, (*map[string]uint64)(c))
+}
+
+func unmarshalCounters(b []byte, m *map[string]uint64) error {
+	var plain map[string]uint64
+	if err := json.Unmarshal(b, &plain); err == nil {
+		*m = plain
+		return nil
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*m = make(map[string]uint64, len(raw))
+	for k, v := range raw {
+		text := string(v)
+		if len(v) > 0 && v[0] == '"' {
+			if err := json.Unmarshal(v, &text); err != nil {
+				return err
+			}
+		}
+		n, err := bind.ParseCounter(text)
+		if err != nil {
+			return fmt.Errorf("counter %q: %w", k, err)
+		}
+		(*m)[k] = n
+	}
+	return nil
+}
+
 type Statistics struct {
-	BootTime   time.Time `json:"boot-time"`
-	ConfigTime time.Time `json:"config-time"`
-	Opcodes    Counters  `json:"opcodes"`
-	QTypes     Counters  `json:"qtypes"`
-	NSStats    Counters  `json:"nsstats"`
-	Rcodes     Counters  `json:"rcodes"`
-	ZoneStats  Counters  `json:"zonestats"`
-	Views      map[string]struct {
-		Resolver struct {
-			Cache  Gauges   `json:"cache"`
-			Qtypes Counters `json:"qtypes"`
-			Stats  Counters `json:"stats"`
-		} `json:"resolver"`
-	} `json:"views"`
+	JSONStatsVersion string          `json:"json-stats-version"`
+	Version          string          `json:"version"`
+	BootTime         time.Time       `json:"boot-time"`
+	ConfigTime       time.Time       `json:"config-time"`
+	CurrentTime      time.Time       `json:"current-time"`
+	Opcodes          Counters        `json:"opcodes"`
+	QTypes           Counters        `json:"qtypes"`
+	NSStats          Counters        `json:"nsstats"`
+	Rcodes           Counters        `json:"rcodes"`
+	ZoneStats        Counters        `json:"zonestats"`
+	Views            map[string]View `json:"views"`
+	Memory           Memory          `json:"memory"`
+	// Extra holds the unknown counter sections, see bind.Extra.
+	Extra bind.Extra `json:"-"`
+	// Extensions holds the decoded sections, see
+	// bind.WithJSONSectionDecoder.
+	Extensions map[string]any `json:"-"`
+}
+
+func (s *Statistics) UnmarshalJSON(b []byte) error {
+	type statistics Statistics
+	if err := json.Unmarshal(b, (*statistics)(s)); err != nil {
+		return err
+	}
+	var err error
+	s.Extra, err = extraCounters(b, "")
+	return err
+}
+
+// Memory is the memory section of the server document, whose summary values
+// are keyed like the fields of bind.MemorySummary.
+type Memory struct {
+	bind.MemorySummary
+	Contexts []MemoryContext `json:"contexts"`
+}
+
+type MemoryContext struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	References uint64 `json:"references"`
+	Total      uint64 `json:"total"`
+	InUse      uint64 `json:"inuse"`
+	MaxInUse   uint64 `json:"maxinuse"`
+}
+
+type View struct {
+	Resolver Resolver `json:"resolver"`
+}
+
+type Resolver struct {
+	Cache      Gauges   `json:"cache"`
+	Qtypes     Counters `json:"qtypes"`
+	Stats      Counters `json:"stats"`
+	CacheStats Counters `json:"cachestats"`
+	// Extra holds the unknown counter sections, see bind.Extra.
+	Extra bind.Extra `json:"-"`
+}
+
+func (r *Resolver) UnmarshalJSON(b []byte) error {
+	type resolver Resolver
+	if err := json.Unmarshal(b, (*resolver)(r)); err != nil {
+		return err
+	}
+	var err error
+	r.Extra, err = extraCounters(b, "views/*/resolver")
+	return err
 }
 
 type ZoneStatistics struct {
-	Views map[string]struct {
-		Zones []struct {
-			Name   string `json:"name"`
-			Class  string `json:"class"`
-			Serial uint32 `json:"serial"` // RFC 1035 specifies SOA serial number as uint32
-		} `json:"zones"`
+	JSONStatsVersion string `json:"json-stats-version"`
+	Views            map[string]struct {
+		Zones []Zone `json:"zones"`
 	} `json:"views"`
+	// Extensions holds the decoded sections, see
+	// bind.WithJSONSectionDecoder.
+	Extensions map[string]any `json:"-"`
+}
+
+type Zone struct {
+	Name          string   `json:"name"`
+	Class         string   `json:"class"`
+	Serial        uint32   `json:"serial"` // RFC 1035 specifies SOA serial number as uint32
+	ZoneStats     Counters `json:"zonestats"`
+	DNSSECSign    Counters `json:"dnssec-sign"`
+	DNSSECRefresh Counters `json:"dnssec-refresh"`
+	Rcodes        Counters `json:"rcodes"`
+	QTypes        Counters `json:"qtypes"`
+	// Extra holds the unknown counter sections, see bind.Extra.
+	Extra bind.Extra `json:"-"`
+}
+
+func (z *Zone) UnmarshalJSON(b []byte) error {
+	type zone Zone
+	if err := json.Unmarshal(b, (*zone)(z)); err != nil {
+		return err
+	}
+	var err error
+	z.Extra, err = extraCounters(b, "views/*/zones")
+	return err
+}
+
+// extraCounters returns the members of the object b which are unknown to the
+// package at path, see knownFields, and hold an object of counters. Other
+// unknown members are ignored.
+func extraCounters(b []byte, path string) (bind.Extra, error) {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	var e bind.Extra
+	for k, raw := range m {
+		p := k
+		if path != "" {
+			p = path + "/" + k
+		}
+		if _, ok := knownFields[p]; ok {
+			continue
+		}
+		var cs Counters
+		if err := json.Unmarshal(raw, &cs); err != nil {
+			continue
+		}
+		e.Add(k, convertCounters(cs))
+	}
+	return e, nil
 }
 
 type TaskStatistics struct {
@@ -69,127 +215,660 @@ type TaskStatistics struct {
 		TasksRunning  uint64 `json:"tasks-running"`
 		WorkerThreads uint64 `json:"worker-threads"`
 	} `json:"taskmgr"`
+	// Extensions holds the decoded sections, see
+	// bind.WithJSONSectionDecoder.
+	Extensions map[string]any `json:"-"`
 }
 
 // Client implements bind.Client and can be used to query a BIND JSON v1 API.
+// A Client is safe for concurrent use by multiple goroutines.
 type Client struct {
-	url  string
-	http *http.Client
+	client *httpclient.Client
+	err    error
+}
+
+// New returns a Client querying the statistics channel at url, which must be
+// an absolute http or https URL.
+func New(url string, opts ...bind.ClientOption) (*Client, error) {
+	c, err := httpclient.New(url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{client: c}, nil
 }
 
-// NewClient returns an initialized Client.
-func NewClient(url string, c *http.Client) *Client {
-	return &Client{
-		url:  url,
-		http: c,
+// NewClient returns an initialized Client. If c is nil, an http.Client based
+// on bind.DefaultHTTPClient is used. Contrary to New, invalid options are
+// reported by Stats and Get.
+func NewClient(url string, c *http.Client, opts ...bind.ClientOption) *Client {
+	if c != nil {
+		opts = append([]bind.ClientOption{bind.WithHTTPClient(c)}, opts...)
+	}
+	client, err := New(url, opts...)
+	if err != nil {
+		return &Client{err: err}
 	}
+	return client
 }
 
 // Get queries the given path and stores the result in the value pointed to by
 // v. The endpoint must return a valid JSON representation which can be
 // unmarshaled into the provided value.
-func (c *Client) Get(p string, v interface{}) error {
-	u, err := url.Parse(c.url)
-	if err != nil {
-		return fmt.Errorf("invalid URL %q: %s", c.url, err)
+func (c *Client) Get(ctx context.Context, p string, v interface{}) error {
+	if c.err != nil {
+		return c.err
 	}
-	u.Path = path.Join(u.Path, p)
+	_, err := c.client.Get(ctx, "", p, c.decoder(v))
+	return err
+}
 
-	resp, err := c.http.Get(u.String())
-	if err != nil {
-		return fmt.Errorf("error querying stats: %s", err)
+// get queries the document of group g, which is expected at the default path p
+// unless overridden by the client options.
+func (c *Client) get(ctx context.Context, g bind.StatisticGroup, p string, v interface{}) (bind.RequestInfo, error) {
+	return c.getPath(ctx, g, c.client.Options.Endpoint(g, p), v)
+}
+
+// getPath queries the document of group g at path p.
+func (c *Client) getPath(ctx context.Context, g bind.StatisticGroup, p string, v interface{}) (bind.RequestInfo, error) {
+	if c.client.Options.Coalesce {
+		return c.client.GetShared(ctx, g, p, v, c.decoder)
+	}
+	return c.client.Get(ctx, g, p, c.decoder(v))
+}
+
+func (c *Client) decoder(v interface{}) httpclient.DecodeFunc {
+	called := false
+	return func(r io.Reader) (bind.DecodeInfo, error) {
+		if called {
+			// The response of a retry replaces the truncated one.
+			httpclient.Reset(v)
+		}
+		called = true
+		r = httpclient.SkipPreamble(r)
+		if httpclient.IsHTML(r) {
+			return bind.DecodeInfo{}, c.statusPageError()
+		}
+		o := c.client.Options
+		if o.StrictDecoding || o.JSONSectionDecoders != nil {
+			b, err := io.ReadAll(r)
+			if err != nil {
+				return bind.DecodeInfo{}, fmt.Errorf("failed to read JSON response: %w", err)
+			}
+			if o.StrictDecoding {
+				if err := checkStrict(bytes.NewReader(b)); err != nil {
+					return bind.DecodeInfo{}, fmt.Errorf("failed to unmarshal JSON response: %w", err)
+				}
+			}
+			if o.JSONSectionDecoders != nil {
+				ext, err := decodeSections(bytes.NewReader(b), o.JSONSectionDecoders)
+				if err != nil {
+					return bind.DecodeInfo{}, fmt.Errorf("failed to decode JSON section: %w", err)
+				}
+				switch v := v.(type) {
+				case *Statistics:
+					v.Extensions = ext
+				case *ZoneStatistics:
+					v.Extensions = ext
+				case *TaskStatistics:
+					v.Extensions = ext
+				}
+			}
+			r = bytes.NewReader(b)
+		}
+		limit := httpclient.NewZoneLimit(o)
+		if zs, ok := v.(*ZoneStatistics); ok && (o.ExcludedZones != nil || o.PartialZones || limit != nil) {
+			d := json.NewDecoder(r)
+			err := httpclient.TruncatedResponse(decodeZones(d, zs, o, limit), d.InputOffset())
+			if err != nil && !(o.PartialZones && (errors.Is(err, context.DeadlineExceeded) || errors.Is(err, bind.ErrTruncatedResponse))) {
+				return bind.DecodeInfo{}, fmt.Errorf("failed to unmarshal JSON response: %w", err)
+			}
+			info := decodeInfo(v)
+			return info, limit.Truncated(info.Zones, err)
+		}
+		if err := json.NewDecoder(r).Decode(v); err != nil {
+			return bind.DecodeInfo{}, fmt.Errorf("failed to unmarshal JSON response: %w", err)
+		}
+		return decodeInfo(v), nil
 	}
-	defer resp.Body.Close()
+}
+
+// statusPageError returns the error of an HTML page served instead of a
+// document, which lists the paths queried by c.
+func (c *Client) statusPageError() error {
+	o := c.client.Options
+	return &bind.StatusPageError{Paths: map[bind.Format][]string{
+		bind.FormatJSONv1: {
+			o.Endpoint(bind.ServerStats, ServerPath),
+			o.Endpoint(bind.ViewStats, ZonesPath),
+			o.Endpoint(bind.TaskStats, TasksPath),
+			o.Endpoint(bind.StatusStats, StatusPath),
+		},
+	}}
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status for %q: %s", u, resp.Status)
+// Stats implements bind.Stats.
+func (c *Client) Stats(ctx context.Context, groups ...bind.StatisticGroup) (bind.Statistics, error) {
+	if c.err != nil {
+		return bind.Statistics{}, c.err
 	}
+	return c.stats(ctx, groups)
+}
 
-	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
-		return fmt.Errorf("failed to unmarshal JSON response: %s", err)
+func (c *Client) stats(ctx context.Context, groups []bind.StatisticGroup) (bind.Statistics, error) {
+	var rec httpclient.DecodeRecorder
+	s, err := c.fetch(rec.Context(ctx), groups)
+	if c.client.Options.Coalesce {
+		// The decoded documents are shared with concurrent calls.
+		s = s.Clone()
+	}
+	var truncated *bind.TruncatedError
+	if err == nil || errors.As(err, &truncated) {
+		// Truncated zones are returned with the options applied like
+		// complete ones.
+		if perr := c.postprocess(&s, groups); perr != nil {
+			err = perr
+		}
+	}
+	s.Decode = rec.Result()
+	if err == nil {
+		err = c.client.Options.CheckWarnings(s.Warnings)
 	}
+	return s, err
+}
 
+// postprocess applies the options adjusting the counters of s fetched for
+// groups.
+func (c *Client) postprocess(s *bind.Statistics, groups []bind.StatisticGroup) error {
+	if c.client.Options.ZeroFill {
+		for _, g := range groups {
+			if g == bind.ServerStats || g == bind.ViewStats {
+				if err := bind.FillZeroCounters(s); err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+	if keep := c.client.Options.LongTailKeep; keep != nil {
+		bind.AggregateLongTail(s, keep)
+	}
 	return nil
 }
 
-// Stats implements bind.Stats.
-func (c *Client) Stats(groups ...bind.StatisticGroup) (bind.Statistics, error) {
+func (c *Client) fetch(ctx context.Context, groups []bind.StatisticGroup) (bind.Statistics, error) {
 	s := bind.Statistics{}
 	m := map[bind.StatisticGroup]bool{}
 	for _, g := range groups {
 		m[g] = true
 	}
 
+	s.Source.Format = bind.FormatJSONv1
+	if m[bind.StatusStats] && !m[bind.ServerStats] && !m[bind.ViewStats] {
+		// The server document covers the status document.
+		if err := c.fetchStatus(ctx, &s); err != nil {
+			return s, err
+		}
+		if !m[bind.TaskStats] {
+			return s, nil
+		}
+	}
 	if m[bind.ServerStats] || m[bind.ViewStats] {
 		var stats Statistics
-		if err := c.Get(ServerPath, &stats); err != nil {
+		info, err := c.get(ctx, bind.ServerStats, ServerPath, &stats)
+		if err != nil {
 			return s, err
 		}
 
+		s.Source.SchemaVersion = stats.JSONStatsVersion
+		s.Source.BINDVersion = stats.Version
+		s.Source.FetchTime = info.Received
+
 		s.Server.BootTime = stats.BootTime
 		s.Server.ConfigTime = stats.ConfigTime
+		s.Server.CurrentTime = stats.CurrentTime
+		s.ClockSkew = httpclient.ClockSkew(stats.CurrentTime, info)
+		s.Server.Extra = stats.Extra
+		s.AddExtensions(stats.Extensions)
 
-		for k, val := range stats.Opcodes {
+		for _, k := range sortedKeys(stats.Opcodes) {
+			val := stats.Opcodes[k]
 			s.Server.IncomingRequests = append(s.Server.IncomingRequests, bind.Counter{Name: k, Counter: val})
 		}
-		for k, val := range stats.QTypes {
+		for _, k := range sortedKeys(stats.QTypes) {
+			val := stats.QTypes[k]
 			s.Server.IncomingQueries = append(s.Server.IncomingQueries, bind.Counter{Name: k, Counter: val})
 		}
-		for k, val := range stats.NSStats {
+		for _, k := range sortedKeys(stats.NSStats) {
+			val := stats.NSStats[k]
 			s.Server.NameServerStats = append(s.Server.NameServerStats, bind.Counter{Name: k, Counter: val})
 		}
-		for k, val := range stats.Rcodes {
-			s.Server.ServerRcodes = append(s.Server.ServerRcodes, bind.Counter{Name: k, Counter: val})
+		for _, k := range sortedKeys(stats.Rcodes) {
+			val := stats.Rcodes[k]
+			s.Server.ServerRcodes = append(s.Server.ServerRcodes, bind.Counter{Name: bind.NormalizeRcodeName(k), Counter: val})
 		}
-		for k, val := range stats.ZoneStats {
-			s.Server.ZoneStatistics = append(s.Server.ZoneStatistics, bind.Counter{Name: k, Counter: val})
+		for _, k := range sortedKeys(stats.ZoneStats) {
+			val := stats.ZoneStats[k]
+			s.Server.ZoneMaintenance = append(s.Server.ZoneMaintenance, bind.Counter{Name: k, Counter: val})
 		}
+		s.Server.ZoneStatistics = s.Server.ZoneMaintenance
 
-		for name, view := range stats.Views {
-			v := bind.View{Name: name}
-			for k, val := range view.Resolver.Cache {
-				v.Cache = append(v.Cache, bind.Gauge{Name: k, Gauge: val})
-			}
-			for k, val := range view.Resolver.Qtypes {
-				v.ResolverQueries = append(v.ResolverQueries, bind.Counter{Name: k, Counter: val})
-			}
-			for k, val := range view.Resolver.Stats {
-				v.ResolverStats = append(v.ResolverStats, bind.Counter{Name: k, Counter: val})
-			}
+		for _, name := range sortedKeys(stats.Views) {
+			view := stats.Views[name]
+			v, ws := convertView(name, view)
+			s.AddWarnings(c.client.Options.MaxWarnings, ws...)
 			s.Views = append(s.Views, v)
 		}
+		s.Memory.Summary = stats.Memory.MemorySummary
+		for _, c := range stats.Memory.Contexts {
+			s.Memory.Contexts = append(s.Memory.Contexts, bind.MemoryContext(c))
+		}
 	}
 
 	var zonestats ZoneStatistics
-	if err := c.Get(ZonesPath, &zonestats); err != nil {
+	info, strategy, err := c.getZones(ctx, &zonestats)
+	var truncated *bind.TruncatedError
+	if err != nil && !errors.As(err, &truncated) {
 		return s, err
 	}
+	if s.Source.SchemaVersion == "" {
+		s.Source.SchemaVersion = zonestats.JSONStatsVersion
+	}
+	s.Source.ZonesStrategy = strategy
+	s.AddExtensions(zonestats.Extensions)
+	if s.Source.FetchTime.IsZero() {
+		s.Source.FetchTime = info.Received
+	}
 
-	for name, view := range zonestats.Views {
+	for _, name := range sortedKeys(zonestats.Views) {
+		if !c.client.Options.IncludesView(name) {
+			continue
+		}
+		view := zonestats.Views[name]
 		v := bind.ZoneView{
 			Name: name,
 		}
 		for _, zone := range view.Zones {
-			if zone.Class != "IN" {
-				continue
+			if zone.QTypes != nil {
+				v.QueriesByClass.Add(zone.Class, convertCounters(zone.QTypes))
 			}
-			z := bind.ZoneCounter{
-				Name:   zone.Name,
-				Serial: strconv.FormatUint(uint64(zone.Serial), 10),
+			if zone.Class == bind.ClassIN {
+				v.ZoneData = append(v.ZoneData, convertZone(zone))
 			}
-			v.ZoneData = append(v.ZoneData, z)
 		}
 		s.ZoneViews = append(s.ZoneViews, v)
 	}
-
+	s.AddWarnings(c.client.Options.MaxWarnings, bind.Validate(s)...)
 	if m[bind.TaskStats] {
 		var taskstats TaskStatistics
-		if err := c.Get(TasksPath, &taskstats); err != nil {
-			return s, err
+		if _, terr := c.get(ctx, bind.TaskStats, TasksPath, &taskstats); terr == nil {
+			s.TaskManager.ThreadModel.TasksRunning = taskstats.TaskMgr.TasksRunning
+			s.TaskManager.ThreadModel.WorkerThreads = taskstats.TaskMgr.WorkerThreads
+			s.AddExtensions(taskstats.Extensions)
+		} else if !c.missing(ctx, bind.TaskStats, terr, &s) {
+			if truncated == nil {
+				return s, terr
+			}
+			// The truncated zones are returned along with the error of
+			// the tasks.
+			err = errors.Join(err, terr)
 		}
-		s.TaskManager.ThreadModel.TasksRunning = taskstats.TaskMgr.TasksRunning
-		s.TaskManager.ThreadModel.WorkerThreads = taskstats.TaskMgr.WorkerThreads
 	}
 
+	if truncated != nil {
+		return s, err
+	}
 	return s, nil
 }
+
+// getZones fetches the zones document into zs, or the zones documents of the
+// views selected by bind.WithViews.
+func (c *Client) getZones(ctx context.Context, zs *ZoneStatistics) (bind.RequestInfo, bind.ZonesStrategy, error) {
+	full := c.client.Options.Endpoint(bind.ViewStats, ZonesPath)
+	return c.client.GetZones(full, func(p string) (bind.RequestInfo, error) {
+		if p == full {
+			*zs = ZoneStatistics{}
+			return c.getPath(ctx, bind.ViewStats, p, zs)
+		}
+		var view ZoneStatistics
+		info, err := c.getPath(ctx, bind.ViewStats, p, &view)
+		zs.merge(view)
+		return info, err
+	})
+}
+
+// merge adds the zones document o of a single view to zs. The views of o are
+// copied to a map of zs, as decoded documents may be shared, see
+// bind.WithCoalescing.
+func (zs *ZoneStatistics) merge(o ZoneStatistics) {
+	if zs.JSONStatsVersion == "" {
+		zs.JSONStatsVersion = o.JSONStatsVersion
+	}
+	for name, v := range o.Views {
+		if zs.Views == nil {
+			zs.Views = make(map[string]struct {
+				Zones []Zone `json:"zones"`
+			})
+		}
+		zs.Views[name] = v
+	}
+	for k, v := range o.Extensions {
+		if zs.Extensions == nil {
+			zs.Extensions = map[string]any{}
+		}
+		zs.Extensions[k] = v
+	}
+}
+
+// fetchStatus fetches the status document into s.
+func (c *Client) fetchStatus(ctx context.Context, s *bind.Statistics) error {
+	var status Statistics
+	info, err := c.get(ctx, bind.StatusStats, StatusPath, &status)
+	if err != nil {
+		return err
+	}
+	s.Source.SchemaVersion = status.JSONStatsVersion
+	s.Source.BINDVersion = status.Version
+	s.Source.FetchTime = info.Received
+	s.Server.BootTime = status.BootTime
+	s.Server.ConfigTime = status.ConfigTime
+	s.Server.CurrentTime = status.CurrentTime
+	s.ClockSkew = httpclient.ClockSkew(status.CurrentTime, info)
+	return nil
+}
+
+// missing reports whether err has been caused by a missing document of group
+// g which is optional on the server version, and records g as missing in s.
+// The version is taken from the status document unless already known.
+func (c *Client) missing(ctx context.Context, g bind.StatisticGroup, err error, s *bind.Statistics) bool {
+	if !httpclient.IsNotFound(err) {
+		return false
+	}
+	if s.Source.BINDVersion == "" {
+		var status Statistics
+		if _, err := c.client.Get(ctx, "", c.client.Options.Endpoint(bind.StatusStats, StatusPath), c.decoder(&status)); err != nil {
+			return false
+		}
+		s.Source.BINDVersion = status.Version
+	}
+	if !bind.GroupOptional(g, s.Source.BINDVersion) {
+		return false
+	}
+	s.MissingGroups = append(s.MissingGroups, g)
+	return true
+}
+
+// GetView returns the statistics of a single view. BIND has no resource for a
+// single view, so the view is taken from the server document.
+func (c *Client) GetView(ctx context.Context, view string) (bind.View, error) {
+	if c.err != nil {
+		return bind.View{}, c.err
+	}
+	var stats Statistics
+	if _, err := c.get(ctx, bind.ServerStats, ServerPath, &stats); err != nil {
+		return bind.View{}, err
+	}
+	v, ok := stats.Views[view]
+	if !ok {
+		return bind.View{}, fmt.Errorf("%w: %q", bind.ErrViewNotFound, view)
+	}
+	bv, _ := convertView(view, v)
+	return bv, nil
+}
+
+// GetZone returns the statistics of a single zone of class IN in view, which
+// may be named with or without trailing dot. The JSON channel has no resource
+// for a single zone, so unlike the XML client this fetches the complete zones
+// document.
+func (c *Client) GetZone(ctx context.Context, view, zone string) (bind.ZoneCounter, error) {
+	if c.err != nil {
+		return bind.ZoneCounter{}, c.err
+	}
+	var zonestats ZoneStatistics
+	if _, err := c.get(ctx, bind.ViewStats, ZonesPath, &zonestats); err != nil {
+		return bind.ZoneCounter{}, err
+	}
+	for _, z := range zonestats.Views[view].Zones {
+		if bind.ZoneKey(z.Name) == bind.ZoneKey(zone) && z.Class == "IN" {
+			return convertZone(z), nil
+		}
+	}
+	return bind.ZoneCounter{}, fmt.Errorf("%w: %q in view %q", bind.ErrZoneNotFound, zone, view)
+}
+
+// convertView converts the view name. Resolver statistics which do not
+// describe a histogram of query round-trip times are reported as warnings and
+// leave the QueryRTT of the view empty.
+func convertView(name string, view View) (bind.View, []bind.Warning) {
+	v := bind.View{Name: name, Extra: view.Resolver.Extra}
+	for _, k := range sortedKeys(view.Resolver.Cache) {
+		val := view.Resolver.Cache[k]
+		v.Cache = append(v.Cache, bind.Gauge{Name: k, Gauge: val})
+	}
+	for _, k := range sortedKeys(view.Resolver.Qtypes) {
+		val := view.Resolver.Qtypes[k]
+		v.ResolverQueries = append(v.ResolverQueries, bind.Counter{Name: k, Counter: val})
+	}
+	for _, k := range sortedKeys(view.Resolver.Stats) {
+		val := view.Resolver.Stats[k]
+		if bind.ResolverGaugeStat(k) {
+			v.ResolverGauges = append(v.ResolverGauges, bind.Gauge{Name: k, Gauge: val})
+		} else {
+			v.ResolverStats = append(v.ResolverStats, bind.Counter{Name: k, Counter: val})
+		}
+	}
+	for _, k := range sortedKeys(view.Resolver.CacheStats) {
+		val := view.Resolver.CacheStats[k]
+		switch {
+		case bind.CacheMemoryStat(k):
+			v.CacheMemory = append(v.CacheMemory, bind.Gauge{Name: k, Gauge: val})
+		case bind.CacheCounterStat(k):
+			v.CacheStats = append(v.CacheStats, bind.Counter{Name: k, Counter: val})
+		}
+	}
+	var err error
+	if v.QueryRTT, err = bind.QueryRTTHistogram(v.ResolverStats); err != nil {
+		return v, []bind.Warning{{
+			Code:    bind.WarnInvalidHistogram,
+			Message: fmt.Sprintf("invalid resolver statistics of view %q: %s", v.Name, err),
+		}}
+	}
+	return v, nil
+}
+
+// convertCounters returns the counters of cs sorted by name.
+func convertCounters(cs Counters) []bind.Counter {
+	out := make([]bind.Counter, 0, len(cs))
+	for _, k := range sortedKeys(cs) {
+		out = append(out, bind.Counter{Name: k, Counter: cs[k]})
+	}
+	return out
+}
+
+// sortedKeys returns the keys of m in ascending order. The client converts
+// the objects of the documents in this order, as JSON objects have none, see
+// bind.Statistics.
+func sortedKeys[M ~map[string]V, V any](m M) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func convertZone(zone Zone) bind.ZoneCounter {
+	z := bind.ZoneCounter{
+		Serial: strconv.FormatUint(uint64(zone.Serial), 10),
+		Extra:  zone.Extra,
+	}
+	z.SetName(zone.Name)
+	for _, k := range sortedKeys(zone.ZoneStats) {
+		val := zone.ZoneStats[k]
+		z.ZoneStats = append(z.ZoneStats, bind.Counter{Name: k, Counter: val})
+	}
+	for _, k := range sortedKeys(zone.DNSSECSign) {
+		val := zone.DNSSECSign[k]
+		z.DNSSECSignStats = append(z.DNSSECSignStats, bind.Counter{Name: k, Counter: val})
+	}
+	for _, k := range sortedKeys(zone.DNSSECRefresh) {
+		val := zone.DNSSECRefresh[k]
+		z.DNSSECRefreshStats = append(z.DNSSECRefreshStats, bind.Counter{Name: k, Counter: val})
+	}
+	for _, k := range sortedKeys(zone.Rcodes) {
+		val := zone.Rcodes[k]
+		z.QueryResults = append(z.QueryResults, bind.Counter{Name: k, Counter: val})
+	}
+	for _, k := range sortedKeys(zone.QTypes) {
+		val := zone.QTypes[k]
+		z.IncomingQueries = append(z.IncomingQueries, bind.Counter{Name: k, Counter: val})
+	}
+	return z
+}
+
+// decodeZones decodes the zones document from dec into zs one zone at a time,
+// omitting the zones excluded by o or refused by limit. The zones decoded
+// before an error remain in zs. Excluded zones are kept as raw messages until
+// their name and class have been decoded, so that their counters are never
+// decoded.
+func decodeZones(dec *json.Decoder, zs *ZoneStatistics, o bind.ClientOptions, limit *httpclient.ZoneLimit) error {
+	return decodeObject(dec, func(key string) error {
+		switch key {
+		case "json-stats-version":
+			return dec.Decode(&zs.JSONStatsVersion)
+		case "views":
+			zs.Views = map[string]struct {
+				Zones []Zone `json:"zones"`
+			}{}
+			return decodeObject(dec, func(name string) error {
+				view := zs.Views[name]
+				zs.Views[name] = view
+				return decodeObject(dec, func(key string) error {
+					if key != "zones" {
+						return dec.Decode(&json.RawMessage{})
+					}
+					return decodeArray(dec, func() error {
+						z, err := decodeZone(dec, o, func() bool { return limit.Admit(name) })
+						if err != nil || z == nil {
+							return err
+						}
+						view.Zones = append(view.Zones, *z)
+						zs.Views[name] = view
+						return nil
+					})
+				})
+			})
+		}
+		return dec.Decode(&json.RawMessage{})
+	})
+}
+
+// decodeZone decodes the next zone of dec, or returns nil if it is excluded by
+// o or not admitted.
+func decodeZone(dec *json.Decoder, o bind.ClientOptions, admit func() bool) (*Zone, error) {
+	var z Zone
+	if o.ExcludedZones == nil {
+		if !admit() {
+			return nil, dec.Decode(&json.RawMessage{})
+		}
+		if err := dec.Decode(&z); err != nil {
+			return nil, err
+		}
+		return &z, nil
+	}
+	var m json.RawMessage
+	if err := dec.Decode(&m); err != nil {
+		return nil, err
+	}
+	var id struct {
+		Name  string `json:"name"`
+		Class string `json:"class"`
+	}
+	if err := json.Unmarshal(m, &id); err != nil {
+		return nil, err
+	}
+	if o.ExcludesZone(id.Name, id.Class) || !admit() {
+		return nil, nil
+	}
+	if err := json.Unmarshal(m, &z); err != nil {
+		return nil, err
+	}
+	return &z, nil
+}
+
+// decodeObject reads an object from dec, calling member to decode the value of
+// every key.
+func decodeObject(dec *json.Decoder, member func(key string) error) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := t.(string)
+		if !ok {
+			return fmt.Errorf("unexpected object key %v", t)
+		}
+		if err := member(key); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+// decodeArray reads an array from dec, calling elem to decode every element.
+func decodeArray(dec *json.Decoder, elem func() error) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+	for dec.More() {
+		if err := elem(); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+func expectDelim(dec *json.Decoder, d json.Delim) error {
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if t != d {
+		return fmt.Errorf("expected %q, got %v", d, t)
+	}
+	return nil
+}
+
+func decodeInfo(v interface{}) bind.DecodeInfo {
+	info := bind.DecodeInfo{}
+	extra := func(e bind.Extra) {
+		for _, cs := range e {
+			info.Counters += len(cs)
+		}
+	}
+	switch v := v.(type) {
+	case *Statistics:
+		info.Counters = len(v.Opcodes) + len(v.QTypes) + len(v.NSStats) + len(v.Rcodes) + len(v.ZoneStats)
+		extra(v.Extra)
+		info.Views = len(v.Views)
+		for _, view := range v.Views {
+			r := view.Resolver
+			info.Counters += len(r.Cache) + len(r.Qtypes) + len(r.Stats) + len(r.CacheStats)
+			extra(r.Extra)
+		}
+	case *ZoneStatistics:
+		info.Views = len(v.Views)
+		for _, view := range v.Views {
+			info.Zones += len(view.Zones)
+			for _, z := range view.Zones {
+				info.Counters += len(z.ZoneStats) + len(z.DNSSECSign) + len(z.DNSSECRefresh) + len(z.Rcodes) + len(z.QTypes)
+				extra(z.Extra)
+			}
+		}
+	case *TaskStatistics:
+		// The JSON channel lists no tasks, only their number.
+	}
+	return info
+}
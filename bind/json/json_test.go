@@ -0,0 +1,1285 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package json
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+)
+
+func newServer() *httptest.Server {
+	return httptest.NewServer(fixtureHandler())
+}
+
+func fixtureHandler() http.Handler {
+	m := map[string]string{
+		"/json/v1/server": "../../fixtures/json/server.json",
+		"/json/v1/status": "../../fixtures/json/status.json",
+		"/json/v1/tasks":  "../../fixtures/json/tasks.json",
+		"/json/v1/zones":  "../../fixtures/json/zones.json",
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if f, ok := m[r.URL.Path]; ok {
+			http.ServeFile(w, r, f)
+		} else {
+			http.NotFound(w, r)
+		}
+	})
+}
+
+func TestHeaders(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		headers []http.Header
+	)
+	ts := newServer()
+	defer ts.Close()
+	handler := ts.Config.Handler
+	ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		headers = append(headers, r.Header.Clone())
+		mu.Unlock()
+		handler.ServeHTTP(w, r)
+	})
+
+	c := NewClient(ts.URL, http.DefaultClient,
+		bind.WithHeader("X-Api-Key", "secret"),
+		bind.WithHeader("X-Tag", "a"),
+		bind.WithHeader("X-Tag", "b"),
+		bind.WithUserAgent("test-agent/1.0"),
+	)
+	if _, err := c.Stats(context.Background(), bind.ServerStats, bind.ViewStats, bind.TaskStats); err != nil {
+		t.Fatal(err)
+	}
+
+	if want, got := 3, len(headers); want != got {
+		t.Fatalf("want %d requests, got %d", want, got)
+	}
+	for _, h := range headers {
+		if want, got := "secret", h.Get("X-Api-Key"); want != got {
+			t.Errorf("want X-Api-Key %q, got %q", want, got)
+		}
+		if want, got := []string{"a", "b"}, h.Values("X-Tag"); !reflect.DeepEqual(want, got) {
+			t.Errorf("want X-Tag %q, got %q", want, got)
+		}
+		if want, got := "test-agent/1.0", h.Get("User-Agent"); want != got {
+			t.Errorf("want User-Agent %q, got %q", want, got)
+		}
+	}
+}
+
+func TestDefaultUserAgent(t *testing.T) {
+	var ua string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ua = r.Header.Get("User-Agent")
+		http.NotFound(w, r)
+	}))
+	defer ts.Close()
+
+	NewClient(ts.URL, http.DefaultClient).Stats(context.Background())
+	if want := bind.DefaultUserAgent(); ua != want {
+		t.Errorf("want User-Agent %q, got %q", want, ua)
+	}
+}
+
+func newRedirectServer() *httptest.Server {
+	backend := fixtureHandler()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<html><body>Please log in</body></html>"))
+	})
+	mux.HandleFunc("/proxy/", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, strings.TrimPrefix(r.URL.Path, "/proxy"), http.StatusFound)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/json/") {
+			backend.ServeHTTP(w, r)
+			return
+		}
+		http.Redirect(w, r, "/login", http.StatusFound)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestRedirectPolicy(t *testing.T) {
+	ts := newRedirectServer()
+	defer ts.Close()
+
+	// Redirects are refused by default.
+	_, err := NewClient(ts.URL+"/proxy", nil).Stats(context.Background(), bind.ServerStats)
+	var rerr *bind.RedirectError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("want RedirectError, got %v", err)
+	}
+	if want := ts.URL + "/json/v1/server"; rerr.Location != want {
+		t.Errorf("want location %q, got %q", want, rerr.Location)
+	}
+	if rerr.StatusCode != http.StatusFound {
+		t.Errorf("want status %d, got %d", http.StatusFound, rerr.StatusCode)
+	}
+
+	// Redirects within the limit are followed.
+	c := NewClient(ts.URL+"/proxy", nil, bind.WithMaxRedirects(1))
+	if _, err := c.Stats(context.Background(), bind.ServerStats); err != nil {
+		t.Errorf("want redirect to be followed, got %v", err)
+	}
+
+	// A caller supplied client follows redirects, but landing on HTML is
+	// reported as a redirect.
+	_, err = NewClient(ts.URL+"/stats", http.DefaultClient).Stats(context.Background(), bind.ServerStats)
+	if !errors.As(err, &rerr) {
+		t.Fatalf("want RedirectError, got %v", err)
+	}
+	if want := ts.URL + "/login"; rerr.Location != want || rerr.StatusCode != 0 {
+		t.Errorf("want followed redirect to %q, got %+v", want, rerr)
+	}
+}
+
+type countingRoundTripper struct {
+	mu    sync.Mutex
+	paths []string
+}
+
+func (rt *countingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.mu.Lock()
+	rt.paths = append(rt.paths, req.URL.Path)
+	rt.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestRoundTripper(t *testing.T) {
+	ts := newServer()
+	defer ts.Close()
+
+	rt := &countingRoundTripper{}
+	c, err := New(ts.URL, bind.WithRoundTripper(rt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Stats(context.Background(), bind.ServerStats, bind.ViewStats, bind.TaskStats); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{ServerPath, ZonesPath, TasksPath}; !reflect.DeepEqual(want, rt.paths) {
+		t.Errorf("want requests %q, got %q", want, rt.paths)
+	}
+
+	if _, err := New(ts.URL, bind.WithRoundTripper(rt), bind.WithHTTPClient(http.DefaultClient)); err == nil {
+		t.Errorf("want error combining WithRoundTripper and WithHTTPClient")
+	}
+	if _, err := NewClient(ts.URL, http.DefaultClient, bind.WithRoundTripper(rt)).Stats(context.Background()); err == nil {
+		t.Errorf("want error combining WithRoundTripper and an http.Client")
+	}
+}
+
+func TestDNSSECSignStats(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "../../fixtures/json/zones-dnssec.json")
+	}))
+	defer ts.Close()
+
+	s, err := NewClient(ts.URL, nil).Stats(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	zones := s.ZoneViews[0].ZoneData
+	if want, got := 2, len(zones); want != got {
+		t.Fatalf("want %d zones, got %d", want, got)
+	}
+	sign := zones[0].DNSSECSignStats
+	sort.Slice(sign, func(i, j int) bool { return sign[i].Name < sign[j].Name })
+	wantSign := []bind.Counter{{Name: "20326", Counter: 14233}, {Name: "38696", Counter: 412}}
+	if !reflect.DeepEqual(wantSign, sign) {
+		t.Errorf("want sign stats %v, got %v", wantSign, sign)
+	}
+	if want, got := 2, len(zones[0].DNSSECRefreshStats); want != got {
+		t.Errorf("want %d refresh counters, got %d", want, got)
+	}
+	if zones[1].DNSSECSignStats != nil || zones[1].DNSSECRefreshStats != nil {
+		t.Errorf("want no DNSSEC stats for unsigned zone, got %+v", zones[1])
+	}
+}
+
+func TestZoneNotifyStats(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "../../fixtures/json/zones-secondary.json")
+	}))
+	defer ts.Close()
+
+	s, err := NewClient(ts.URL, nil).Stats(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	zones := s.ZoneViews[0].ZoneData
+	got := map[string]uint64{}
+	for _, c := range zones[0].ZoneStats {
+		got[c.Name] = c.Counter
+	}
+	want := map[string]uint64{
+		"NotifyInv4": 38,
+		"NotifyInv6": 12,
+		"NotifyRej":  2,
+		"SOAOutv4":   51,
+		"IXFRReqv4":  48,
+		"XfrSuccess": 48,
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want zone stats %v, got %v", want, got)
+	}
+	if zones[1].ZoneStats != nil {
+		t.Errorf("want nil zone stats for static zone, got %v", zones[1].ZoneStats)
+	}
+}
+
+func TestServerCounters(t *testing.T) {
+	ts := newServer()
+	defer ts.Close()
+
+	s, err := NewClient(ts.URL, nil).Stats(context.Background(), bind.ServerStats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := bind.ServerCounters{Requestv4: 156, Response: 156}
+	if got := s.Server.Counters(); got != want {
+		t.Errorf("want server counters %+v, got %+v", want, got)
+	}
+}
+
+func TestSource(t *testing.T) {
+	ts := newServer()
+	defer ts.Close()
+
+	s, err := NewClient(ts.URL, nil).Stats(context.Background(), bind.ServerStats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := bind.Source{Format: bind.FormatJSONv1, SchemaVersion: "1.7", BINDVersion: "9.18.12-1-Debian", FetchTime: s.Source.FetchTime}
+	if s.Source != want || s.Source.FetchTime.IsZero() {
+		t.Errorf("want source %+v, got %+v", want, s.Source)
+	}
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dump struct{ Source bind.Source }
+	if err := json.Unmarshal(b, &dump); err != nil {
+		t.Fatal(err)
+	}
+	if !dump.Source.FetchTime.Equal(want.FetchTime) || dump.Source.BINDVersion != want.BINDVersion {
+		t.Errorf("want source %+v in JSON dump, got %+v", want, dump.Source)
+	}
+}
+
+func TestStatusOnly(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	h := fixtureHandler()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.URL.Path)
+		mu.Unlock()
+		h.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	s, err := NewClient(ts.URL, nil).Stats(context.Background(), bind.StatusStats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{StatusPath}; !reflect.DeepEqual(requests, want) {
+		t.Errorf("want requests %v, got %v", want, requests)
+	}
+	want := bind.Source{Format: bind.FormatJSONv1, SchemaVersion: "1.7", BINDVersion: "9.18.12-1-Debian", FetchTime: s.Source.FetchTime}
+	if s.Source != want || s.Source.FetchTime.IsZero() {
+		t.Errorf("want source %+v, got %+v", want, s.Source)
+	}
+	boot := time.Date(2021, 7, 15, 5, 11, 8, 926000000, time.UTC)
+	if !s.Server.BootTime.Equal(boot) || s.Server.ConfigTime.IsZero() || s.Server.CurrentTime.IsZero() {
+		t.Errorf("want times of the status document, got %+v", s.Server)
+	}
+	if s.Server.IncomingQueries != nil || s.Views != nil || s.ZoneViews != nil {
+		t.Errorf("want only the status, got %+v", s)
+	}
+}
+
+func TestStrictDecoding(t *testing.T) {
+	ts := newServer()
+	defer ts.Close()
+
+	groups := []bind.StatisticGroup{bind.ServerStats, bind.ViewStats, bind.TaskStats}
+	if _, err := NewClient(ts.URL, nil, bind.WithStrictDecoding()).Stats(context.Background(), groups...); err != nil {
+		t.Fatalf("want known fixtures to pass strict decoding, got %v", err)
+	}
+
+	unknown := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "../../fixtures/json/server-unknown.json")
+	}))
+	defer unknown.Close()
+
+	var stats Statistics
+	if err := NewClient(unknown.URL, nil).Get(context.Background(), ServerPath, &stats); err != nil {
+		t.Fatalf("want unknown items to be ignored by default, got %v", err)
+	}
+	err := NewClient(unknown.URL, nil, bind.WithStrictDecoding()).Get(context.Background(), ServerPath, &stats)
+	if !errors.Is(err, bind.ErrUnknownItem) {
+		t.Fatalf("want unknown item error, got %v", err)
+	}
+	for _, item := range []string{"field /uptime", "field /ednsoptions"} {
+		if !strings.Contains(err.Error(), item) {
+			t.Errorf("want error to list %s, got %v", item, err)
+		}
+	}
+}
+
+func TestZoneQueryStats(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "../../fixtures/json/zones-full.json")
+	}))
+	defer ts.Close()
+
+	s, err := NewClient(ts.URL, nil, bind.WithStrictDecoding()).Stats(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	z := s.ZoneViews[0].ZoneData[0]
+	sort.Slice(z.IncomingQueries, func(i, j int) bool { return z.IncomingQueries[i].Name < z.IncomingQueries[j].Name })
+	want := []bind.Counter{{Name: "A", Counter: 1402}, {Name: "AAAA", Counter: 388}, {Name: "MX", Counter: 41}}
+	if !reflect.DeepEqual(want, z.IncomingQueries) {
+		t.Errorf("want queries %v, got %v", want, z.IncomingQueries)
+	}
+	results := map[string]uint64{}
+	for _, c := range z.QueryResults {
+		results[c.Name] = c.Counter
+	}
+	if len(results) != 12 || results["QrySuccess"] != 1620 || results["QryNXDOMAIN"] != 115 {
+		t.Errorf("unexpected query results %v", z.QueryResults)
+	}
+}
+
+func TestServeStaleCounters(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "../../fixtures/json/server-stale.json")
+	}))
+	defer ts.Close()
+
+	s, err := NewClient(ts.URL, nil).Stats(context.Background(), bind.ServerStats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := bind.ServerCounters{Requestv4: 48211, Response: 48209, QryUsedStale: 318, QryTryStale: 41, BadVers: 3, BadCookie: 42}
+	if got := s.Server.Counters(); got != want {
+		t.Errorf("want server counters %+v, got %+v", want, got)
+	}
+}
+
+func TestExtendedRcodes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "../../fixtures/json/server-stale.json")
+	}))
+	defer ts.Close()
+
+	s, err := NewClient(ts.URL, nil).Stats(context.Background(), bind.ServerStats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]uint64{}
+	for _, c := range s.Server.ServerRcodes {
+		got[c.Name] = c.Counter
+	}
+	want := map[string]uint64{"NOERROR": 40117, "NXDOMAIN": 311, "BADVERS": 3, "RCODE17": 5, "BADCOOKIE": 42}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want rcodes %v, got %v", want, got)
+	}
+}
+
+func TestCacheMemory(t *testing.T) {
+	ts := newServer()
+	defer ts.Close()
+
+	s, err := NewClient(ts.URL, nil).Stats(context.Background(), bind.ViewStats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range s.Views {
+		if v.Name != "_default" {
+			continue
+		}
+		got := map[string]uint64{}
+		for _, g := range v.CacheMemory {
+			got[g.Name] = g.Gauge
+		}
+		if len(got) != 6 || got["HeapMemInUse"] != 5905580032 || got["TreeMemInUse"] != 2998312160 {
+			t.Errorf("unexpected cache memory %v", v.CacheMemory)
+		}
+		want := []bind.Counter{{Name: "CacheHits", Counter: 1922871}, {Name: "CacheMisses", Counter: 310538}}
+		if !reflect.DeepEqual(want, v.CacheStats) {
+			t.Errorf("want cache statistics %v, got %v", want, v.CacheStats)
+		}
+		return
+	}
+	t.Fatal("missing view _default")
+}
+
+func TestNumericQTypes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "../../fixtures/json/server-qtypes.json")
+	}))
+	defer ts.Close()
+
+	s, err := NewClient(ts.URL, nil).Stats(context.Background(), bind.ServerStats, bind.ViewStats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]uint64{}
+	for _, c := range s.Server.IncomingQueries {
+		got[c.Name] = c.Counter
+	}
+	want := map[string]uint64{
+		"A": 31017, "AAAA": 9182, "TYPE65": 412, "HTTPS": 1203,
+		"TYPE65280": 17, "TYPE65534": 4, "TYPE65535": 2, "TYPE4711": 1,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want incoming queries %v, got %v", want, got)
+	}
+	if len(s.Views) != 1 {
+		t.Fatalf("want 1 view, got %d", len(s.Views))
+	}
+	got = map[string]uint64{}
+	for _, c := range s.Views[0].ResolverQueries {
+		got[c.Name] = c.Counter
+	}
+	want = map[string]uint64{"A": 2041, "TYPE28": 388, "TYPE65281": 6}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want resolver queries %v, got %v", want, got)
+	}
+}
+
+func TestBuiltinZones(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "../../fixtures/json/zones-resolver.json")
+	}))
+	defer ts.Close()
+
+	for _, tc := range []struct {
+		opts  []bind.ClientOption
+		zones []string
+		n     int
+	}{
+		{n: len(bind.BuiltinZones) + 1},
+		{opts: []bind.ClientOption{bind.WithoutBuiltinZones()}, zones: []string{"example.com"}, n: 1},
+		{opts: []bind.ClientOption{bind.WithoutBuiltinZones(), bind.WithExcludedZones("Example.COM.")}},
+	} {
+		var decoded int
+		ctx := bind.WithClientTrace(context.Background(), &bind.ClientTrace{
+			DecodeDone: func(_ context.Context, _ bind.StatisticGroup, info bind.DecodeInfo, _ error) {
+				decoded = info.Zones
+			},
+		})
+		s, err := NewClient(ts.URL, nil, tc.opts...).Stats(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var zones []string
+		for _, v := range s.ZoneViews {
+			if v.Name == "_bind" && len(v.ZoneData) > 0 {
+				t.Errorf("want no zones of class CH, got %v", v.ZoneData)
+			}
+			for _, z := range v.ZoneData {
+				zones = append(zones, z.Name)
+			}
+		}
+		if len(zones) != tc.n || (tc.zones != nil && !reflect.DeepEqual(zones, tc.zones)) {
+			t.Errorf("want %d zones %v, got %d zones %v", tc.n, tc.zones, len(zones), zones)
+		}
+		if tc.opts != nil && decoded != tc.n {
+			t.Errorf("want %d decoded zones, got %d", tc.n, decoded)
+		}
+	}
+}
+
+func TestPartialZones(t *testing.T) {
+	b, err := os.ReadFile("../../fixtures/json/zones-resolver.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Cut the document within the eleventh zone.
+	cut := 0
+	for i := 0; i < 11; i++ {
+		cut += bytes.Index(b[cut+1:], []byte(`"name"`)) + 1
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(b[:cut])
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	for _, opts := range [][]bind.ClientOption{
+		{bind.WithPartialZones()},
+		{bind.WithPartialZones(), bind.WithoutBuiltinZones()},
+	} {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		s, err := NewClient(ts.URL, nil, opts...).Stats(ctx)
+		cancel()
+		var truncated *bind.TruncatedError
+		if !errors.As(err, &truncated) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("want truncated error, got %v", err)
+		}
+		want := 10
+		if len(opts) > 1 {
+			want = 1
+		}
+		if truncated.Zones != want {
+			t.Errorf("want %d zones in error, got %d", want, truncated.Zones)
+		}
+		if len(s.ZoneViews) != 1 || len(s.ZoneViews[0].ZoneData) != want {
+			t.Errorf("want %d zones in view _default, got %v", want, s.ZoneViews)
+		}
+	}
+}
+
+func TestMaxZones(t *testing.T) {
+	var b bytes.Buffer
+	b.WriteString(`{"json-stats-version":"1.7","views":{`)
+	for i, v := range []struct {
+		name string
+		n    int
+	}{{"internal", 6000}, {"external", 4000}} {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, `"%s":{"zones":[`, v.name)
+		for _, z := range bind.BuiltinZones {
+			fmt.Fprintf(&b, `{"name":"%s","class":"IN","serial":0},`, z)
+		}
+		for j := 0; j < v.n; j++ {
+			if j > 0 {
+				b.WriteString(",")
+			}
+			fmt.Fprintf(&b, `{"name":"zone%d.%s.example","class":"IN","serial":%d,"rcodes":{"QrySuccess":%d}}`, j, v.name, j, j)
+		}
+		b.WriteString("]}")
+	}
+	b.WriteString("}}")
+	doc := b.Bytes()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(doc)
+	}))
+	defer ts.Close()
+
+	builtin := len(bind.BuiltinZones)
+	for _, tc := range []struct {
+		opts    []bind.ClientOption
+		zones   map[string]int
+		skipped map[string]int
+	}{
+		{
+			opts:    []bind.ClientOption{bind.WithMaxZones(100)},
+			zones:   map[string]int{"internal": 100, "external": 0},
+			skipped: map[string]int{"internal": 6000, "external": 4000 + builtin},
+		},
+		{
+			opts:    []bind.ClientOption{bind.WithMaxZonesPerView(100)},
+			zones:   map[string]int{"internal": 100, "external": 100},
+			skipped: map[string]int{"internal": 6000, "external": 4000},
+		},
+		{
+			opts:    []bind.ClientOption{bind.WithMaxZonesPerView(100), bind.WithoutBuiltinZones()},
+			zones:   map[string]int{"internal": 100, "external": 100},
+			skipped: map[string]int{"internal": 5900, "external": 3900},
+		},
+	} {
+		s, err := NewClient(ts.URL, nil, tc.opts...).Stats(context.Background())
+		var truncated *bind.TruncatedError
+		if !errors.As(err, &truncated) || !errors.Is(err, bind.ErrTooManyZones) {
+			t.Fatalf("want truncated error, got %v", err)
+		}
+		zones := map[string]int{}
+		for _, v := range s.ZoneViews {
+			zones[v.Name] = len(v.ZoneData)
+		}
+		if !reflect.DeepEqual(zones, tc.zones) {
+			t.Errorf("want zones %v, got %v", tc.zones, zones)
+		}
+		if !reflect.DeepEqual(truncated.Skipped, tc.skipped) {
+			t.Errorf("want skipped zones %v, got %v", tc.skipped, truncated.Skipped)
+		}
+	}
+}
+
+func TestMaxZonesTasksError(t *testing.T) {
+	var b bytes.Buffer
+	b.WriteString(`{"json-stats-version":"1.7","views":{"_default":{"zones":[`)
+	for i := 0; i < 200; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, `{"name":"zone%d.example","class":"IN","serial":%d}`, i, i)
+	}
+	b.WriteString("]}}}")
+	doc := b.Bytes()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case ServerPath:
+			http.ServeFile(w, r, "../../fixtures/json/server.json")
+		case ZonesPath:
+			w.Write(doc)
+		default:
+			http.Error(w, "internal error", http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+
+	// The failure of the tasks is reported along with the truncation.
+	_, err := NewClient(ts.URL, nil, bind.WithMaxZones(100)).Stats(context.Background(), bind.ServerStats, bind.ViewStats, bind.TaskStats)
+	var truncated *bind.TruncatedError
+	if !errors.As(err, &truncated) || !errors.Is(err, bind.ErrTooManyZones) {
+		t.Fatalf("want truncated error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), TasksPath) || !strings.Contains(err.Error(), "500") {
+		t.Errorf("want error of the tasks document, got %v", err)
+	}
+}
+
+func TestQueriesByClass(t *testing.T) {
+	stats := func(zones string, opts ...bind.ClientOption) bind.Statistics {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case ServerPath:
+				http.ServeFile(w, r, "../../fixtures/json/server.json")
+			case ZonesPath:
+				http.ServeFile(w, r, "../../fixtures/json/"+zones)
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		defer ts.Close()
+		s, err := NewClient(ts.URL, nil, opts...).Stats(context.Background(), bind.ViewStats)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	s := stats("zones-chaos.json")
+	want := bind.QueriesByClass{
+		bind.ClassIN: {{Name: "A", Counter: 1402}, {Name: "TXT", Counter: 130}},
+		bind.ClassCH: {{Name: "TXT", Counter: 41}},
+	}
+	if got := s.QueriesByClass(); !reflect.DeepEqual(got, want) {
+		t.Errorf("want queries by class %v, got %v", want, got)
+	}
+	for _, v := range s.ZoneViews {
+		for _, z := range v.ZoneData {
+			if strings.HasSuffix(z.Name, ".bind") {
+				t.Errorf("want only IN zones in view %s, got %s", v.Name, z.Name)
+			}
+		}
+	}
+
+	// Excluding a zone keeps the queries of the other classes, unless the
+	// builtin zones are excluded.
+	want = bind.QueriesByClass{
+		bind.ClassIN: {{Name: "A", Counter: 1402}, {Name: "TXT", Counter: 118}},
+		bind.ClassCH: {{Name: "TXT", Counter: 41}},
+	}
+	s = stats("zones-chaos.json", bind.WithExcludedZones("example.net"))
+	if got := s.QueriesByClass(); !reflect.DeepEqual(got, want) {
+		t.Errorf("want queries by class %v, got %v", want, got)
+	}
+	want = bind.QueriesByClass{bind.ClassIN: {{Name: "A", Counter: 1402}, {Name: "TXT", Counter: 130}}}
+	s = stats("zones-chaos.json", bind.WithoutBuiltinZones())
+	if got := s.QueriesByClass(); !reflect.DeepEqual(got, want) {
+		t.Errorf("want queries by class %v, got %v", want, got)
+	}
+
+	// Without query types by zone, the classes cannot be told apart.
+	s = stats("zones-nostats.json")
+	if got := s.QueriesByClass(); got != nil {
+		t.Errorf("want no queries by class, got %v", got)
+	}
+}
+
+func TestZoneStatisticsWarning(t *testing.T) {
+	for _, tc := range []struct {
+		zones string
+		want  []string
+	}{
+		{zones: "zones-nostats.json", want: []string{bind.WarnZoneStatisticsDisabled}},
+		{zones: "zones-idle.json"},
+		{zones: "zones-full.json"},
+	} {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case ServerPath:
+				http.ServeFile(w, r, "../../fixtures/json/server.json")
+			case ZonesPath:
+				http.ServeFile(w, r, "../../fixtures/json/"+tc.zones)
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		s, err := NewClient(ts.URL, nil).Stats(context.Background(), bind.ServerStats, bind.ViewStats)
+		ts.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, w := range s.Warnings {
+			got = append(got, w.Code)
+		}
+		if !reflect.DeepEqual(tc.want, got) {
+			t.Errorf("%s: want warnings %v, got %v", tc.zones, tc.want, got)
+		}
+	}
+}
+
+// rrlStats is a made-up section of a patched named reporting response rate
+// limiting.
+type rrlStats struct {
+	Dropped uint64 `json:"dropped"`
+	Slipped uint64 `json:"slipped"`
+}
+
+func TestSectionDecoder(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case ServerPath:
+			w.Write([]byte(`{"json-stats-version":"1.2","qtypes":{"A":812},"rrl":{"dropped":17,"slipped":4},"views":{}}`))
+		case ZonesPath:
+			w.Write([]byte(`{"json-stats-version":"1.2","views":{}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	rrl := func(d *json.Decoder) (any, error) {
+		var v rrlStats
+		err := d.Decode(&v)
+		return v, err
+	}
+	c := NewClient(ts.URL, nil, bind.WithJSONSectionDecoder("rrl", rrl))
+	s, err := c.Stats(context.Background(), bind.ServerStats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"rrl": rrlStats{Dropped: 17, Slipped: 4}}
+	if !reflect.DeepEqual(s.Extensions, want) {
+		t.Errorf("want extensions %v, got %v", want, s.Extensions)
+	}
+	if len(s.Server.IncomingQueries) != 1 {
+		t.Errorf("want server counters decoded, got %v", s.Server.IncomingQueries)
+	}
+
+	fail := func(*json.Decoder) (any, error) {
+		return nil, errors.New("bad section")
+	}
+	c = NewClient(ts.URL, nil, bind.WithJSONSectionDecoder("rrl", fail))
+	if _, err := c.Stats(context.Background(), bind.ServerStats); err == nil || !strings.Contains(err.Error(), "bad section") {
+		t.Errorf("want error of section decoder, got %v", err)
+	}
+}
+
+func TestLongTailAggregation(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "../../fixtures/json/server-longtail.json")
+	}))
+	defer ts.Close()
+
+	full, err := NewClient(ts.URL, nil).Stats(context.Background(), bind.ServerStats, bind.ViewStats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(full.Server.IncomingQueries); n != 40 {
+		t.Fatalf("want 40 query types in fixture, got %d", n)
+	}
+	keep := []string{"A", "AAAA", "NS", "CNAME", "SOA", "PTR", "MX", "TXT", "SRV", "QUERY"}
+	s, err := NewClient(ts.URL, nil, bind.WithLongTailAggregation(keep)).Stats(context.Background(), bind.ServerStats, bind.ViewStats)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sum := func(cs []bind.Counter) uint64 {
+		var n uint64
+		for _, c := range cs {
+			n += c.Counter
+		}
+		return n
+	}
+	for _, tc := range []struct {
+		name      string
+		full, agg []bind.Counter
+		want      int
+	}{
+		// TYPE28 is kept as AAAA.
+		{name: "server qtypes", full: full.Server.IncomingQueries, agg: s.Server.IncomingQueries, want: 11},
+		{name: "server opcodes", full: full.Server.IncomingRequests, agg: s.Server.IncomingRequests, want: 2},
+		{name: "resolver qtypes", full: full.Views[0].ResolverQueries, agg: s.Views[0].ResolverQueries, want: 4},
+	} {
+		if len(tc.agg) != tc.want || tc.agg[len(tc.agg)-1].Name != bind.OtherCounter {
+			t.Errorf("%s: want %d counters ending with %s, got %v", tc.name, tc.want, bind.OtherCounter, tc.agg)
+		}
+		if got, want := sum(tc.agg), sum(tc.full); got != want {
+			t.Errorf("%s: want total %d to be preserved, got %d", tc.name, want, got)
+		}
+	}
+}
+
+func TestTCPFallbackCounters(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "../../fixtures/json/server-tcpfallback.json")
+	}))
+	defer ts.Close()
+
+	s, err := NewClient(ts.URL, nil, bind.WithStrictDecoding()).Stats(context.Background(), bind.ServerStats, bind.ViewStats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Views) != 1 {
+		t.Fatalf("want 1 view, got %d", len(s.Views))
+	}
+	want := bind.ViewCounters{
+		Queryv4: 41877, Queryv6: 9310, Responsev4: 40123, Responsev6: 8875,
+		Truncated: 6204, Retry: 7391, EDNS0Fail: 412, QueryTimeout: 1187, QueryCurUDP: 58, QueryCurTCP: 121,
+	}
+	if got := s.Views[0].Counters(); got != want {
+		t.Errorf("want counters %+v, got %+v", want, got)
+	}
+}
+
+func TestMiddleboxCounters(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "../../fixtures/json/server-middlebox.json")
+	}))
+	defer ts.Close()
+
+	s, err := NewClient(ts.URL, nil, bind.WithStrictDecoding()).Stats(context.Background(), bind.ServerStats, bind.ViewStats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := bind.ViewCounters{
+		Queryv4: 30544, Responsev4: 26120, Truncated: 86, Retry: 4402,
+		EDNS0Fail: 3015, BadEDNSVersion: 17, SERVFAIL: 318, FORMERR: 2741, QueryTimeout: 4424,
+	}
+	if got := s.Views[0].Counters(); got != want {
+		t.Errorf("want counters %+v, got %+v", want, got)
+	}
+}
+
+func TestMemory(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "../../fixtures/json/server-memory.json")
+	}))
+	defer ts.Close()
+
+	s, err := NewClient(ts.URL, nil, bind.WithStrictDecoding()).Stats(context.Background(), bind.ServerStats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := bind.MemorySummary{InUse: 181248305, Malloced: 203919732, ContextSize: 1874304}
+	if s.Memory.Summary != want {
+		t.Errorf("want memory summary %+v, got %+v", want, s.Memory.Summary)
+	}
+	if n := len(s.Memory.Contexts); n != 4 {
+		t.Fatalf("want 4 memory contexts, got %d", n)
+	}
+	if c := s.Memory.Contexts[1]; c.Name != "cache" || c.InUse != 151004211 || c.MaxInUse != 152118440 || c.References != 8 {
+		t.Errorf("want cache context, got %+v", c)
+	}
+}
+
+func TestResolverGauges(t *testing.T) {
+	for fixture, want := range map[string]bind.ViewGauges{
+		"server-loaded.json": {Reported: true, NumFetch: 1873, BucketSize: 31, QueryCurUDP: 2417, QueryCurTCP: 96},
+		"server.json":        {},
+	} {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "../../fixtures/json/"+fixture)
+		}))
+		s, err := NewClient(ts.URL, nil).Stats(context.Background(), bind.ServerStats, bind.ViewStats)
+		ts.Close()
+		if err != nil {
+			t.Fatalf("%s: %s", fixture, err)
+		}
+		v := s.Views[0]
+		if got := v.Gauges(); got != want {
+			t.Errorf("%s: want gauges %+v, got %+v", fixture, want, got)
+		}
+		if !want.Reported && v.ResolverGauges != nil {
+			t.Errorf("%s: want no resolver gauges, got %v", fixture, v.ResolverGauges)
+		}
+		for _, c := range v.ResolverStats {
+			if bind.ResolverGaugeStat(c.Name) {
+				t.Errorf("%s: want gauge %s not in resolver counters", fixture, c.Name)
+			}
+		}
+	}
+}
+
+func TestPreamble(t *testing.T) {
+	server, err := os.ReadFile("../../fixtures/json/server.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	zones, err := os.ReadFile("../../fixtures/json/zones.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, prefix := range map[string]string{
+		"BOM":            "\xef\xbb\xbf",
+		"whitespace":     "\r\n \t\n",
+		"BOM whitespace": "\xef\xbb\xbf\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b := server
+				if r.URL.Path == ZonesPath {
+					b = zones
+				}
+				w.Write(append([]byte(prefix), b...))
+			}))
+			defer ts.Close()
+
+			for _, opts := range [][]bind.ClientOption{nil, {bind.WithStrictDecoding()}} {
+				s, err := NewClient(ts.URL, nil, opts...).Stats(context.Background(), bind.ServerStats, bind.ViewStats)
+				if err != nil {
+					t.Fatalf("want document with leading %q to be decoded, got %v", prefix, err)
+				}
+				if len(s.Server.NameServerStats) == 0 || len(s.ZoneViews) == 0 {
+					t.Errorf("want statistics to be decoded, got %+v", s)
+				}
+			}
+		})
+	}
+}
+
+func TestStringCounters(t *testing.T) {
+	server, err := os.ReadFile("../../fixtures/json/server.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	zones, err := os.ReadFile("../../fixtures/json/zones.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats := func(server []byte) (bind.Statistics, error) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == ZonesPath {
+				w.Write(zones)
+			} else {
+				w.Write(server)
+			}
+		}))
+		defer ts.Close()
+		s, err := NewClient(ts.URL, nil).Stats(context.Background(), bind.ServerStats, bind.ViewStats)
+		s.Source.FetchTime, s.ClockSkew, s.Decode = time.Time{}, 0, nil
+		return s, err
+	}
+	want, err := stats(server)
+	if err != nil {
+		t.Fatal(err)
+	}
+	quoted := regexp.MustCompile(`":\s*([0-9]+)`).ReplaceAll(server, []byte(`":" $1 "`))
+	if bytes.Equal(quoted, server) {
+		t.Fatal("want counters of the fixture quoted")
+	}
+	got, err := stats(quoted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Error("want counters encoded as strings decoded like numbers")
+	}
+
+	for value, want := range map[string]string{
+		`"+12"`:  "signs are not allowed",
+		`"0x1f"`: "hexadecimal numbers are not allowed",
+		`-12`:    "signs are not allowed",
+		`"n/a"`:  "invalid syntax",
+	} {
+		doc := bytes.Replace(server, []byte(`"QUERY":`), []byte(`"QUERY":`+value+`, "QUERY2":`), 1)
+		if _, err := stats(doc); !errors.Is(err, bind.ErrInvalidCounter) || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: want error %q, got %v", value, want, err)
+		}
+	}
+}
+
+func TestDecodeStatistics(t *testing.T) {
+	ts := newServer()
+	defer ts.Close()
+
+	observed := map[bind.StatisticGroup]bind.DecodeInfo{}
+	ctx := bind.WithClientTrace(context.Background(), &bind.ClientTrace{
+		DecodeDone: func(_ context.Context, g bind.StatisticGroup, info bind.DecodeInfo, _ error) {
+			observed[g] = info
+		},
+	})
+	s, err := NewClient(ts.URL, nil).Stats(ctx, bind.ServerStats, bind.ViewStats, bind.TaskStats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[bind.StatisticGroup]bind.DecodeInfo{
+		bind.ServerStats: {Views: 2, Counters: 45},
+		bind.ViewStats:   {Views: 1, Zones: 1},
+		bind.TaskStats:   {},
+	}
+	got := map[bind.StatisticGroup]bind.DecodeInfo{}
+	for g, info := range s.Decode {
+		if !reflect.DeepEqual(info, observed[g]) {
+			t.Errorf("want observed %s decode statistics %+v, got %+v", g, info, observed[g])
+		}
+		info.Duration = 0
+		got[g] = info
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want decode statistics %+v, got %+v", want, got)
+	}
+}
+
+func TestFailureCapture(t *testing.T) {
+	doc := `{"json-stats-version":"1.7","nsstats":{"Requestv4":81240,"QryDropped":`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, doc)
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	_, err := NewClient(ts.URL, nil, bind.WithFailureCapture(dir, 1)).Stats(context.Background(), bind.ServerStats)
+	var derr *bind.DecodeError
+	if !errors.As(err, &derr) || !strings.HasSuffix(derr.CapturePath, "-server.json") {
+		t.Fatalf("want decode error with capture of the server document, got %v", err)
+	}
+	captured, err := os.ReadFile(derr.CapturePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(captured) != doc {
+		t.Errorf("want capture %q, got %q", doc, captured)
+	}
+
+	// The captured bytes reproduce the failure.
+	replay := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(captured)
+	}))
+	defer replay.Close()
+	_, err = NewClient(replay.URL, nil).Stats(context.Background(), bind.ServerStats)
+	if err == nil || err.Error() != derr.Err.Error() {
+		t.Errorf("want capture to fail with %v, got %v", derr.Err, err)
+	}
+	if errors.As(err, new(*bind.DecodeError)) {
+		t.Errorf("want no capture by default, got %v", err)
+	}
+}
+
+func TestViews(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		perView  bool
+		strategy bind.ZonesStrategy
+	}{
+		{name: "per view", perView: true, strategy: bind.ZonesPerView},
+		{name: "full document", strategy: bind.ZonesFiltered},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var full bool
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == ServerPath:
+					http.ServeFile(w, r, "../../fixtures/json/server.json")
+				case r.URL.Path == ZonesPath:
+					full = true
+					http.ServeFile(w, r, "../../fixtures/json/zones-chaos.json")
+				case r.URL.Path == ZonesPath+"/_default" && tc.perView:
+					http.ServeFile(w, r, "../../fixtures/json/zones-view-default.json")
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer ts.Close()
+			s, err := NewClient(ts.URL, nil, bind.WithViews("_default")).Stats(context.Background(), bind.ViewStats)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.Source.ZonesStrategy != tc.strategy {
+				t.Errorf("want strategy %q, got %q", tc.strategy, s.Source.ZonesStrategy)
+			}
+			if full == tc.perView {
+				t.Errorf("want full zones document requested %t, got %t", !tc.perView, full)
+			}
+			if len(s.ZoneViews) != 1 || s.ZoneViews[0].Name != "_default" || len(s.ZoneViews[0].ZoneData) != 2 {
+				t.Errorf("want 2 zones of view _default, got %+v", s.ZoneViews)
+			}
+		})
+	}
+}
+
+func TestZoneMaintenance(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case ServerPath:
+			http.ServeFile(w, r, "../../fixtures/json/server-secondary.json")
+		case ZonesPath:
+			http.ServeFile(w, r, "../../fixtures/json/zones-secondary.json")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	s, err := NewClient(ts.URL, nil).Stats(context.Background(), bind.ServerStats, bind.ViewStats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s.Server.ZoneStatistics, s.Server.ZoneMaintenance) {
+		t.Errorf("want deprecated zone statistics %v, got %v", s.Server.ZoneMaintenance, s.Server.ZoneStatistics)
+	}
+	got := s.Server.Maintenance()
+	if got.SOAOutv4 != 51 || got.XfrSuccess != 48 {
+		t.Errorf("want 51 SOA queries and 48 transfers, got %+v", got)
+	}
+
+	// The server counters are the totals of those of the zones.
+	var sum bind.MaintenanceCounters
+	for _, v := range s.ZoneViews {
+		for _, z := range v.ZoneData {
+			zc := reflect.ValueOf(z.Maintenance())
+			sv := reflect.ValueOf(&sum).Elem()
+			for i := 0; i < sv.NumField(); i++ {
+				sv.Field(i).SetUint(sv.Field(i).Uint() + zc.Field(i).Uint())
+			}
+		}
+	}
+	if sum != got {
+		t.Errorf("want server counters %+v to equal the sum over zones %+v", got, sum)
+	}
+}
+
+func TestZoneNames(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "../../fixtures/json/zones-dotted.json")
+	}))
+	defer ts.Close()
+
+	s, err := NewClient(ts.URL, nil).Stats(context.Background(), bind.ViewStats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names, raw []string
+	for _, z := range s.ZoneViews[0].ZoneData {
+		names = append(names, z.Name)
+		raw = append(raw, z.ReportedName())
+	}
+	if want := []string{".", "example.com", "10.in-addr.arpa"}; !reflect.DeepEqual(want, names) {
+		t.Errorf("want zones %v, got %v", want, names)
+	}
+	if want := []string{".", "example.com.", "10.in-addr.arpa"}; !reflect.DeepEqual(want, raw) {
+		t.Errorf("want reported zones %v, got %v", want, raw)
+	}
+
+	for _, name := range []string{".", "example.com", "example.com.", "10.in-addr.arpa."} {
+		z, err := NewClient(ts.URL, nil).GetZone(context.Background(), bind.DefaultView, name)
+		if err != nil {
+			t.Errorf("GetZone(%q): %s", name, err)
+		} else if z.Name != bind.CanonicalZoneName(name) {
+			t.Errorf("GetZone(%q): want zone %s, got %s", name, bind.CanonicalZoneName(name), z.Name)
+		}
+	}
+
+	s, err = NewClient(ts.URL, nil, bind.WithExcludedZones(".", "example.com", "10.in-addr.arpa.")).Stats(context.Background(), bind.ViewStats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if zs := s.ZoneViews[0].ZoneData; len(zs) != 0 {
+		t.Errorf("want all zones excluded, got %v", zs)
+	}
+}
+
+func TestGetViewEndpointOverride(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/server" {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, "../../fixtures/json/server.json")
+	}))
+	defer ts.Close()
+
+	// Views follow the override of the server document, not that of the
+	// zones document.
+	c := NewClient(ts.URL, nil,
+		bind.WithEndpointOverride(bind.ServerStats, "/server"),
+		bind.WithEndpointOverride(bind.ViewStats, "/zones"),
+	)
+	if v, err := c.GetView(context.Background(), bind.DefaultView); err != nil || v.Name != bind.DefaultView {
+		t.Errorf("want view %s, got %q (%v)", bind.DefaultView, v.Name, err)
+	}
+	if _, err := c.GetView(context.Background(), "missing"); !errors.Is(err, bind.ErrViewNotFound) {
+		t.Errorf("want view not found error, got %v", err)
+	}
+}
+
+func TestInvalidQueryRTT(t *testing.T) {
+	b, err := os.ReadFile("../../fixtures/json/server.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b = bytes.Replace(b, []byte(`"QryRTT10"`), []byte(`"QryRTTten"`), 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case ServerPath:
+			w.Write(b)
+		case ZonesPath:
+			http.ServeFile(w, r, "../../fixtures/json/zones.json")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	// The view is kept without histogram.
+	s, err := NewClient(ts.URL, nil).Stats(context.Background(), bind.ServerStats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var codes []string
+	for _, w := range s.Warnings {
+		codes = append(codes, w.Code)
+	}
+	if want := []string{bind.WarnInvalidHistogram}; len(s.Views) == 0 || !reflect.DeepEqual(codes, want) {
+		t.Fatalf("want views with warnings %v, got %d views with %v", want, len(s.Views), s.Warnings)
+	}
+	if h := s.Views[0].QueryRTT; h.Count != 0 || len(s.Views[0].ResolverStats) == 0 {
+		t.Errorf("want resolver statistics without histogram, got %v", h)
+	}
+}
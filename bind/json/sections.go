@@ -0,0 +1,49 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+)
+
+// decodeSections passes the members of the root object of the document read
+// from r which have a decoder in decoders to their decoder, see
+// bind.WithJSONSectionDecoder, and returns the results by key.
+func decodeSections(r io.Reader, decoders map[string]bind.JSONSectionDecoder) (map[string]any, error) {
+	dec := json.NewDecoder(r)
+	var results map[string]any
+	err := decodeObject(dec, func(key string) error {
+		fn, ok := decoders[key]
+		if !ok {
+			return dec.Decode(&json.RawMessage{})
+		}
+		v, err := fn(dec)
+		if err != nil {
+			return fmt.Errorf("section %q: %w", key, err)
+		}
+		if results == nil {
+			results = map[string]any{}
+		}
+		results[key] = v
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+)
+
+type field int
+
+const (
+	// value is a field decoded by the package.
+	value field = iota
+	// counters is an object of counters with arbitrary names.
+	counters
+	// ignored is a field whose content is known but not decoded.
+	ignored
+)
+
+// knownFields maps the path of every field known to the package to its kind.
+// Names of views are matched by "*".
+var knownFields = map[string]field{
+	"json-stats-version":           value,
+	"boot-time":                    value,
+	"config-time":                  value,
+	"current-time":                 value,
+	"version":                      value,
+	"opcodes":                      counters,
+	"qtypes":                       counters,
+	"nsstats":                      counters,
+	"rcodes":                       counters,
+	"zonestats":                    counters,
+	"sockstats":                    ignored,
+	"memory":                       value,
+	"memory/TotalUse":              value,
+	"memory/InUse":                 value,
+	"memory/Malloced":              value,
+	"memory/BlockSize":             value,
+	"memory/ContextSize":           value,
+	"memory/Lost":                  value,
+	"memory/contexts":              ignored,
+	"socketmgr":                    ignored,
+	"traffic":                      ignored,
+	"taskmgr":                      value,
+	"taskmgr/tasks-running":        value,
+	"taskmgr/worker-threads":       value,
+	"taskmgr/tasks-ready":          ignored,
+	"taskmgr/thread-model":         ignored,
+	"taskmgr/default-quantum":      ignored,
+	"taskmgr/tasks":                ignored,
+	"views":                        value,
+	"views/*":                      value,
+	"views/*/resolver":             value,
+	"views/*/resolver/cache":       counters,
+	"views/*/resolver/qtypes":      counters,
+	"views/*/resolver/stats":       counters,
+	"views/*/resolver/adb":         ignored,
+	"views/*/resolver/cachestats":  counters,
+	"views/*/zones":                value,
+	"views/*/zones/name":           value,
+	"views/*/zones/class":          value,
+	"views/*/zones/serial":         value,
+	"views/*/zones/type":           value,
+	"views/*/zones/loaded":         value,
+	"views/*/zones/expires":        value,
+	"views/*/zones/refresh":        value,
+	"views/*/zones/zonestats":      counters,
+	"views/*/zones/dnssec-sign":    counters,
+	"views/*/zones/dnssec-refresh": counters,
+	"views/*/zones/rcodes":         counters,
+	"views/*/zones/qtypes":         counters,
+}
+
+// checkStrict returns an error wrapping bind.ErrUnknownItem which lists every
+// field of the document read from r which is unknown to the package.
+func checkStrict(r io.Reader) error {
+	var doc interface{}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return err
+	}
+	var unknown []string
+	walk(doc, "", "", &unknown)
+	if len(unknown) > 0 {
+		return fmt.Errorf("%w: %s", bind.ErrUnknownItem, strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// walk appends the unknown fields of v to unknown. The path of v is given
+// both with view names replaced by "*", which is matched against knownFields,
+// and verbatim for reporting.
+func walk(v interface{}, pattern, path string, unknown *[]string) {
+	switch v := v.(type) {
+	case []interface{}:
+		for i, e := range v {
+			walk(e, pattern, fmt.Sprintf("%s[%d]", path, i), unknown)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := k
+			switch pattern {
+			case "":
+			case "views":
+				p = "views/*"
+			default:
+				p = pattern + "/" + k
+			}
+			kind, ok := knownFields[p]
+			if !ok {
+				*unknown = append(*unknown, fmt.Sprintf("field %s/%s", path, k))
+				continue
+			}
+			if kind == value {
+				walk(v[k], p, path+"/"+k, unknown)
+			}
+		}
+	}
+}
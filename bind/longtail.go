@@ -0,0 +1,88 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+// OtherCounter is the name of the counter holding the sum of the counters
+// aggregated by AggregateLongTail.
+const OtherCounter = "other"
+
+// AggregateLongTail replaces the query type and opcode counters of s which
+// are not listed in keep by a single counter named OtherCounter per section
+// holding their sum, so that the total of every section is preserved. It
+// covers the incoming queries and requests of the server, the resolver
+// queries of views and the incoming queries of zones. Query types are
+// compared by their canonical name, see ClassifyQType, so that keeping AAAA
+// keeps TYPE28 as well. Sums exceeding math.MaxUint64 are saturated and
+// reported by a WarnCounterOverflow warning of s.
+func AggregateLongTail(s *Statistics, keep []string) {
+	qtypes := make(map[string]bool, len(keep))
+	opcodes := make(map[string]bool, len(keep))
+	for _, name := range keep {
+		canonical, _ := ClassifyQType(name)
+		qtypes[canonical] = true
+		opcodes[name] = true
+	}
+	isQType := func(name string) bool {
+		canonical, _ := ClassifyQType(name)
+		return qtypes[canonical]
+	}
+	isOpcode := func(name string) bool { return opcodes[name] }
+
+	var ws []Warning
+	aggregate := func(what string, cs []Counter, kept func(string) bool) []Counter {
+		cs, overflow := aggregateCounters(cs, kept)
+		if overflow {
+			ws = append(ws, overflowWarning(what+" aggregated as "+OtherCounter))
+		}
+		return cs
+	}
+	s.Server.IncomingQueries = aggregate("incoming queries", s.Server.IncomingQueries, isQType)
+	s.Server.IncomingRequests = aggregate("incoming requests", s.Server.IncomingRequests, isOpcode)
+	for i := range s.Views {
+		s.Views[i].ResolverQueries = aggregate("resolver queries of view "+s.Views[i].Name, s.Views[i].ResolverQueries, isQType)
+	}
+	for i := range s.ZoneViews {
+		zs := s.ZoneViews[i].ZoneData
+		for j := range zs {
+			zs[j].IncomingQueries = aggregate("incoming queries of zone "+zs[j].Name, zs[j].IncomingQueries, isQType)
+		}
+	}
+	s.Warnings = append(s.Warnings, ws...)
+}
+
+// aggregateCounters sums the counters of cs for which kept returns false into
+// a trailing OtherCounter and reports whether the sum overflowed. It returns
+// cs unchanged if all counters are kept.
+func aggregateCounters(cs []Counter, kept func(string) bool) ([]Counter, bool) {
+	var (
+		out      []Counter
+		other    uint64
+		n        int
+		overflow bool
+	)
+	for _, c := range cs {
+		if kept(c.Name) {
+			out = append(out, c)
+			continue
+		}
+		var o bool
+		other, o = AddCounters(other, c.Counter)
+		overflow = overflow || o
+		n++
+	}
+	if n == 0 {
+		return cs, false
+	}
+	return append(out, Counter{Name: OtherCounter, Counter: other}), overflow
+}
@@ -0,0 +1,57 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestAggregateLongTail(t *testing.T) {
+	s := Statistics{
+		Server: Server{
+			IncomingQueries:  []Counter{{Name: "A", Counter: 10}, {Name: "TYPE28", Counter: 4}, {Name: "TYPE4711", Counter: 1}, {Name: "NAPTR", Counter: 2}},
+			IncomingRequests: []Counter{{Name: CounterQUERY, Counter: 20}},
+		},
+		ZoneViews: []ZoneView{{Name: "_default", ZoneData: []ZoneCounter{{Name: "example.com", IncomingQueries: []Counter{{Name: "MX", Counter: 3}}}}}},
+	}
+	AggregateLongTail(&s, []string{"a", "AAAA", CounterQUERY})
+
+	want := []Counter{{Name: "A", Counter: 10}, {Name: "TYPE28", Counter: 4}, {Name: OtherCounter, Counter: 3}}
+	if !reflect.DeepEqual(s.Server.IncomingQueries, want) {
+		t.Errorf("want query types %v, got %v", want, s.Server.IncomingQueries)
+	}
+	if want := []Counter{{Name: CounterQUERY, Counter: 20}}; !reflect.DeepEqual(s.Server.IncomingRequests, want) {
+		t.Errorf("want opcodes without other counter, got %v", s.Server.IncomingRequests)
+	}
+	if want := []Counter{{Name: OtherCounter, Counter: 3}}; !reflect.DeepEqual(s.ZoneViews[0].ZoneData[0].IncomingQueries, want) {
+		t.Errorf("want zone query types %v, got %v", want, s.ZoneViews[0].ZoneData[0].IncomingQueries)
+	}
+}
+
+func TestAggregateLongTailOverflow(t *testing.T) {
+	s := Statistics{Server: Server{
+		IncomingQueries: []Counter{{Name: "A", Counter: 1}, {Name: "NAPTR", Counter: math.MaxUint64}, {Name: "SRV", Counter: 2}},
+	}}
+	AggregateLongTail(&s, []string{"A"})
+
+	want := []Counter{{Name: "A", Counter: 1}, {Name: OtherCounter, Counter: math.MaxUint64}}
+	if !reflect.DeepEqual(s.Server.IncomingQueries, want) {
+		t.Errorf("want saturated query types %v, got %v", want, s.Server.IncomingQueries)
+	}
+	if len(s.Warnings) != 1 || s.Warnings[0].Code != WarnCounterOverflow {
+		t.Errorf("want overflow warning, got %v", s.Warnings)
+	}
+}
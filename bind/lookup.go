@@ -0,0 +1,78 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+// ZoneInView is a zone found by Statistics.Zone.
+type ZoneInView struct {
+	// View is the name of the view holding the zone.
+	View string
+	// Zone points into the ZoneViews of the statistics.
+	Zone *ZoneCounter
+}
+
+// zoneIndex maps the keys of the zone names, see ZoneKey, to the positions of
+// the zones in the ZoneViews it has been built for.
+type zoneIndex struct {
+	views []ZoneView
+	zones map[string][][2]int
+}
+
+// valid reports whether the index has been built for views. Replacing the
+// ZoneViews or adding views invalidates it.
+func (idx *zoneIndex) valid(views []ZoneView) bool {
+	if idx == nil || len(idx.views) != len(views) {
+		return false
+	}
+	return len(views) == 0 || &idx.views[0] == &views[0]
+}
+
+// Zone returns the zones of the given name in all views, in the order of the
+// views. Names are compared case-insensitively and regardless of a trailing
+// dot. An index of the zones is built on the first call and reused until the
+// ZoneViews are replaced, e.g. by Merge, so Zone must not be called
+// concurrently on the same statistics. Zones added to the views in place
+// require calling Merge or Clone to be found.
+func (s *Statistics) Zone(name string) []ZoneInView {
+	if !s.zones.valid(s.ZoneViews) {
+		s.zones = newZoneIndex(s.ZoneViews)
+	}
+	var zs []ZoneInView
+	for _, p := range s.zones.zones[ZoneKey(name)] {
+		v := &s.ZoneViews[p[0]]
+		zs = append(zs, ZoneInView{View: v.Name, Zone: &v.ZoneData[p[1]]})
+	}
+	return zs
+}
+
+func newZoneIndex(views []ZoneView) *zoneIndex {
+	idx := &zoneIndex{views: views, zones: map[string][][2]int{}}
+	for i, v := range views {
+		for j, z := range v.ZoneData {
+			k := ZoneKey(z.Name)
+			idx.zones[k] = append(idx.zones[k], [2]int{i, j})
+		}
+	}
+	return idx
+}
+
+// View returns the view of the given name, which is compared exactly as view
+// names are case-sensitive.
+func (s *Statistics) View(name string) (*View, bool) {
+	for i := range s.Views {
+		if s.Views[i].Name == name {
+			return &s.Views[i], true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,95 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import "testing"
+
+func lookupStatistics() Statistics {
+	return Statistics{
+		Views: []View{{Name: "internal"}, {Name: "external"}},
+		ZoneViews: []ZoneView{
+			{Name: "internal", ZoneData: []ZoneCounter{{Name: "example.com", Serial: "1"}, {Name: "example.net", Serial: "2"}}},
+			{Name: "external", ZoneData: []ZoneCounter{{Name: "Example.COM.", Serial: "3"}}},
+			{Name: "_bind"},
+		},
+	}
+}
+
+func TestZone(t *testing.T) {
+	s := lookupStatistics()
+	for _, name := range []string{"example.com", "EXAMPLE.com", "example.com.", "eXample.Com."} {
+		zs := s.Zone(name)
+		if len(zs) != 2 {
+			t.Fatalf("%s: want 2 zones, got %+v", name, zs)
+		}
+		if zs[0].View != "internal" || zs[0].Zone.Serial != "1" || zs[1].View != "external" || zs[1].Zone.Serial != "3" {
+			t.Errorf("%s: unexpected zones %+v %+v", name, zs[0], zs[1])
+		}
+	}
+	if zs := s.Zone("example.org"); zs != nil {
+		t.Errorf("want no zones, got %+v", zs)
+	}
+	if zs := (&Statistics{}).Zone("example.com"); zs != nil {
+		t.Errorf("want no zones without views, got %+v", zs)
+	}
+
+	// The zones point into the statistics.
+	s.Zone("example.net")[0].Zone.Serial = "4"
+	if got := s.ZoneViews[0].ZoneData[1].Serial; got != "4" {
+		t.Errorf("want serial modified through zone, got %s", got)
+	}
+}
+
+func TestZoneMerge(t *testing.T) {
+	s := lookupStatistics()
+	if zs := s.Zone("example.org"); zs != nil {
+		t.Fatalf("want no zones, got %+v", zs)
+	}
+	s.Merge(Statistics{ZoneViews: []ZoneView{{Name: "internal", ZoneData: []ZoneCounter{{Name: "EXAMPLE.org.", Serial: "5"}}}}}, ViewStats)
+	if zs := s.Zone("example.org"); len(zs) != 1 || zs[0].Zone.Serial != "5" {
+		t.Errorf("want merged zone, got %+v", zs)
+	}
+	if zs := s.Zone("example.com"); zs != nil {
+		t.Errorf("want zones replaced by merge, got %+v", zs)
+	}
+
+	// Views added to the statistics invalidate the index as well.
+	s.ZoneViews = append(s.ZoneViews, ZoneView{Name: "external", ZoneData: []ZoneCounter{{Name: "example.org"}}})
+	if zs := s.Zone("example.org."); len(zs) != 2 || zs[1].View != "external" {
+		t.Errorf("want zone of added view, got %+v", zs)
+	}
+
+	c := s.Clone()
+	c.ZoneViews[0].ZoneData[0].Serial = "6"
+	if got := s.Zone("example.org")[0].Zone.Serial; got != "5" {
+		t.Errorf("want clone independent of original, got serial %s", got)
+	}
+	if got := c.Zone("example.org")[0].Zone.Serial; got != "6" {
+		t.Errorf("want zones of clone, got serial %s", got)
+	}
+}
+
+func TestView(t *testing.T) {
+	s := lookupStatistics()
+	v, ok := s.View("external")
+	if !ok || v != &s.Views[1] {
+		t.Errorf("want view external, got %+v, %v", v, ok)
+	}
+	if _, ok := s.View("External"); ok {
+		t.Error("want view names compared exactly")
+	}
+	if v, ok := s.View("_bind"); ok || v != nil {
+		t.Errorf("want no view, got %+v", v)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import "sort"
+
+// Memory holds the memory statistics of named.
+type Memory struct {
+	Summary MemorySummary
+	// Contexts lists the memory contexts of named in the order of the
+	// document. Their number grows with the number of zones and views, see
+	// TopMemoryContexts.
+	Contexts []MemoryContext
+}
+
+// MemorySummary holds the memory summary of named in bytes, see
+// MemoryCounters. Values not reported by the version of BIND are zero, e.g.
+// Malloced, which older releases do not report.
+type MemorySummary struct {
+	TotalUse    uint64
+	InUse       uint64
+	Malloced    uint64
+	BlockSize   uint64
+	ContextSize uint64
+	Lost        uint64
+}
+
+// Reported reports whether the server reported a memory summary.
+func (m MemorySummary) Reported() bool {
+	return m != MemorySummary{}
+}
+
+// Gauges returns the values of m as gauges named after the statistics of
+// MemoryCounters, in the order of the catalog.
+func (m MemorySummary) Gauges() []Gauge {
+	return []Gauge{
+		{Name: CounterTotalUse, Gauge: m.TotalUse},
+		{Name: CounterInUse, Gauge: m.InUse},
+		{Name: CounterMalloced, Gauge: m.Malloced},
+		{Name: CounterBlockSize, Gauge: m.BlockSize},
+		{Name: CounterContextSize, Gauge: m.ContextSize},
+		{Name: CounterLost, Gauge: m.Lost},
+	}
+}
+
+// MemoryContext holds the statistics of a memory context of named in bytes.
+type MemoryContext struct {
+	ID         string
+	Name       string
+	References uint64
+	Total      uint64
+	InUse      uint64
+	MaxInUse   uint64
+}
+
+// DefaultMemoryContexts is the number of memory contexts reported by Flatten
+// and the exposition package unless configured otherwise, see
+// TopMemoryContexts.
+const DefaultMemoryContexts = 10
+
+// OtherMemoryContexts is the name under which TopMemoryContexts sums the
+// memory contexts beyond the top ones.
+const OtherMemoryContexts = "(other)"
+
+// TopMemoryContexts returns the bytes in use by the n memory contexts of cs
+// using most, as gauges named after the contexts. Contexts of the same name,
+// e.g. the pools of the zone manager, are summed first. The remaining
+// contexts are summed into a gauge named OtherMemoryContexts, which is
+// omitted if there are none. The gauges are sorted by decreasing use, ties by
+// name. It returns nil if n is not positive.
+func TopMemoryContexts(cs []MemoryContext, n int) []Gauge {
+	if n <= 0 || len(cs) == 0 {
+		return nil
+	}
+	byName := map[string]uint64{}
+	var gs []Gauge
+	for _, c := range cs {
+		if _, ok := byName[c.Name]; !ok {
+			gs = append(gs, Gauge{Name: c.Name})
+		}
+		byName[c.Name] += c.InUse
+	}
+	for i := range gs {
+		gs[i].Gauge = byName[gs[i].Name]
+	}
+	sort.Slice(gs, func(i, j int) bool {
+		if gs[i].Gauge != gs[j].Gauge {
+			return gs[i].Gauge > gs[j].Gauge
+		}
+		return gs[i].Name < gs[j].Name
+	})
+	if len(gs) <= n {
+		return gs
+	}
+	other := Gauge{Name: OtherMemoryContexts}
+	for _, g := range gs[n:] {
+		other.Gauge += g.Gauge
+	}
+	return append(gs[:n:n], other)
+}
@@ -0,0 +1,65 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTopMemoryContexts(t *testing.T) {
+	cs := []MemoryContext{
+		{Name: "main", InUse: 300},
+		{Name: "zonemgr-pool", InUse: 200},
+		{Name: "cache", InUse: 400},
+		{Name: "zonemgr-pool", InUse: 250},
+		{Name: "dst", InUse: 10},
+		{Name: "res0", InUse: 10},
+	}
+	for _, tc := range []struct {
+		n    int
+		want []Gauge
+	}{
+		{n: 0},
+		{n: 2, want: []Gauge{{Name: "zonemgr-pool", Gauge: 450}, {Name: "cache", Gauge: 400}, {Name: OtherMemoryContexts, Gauge: 320}}},
+		{n: 4, want: []Gauge{{Name: "zonemgr-pool", Gauge: 450}, {Name: "cache", Gauge: 400}, {Name: "main", Gauge: 300}, {Name: "dst", Gauge: 10}, {Name: OtherMemoryContexts, Gauge: 10}}},
+		{n: 5, want: []Gauge{{Name: "zonemgr-pool", Gauge: 450}, {Name: "cache", Gauge: 400}, {Name: "main", Gauge: 300}, {Name: "dst", Gauge: 10}, {Name: "res0", Gauge: 10}}},
+	} {
+		if got := TopMemoryContexts(cs, tc.n); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("n %d: want %v, got %v", tc.n, tc.want, got)
+		}
+	}
+}
+
+func TestMemorySummaryGauges(t *testing.T) {
+	m := MemorySummary{TotalUse: 1, InUse: 2, Malloced: 3, BlockSize: 4, ContextSize: 5, Lost: 6}
+	gs := m.Gauges()
+	var names []string
+	for _, c := range CounterCatalog() {
+		if c.Group == MemoryCounters {
+			names = append(names, c.Name)
+		}
+	}
+	if len(gs) != len(names) {
+		t.Fatalf("want a gauge per catalog entry %v, got %v", names, gs)
+	}
+	for i, g := range gs {
+		if g.Name != names[i] || g.Gauge != uint64(i+1) {
+			t.Errorf("want gauge %d to be %s of value %d, got %v", i, names[i], i+1, g)
+		}
+	}
+	if (MemorySummary{}).Reported() || !m.Reported() {
+		t.Errorf("want only non-zero summaries reported")
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import "fmt"
+
+// Merge copies the statistics of the given groups from o into s, replacing
+// the statistics of these groups in s. ServerStats covers the Server, the
+// Memory and the ClockSkew, ViewStats the Views and ZoneViews, TaskStats the
+// TaskManager, and StatusStats the times of the Server and the ClockSkew. The
+// Source of s is completed from o and the earliest fetch time is kept. The
+// Decode entries of the groups are copied as well. The Extensions of o are
+// added to s regardless of groups. The Warnings of s are recomputed with
+// Validate, keeping the warnings about the documents of s and o, e.g.
+// WarnBlankCounter, which cannot be recomputed, unless Validate reported them
+// again. Their OmittedWarnings are summed. The Format of s is kept; merging
+// statistics of another format adds a WarnMixedFormats warning.
+func (s *Statistics) Merge(o Statistics, groups ...StatisticGroup) {
+	s.zones = nil
+	s.indexed = nil
+	var mixed []Warning
+	if f := o.Source.Format; len(groups) > 0 && f != "" && s.Source.Format != "" && f != s.Source.Format {
+		mixed = append(mixed, Warning{
+			Code:    WarnMixedFormats,
+			Message: fmt.Sprintf("statistics of format %s merged into statistics of format %s", f, s.Source.Format),
+		})
+	}
+	for _, g := range groups {
+		switch g {
+		case ServerStats:
+			s.Server = o.Server
+			s.Memory = o.Memory
+			s.ClockSkew = o.ClockSkew
+			if o.Source.BINDVersion != "" {
+				s.Source.BINDVersion = o.Source.BINDVersion
+			}
+		case ViewStats:
+			s.Views = o.Views
+			s.ZoneViews = o.ZoneViews
+		case TaskStats:
+			s.TaskManager = o.TaskManager
+		case StatusStats:
+			s.Server.BootTime = o.Server.BootTime
+			s.Server.ConfigTime = o.Server.ConfigTime
+			s.Server.CurrentTime = o.Server.CurrentTime
+			s.ClockSkew = o.ClockSkew
+			if o.Source.BINDVersion != "" {
+				s.Source.BINDVersion = o.Source.BINDVersion
+			}
+		}
+		if i, ok := o.Decode[g]; ok {
+			if s.Decode == nil {
+				s.Decode = map[StatisticGroup]DecodeInfo{}
+			}
+			s.Decode[g] = i
+		}
+		for _, m := range o.MissingGroups {
+			if m == g && !containsGroup(s.MissingGroups, g) {
+				s.MissingGroups = append(s.MissingGroups, g)
+			}
+		}
+	}
+	if s.Source.Format == "" {
+		s.Source.Format = o.Source.Format
+	}
+	if s.Source.SchemaVersion == "" {
+		s.Source.SchemaVersion = o.Source.SchemaVersion
+	}
+	if s.Source.ZonesStrategy == "" {
+		s.Source.ZonesStrategy = o.Source.ZonesStrategy
+	}
+	if t := o.Source.FetchTime; !t.IsZero() && (s.Source.FetchTime.IsZero() || t.Before(s.Source.FetchTime)) {
+		s.Source.FetchTime = t
+	}
+	s.AddExtensions(o.Extensions)
+	validated := Validate(*s)
+	recomputed := make(map[Warning]bool, len(validated))
+	for _, w := range validated {
+		recomputed[w] = true
+	}
+	var docs []Warning
+	for _, ws := range [][]Warning{s.Warnings, o.Warnings, mixed} {
+		for _, w := range ws {
+			if w.Code != WarnZoneStatisticsDisabled && !recomputed[w] {
+				docs = append(docs, w)
+				// A warning kept from an earlier merge is not repeated.
+				recomputed[w] = true
+			}
+		}
+	}
+	s.Warnings = append(validated, docs...)
+	s.OmittedWarnings += o.OmittedWarnings
+}
+
+func containsGroup(groups []StatisticGroup, g StatisticGroup) bool {
+	for _, e := range groups {
+		if e == g {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"strconv"
+	"strings"
+)
+
+// optionalGroups lists the statistic groups whose documents do not exist on
+// some BIND versions. A missing document of such a group is not an error on
+// these versions. Versions are compared by major and minor release.
+var optionalGroups = []struct {
+	group StatisticGroup
+	// since is the first release without the document.
+	since [2]int
+}{
+	// The task manager statistics have been removed in BIND 9.17.
+	{group: TaskStats, since: [2]int{9, 17}},
+}
+
+// GroupOptional reports whether the document of group g may legitimately be
+// missing on a server running the given BIND version, e.g. "9.18.12-1-Debian".
+// It returns false if the version cannot be parsed.
+func GroupOptional(g StatisticGroup, version string) bool {
+	v, ok := parseVersion(version)
+	if !ok {
+		return false
+	}
+	for _, o := range optionalGroups {
+		if o.group == g && !versionLess(v, o.since) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseVersion returns the major and minor release of a BIND version.
+func parseVersion(version string) ([2]int, bool) {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return [2]int{}, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return [2]int{}, false
+	}
+	// The minor release may carry a suffix, e.g. "9.18-S1".
+	minor := parts[1]
+	if i := strings.IndexFunc(minor, func(r rune) bool { return r < '0' || r > '9' }); i >= 0 {
+		minor = minor[:i]
+	}
+	m, err := strconv.Atoi(minor)
+	if err != nil {
+		return [2]int{}, false
+	}
+	return [2]int{major, m}, true
+}
+
+func versionLess(a, b [2]int) bool {
+	return a[0] < b[0] || a[0] == b[0] && a[1] < b[1]
+}
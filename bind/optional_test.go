@@ -0,0 +1,39 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import "testing"
+
+func TestGroupOptional(t *testing.T) {
+	for _, tc := range []struct {
+		version string
+		group   StatisticGroup
+		want    bool
+	}{
+		{version: "9.11.31", group: TaskStats, want: false},
+		{version: "9.16.48", group: TaskStats, want: false},
+		{version: "9.17.0", group: TaskStats, want: true},
+		{version: "9.18.12-1-Debian", group: TaskStats, want: true},
+		{version: "9.20-S1", group: TaskStats, want: true},
+		{version: "10.0.0", group: TaskStats, want: true},
+		{version: "9.18.12", group: ServerStats, want: false},
+		{version: "9.18.12", group: ViewStats, want: false},
+		{version: "", group: TaskStats, want: false},
+		{version: "unknown", group: TaskStats, want: false},
+	} {
+		if got := GroupOptional(tc.group, tc.version); got != tc.want {
+			t.Errorf("GroupOptional(%q, %q): want %t, got %t", tc.group, tc.version, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind_test
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/bindtest"
+	bindjson "github.com/prometheus-community/bind_exporter/bind/json"
+	"github.com/prometheus-community/bind_exporter/bind/xml"
+	"github.com/prometheus-community/bind_exporter/fixtures"
+)
+
+// orderFixtures are the server and zones documents decoded by the order
+// tests, by format. The documents of both formats hold the same statistics.
+var orderFixtures = map[bind.Format][2]string{
+	bind.FormatXMLv3:  {"xml/server.xml", "xml/zones.xml"},
+	bind.FormatJSONv1: {"json/server.json", "json/zones.json"},
+}
+
+// decodeOrderFixtures returns the statistics of the fixtures of format f,
+// without the fields which depend on the time of decoding.
+func decodeOrderFixtures(t *testing.T, f bind.Format) bind.Statistics {
+	t.Helper()
+	ts := bindtest.NewServerWith(map[string]string{
+		xml.ServerPath:      orderFixtures[f][0],
+		xml.ZonesPath:       orderFixtures[f][1],
+		bindjson.ServerPath: orderFixtures[f][0],
+		bindjson.ZonesPath:  orderFixtures[f][1],
+	})
+	defer ts.Close()
+	var c bind.Client = xml.NewClient(ts.URL, nil)
+	if f == bind.FormatJSONv1 {
+		c = bindjson.NewClient(ts.URL, nil)
+	}
+	s, err := c.Stats(context.Background(), bind.ServerStats, bind.ViewStats)
+	if err != nil {
+		t.Fatalf("%s: %s", f, err)
+	}
+	s.Source.FetchTime = time.Time{}
+	s.ClockSkew = 0
+	s.Decode = nil
+	return s
+}
+
+// orderOf returns the names of the lists of s in their order.
+func orderOf(s bind.Statistics) []string {
+	var out []string
+	names := func(what string, cs []bind.Counter) {
+		for _, c := range cs {
+			out = append(out, what+"/"+c.Name)
+		}
+	}
+	names("qtypes", s.Server.IncomingQueries)
+	names("opcodes", s.Server.IncomingRequests)
+	names("nsstats", s.Server.NameServerStats)
+	names("zonestats", s.Server.ZoneMaintenance)
+	names("rcodes", s.Server.ServerRcodes)
+	for _, v := range s.Views {
+		out = append(out, "view/"+v.Name)
+		for _, g := range v.Cache {
+			out = append(out, v.Name+"/cache/"+g.Name)
+		}
+		names(v.Name+"/resstats", v.ResolverStats)
+		names(v.Name+"/resqtypes", v.ResolverQueries)
+	}
+	for _, v := range s.ZoneViews {
+		out = append(out, "zoneview/"+v.Name)
+		for _, z := range v.ZoneData {
+			out = append(out, v.Name+"/zone/"+z.Name)
+			names(v.Name+"/"+z.Name+"/rcodes", z.QueryResults)
+			names(v.Name+"/"+z.Name+"/qtypes", z.IncomingQueries)
+		}
+	}
+	return out
+}
+
+func TestStatisticsOrderStable(t *testing.T) {
+	for f := range orderFixtures {
+		want := decodeOrderFixtures(t, f)
+		for i := 0; i < 10; i++ {
+			if got := decodeOrderFixtures(t, f); !reflect.DeepEqual(got, want) {
+				t.Fatalf("%s: decoding %d differs:\n%v\nwant:\n%v", f, i, orderOf(got), orderOf(want))
+			}
+		}
+	}
+}
+
+func TestStatisticsOrderJSONSorted(t *testing.T) {
+	s := decodeOrderFixtures(t, bind.FormatJSONv1)
+	sorted := func(what string, names []string) {
+		if !sort.StringsAreSorted(names) {
+			t.Errorf("want %s sorted, got %v", what, names)
+		}
+	}
+	counters := func(what string, cs []bind.Counter) {
+		var names []string
+		for _, c := range cs {
+			names = append(names, c.Name)
+		}
+		sorted(what, names)
+	}
+	counters("server qtypes", s.Server.IncomingQueries)
+	counters("server nsstats", s.Server.NameServerStats)
+	counters("server rcodes", s.Server.ServerRcodes)
+	var views []string
+	for _, v := range s.Views {
+		views = append(views, v.Name)
+		counters(v.Name+" resstats", v.ResolverStats)
+		counters(v.Name+" resqtypes", v.ResolverQueries)
+		var cache []string
+		for _, g := range v.Cache {
+			cache = append(cache, g.Name)
+		}
+		sorted(v.Name+" cache", cache)
+	}
+	sorted("views", views)
+	var zoneViews []string
+	for _, v := range s.ZoneViews {
+		zoneViews = append(zoneViews, v.Name)
+		for _, z := range v.ZoneData {
+			counters(v.Name+"/"+z.Name+" rcodes", z.QueryResults)
+			counters(v.Name+"/"+z.Name+" qtypes", z.IncomingQueries)
+		}
+	}
+	sorted("zone views", zoneViews)
+}
+
+func TestStatisticsOrderXMLDocument(t *testing.T) {
+	s := decodeOrderFixtures(t, bind.FormatXMLv3)
+	b, err := fs.ReadFile(fixtures.FS, orderFixtures[bind.FormatXMLv3][0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The counters of the server keep the order of the document, which
+	// is not sorted.
+	var names []string
+	for _, c := range s.Server.NameServerStats {
+		names = append(names, c.Name)
+	}
+	if sort.StringsAreSorted(names) {
+		t.Fatalf("want nsstats of %s in document order, fixture is sorted", orderFixtures[bind.FormatXMLv3][0])
+	}
+	doc := string(b)
+	for _, n := range names {
+		i := strings.Index(doc, fmt.Sprintf("name=%q", n))
+		if i < 0 {
+			t.Fatalf("nsstat %s out of document order: %v", n, names)
+		}
+		doc = doc[i:]
+	}
+}
+
+func TestStatisticsOrderAcrossFormats(t *testing.T) {
+	// keys returns the identities of the metrics of s in the order of
+	// Flatten, and the set of them.
+	keys := func(s bind.Statistics) ([]string, map[string]bool) {
+		var out []string
+		set := map[string]bool{}
+		for _, m := range bind.Flatten(s) {
+			labels := make([]string, 0, len(m.Labels))
+			for k, v := range m.Labels {
+				labels = append(labels, k+"="+v)
+			}
+			sort.Strings(labels)
+			k := m.Name + "{" + strings.Join(labels, ",") + "}"
+			out = append(out, k)
+			set[k] = true
+		}
+		return out, set
+	}
+	x, xs := keys(decodeOrderFixtures(t, bind.FormatXMLv3))
+	j, js := keys(decodeOrderFixtures(t, bind.FormatJSONv1))
+
+	// The fixtures of the formats differ in some counters, so only the
+	// metrics of both are compared.
+	common := func(ks []string, other map[string]bool) []string {
+		var out []string
+		for _, k := range ks {
+			if other[k] {
+				out = append(out, k)
+			}
+		}
+		return out
+	}
+	cx, cj := common(x, js), common(j, xs)
+	if len(cx) < 20 {
+		t.Fatalf("want the formats to share metrics, got %d", len(cx))
+	}
+	if !reflect.DeepEqual(cx, cj) {
+		t.Errorf("want the shared metrics of both formats in the same order, got\n%v\nand\n%v", cx, cj)
+	}
+}
@@ -0,0 +1,700 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+// Sample is the result of a single poll.
+type Sample struct {
+	// Time is the local time the statistics have been fetched.
+	Time  time.Time
+	Stats Statistics
+	// Delta holds the increase of the counters since the previous sample,
+	// see the Delta function. After a restart of named it holds the
+	// counters since the restart. It is nil if there is no usable previous
+	// sample, e.g. for the first sample.
+	Delta *Statistics
+	// Interval is the time elapsed since the previous sample, or since the
+	// restart of named if Restart is set. It is zero if Delta is nil.
+	Interval time.Duration
+	// Partial is set for the samples of the warm-up of Run, see WithWarmup,
+	// which hold only the groups fetched so far. Their Delta is nil and they
+	// do not become the previous sample of the next one.
+	Partial bool
+	// Restart is set if named has been restarted since the previous sample.
+	Restart *Restart
+	// Reload is set if named has been reconfigured since the previous
+	// sample, see Reloaded.
+	Reload *Reload
+	// Window holds the responses by rcode during the window ending with
+	// the sample, see WithRcodeWindow. It is nil unless a window is
+	// configured.
+	Window *RcodeWindow
+	// Derived holds the rates and ratios derived from Delta over Interval,
+	// see DeriveDelta. It is nil if Delta is nil.
+	Derived *Derived
+	// Unchanged is set by a Poller configured with WithEmitOnlyOnChange if
+	// the content of all polled groups equals that of the last emitted
+	// sample, see ContentHash. Run does not deliver such samples unless
+	// Recovered is set.
+	Unchanged bool
+	// Recovered is set by Run to the number of consecutive failed polls
+	// preceding the sample, after which the interval is reset from the
+	// backoff, see BackoffError. It is zero if the previous poll succeeded.
+	Recovered int
+}
+
+// Restart describes a restart of named detected by a Poller from a change of
+// the boot time, see SameBoot.
+type Restart struct {
+	OldBootTime time.Time
+	NewBootTime time.Time
+}
+
+// PollerStats holds the statistics of a Poller itself.
+type PollerStats struct {
+	// Polls is the number of polls, including failed ones.
+	Polls uint64
+	// Failures is the number of failed polls.
+	Failures uint64
+	// Restarts is the number of restarts of named detected.
+	Restarts uint64
+	// Reloads is the number of reconfigurations of named detected.
+	Reloads uint64
+}
+
+// Rate returns the per second rate of an increase n of a counter of the
+// Delta of s, or zero if Delta is nil.
+func (s Sample) Rate(n uint64) float64 {
+	if s.Delta == nil || s.Interval <= 0 {
+		return 0
+	}
+	return float64(n) / s.Interval.Seconds()
+}
+
+// Poller fetches statistics from a Client at a fixed interval and computes
+// the increase of the counters between consecutive polls.
+type Poller struct {
+	client    Client
+	interval  time.Duration
+	groups    []StatisticGroup
+	stateFile string
+	logger    log.Logger
+	clock     Clock
+	now       func() time.Time
+	rand      func() float64
+	// jitterFraction is the maximum relative deviation of intervals.
+	jitterFraction float64
+	// maxBackoff limits the interval after failed polls.
+	maxBackoff time.Duration
+	// onChange suppresses samples whose content equals the last emitted
+	// sample, unless it is older than maxStaleness.
+	onChange     bool
+	maxStaleness time.Duration
+	// warmup spreads the first fetch of the heavy groups, fetching them
+	// warmupGap apart if positive.
+	warmup    bool
+	warmupGap time.Duration
+	// window aggregates the rcode counters if set, guarded by windowMu.
+	window   *rcodeRing
+	windowMu sync.Mutex
+
+	polls    atomic.Uint64
+	failures atomic.Uint64
+	restarts atomic.Uint64
+	reloads  atomic.Uint64
+
+	mu       sync.Mutex
+	last     *Sample
+	restored bool
+	emitted  time.Time
+	hashes   map[StatisticGroup]uint64
+}
+
+// PollerOption configures a Poller.
+type PollerOption func(*Poller)
+
+// WithPollGroups sets the statistic groups fetched by the Poller. By default
+// all groups are fetched.
+func WithPollGroups(groups ...StatisticGroup) PollerOption {
+	return func(p *Poller) {
+		p.groups = groups
+	}
+}
+
+// WithStateFile makes the Poller persist the last sample to path after every
+// poll and restore it before the first poll. This keeps rates available
+// across restarts of the process. A state file which cannot be read, or which
+// is older than ten intervals, is ignored with a warning.
+func WithStateFile(path string) PollerOption {
+	return func(p *Poller) {
+		p.stateFile = path
+	}
+}
+
+// WithEmitOnlyOnChange makes the Poller mark samples as Unchanged, and Run
+// skip them, if the content of every polled group equals that of the last
+// emitted sample. This suits pipelines pushing the statistics somewhere on
+// change. A sample is emitted regardless once the last emitted sample is
+// older than the maximum staleness, see WithMaxStaleness.
+func WithEmitOnlyOnChange() PollerOption {
+	return func(p *Poller) {
+		p.onChange = true
+	}
+}
+
+// WithMaxStaleness sets the age of the last emitted sample after which a
+// Poller configured with WithEmitOnlyOnChange emits a sample even if nothing
+// changed. It defaults to ten intervals.
+func WithMaxStaleness(d time.Duration) PollerOption {
+	return func(p *Poller) {
+		p.maxStaleness = d
+	}
+}
+
+// WithJitter makes Run vary every interval randomly by up to fraction of the
+// interval in either direction, so that the polls of many processes do not
+// synchronize. It defaults to 0.1, i.e. ±10%, and 0 disables jitter.
+func WithJitter(fraction float64) PollerOption {
+	return func(p *Poller) {
+		p.jitterFraction = fraction
+	}
+}
+
+// WithMaxBackoff limits the interval of Run after consecutive failed polls
+// to d. It defaults to ten intervals.
+func WithMaxBackoff(d time.Duration) PollerOption {
+	return func(p *Poller) {
+		p.maxBackoff = d
+	}
+}
+
+// WithWarmup makes Run spread the first fetch of the heavy groups, ViewStats,
+// which includes the zones, and TaskStats, so that many processes started at
+// once, e.g. by a rollout, do not fetch the zones of their servers at the
+// same time. Run fetches the other polled groups immediately and the heavy
+// groups after a random fraction of the interval. If gap is positive, the
+// heavy groups are fetched one at a time, gap apart. See Run.
+func WithWarmup(gap time.Duration) PollerOption {
+	return func(p *Poller) {
+		p.warmup = true
+		p.warmupGap = gap
+	}
+}
+
+// WithPollSeed seeds the random offsets of Run, i.e. the warm-up spread and
+// the jitter, so that they are reproducible, e.g. in tests. By default they
+// are drawn from the global source of math/rand.
+func WithPollSeed(seed int64) PollerOption {
+	return func(p *Poller) {
+		p.rand = rand.New(rand.NewSource(seed)).Float64
+	}
+}
+
+// WithPollClock makes the Poller read the time of samples and wait for its
+// intervals through c. It defaults to SystemClock.
+func WithPollClock(c Clock) PollerOption {
+	return func(p *Poller) {
+		p.clock = c
+	}
+}
+
+// WithPollLogger sets the logger receiving the warnings of the Poller.
+func WithPollLogger(l log.Logger) PollerOption {
+	return func(p *Poller) {
+		p.logger = l
+	}
+}
+
+// NewPoller returns a Poller fetching statistics from c every interval.
+func NewPoller(c Client, interval time.Duration, opts ...PollerOption) *Poller {
+	p := &Poller{
+		client:   c,
+		interval: interval,
+		groups:   []StatisticGroup{ServerStats, ViewStats, TaskStats},
+		logger:   log.NewNopLogger(),
+		clock:    SystemClock(),
+		rand:     rand.Float64,
+
+		jitterFraction: 0.1,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.now = p.clock.Now
+	if p.maxStaleness <= 0 {
+		p.maxStaleness = 10 * interval
+	}
+	if p.maxBackoff <= 0 {
+		p.maxBackoff = 10 * interval
+	}
+	return p
+}
+
+// Clock returns the Clock of p, see WithPollClock.
+func (p *Poller) Clock() Clock {
+	return p.clock
+}
+
+// StateFile returns the path of the state file of p, see WithStateFile, or
+// "" if p has none.
+func (p *Poller) StateFile() string {
+	return p.stateFile
+}
+
+// ErrPollSkipped is passed to the callback of Run if a tick has been skipped
+// because the previous poll was still running.
+var ErrPollSkipped = errors.New("poll skipped, previous poll still running")
+
+// BackoffError is passed to the callback of Run if a poll failed. It carries
+// the interval until the next poll, which doubles with every consecutive
+// failure up to the limit set by WithMaxBackoff.
+type BackoffError struct {
+	// Failures is the number of consecutive failed polls.
+	Failures int
+	// Interval is the time until the next poll, before jitter.
+	Interval time.Duration
+	Err      error
+}
+
+func (e *BackoffError) Error() string {
+	return fmt.Sprintf("poll failed %d times in a row, retrying in %s: %s", e.Failures, e.Interval, e.Err)
+}
+
+func (e *BackoffError) Unwrap() error {
+	return e.Err
+}
+
+// Run polls immediately and then every interval until ctx is done, passing the
+// result of every poll to f, except for Unchanged samples. It returns the
+// error of ctx.
+//
+// Every interval is varied randomly by the jitter set with WithJitter. A poll
+// is not started while the previous one is still running; the tick is skipped
+// and f receives ErrPollSkipped instead. A failed poll is passed to f as a
+// BackoffError and doubles the interval until the next poll, until a poll
+// succeeds again, whose sample is passed to f with Recovered set even if it
+// is Unchanged. The calls of f are sequential.
+//
+// With WithWarmup, Run starts with a warm-up instead: it fetches the cheap
+// groups immediately and the heavy groups after a random fraction of the
+// interval, one at a time if a gap has been set, passing a Partial sample to
+// f after every fetch but the last. The last fetch completes the first full
+// sample, which is passed to f like the samples of later polls, and the
+// interval starts. A failed fetch ends the warm-up.
+func (p *Poller) Run(ctx context.Context, f func(Sample, error)) error {
+	type result struct {
+		s   Sample
+		err error
+	}
+	done := make(chan result, 1)
+	w := p.newWarmup()
+	poll := func() {
+		go func() {
+			var r result
+			if w != nil {
+				r.s, r.err = p.pollWarmup(ctx, w)
+			} else {
+				r.s, r.err = p.Poll(ctx)
+			}
+			done <- r
+		}()
+	}
+
+	failures := 0
+	running := true
+	started := p.now()
+	ticked := started
+	var tick Timer
+	if w != nil && w.heavy() {
+		// Only heavy groups are polled, so the warm-up starts with them.
+		running = false
+		tick = p.clock.NewTimer(w.offset)
+	} else {
+		poll()
+		tick = p.clock.NewTimer(p.jitter(p.interval))
+		if w != nil {
+			// The warm-up schedules its fetches once the previous
+			// one is done.
+			tick.Stop()
+		}
+	}
+	defer tick.Stop()
+	// reset makes tick fire after d, dropping a pending tick.
+	reset := func(d time.Duration) {
+		if !tick.Stop() {
+			select {
+			case <-tick.C():
+			default:
+			}
+		}
+		tick.Reset(d)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case r := <-done:
+			running = false
+			if w != nil && r.err == nil && len(w.steps) > 0 {
+				f(r.s, nil)
+				if !w.spread {
+					reset(w.offset - p.now().Sub(started))
+				} else {
+					reset(w.gap)
+				}
+				continue
+			}
+			if w != nil {
+				w = nil
+				if r.err == nil {
+					reset(p.jitter(p.interval) - p.now().Sub(ticked))
+				}
+			}
+			if r.err != nil {
+				failures++
+				d := p.backoff(failures)
+				f(Sample{}, &BackoffError{Failures: failures, Interval: d, Err: r.err})
+				reset(p.jitter(d) - p.now().Sub(ticked))
+				continue
+			}
+			if failures > 0 {
+				level.Info(p.logger).Log("msg", "Poll succeeded, resetting interval", "failures", failures)
+				r.s.Recovered = failures
+				failures = 0
+				reset(p.jitter(p.interval) - p.now().Sub(ticked))
+			}
+			if !r.s.Unchanged || r.s.Recovered > 0 {
+				f(r.s, nil)
+			}
+		case <-tick.C():
+			ticked = p.now()
+			if w != nil {
+				w.spread = true
+				running = true
+				poll()
+				continue
+			}
+			if running {
+				f(Sample{}, ErrPollSkipped)
+			} else {
+				running = true
+				poll()
+			}
+			tick.Reset(p.jitter(p.backoff(failures)))
+		}
+	}
+}
+
+// backoff returns the interval after the given number of consecutive
+// failures.
+func (p *Poller) backoff(failures int) time.Duration {
+	d := p.interval
+	for i := 0; i < failures && d < p.maxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.maxBackoff {
+		d = p.maxBackoff
+	}
+	return d
+}
+
+// jitter varies d randomly by up to the configured fraction in either
+// direction.
+func (p *Poller) jitter(d time.Duration) time.Duration {
+	return d + time.Duration(p.jitterFraction*(2*p.rand()-1)*float64(d))
+}
+
+// Poll fetches the statistics once and returns them along with the increase
+// of the counters since the previous poll. If the boot time of named changed
+// since the previous poll, the sample reports the Restart and the increase of
+// the counters since the restart. A reconfiguration is reported as Reload.
+func (p *Poller) Poll(ctx context.Context) (Sample, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.restored {
+		p.restored = true
+		p.restore()
+	}
+
+	p.polls.Add(1)
+	stats, err := p.client.Stats(ctx, p.groups...)
+	if err != nil {
+		p.failures.Add(1)
+		return Sample{}, err
+	}
+	return p.sample(stats), nil
+}
+
+// sample returns the sample of stats, fetched just now, and records it as
+// the previous sample. p must be locked.
+func (p *Poller) sample(stats Statistics) Sample {
+	s := Sample{Time: p.now(), Stats: stats}
+	if p.last != nil && p.restarted(&s) {
+		// The counters of named start at zero and are measured from the
+		// boot time instead.
+		prev := Statistics{Source: stats.Source, Server: Server{BootTime: stats.Server.BootTime}}
+		d, err := Delta(prev, stats)
+		if err != nil {
+			level.Warn(p.logger).Log("msg", "Cannot compute statistics since restart", "err", err)
+		} else {
+			s.Delta = &d
+			s.Interval = p.sinceBoot(s, p.last.Time)
+		}
+	} else if p.last != nil {
+		if s.Reload = Reloaded(p.last.Stats, stats); s.Reload != nil {
+			p.reloads.Add(1)
+			level.Info(p.logger).Log("msg", "named has been reconfigured", "added_views", len(s.Reload.AddedViews), "removed_views", len(s.Reload.RemovedViews), "added_zones", len(s.Reload.AddedZones), "removed_zones", len(s.Reload.RemovedZones))
+		}
+		d, err := Delta(p.last.Stats, stats)
+		switch {
+		case err == nil:
+			s.Delta = &d
+			s.Interval = s.Time.Sub(p.last.Time)
+		case errors.Is(err, ErrCounterReset):
+			level.Info(p.logger).Log("msg", "Counters have been reset, starting new baseline", "err", err)
+		default:
+			level.Warn(p.logger).Log("msg", "Cannot compare statistics to previous poll", "err", err)
+		}
+	}
+	if s.Delta != nil {
+		d := DeriveDelta(*s.Delta, s.Interval)
+		s.Derived = &d
+	}
+	s.Window = p.addWindow(s)
+	if p.onChange {
+		s.Unchanged = p.unchanged(s)
+	}
+	p.last = &s
+	p.persist(s)
+	return s
+}
+
+// warmup is the state of the warm-up of Run, see WithWarmup.
+type warmup struct {
+	// steps lists the groups of the remaining fetches.
+	steps [][]StatisticGroup
+	// offset is the time from the start of Run to the first fetch of the
+	// heavy groups, and gap the time between their fetches.
+	offset time.Duration
+	gap    time.Duration
+	// spread is set once the first fetch of the heavy groups started.
+	spread bool
+	// stats holds the statistics fetched so far.
+	stats Statistics
+}
+
+// heavyGroup reports whether fetching g is expensive for the server.
+func heavyGroup(g StatisticGroup) bool {
+	return g == ViewStats || g == TaskStats
+}
+
+// newWarmup returns the warm-up of Run, or nil if it is disabled or no heavy
+// group is polled.
+func (p *Poller) newWarmup() *warmup {
+	if !p.warmup {
+		return nil
+	}
+	var cheap, heavy []StatisticGroup
+	for _, g := range p.groups {
+		if heavyGroup(g) {
+			heavy = append(heavy, g)
+		} else {
+			cheap = append(cheap, g)
+		}
+	}
+	if len(heavy) == 0 {
+		return nil
+	}
+	w := &warmup{offset: time.Duration(p.rand() * float64(p.interval)), gap: p.warmupGap}
+	if len(cheap) > 0 {
+		w.steps = append(w.steps, cheap)
+	}
+	if w.gap <= 0 {
+		w.steps = append(w.steps, heavy)
+	} else {
+		for _, g := range heavy {
+			w.steps = append(w.steps, []StatisticGroup{g})
+		}
+	}
+	return w
+}
+
+// heavy reports whether the next fetch of w is that of heavy groups.
+func (w *warmup) heavy() bool {
+	return heavyGroup(w.steps[0][0])
+}
+
+// pollWarmup performs the next fetch of w. It returns a Partial sample unless
+// the fetch completed the warm-up.
+func (p *Poller) pollWarmup(ctx context.Context, w *warmup) (Sample, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.restored {
+		p.restored = true
+		p.restore()
+	}
+
+	groups := w.steps[0]
+	p.polls.Add(1)
+	stats, err := p.client.Stats(ctx, groups...)
+	if err != nil {
+		p.failures.Add(1)
+		return Sample{}, err
+	}
+	w.steps = w.steps[1:]
+	if w.stats.Source.Format == "" {
+		w.stats = stats
+	} else {
+		w.stats.Merge(stats, groups...)
+	}
+	if len(w.steps) > 0 {
+		return Sample{Time: p.now(), Stats: w.stats.Clone(), Partial: true}, nil
+	}
+	return p.sample(w.stats), nil
+}
+
+// Stats returns the statistics of the Poller. It does not block while a poll
+// is running.
+func (p *Poller) Stats() PollerStats {
+	return PollerStats{
+		Polls:    p.polls.Load(),
+		Failures: p.failures.Load(),
+		Restarts: p.restarts.Load(),
+		Reloads:  p.reloads.Load(),
+	}
+}
+
+// restarted reports whether the boot time of s differs from that of the
+// previous sample, and records the restart in s.
+func (p *Poller) restarted(s *Sample) bool {
+	old, cur := p.last.Stats.Server.BootTime, s.Stats.Server.BootTime
+	if old.IsZero() || cur.IsZero() || SameBoot(old, cur) {
+		return false
+	}
+	s.Restart = &Restart{OldBootTime: old, NewBootTime: cur}
+	p.restarts.Add(1)
+	level.Info(p.logger).Log("msg", "named has been restarted, measuring counters from zero", "boot_time", cur, "previous_boot_time", old)
+	return true
+}
+
+// sinceBoot returns the local time elapsed between the boot of named and s,
+// corrected by the clock skew. It is limited to the time since the previous
+// sample taken at last, during which named has been restarted.
+func (p *Poller) sinceBoot(s Sample, last time.Time) time.Duration {
+	limit := s.Time.Sub(last)
+	d := s.Time.Sub(s.Stats.Server.BootTime.Add(-s.Stats.ClockSkew))
+	if d <= 0 || d > limit {
+		return limit
+	}
+	return d
+}
+
+// unchanged reports whether the content of s equals that of the last emitted
+// sample, which has not become stale, and otherwise records s as emitted.
+func (p *Poller) unchanged(s Sample) bool {
+	hashes := make(map[StatisticGroup]uint64, len(p.groups))
+	same := p.hashes != nil && s.Time.Sub(p.emitted) < p.maxStaleness
+	for _, g := range p.groups {
+		hashes[g] = ContentHash(s.Stats, g)
+		if h, ok := p.hashes[g]; !ok || h != hashes[g] {
+			same = false
+		}
+	}
+	if same {
+		return true
+	}
+	p.hashes = hashes
+	p.emitted = s.Time
+	return false
+}
+
+// state is the content of the state file.
+type state struct {
+	Time  time.Time
+	Stats Statistics
+}
+
+func (p *Poller) restore() {
+	if p.stateFile == "" {
+		return
+	}
+	b, err := os.ReadFile(p.stateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		level.Warn(p.logger).Log("msg", "Ignoring unreadable state file", "path", p.stateFile, "err", err)
+		return
+	}
+	var st state
+	if err := json.Unmarshal(b, &st); err != nil {
+		level.Warn(p.logger).Log("msg", "Ignoring corrupt state file", "path", p.stateFile, "err", err)
+		return
+	}
+	if age := p.now().Sub(st.Time); age > 10*p.interval || age < 0 {
+		level.Warn(p.logger).Log("msg", "Ignoring stale state file", "path", p.stateFile, "age", age)
+		return
+	}
+	p.last = &Sample{Time: st.Time, Stats: st.Stats}
+}
+
+// persist atomically replaces the state file by the state of s.
+func (p *Poller) persist(s Sample) {
+	if p.stateFile == "" {
+		return
+	}
+	if err := writeState(p.stateFile, state{Time: s.Time, Stats: s.Stats}); err != nil {
+		level.Warn(p.logger).Log("msg", "Failed to write state file", "path", p.stateFile, "err", err)
+	}
+}
+
+func writeState(path string, st state) error {
+	b, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace state file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,861 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus-community/bind_exporter/bind/internal/clock"
+)
+
+// countingClient returns statistics whose QUERY opcode counter has the value
+// of queries, reported by a server booted at boot.
+type countingClient struct {
+	boot    time.Time
+	queries uint64
+}
+
+func (c *countingClient) Stats(context.Context, ...StatisticGroup) (Statistics, error) {
+	return NewStatisticsBuilder().
+		Source(Source{Format: FormatJSONv1}).
+		Times(c.boot, time.Time{}, time.Time{}).
+		Server("opcodes", map[string]uint64{"QUERY": c.queries}).
+		Build()
+}
+
+func newTestPoller(c Client, now *time.Time, opts ...PollerOption) *Poller {
+	p := NewPoller(c, time.Minute, opts...)
+	p.now = func() time.Time { return *now }
+	return p
+}
+
+func TestPollerStateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	boot := time.Date(2024, 3, 15, 8, 0, 0, 0, time.UTC)
+	now := boot.Add(time.Hour)
+	c := &countingClient{boot: boot, queries: 100}
+
+	s, err := newTestPoller(c, &now, WithStateFile(path)).Poll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Delta != nil {
+		t.Fatalf("want no delta without baseline, got %+v", s.Delta)
+	}
+
+	// A new poller continues from the state of the previous one.
+	now = now.Add(2 * time.Minute)
+	c.queries = 340
+	s, err = newTestPoller(c, &now, WithStateFile(path)).Poll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Delta == nil {
+		t.Fatal("want delta from restored state")
+	}
+	if got := s.Delta.Server.IncomingRequests[0].Counter; got != 240 || s.Interval != 2*time.Minute {
+		t.Errorf("want 240 queries in 2m, got %d in %s", got, s.Interval)
+	}
+	if got := s.Rate(240); got != 2 {
+		t.Errorf("want rate 2/s, got %v", got)
+	}
+
+	// named restarted while the process was down.
+	now = now.Add(time.Minute)
+	c.boot = now.Add(-30 * time.Second)
+	c.queries = 12
+	p := newTestPoller(c, &now, WithStateFile(path))
+	if s, err = p.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if s.Restart == nil || s.Delta == nil || s.Delta.Server.IncomingRequests[0].Counter != 12 || s.Interval != 30*time.Second {
+		t.Fatalf("want restart with 12 queries in 30s, got %+v", s)
+	}
+	now = now.Add(time.Minute)
+	c.queries = 72
+	if s, err = p.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if s.Delta == nil || s.Delta.Server.IncomingRequests[0].Counter != 60 {
+		t.Errorf("want delta of 60 queries after new baseline, got %+v", s.Delta)
+	}
+}
+
+func TestPollerIgnoresBadStateFile(t *testing.T) {
+	dir := t.TempDir()
+	boot := time.Date(2024, 3, 15, 8, 0, 0, 0, time.UTC)
+	now := boot.Add(time.Hour)
+	c := &countingClient{boot: boot, queries: 100}
+
+	corrupt := filepath.Join(dir, "corrupt.json")
+	if err := os.WriteFile(corrupt, []byte(`{"Time":`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stale := filepath.Join(dir, "stale.json")
+	if err := writeState(stale, state{Time: now.Add(-11 * time.Minute), Stats: Statistics{Source: Source{Format: FormatJSONv1}, Server: Server{BootTime: boot}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string]string{corrupt: "corrupt", stale: "stale"} {
+		var logged []string
+		logger := log.LoggerFunc(func(keyvals ...interface{}) error {
+			logged = append(logged, strings.ToLower(keyvals[3].(string)))
+			return nil
+		})
+		s, err := newTestPoller(c, &now, WithStateFile(path), WithPollLogger(logger)).Poll(context.Background())
+		if err != nil {
+			t.Fatalf("%s: want state file to be ignored, got %v", want, err)
+		}
+		if s.Delta != nil {
+			t.Errorf("%s: want no delta from ignored state file", want)
+		}
+		if len(logged) != 1 || !strings.Contains(logged[0], want) {
+			t.Errorf("%s: want warning, got %q", want, logged)
+		}
+	}
+}
+
+// scriptedClient returns its responses in turn, failing once they are
+// exhausted.
+type scriptedClient struct {
+	responses []Statistics
+}
+
+func (c *scriptedClient) Stats(context.Context, ...StatisticGroup) (Statistics, error) {
+	if len(c.responses) == 0 {
+		return Statistics{}, errors.New("no more responses")
+	}
+	s := c.responses[0]
+	c.responses = c.responses[1:]
+	return s, nil
+}
+
+func TestPollerRestart(t *testing.T) {
+	boot := time.Date(2024, 3, 15, 8, 0, 0, 0, time.UTC)
+	reboot := boot.Add(150 * time.Minute)
+	stats := func(boot time.Time, queries uint64) Statistics {
+		return Statistics{
+			Source: Source{Format: FormatXMLv3},
+			Server: Server{BootTime: boot, NameServerStats: []Counter{{Name: CounterQrySuccess, Counter: queries}}},
+		}
+	}
+	c := &scriptedClient{responses: []Statistics{
+		stats(boot, 1000),
+		// The same boot reported with a different precision.
+		stats(boot.Add(300*time.Millisecond), 1600),
+		stats(reboot, 150),
+		stats(reboot, 270),
+	}}
+	now := boot.Add(time.Hour)
+	p := newTestPoller(c, &now)
+
+	var samples []Sample
+	for i := 0; i < 4; i++ {
+		s, err := p.Poll(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		samples = append(samples, s)
+		now = now.Add(time.Hour)
+	}
+	if _, err := p.Poll(context.Background()); err == nil {
+		t.Fatal("want error of exhausted client")
+	}
+
+	if s := samples[1]; s.Restart != nil || s.Delta == nil || s.Rate(s.Delta.Server.NameServerStats[0].Counter) != 600.0/3600 {
+		t.Errorf("want boot time jitter not to be taken as restart, got %+v", s)
+	}
+	s := samples[2]
+	want := &Restart{OldBootTime: boot.Add(300 * time.Millisecond), NewBootTime: reboot}
+	if !reflect.DeepEqual(s.Restart, want) {
+		t.Errorf("want restart %+v, got %+v", want, s.Restart)
+	}
+	if s.Delta == nil || s.Delta.Server.NameServerStats[0].Counter != 150 || s.Interval != 30*time.Minute {
+		t.Errorf("want 150 queries in 30m since restart, got %+v", s)
+	}
+	if s := samples[3]; s.Restart != nil || s.Delta == nil || s.Delta.Server.NameServerStats[0].Counter != 120 || s.Interval != time.Hour {
+		t.Errorf("want delta from restart sample, got %+v", s)
+	}
+	if got, want := p.Stats(), (PollerStats{Polls: 5, Failures: 1, Restarts: 1}); got != want {
+		t.Errorf("want poller stats %+v, got %+v", want, got)
+	}
+}
+
+func TestPollerReload(t *testing.T) {
+	boot := time.Date(2024, 3, 15, 8, 0, 0, 0, time.UTC)
+	zone := func(name string, queries uint64) ZoneCounter {
+		return ZoneCounter{Name: name, QueryResults: []Counter{{Name: CounterQrySuccess, Counter: queries}}}
+	}
+	stats := func(config time.Time, zones map[string][]ZoneCounter) Statistics {
+		s := Statistics{
+			Source: Source{Format: FormatJSONv1},
+			Server: Server{BootTime: boot, ConfigTime: config},
+		}
+		for _, name := range []string{"external", "internal", "inside"} {
+			if zs, ok := zones[name]; ok {
+				s.Views = append(s.Views, View{Name: name, ResolverStats: []Counter{{Name: "Queryv4", Counter: 10}}})
+				s.ZoneViews = append(s.ZoneViews, ZoneView{Name: name, ZoneData: zs})
+			}
+		}
+		return s
+	}
+	reload := boot.Add(time.Hour)
+	before := stats(boot, map[string][]ZoneCounter{
+		"external": {zone("example.com", 500), zone("example.net", 40)},
+		"internal": {zone("corp.example", 70)},
+	})
+	// The reload renames internal to inside, moving its zone, adds 100
+	// zones to external and recreates example.net.
+	external := []ZoneCounter{zone("example.com", 800), zone("example.net", 3)}
+	for i := 0; i < 100; i++ {
+		external = append(external, zone(fmt.Sprintf("zone%d.example", i), uint64(i)))
+	}
+	after := stats(reload, map[string][]ZoneCounter{
+		"external": external,
+		"inside":   {zone("corp.example", 90)},
+	})
+
+	now := boot.Add(30 * time.Minute)
+	p := newTestPoller(&scriptedClient{responses: []Statistics{before, after, after}}, &now)
+	if _, err := p.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(time.Hour)
+	s, err := p.Poll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Delta == nil {
+		t.Fatal("want delta across reload")
+	}
+	r := s.Reload
+	if r == nil || !r.OldConfigTime.Equal(boot) || !r.NewConfigTime.Equal(reload) {
+		t.Fatalf("want reload, got %+v", r)
+	}
+	if !reflect.DeepEqual(r.AddedViews, []string{"inside"}) || !reflect.DeepEqual(r.RemovedViews, []string{"internal"}) {
+		t.Errorf("want view internal renamed to inside, got added %v, removed %v", r.AddedViews, r.RemovedViews)
+	}
+	if len(r.AddedZones) != 101 || r.AddedZones[100] != (ZoneRef{View: "inside", Zone: "corp.example"}) {
+		t.Errorf("want 100 added zones plus moved zone, got %d: %v", len(r.AddedZones), r.AddedZones)
+	}
+	if want := []ZoneRef{{View: "internal", Zone: "corp.example"}}; !reflect.DeepEqual(r.RemovedZones, want) {
+		t.Errorf("want removed zones %v, got %v", want, r.RemovedZones)
+	}
+
+	got := map[string]uint64{}
+	for _, v := range s.Delta.ZoneViews {
+		for _, z := range v.ZoneData {
+			got[v.Name+"/"+z.Name] = z.QueryResults[0].Counter
+		}
+	}
+	for key, want := range map[string]uint64{
+		"external/example.com":    300,
+		"external/example.net":    3,
+		"external/zone42.example": 42,
+		"inside/corp.example":     20,
+	} {
+		if got[key] != want {
+			t.Errorf("%s: want delta %d, got %d", key, want, got[key])
+		}
+	}
+	if len(s.Delta.Views) != 2 || s.Delta.Views[1].Name != "inside" || s.Delta.Views[1].ResolverStats[0].Counter != 10 {
+		t.Errorf("want added view to start at zero, got %+v", s.Delta.Views)
+	}
+
+	now = now.Add(time.Hour)
+	if s, err = p.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if s.Reload != nil {
+		t.Errorf("want no reload without config time change, got %+v", s.Reload)
+	}
+	if got := p.Stats(); got.Reloads != 1 || got.Restarts != 0 {
+		t.Errorf("want a single reload, got %+v", got)
+	}
+}
+
+func TestDelta(t *testing.T) {
+	prev := Statistics{Views: []View{{Name: "_default", ResolverStats: []Counter{{Name: "QryRTT10", Counter: 5}, {Name: "QryRTT10+", Counter: 1}}}}}
+	cur := Statistics{Views: []View{
+		{Name: "_default", ResolverStats: []Counter{{Name: "QryRTT10", Counter: 9}, {Name: "QryRTT10+", Counter: 3}}},
+		{Name: "added", ResolverStats: []Counter{{Name: "QryRTT10", Counter: 4}}},
+	}}
+	d, err := Delta(prev, cur)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := d.Views[0].QueryRTT.Count; got != 6 {
+		t.Errorf("want 6 queries in delta histogram, got %d", got)
+	}
+	if got := d.Views[1].ResolverStats[0].Counter; got != 4 {
+		t.Errorf("want added view to start at zero, got delta %d", got)
+	}
+
+	prev.Server.Extra = Extra{"quic": {{Name: "QUICConnIn", Counter: 2}}}
+	cur.Server.Extra = Extra{"quic": {{Name: "QUICConnIn", Counter: 7}}}
+	if d, err = Delta(prev, cur); err != nil {
+		t.Fatal(err)
+	}
+	if got := d.Server.Extra["quic"]; len(got) != 1 || got[0].Counter != 5 {
+		t.Errorf("want delta of 5 in extra section, got %v", got)
+	}
+	cur.Server.Extra["quic"][0].Counter = 1
+	if _, err := Delta(prev, cur); !errors.Is(err, ErrCounterReset) {
+		t.Errorf("want counter reset error for extra section, got %v", err)
+	}
+	cur.Server.Extra = nil
+
+	// A counter wrapping around is a reset rather than a huge increase.
+	prev.Views[0].ResolverStats[0].Counter = math.MaxUint64
+	if _, err := Delta(prev, cur); !errors.Is(err, ErrCounterReset) {
+		t.Errorf("want counter reset error for wrapped counter, got %v", err)
+	}
+	prev.Views[0].ResolverStats[0].Counter = 5
+
+	cur.Views[0].ResolverStats[0].Counter = 2
+	if _, err := Delta(prev, cur); !errors.Is(err, ErrCounterReset) {
+		t.Errorf("want counter reset error, got %v", err)
+	}
+	cur.Source.Format = FormatXMLv3
+	if _, err := Delta(prev, cur); err == nil || errors.Is(err, ErrCounterReset) {
+		t.Errorf("want error for mixed formats, got %v", err)
+	}
+}
+
+// shufflingClient returns the same statistics on every call, but with views,
+// zones and counters in a different order and an advancing current time.
+type shufflingClient struct {
+	calls   int
+	queries uint64
+}
+
+func (c *shufflingClient) Stats(context.Context, ...StatisticGroup) (Statistics, error) {
+	c.calls++
+	counters := []Counter{{Name: "A", Counter: c.queries}, {Name: "AAAA", Counter: 7}}
+	zones := []ZoneCounter{
+		{Name: "example.com", Serial: "1", IncomingQueries: counters},
+		{Name: "example.net", Serial: "2"},
+	}
+	if c.calls%2 == 0 {
+		counters[0], counters[1] = counters[1], counters[0]
+		zones[0], zones[1] = zones[1], zones[0]
+	}
+	return Statistics{
+		Source: Source{Format: FormatJSONv1},
+		Server: Server{
+			CurrentTime:     time.Date(2024, 3, 15, 8, c.calls, 0, 0, time.UTC),
+			IncomingQueries: counters,
+		},
+		ZoneViews: []ZoneView{{Name: "_default", ZoneData: zones}},
+	}, nil
+}
+
+func TestPollerEmitOnlyOnChange(t *testing.T) {
+	now := time.Date(2024, 3, 15, 8, 0, 0, 0, time.UTC)
+	c := &shufflingClient{queries: 10}
+	p := newTestPoller(c, &now, WithEmitOnlyOnChange(), WithMaxStaleness(10*time.Minute))
+
+	var emitted []time.Duration
+	poll := func() {
+		s, err := p.Poll(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !s.Unchanged {
+			emitted = append(emitted, s.Time.Sub(time.Date(2024, 3, 15, 8, 0, 0, 0, time.UTC)))
+		}
+		now = now.Add(time.Minute)
+	}
+	for i := 0; i < 12; i++ {
+		poll()
+	}
+	if want := []time.Duration{0, 10 * time.Minute}; !reflect.DeepEqual(want, emitted) {
+		t.Errorf("want identical statistics emitted at %v, got %v", want, emitted)
+	}
+
+	c.queries++
+	poll()
+	if want := 12 * time.Minute; len(emitted) != 3 || emitted[2] != want {
+		t.Errorf("want changed statistics emitted at %s, got %v", want, emitted)
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	c := &shufflingClient{}
+	a, _ := c.Stats(context.Background())
+	b, _ := c.Stats(context.Background())
+	for _, g := range []StatisticGroup{ServerStats, ViewStats, TaskStats} {
+		if ContentHash(a, g) != ContentHash(b, g) {
+			t.Errorf("%s: want hash independent of order and current time", g)
+		}
+	}
+	b.ZoneViews[0].ZoneData[1].Serial = "3"
+	if ContentHash(a, ViewStats) == ContentHash(b, ViewStats) {
+		t.Error("want hash to change with the serial of a zone")
+	}
+	if ContentHash(a, ServerStats) != ContentHash(b, ServerStats) {
+		t.Error("want zones not to affect the hash of the server statistics")
+	}
+}
+
+// blockingClient returns one result from results per call.
+type blockingClient struct {
+	results chan error
+}
+
+func (c *blockingClient) Stats(ctx context.Context, _ ...StatisticGroup) (Statistics, error) {
+	select {
+	case err := <-c.results:
+		return Statistics{Source: Source{Format: FormatJSONv1}}, err
+	case <-ctx.Done():
+		return Statistics{}, ctx.Err()
+	}
+}
+
+func TestPollerRun(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 3, 15, 8, 0, 0, 0, time.UTC))
+	c := &blockingClient{results: make(chan error)}
+	p := NewPoller(c, time.Minute, WithJitter(0), WithMaxBackoff(3*time.Minute), WithPollClock(clk))
+	type event struct {
+		s   Sample
+		err error
+	}
+	events := make(chan event, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go p.Run(ctx, func(s Sample, err error) { events <- event{s, err} })
+
+	// tick waits for the timer of Run to be set to d and fires it.
+	tick := func(d time.Duration) {
+		clk.BlockUntilDue(d)
+		clk.Advance(d)
+	}
+	backoff := func(failures int, interval time.Duration) {
+		t.Helper()
+		var berr *BackoffError
+		if e := <-events; !errors.As(e.err, &berr) || berr.Failures != failures || berr.Interval != interval {
+			t.Fatalf("want backoff to %s after %d failures, got %v", interval, failures, e.err)
+		}
+	}
+
+	// The first tick passes while the first poll is still running.
+	tick(time.Minute)
+	if e := <-events; !errors.Is(e.err, ErrPollSkipped) {
+		t.Fatalf("want skipped poll, got %v", e.err)
+	}
+	clk.BlockUntilDue(time.Minute)
+
+	// Failures double the interval up to the maximum.
+	c.results <- errors.New("connection refused")
+	backoff(1, 2*time.Minute)
+	tick(2 * time.Minute)
+	clk.BlockUntilDue(2 * time.Minute)
+	c.results <- errors.New("connection refused")
+	backoff(2, 3*time.Minute)
+	tick(3 * time.Minute)
+	clk.BlockUntilDue(3 * time.Minute)
+
+	// Success resets the interval.
+	c.results <- nil
+	if e := <-events; e.err != nil || e.s.Recovered != 2 {
+		t.Fatalf("want sample recovered from 2 failures, got %+v, %v", e.s, e.err)
+	}
+	tick(time.Minute)
+	c.results <- nil
+	if e := <-events; e.err != nil || e.s.Recovered != 0 {
+		t.Fatalf("want sample, got %+v, %v", e.s, e.err)
+	}
+	clk.BlockUntilDue(time.Minute)
+}
+
+func TestPollerRunRecoveredUnchanged(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 3, 15, 8, 0, 0, 0, time.UTC))
+	c := &blockingClient{results: make(chan error)}
+	p := NewPoller(c, time.Minute, WithJitter(0), WithEmitOnlyOnChange(), WithPollClock(clk))
+	type event struct {
+		s   Sample
+		err error
+	}
+	events := make(chan event, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go p.Run(ctx, func(s Sample, err error) { events <- event{s, err} })
+
+	c.results <- nil
+	if e := <-events; e.err != nil {
+		t.Fatalf("want sample, got %v", e.err)
+	}
+	clk.BlockUntilDue(time.Minute)
+	clk.Advance(time.Minute)
+	c.results <- errors.New("connection refused")
+	if e := <-events; e.err == nil {
+		t.Fatal("want backoff error")
+	}
+	clk.BlockUntilDue(2 * time.Minute)
+	clk.Advance(2 * time.Minute)
+
+	// The unchanged sample ending the failures is delivered.
+	c.results <- nil
+	if e := <-events; e.err != nil || !e.s.Unchanged || e.s.Recovered != 1 {
+		t.Fatalf("want unchanged sample recovered from 1 failure, got %+v, %v", e.s, e.err)
+	}
+}
+
+func TestPollerJitter(t *testing.T) {
+	p := NewPoller(&countingClient{}, time.Minute)
+	for r, want := range map[float64]time.Duration{0: 54 * time.Second, 0.5: time.Minute, 1: 66 * time.Second} {
+		r := r
+		p.rand = func() float64 { return r }
+		if got := p.jitter(time.Minute); got != want {
+			t.Errorf("rand %v: want %s, got %s", r, want, got)
+		}
+	}
+}
+
+// fetch is a call of a recordingClient.
+type fetch struct {
+	groups []StatisticGroup
+	time   time.Time
+}
+
+// recordingClient records its calls at the time of clock.
+type recordingClient struct {
+	clock   Clock
+	fetches chan fetch
+}
+
+func (c *recordingClient) Stats(_ context.Context, groups ...StatisticGroup) (Statistics, error) {
+	c.fetches <- fetch{groups, c.clock.Now()}
+	return NewStatisticsBuilder().Source(Source{Format: FormatJSONv1}).Build()
+}
+
+func TestPollerWarmup(t *testing.T) {
+	start := time.Date(2024, 3, 15, 8, 0, 0, 0, time.UTC)
+	offsets := map[int64]time.Duration{}
+	for _, seed := range []int64{1, 2} {
+		clk := clock.NewFake(start)
+		c := &recordingClient{clock: clk, fetches: make(chan fetch, 10)}
+		p := NewPoller(c, time.Minute, WithJitter(0), WithWarmup(10*time.Second), WithPollSeed(seed), WithPollClock(clk))
+		samples := make(chan Sample, 10)
+		ctx, cancel := context.WithCancel(context.Background())
+		go p.Run(ctx, func(s Sample, err error) {
+			if err != nil {
+				t.Errorf("seed %d: want no error, got %v", seed, err)
+			}
+			samples <- s
+		})
+
+		// The cheap groups are fetched immediately.
+		if f := <-c.fetches; !reflect.DeepEqual(f.groups, []StatisticGroup{ServerStats}) || !f.time.Equal(start) {
+			t.Fatalf("seed %d: want server statistics fetched at start, got %v at %s", seed, f.groups, f.time)
+		}
+		if s := <-samples; !s.Partial {
+			t.Errorf("seed %d: want partial sample", seed)
+		}
+
+		// The heavy groups are fetched one at a time from the offset.
+		offset := time.Duration(rand.New(rand.NewSource(seed)).Float64() * float64(time.Minute))
+		clk.BlockUntilDue(offset)
+		clk.Advance(offset)
+		if f := <-c.fetches; !reflect.DeepEqual(f.groups, []StatisticGroup{ViewStats}) {
+			t.Fatalf("seed %d: want view statistics fetched, got %v", seed, f.groups)
+		}
+		offsets[seed] = offset
+		if s := <-samples; !s.Partial {
+			t.Errorf("seed %d: want partial sample", seed)
+		}
+		clk.BlockUntilDue(10 * time.Second)
+		clk.Advance(10 * time.Second)
+		if f := <-c.fetches; !reflect.DeepEqual(f.groups, []StatisticGroup{TaskStats}) || f.time.Sub(start) != offset+10*time.Second {
+			t.Fatalf("seed %d: want task statistics fetched after %s, got %v after %s", seed, offset+10*time.Second, f.groups, f.time.Sub(start))
+		}
+		if s := <-samples; s.Partial {
+			t.Errorf("seed %d: want full sample completing the warm-up", seed)
+		}
+
+		// The interval starts once the warm-up is complete.
+		clk.BlockUntilDue(time.Minute)
+		clk.Advance(time.Minute)
+		if f := <-c.fetches; len(f.groups) != 3 {
+			t.Errorf("seed %d: want all groups polled after the warm-up, got %v", seed, f.groups)
+		}
+		<-samples
+		cancel()
+	}
+	if offsets[1] == offsets[2] {
+		t.Errorf("want different offsets for different seeds, got %s", offsets[1])
+	}
+}
+
+// rcodeClient returns statistics with its rcode counters, reported by a
+// server booted at boot.
+type rcodeClient struct {
+	boot   time.Time
+	rcodes map[string]uint64
+}
+
+func (c *rcodeClient) Stats(context.Context, ...StatisticGroup) (Statistics, error) {
+	return NewStatisticsBuilder().
+		Source(Source{Format: FormatJSONv1}).
+		Times(c.boot, time.Time{}, time.Time{}).
+		Server("rcodes", c.rcodes).
+		Build()
+}
+
+func TestPollerRcodeWindow(t *testing.T) {
+	start := time.Date(2024, 3, 15, 8, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(start)
+	c := &rcodeClient{boot: start.Add(-time.Hour), rcodes: map[string]uint64{}}
+	p := NewPoller(c, time.Minute, WithPollClock(clk), WithRcodeWindow(3*time.Minute))
+
+	if _, err := p.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for i, step := range []struct {
+		noerror, servfail uint64
+		restart           bool
+		ratio             float64
+		covered           time.Duration
+	}{
+		{noerror: 100, ratio: 0, covered: time.Minute},
+		// A burst of SERVFAIL raises the ratio.
+		{noerror: 100, servfail: 100, ratio: 100.0 / 300, covered: 2 * time.Minute},
+		{noerror: 100, servfail: 100, ratio: 200.0 / 500, covered: 3 * time.Minute},
+		// The first poll leaves the window.
+		{noerror: 100, ratio: 200.0 / 500, covered: 3 * time.Minute},
+		// The burst decays as its polls leave the window.
+		{noerror: 100, ratio: 100.0 / 400, covered: 3 * time.Minute},
+		{noerror: 100, ratio: 0, covered: 3 * time.Minute},
+		// named restarted 30s ago and counts from zero.
+		{noerror: 50, servfail: 50, restart: true, ratio: 50.0 / 300, covered: 150 * time.Second},
+	} {
+		clk.Advance(time.Minute)
+		if step.restart {
+			c.boot = clk.Now().Add(-30 * time.Second)
+			c.rcodes = map[string]uint64{}
+		}
+		c.rcodes[CounterNOERROR] += step.noerror
+		c.rcodes[CounterSERVFAIL] += step.servfail
+		s, err := p.Poll(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := s.Window
+		if w == nil || math.Abs(w.FailureRatio-step.ratio) > 1e-9 || w.Covered != step.covered {
+			t.Fatalf("step %d: want failure ratio %g over %s, got %+v", i, step.ratio, step.covered, w)
+		}
+		if cw, ok := p.CurrentWindow(); !ok || !reflect.DeepEqual(cw, *w) {
+			t.Errorf("step %d: want current window %+v, got %+v", i, *w, cw)
+		}
+	}
+	w, _ := p.CurrentWindow()
+	if got, want := w.Rates[CounterSERVFAIL], 50.0/150; math.Abs(got-want) > 1e-9 {
+		t.Errorf("want SERVFAIL rate %g/s, got %g/s", want, got)
+	}
+	if w.End != clk.Now() || w.Start != clk.Now().Add(-3*time.Minute) {
+		t.Errorf("want window of the last three polls, got %s to %s", w.Start, w.End)
+	}
+
+	// Without polls, the window empties.
+	clk.Advance(3 * time.Minute)
+	if w, _ := p.CurrentWindow(); w.Total != 0 || w.Covered != 0 || !math.IsNaN(w.FailureRatio) {
+		t.Errorf("want empty window with undefined failure ratio, got %+v", w)
+	}
+
+	if _, ok := NewPoller(c, time.Minute).CurrentWindow(); ok {
+		t.Error("want no window unless configured")
+	}
+}
+
+func TestRcodeWindowSaturates(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	r := &rcodeRing{window: time.Hour}
+	for i := 0; i < 2; i++ {
+		r.add(rcodeBucket{time: now, interval: time.Minute, rcodes: []Counter{
+			{Name: CounterNOERROR, Counter: math.MaxUint64 - 1},
+			{Name: CounterSERVFAIL, Counter: math.MaxUint64 - 1},
+		}})
+	}
+	w := r.current(now)
+	if w.Responses[CounterNOERROR] != math.MaxUint64 || w.Responses[CounterSERVFAIL] != math.MaxUint64 || w.Total != math.MaxUint64 {
+		t.Errorf("want saturated responses, got %v and total %d", w.Responses, w.Total)
+	}
+	if w.FailureRatio != 1 {
+		t.Errorf("want failure ratio 1, got %g", w.FailureRatio)
+	}
+}
+
+func TestDerive(t *testing.T) {
+	boot := time.Date(2024, 3, 15, 8, 0, 0, 0, time.UTC)
+	t0 := boot.Add(time.Hour)
+	type snapshot struct {
+		boot, now time.Time
+		queries   map[string]uint64
+		resolver  map[string]uint64
+		cache     map[string]uint64
+	}
+	build := func(s snapshot) Statistics {
+		b := NewStatisticsBuilder().
+			Source(Source{Format: FormatXMLv3, FetchTime: s.now}).
+			Times(s.boot, s.boot, s.now)
+		if s.queries != nil {
+			b.Server("opcodes", s.queries)
+		}
+		b.View(DefaultView)
+		if s.resolver != nil {
+			b.Resolver(s.resolver)
+		}
+		if s.cache != nil {
+			b.CacheStats(s.cache)
+		}
+		st, err := b.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return st
+	}
+	nan := math.NaN()
+	for _, tc := range []struct {
+		name      string
+		prev, cur snapshot
+		err       error
+		qps       float64
+		rate      float64
+		ratio     float64
+		// missing lists the values which must be omitted.
+		missing []string
+	}{
+		{
+			name:  "traffic",
+			prev:  snapshot{boot: boot, now: t0, queries: map[string]uint64{"QUERY": 100}, resolver: map[string]uint64{"Queryv4": 30}, cache: map[string]uint64{"QueryHits": 10, "QueryMisses": 10}},
+			cur:   snapshot{boot: boot, now: t0.Add(time.Minute), queries: map[string]uint64{"QUERY": 700, "NOTIFY": 5}, resolver: map[string]uint64{"Queryv4": 60, "Queryv6": 30}, cache: map[string]uint64{"QueryHits": 40, "QueryMisses": 20}},
+			qps:   10,
+			rate:  1,
+			ratio: 0.75,
+		},
+		{
+			name:  "zero traffic",
+			prev:  snapshot{boot: boot, now: t0, queries: map[string]uint64{"QUERY": 100}, resolver: map[string]uint64{"Queryv4": 30}, cache: map[string]uint64{"QueryHits": 10, "QueryMisses": 10}},
+			cur:   snapshot{boot: boot, now: t0.Add(time.Minute), queries: map[string]uint64{"QUERY": 100}, resolver: map[string]uint64{"Queryv4": 30}, cache: map[string]uint64{"QueryHits": 10, "QueryMisses": 10}},
+			qps:   0,
+			rate:  0,
+			ratio: nan,
+		},
+		{
+			name:  "zero interval",
+			prev:  snapshot{boot: boot, now: t0, queries: map[string]uint64{"QUERY": 100}, resolver: map[string]uint64{"Queryv4": 30}, cache: map[string]uint64{"QueryHits": 10}},
+			cur:   snapshot{boot: boot, now: t0, queries: map[string]uint64{"QUERY": 100}, resolver: map[string]uint64{"Queryv4": 30}, cache: map[string]uint64{"QueryHits": 10}},
+			qps:   nan,
+			rate:  nan,
+			ratio: nan,
+		},
+		{
+			// named restarted 30s ago, the counters are measured from the
+			// boot time.
+			name:  "restart",
+			prev:  snapshot{boot: boot, now: t0, queries: map[string]uint64{"QUERY": 10000}, resolver: map[string]uint64{"Queryv4": 3000}, cache: map[string]uint64{"QueryHits": 900, "QueryMisses": 100}},
+			cur:   snapshot{boot: t0.Add(30 * time.Second), now: t0.Add(time.Minute), queries: map[string]uint64{"QUERY": 300}, resolver: map[string]uint64{"Queryv4": 60}, cache: map[string]uint64{"QueryHits": 1, "QueryMisses": 3}},
+			qps:   10,
+			rate:  2,
+			ratio: 0.25,
+		},
+		{
+			// Older versions report neither the cache counters nor, for
+			// views without recursion, the resolver statistics.
+			name:    "missing counters",
+			prev:    snapshot{boot: boot, now: t0},
+			cur:     snapshot{boot: boot, now: t0.Add(time.Minute), cache: map[string]uint64{"CacheHits": 5}},
+			qps:     nan,
+			rate:    nan,
+			ratio:   nan,
+			missing: []string{"qps", "rate", "ratio"},
+		},
+		{
+			name: "counter reset",
+			prev: snapshot{boot: boot, now: t0, queries: map[string]uint64{"QUERY": 100}},
+			cur:  snapshot{boot: boot, now: t0.Add(time.Minute), queries: map[string]uint64{"QUERY": 50}},
+			err:  ErrCounterReset,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := Derive(build(tc.prev), build(tc.cur))
+			if tc.err != nil || err != nil {
+				if !errors.Is(err, tc.err) {
+					t.Fatalf("want error %v, got %v", tc.err, err)
+				}
+				return
+			}
+			v, ok := d.View(DefaultView)
+			if !ok {
+				t.Fatalf("want view %s, got %+v", DefaultView, d.Views)
+			}
+			missing := map[string]bool{}
+			for _, m := range tc.missing {
+				missing[m] = true
+			}
+			for _, c := range []struct {
+				name      string
+				want, got float64
+				has       bool
+			}{
+				{"qps", tc.qps, d.QPS, d.HasQPS},
+				{"rate", tc.rate, v.RecursionRate, v.HasRecursionRate},
+				{"ratio", tc.ratio, v.CacheHitRatio, v.HasCacheHitRatio},
+			} {
+				if c.has == missing[c.name] {
+					t.Errorf("%s: want reported %t, got %t", c.name, !missing[c.name], c.has)
+				}
+				if math.IsNaN(c.want) != math.IsNaN(c.got) || !math.IsNaN(c.want) && math.Abs(c.got-c.want) > 1e-9 {
+					t.Errorf("%s: want %g, got %g", c.name, c.want, c.got)
+				}
+			}
+		})
+	}
+}
+
+func TestPollerDerived(t *testing.T) {
+	boot := time.Date(2024, 3, 15, 8, 0, 0, 0, time.UTC)
+	now := boot.Add(time.Hour)
+	c := &countingClient{boot: boot, queries: 100}
+	p := newTestPoller(c, &now)
+
+	s, err := p.Poll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Derived != nil {
+		t.Errorf("want no derived values without previous sample, got %+v", s.Derived)
+	}
+	now = now.Add(time.Minute)
+	c.queries += 1200
+	if s, err = p.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if d := s.Derived; d == nil || !d.HasQPS || d.QPS != 20 || d.Interval != time.Minute {
+		t.Errorf("want 20 queries per second over a minute, got %+v", d)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"strconv"
+	"strings"
+)
+
+// QTypeClass classifies the name of a query type counter.
+type QTypeClass int
+
+const (
+	// QTypeWellKnown is a type assigned in the IANA registry of resource
+	// record types.
+	QTypeWellKnown QTypeClass = iota
+	// QTypePrivateUse is a type of the private use range 65280-65534.
+	QTypePrivateUse
+	// QTypeUnknown is any other type, e.g. an unassigned or reserved number
+	// or a name which is neither a mnemonic nor in RFC 3597 notation.
+	QTypeUnknown
+)
+
+// String returns the name of c.
+func (c QTypeClass) String() string {
+	switch c {
+	case QTypeWellKnown:
+		return "well-known"
+	case QTypePrivateUse:
+		return "private-use"
+	default:
+		return "unknown"
+	}
+}
+
+// qtypeNames maps the numbers of the IANA registry of resource record types
+// to their mnemonics. Obsolete and experimental types are included, as BIND
+// counts queries for them under their mnemonic.
+var qtypeNames = map[uint16]string{
+	1: "A", 2: "NS", 3: "MD", 4: "MF", 5: "CNAME", 6: "SOA", 7: "MB", 8: "MG",
+	9: "MR", 10: "NULL", 11: "WKS", 12: "PTR", 13: "HINFO", 14: "MINFO",
+	15: "MX", 16: "TXT", 17: "RP", 18: "AFSDB", 19: "X25", 20: "ISDN",
+	21: "RT", 22: "NSAP", 23: "NSAP-PTR", 24: "SIG", 25: "KEY", 26: "PX",
+	27: "GPOS", 28: "AAAA", 29: "LOC", 30: "NXT", 31: "EID", 32: "NIMLOC",
+	33: "SRV", 34: "ATMA", 35: "NAPTR", 36: "KX", 37: "CERT", 38: "A6",
+	39: "DNAME", 40: "SINK", 41: "OPT", 42: "APL", 43: "DS", 44: "SSHFP",
+	45: "IPSECKEY", 46: "RRSIG", 47: "NSEC", 48: "DNSKEY", 49: "DHCID",
+	50: "NSEC3", 51: "NSEC3PARAM", 52: "TLSA", 53: "SMIMEA", 55: "HIP",
+	56: "NINFO", 57: "RKEY", 58: "TALINK", 59: "CDS", 60: "CDNSKEY",
+	61: "OPENPGPKEY", 62: "CSYNC", 63: "ZONEMD", 64: "SVCB", 65: "HTTPS",
+	99: "SPF", 100: "UINFO", 101: "UID", 102: "GID", 103: "UNSPEC",
+	104: "NID", 105: "L32", 106: "L64", 107: "LP", 108: "EUI48",
+	109: "EUI64", 249: "TKEY", 250: "TSIG", 251: "IXFR", 252: "AXFR",
+	253: "MAILB", 254: "MAILA", 255: "ANY", 256: "URI", 257: "CAA",
+	258: "AVC", 259: "DOA", 260: "AMTRELAY", 261: "RESINFO",
+	32768: "TA", 32769: "DLV",
+}
+
+// qtypeNumbers is the inverse of qtypeNames.
+var qtypeNumbers = func() map[string]uint16 {
+	m := make(map[string]uint16, len(qtypeNames))
+	for n, name := range qtypeNames {
+		m[name] = n
+	}
+	return m
+}()
+
+// ClassifyQType returns the class of the query type counter name together
+// with its canonical name. The canonical name of a well-known type is its
+// mnemonic, also if name is given in the RFC 3597 notation, e.g. "TYPE28" is
+// "AAAA". Other numeric types are canonicalized to the RFC 3597 notation,
+// e.g. "type65280" and "65280" to "TYPE65280". Names which are neither are
+// returned unchanged as unknown.
+//
+// The clients report query type counters as named by BIND, ClassifyQType is
+// meant for consumers which need consistent names across BIND versions.
+func ClassifyQType(name string) (string, QTypeClass) {
+	upper := strings.ToUpper(name)
+	if _, ok := qtypeNumbers[upper]; ok {
+		return upper, QTypeWellKnown
+	}
+	n, err := strconv.ParseUint(strings.TrimPrefix(upper, "TYPE"), 10, 16)
+	if err != nil {
+		return name, QTypeUnknown
+	}
+	if mnemonic, ok := qtypeNames[uint16(n)]; ok {
+		return mnemonic, QTypeWellKnown
+	}
+	canonical := "TYPE" + strconv.FormatUint(n, 10)
+	if n >= 65280 && n <= 65534 {
+		return canonical, QTypePrivateUse
+	}
+	return canonical, QTypeUnknown
+}
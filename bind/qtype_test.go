@@ -0,0 +1,59 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestClassifyQType(t *testing.T) {
+	tests := []struct {
+		name      string
+		canonical string
+		class     QTypeClass
+	}{
+		{name: "A", canonical: "A", class: QTypeWellKnown},
+		{name: "aaaa", canonical: "AAAA", class: QTypeWellKnown},
+		{name: "NSAP-PTR", canonical: "NSAP-PTR", class: QTypeWellKnown},
+		{name: "TYPE1", canonical: "A", class: QTypeWellKnown},
+		{name: "TYPE65", canonical: "HTTPS", class: QTypeWellKnown},
+		{name: "type257", canonical: "CAA", class: QTypeWellKnown},
+		{name: "32769", canonical: "DLV", class: QTypeWellKnown},
+		{name: "TYPE65280", canonical: "TYPE65280", class: QTypePrivateUse},
+		{name: "type065534", canonical: "TYPE65534", class: QTypePrivateUse},
+		{name: "65281", canonical: "TYPE65281", class: QTypePrivateUse},
+		{name: "TYPE65535", canonical: "TYPE65535", class: QTypeUnknown},
+		{name: "TYPE4711", canonical: "TYPE4711", class: QTypeUnknown},
+		{name: "TYPE0", canonical: "TYPE0", class: QTypeUnknown},
+		{name: "TYPE65536", canonical: "TYPE65536", class: QTypeUnknown},
+		{name: "Others", canonical: "Others", class: QTypeUnknown},
+		{name: "", canonical: "", class: QTypeUnknown},
+	}
+	for _, tt := range tests {
+		canonical, class := ClassifyQType(tt.name)
+		if canonical != tt.canonical || class != tt.class {
+			t.Errorf("ClassifyQType(%q): want %q (%s), got %q (%s)", tt.name, tt.canonical, tt.class, canonical, class)
+		}
+	}
+}
+
+func TestQTypeRegistry(t *testing.T) {
+	for n, name := range qtypeNames {
+		canonical, class := ClassifyQType("TYPE" + strconv.Itoa(int(n)))
+		if canonical != name || class != QTypeWellKnown {
+			t.Errorf("type %d: want %q, got %q (%s)", n, name, canonical, class)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind/internal/clock"
+	"golang.org/x/time/rate"
+)
+
+// RateLimitedClient is a Client which limits the rate of HTTP requests issued
+// by the wrapped client. Every request consumes one token, so a Stats call
+// fetching several documents consumes several tokens.
+type RateLimitedClient struct {
+	client  Client
+	limiter *rate.Limiter
+	now     func() time.Time
+	sleep   func(context.Context, time.Duration) error
+}
+
+// NewRateLimitedClient returns a Client allowing at most limit requests per
+// second to be issued by c, with bursts of up to burst requests. Of opts, only
+// WithClock applies.
+func NewRateLimitedClient(c Client, limit rate.Limit, burst int, opts ...ClientOption) *RateLimitedClient {
+	clk := clockOf(opts)
+	return &RateLimitedClient{
+		client:  c,
+		limiter: rate.NewLimiter(limit, burst),
+		now:     clk.Now,
+		sleep: func(ctx context.Context, d time.Duration) error {
+			return clock.Sleep(ctx, clk, d)
+		},
+	}
+}
+
+// Stats implements Client. It blocks before every request until the rate
+// limit permits it or ctx is done.
+func (c *RateLimitedClient) Stats(ctx context.Context, groups ...StatisticGroup) (Statistics, error) {
+	ctx = WithClientTrace(ctx, &ClientTrace{WaitRequest: c.wait})
+	return c.client.Stats(ctx, groups...)
+}
+
+func (c *RateLimitedClient) wait(ctx context.Context) error {
+	now := c.now()
+	r := c.limiter.ReserveN(now, 1)
+	if !r.OK() {
+		return fmt.Errorf("rate limit with burst %d does not permit any request", c.limiter.Burst())
+	}
+	d := r.DelayFrom(now)
+	if d <= 0 {
+		return nil
+	}
+	if err := c.sleep(ctx, d); err != nil {
+		r.CancelAt(c.now())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind/internal/clock"
+)
+
+// requestClient simulates a client issuing one request per group.
+type requestClient struct {
+	now      func() time.Time
+	requests []time.Time
+}
+
+func (c *requestClient) Stats(ctx context.Context, groups ...StatisticGroup) (Statistics, error) {
+	for range groups {
+		if t := ContextClientTrace(ctx); t != nil && t.WaitRequest != nil {
+			if err := t.WaitRequest(ctx); err != nil {
+				return Statistics{}, err
+			}
+		}
+		c.requests = append(c.requests, c.now())
+	}
+	return Statistics{}, nil
+}
+
+func TestRateLimitedClient(t *testing.T) {
+	start := time.Unix(0, 0)
+	clk := clock.NewFake(start)
+	inner := &requestClient{now: clk.Now}
+	c := NewRateLimitedClient(inner, 2, 1, WithClock(clk))
+
+	done := make(chan error)
+	go func() {
+		_, err := c.Stats(context.Background(), ServerStats, ViewStats, TaskStats)
+		if err == nil {
+			_, err = c.Stats(context.Background(), ServerStats)
+		}
+		done <- err
+	}()
+	// The burst permits the first request, every other waits for a token.
+	for i := 0; i < 3; i++ {
+		clk.BlockUntilDue(500 * time.Millisecond)
+		clk.Advance(500 * time.Millisecond)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	var got []time.Duration
+	for _, r := range inner.requests {
+		got = append(got, r.Sub(start))
+	}
+	want := []time.Duration{0, 500 * time.Millisecond, time.Second, 1500 * time.Millisecond}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want requests at %v, got %v", want, got)
+	}
+}
+
+func TestRateLimitedClientContext(t *testing.T) {
+	inner := &requestClient{now: time.Now}
+	c := NewRateLimitedClient(inner, 0.001, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := c.Stats(ctx, ServerStats, ViewStats)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want deadline exceeded, got %v", err)
+	}
+	if want, got := 1, len(inner.requests); want != got {
+		t.Errorf("want %d requests, got %d", want, got)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"strconv"
+	"strings"
+)
+
+// rcodeNames holds the names BIND uses for the rcode counters, indexed by
+// code. Codes without a name are reported by number.
+var rcodeNames = []string{
+	CounterNOERROR, CounterFORMERR, CounterSERVFAIL, CounterNXDOMAIN,
+	CounterNOTIMP, CounterREFUSED, CounterYXDOMAIN, CounterYXRRSET,
+	CounterNXRRSET, CounterNOTAUTH, CounterNOTZONE, CounterRESERVED11,
+	CounterRESERVED12, CounterRESERVED13, CounterRESERVED14, CounterRESERVED15,
+	// Extended rcodes, which need EDNS.
+	CounterBADVERS, "", "", "", "", "", "", CounterBADCOOKIE,
+}
+
+// RcodeName returns the name of the rcode counter for code, e.g. "NXDOMAIN"
+// for 3 or "BADCOOKIE" for 23. Codes without a name, which BIND reports by
+// number, are named "RCODE" followed by the code, e.g. "RCODE17".
+func RcodeName(code int) string {
+	if code >= 0 && code < len(rcodeNames) && rcodeNames[code] != "" {
+		return rcodeNames[code]
+	}
+	return "RCODE" + strconv.Itoa(code)
+}
+
+// ParseRcode returns the code of the rcode counter name. It accepts the names
+// returned by RcodeName as well as the bare numbers reported by BIND for codes
+// without a name.
+func ParseRcode(name string) (int, bool) {
+	for code, n := range rcodeNames {
+		if n != "" && n == name {
+			return code, true
+		}
+	}
+	// Rcodes are 12 bits wide, see RFC 6891.
+	n, err := strconv.ParseUint(strings.TrimPrefix(name, "RCODE"), 10, 12)
+	if err != nil {
+		return 0, false
+	}
+	return int(n), true
+}
+
+// NormalizeRcodeName returns the name RcodeName uses for the rcode counter
+// name, so that numbered codes are consistently named "RCODE17" regardless of
+// how they are reported. Unparseable names are returned unchanged.
+func NormalizeRcodeName(name string) string {
+	if code, ok := ParseRcode(name); ok {
+		return RcodeName(code)
+	}
+	return name
+}
+
+// zoneResponses holds the query result counters of zones which partition the
+// responses sent for a zone by outcome.
+var zoneResponses = []string{
+	CounterQrySuccess, CounterQryReferral, CounterQryNxrrset,
+	CounterQrySERVFAIL, CounterQryFORMERR, CounterQryNXDOMAIN,
+}
+
+// TotalResponses returns the number of responses sent for the zone, i.e. the
+// sum of its QrySuccess, QryReferral, QryNxrrset, QrySERVFAIL, QryFORMERR and
+// QryNXDOMAIN query results. It is zero without zone-statistics.
+func (z ZoneCounter) TotalResponses() uint64 {
+	var n uint64
+	for _, c := range z.QueryResults {
+		for _, name := range zoneResponses {
+			if c.Name == name {
+				n, _ = AddCounters(n, c.Counter)
+			}
+		}
+	}
+	return n
+}
+
+// RcodeRatio returns the share of the query result counter name, e.g.
+// CounterQryNXDOMAIN, of the TotalResponses of the zone, or NaN if the zone
+// sent no responses.
+func (z ZoneCounter) RcodeRatio(name string) float64 {
+	return ratio(counterValue(z.QueryResults, name), z.TotalResponses())
+}
+
+// TotalResponses returns the number of responses sent by the server, i.e. the
+// sum of its rcode counters.
+func (s Server) TotalResponses() uint64 {
+	var n uint64
+	for _, c := range s.ServerRcodes {
+		n, _ = AddCounters(n, c.Counter)
+	}
+	return n
+}
+
+// RcodeRatio returns the share of the responses sent with the rcode name,
+// e.g. CounterNXDOMAIN, of the TotalResponses of the server. Names are
+// compared as normalized by NormalizeRcodeName. It is NaN if the server sent
+// no responses.
+func (s Server) RcodeRatio(name string) float64 {
+	name = NormalizeRcodeName(name)
+	var n uint64
+	for _, c := range s.ServerRcodes {
+		if NormalizeRcodeName(c.Name) == name {
+			n, _ = AddCounters(n, c.Counter)
+		}
+	}
+	return ratio(n, s.TotalResponses())
+}
+
+// TotalResponses returns the number of responses received by the resolver of
+// the view, i.e. the sum of its Responsev4 and Responsev6 counters.
+func (v View) TotalResponses() uint64 {
+	n, _ := AddCounters(counterValue(v.ResolverStats, CounterResponsev4), counterValue(v.ResolverStats, CounterResponsev6))
+	return n
+}
+
+// RcodeRatio returns the share of the resolver counter name, e.g.
+// CounterNXDOMAIN or CounterSERVFAIL, of the TotalResponses of the view, or
+// NaN if the resolver received no responses.
+func (v View) RcodeRatio(name string) float64 {
+	return ratio(counterValue(v.ResolverStats, name), v.TotalResponses())
+}
+
+// counterValue returns the value of the counter name of cs, or zero if there
+// is no such counter.
+func counterValue(cs []Counter, name string) uint64 {
+	for _, c := range cs {
+		if c.Name == name {
+			return c.Counter
+		}
+	}
+	return 0
+}
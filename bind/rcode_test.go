@@ -0,0 +1,148 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRcodeName(t *testing.T) {
+	tests := []struct {
+		code int
+		name string
+	}{
+		{code: 0, name: "NOERROR"},
+		{code: 3, name: "NXDOMAIN"},
+		{code: 12, name: "RESERVED12"},
+		{code: 16, name: "BADVERS"},
+		{code: 17, name: "RCODE17"},
+		{code: 23, name: "BADCOOKIE"},
+		{code: 24, name: "RCODE24"},
+	}
+	for _, tt := range tests {
+		if got := RcodeName(tt.code); got != tt.name {
+			t.Errorf("RcodeName(%d): want %q, got %q", tt.code, tt.name, got)
+		}
+		if got, ok := ParseRcode(tt.name); !ok || got != tt.code {
+			t.Errorf("ParseRcode(%q): want %d, got %d (ok %t)", tt.name, tt.code, got, ok)
+		}
+	}
+}
+
+func TestParseRcode(t *testing.T) {
+	for name, want := range map[string]int{
+		"17":        17,
+		"RCODE17":   17,
+		"4095":      0xfff,
+		"RCODE4095": 0xfff,
+		"BADCOOKIE": 23,
+	} {
+		if got, ok := ParseRcode(name); !ok || got != want {
+			t.Errorf("ParseRcode(%q): want %d, got %d (ok %t)", name, want, got, ok)
+		}
+	}
+	for _, name := range []string{"", "RCODE", "BOGUS", "4096", "RCODE4096", "-1", "-0", "+5", "RCODE+5", "RCODE-0", " 5", "0x5"} {
+		if got, ok := ParseRcode(name); ok {
+			t.Errorf("ParseRcode(%q): want no code, got %d", name, got)
+		}
+	}
+}
+
+func TestNormalizeRcodeName(t *testing.T) {
+	for name, want := range map[string]string{
+		"17":        "RCODE17",
+		"RCODE17":   "RCODE17",
+		"23":        "BADCOOKIE",
+		"BADCOOKIE": "BADCOOKIE",
+		"":          "",
+		"BOGUS":     "BOGUS",
+		"-1":        "-1",
+	} {
+		if got := NormalizeRcodeName(name); got != want {
+			t.Errorf("NormalizeRcodeName(%q): want %q, got %q", name, want, got)
+		}
+	}
+}
+
+func TestRcodeRatios(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats interface {
+			TotalResponses() uint64
+			RcodeRatio(string) float64
+		}
+		counter   string
+		wantTotal uint64
+		want      float64
+	}{
+		{
+			name:      "zone",
+			stats:     ZoneCounter{QueryResults: []Counter{{Name: "QrySuccess", Counter: 70}, {Name: "QryNXDOMAIN", Counter: 20}, {Name: "QrySERVFAIL", Counter: 10}, {Name: "QryAuthAns", Counter: 80}}},
+			counter:   CounterQryNXDOMAIN,
+			wantTotal: 100,
+			want:      0.2,
+		},
+		{
+			name:      "zero traffic zone",
+			stats:     ZoneCounter{QueryResults: []Counter{{Name: "QrySuccess"}, {Name: "QryNXDOMAIN"}}},
+			counter:   CounterQryNXDOMAIN,
+			wantTotal: 0,
+			want:      math.NaN(),
+		},
+		{
+			name:      "zone without statistics",
+			stats:     ZoneCounter{},
+			counter:   CounterQrySuccess,
+			wantTotal: 0,
+			want:      math.NaN(),
+		},
+		{
+			name:      "server",
+			stats:     Server{ServerRcodes: []Counter{{Name: "NOERROR", Counter: 30}, {Name: "SERVFAIL", Counter: 10}, {Name: "17", Counter: 10}}},
+			counter:   "RCODE17",
+			wantTotal: 50,
+			want:      0.2,
+		},
+		{
+			name:      "idle server",
+			stats:     Server{},
+			counter:   CounterNXDOMAIN,
+			wantTotal: 0,
+			want:      math.NaN(),
+		},
+		{
+			name:      "view",
+			stats:     View{ResolverStats: []Counter{{Name: "Responsev4", Counter: 30}, {Name: "Responsev6", Counter: 10}, {Name: "SERVFAIL", Counter: 4}}},
+			counter:   CounterSERVFAIL,
+			wantTotal: 40,
+			want:      0.1,
+		},
+		{
+			name:      "idle view",
+			stats:     View{ResolverStats: []Counter{{Name: "SERVFAIL"}}},
+			counter:   CounterSERVFAIL,
+			wantTotal: 0,
+			want:      math.NaN(),
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.stats.TotalResponses(); got != tt.wantTotal {
+			t.Errorf("%s: want %d responses, got %d", tt.name, tt.wantTotal, got)
+		}
+		if got := tt.stats.RcodeRatio(tt.counter); got != tt.want && !(math.IsNaN(got) && math.IsNaN(tt.want)) {
+			t.Errorf("%s: want %s ratio %g, got %g", tt.name, tt.counter, tt.want, got)
+		}
+	}
+}
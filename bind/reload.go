@@ -0,0 +1,112 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"sort"
+	"time"
+)
+
+// Reload describes a reconfiguration of named between two statistics, see
+// Reloaded.
+type Reload struct {
+	OldConfigTime time.Time
+	NewConfigTime time.Time
+	// AddedViews and RemovedViews list the names of the views which
+	// appeared or disappeared, sorted.
+	AddedViews   []string
+	RemovedViews []string
+	// AddedZones and RemovedZones list the zones which appeared or
+	// disappeared, sorted by view and zone. A zone which moved between
+	// views is listed in both.
+	AddedZones   []ZoneRef
+	RemovedZones []ZoneRef
+}
+
+// ZoneRef identifies a zone of a view.
+type ZoneRef struct {
+	View string
+	// Zone is the name of the zone in canonical form, see
+	// CanonicalZoneName.
+	Zone string
+}
+
+// Reloaded returns the changes of the views and zones between prev and cur
+// if named has been reconfigured in between, which changes the config time
+// but not the boot time. It returns nil otherwise.
+func Reloaded(prev, cur Statistics) *Reload {
+	old, now := prev.Server.ConfigTime, cur.Server.ConfigTime
+	if old.IsZero() || now.IsZero() || old.Equal(now) || !SameBoot(prev.Server.BootTime, cur.Server.BootTime) {
+		return nil
+	}
+	r := &Reload{OldConfigTime: old, NewConfigTime: now}
+	prevViews, curViews := viewNames(prev), viewNames(cur)
+	r.AddedViews = missingKeys(curViews, prevViews)
+	r.RemovedViews = missingKeys(prevViews, curViews)
+
+	prevZones, curZones := zoneRefs(prev), zoneRefs(cur)
+	r.AddedZones = missingZones(curZones, prevZones)
+	r.RemovedZones = missingZones(prevZones, curZones)
+	return r
+}
+
+func viewNames(s Statistics) map[string]bool {
+	names := map[string]bool{}
+	for _, v := range s.Views {
+		names[v.Name] = true
+	}
+	for _, v := range s.ZoneViews {
+		names[v.Name] = true
+	}
+	return names
+}
+
+func zoneRefs(s Statistics) map[ZoneRef]bool {
+	refs := map[ZoneRef]bool{}
+	for _, v := range s.ZoneViews {
+		for _, z := range v.ZoneData {
+			refs[ZoneRef{View: v.Name, Zone: CanonicalZoneName(z.Name)}] = true
+		}
+	}
+	return refs
+}
+
+// missingKeys returns the sorted keys of a which are missing from b.
+func missingKeys(a, b map[string]bool) []string {
+	var keys []string
+	for k := range a {
+		if !b[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// missingZones returns the zones of a which are missing from b.
+func missingZones(a, b map[ZoneRef]bool) []ZoneRef {
+	var refs []ZoneRef
+	for r := range a {
+		if !b[r] {
+			refs = append(refs, r)
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].View != refs[j].View {
+			return refs[i].View < refs[j].View
+		}
+		return refs[i].Zone < refs[j].Zone
+	})
+	return refs
+}
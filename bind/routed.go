@@ -0,0 +1,142 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// RouteError is returned by a RoutedClient if the client of some groups
+// failed. If several clients failed, Stats returns the RouteErrors joined with
+// errors.Join.
+type RouteError struct {
+	// Groups are the requested groups routed to the failed client.
+	Groups []StatisticGroup
+	Err    error
+}
+
+func (e *RouteError) Error() string {
+	names := make([]string, 0, len(e.Groups))
+	for _, g := range e.Groups {
+		names = append(names, string(g))
+	}
+	return fmt.Sprintf("error fetching %s stats: %s", strings.Join(names, ","), e.Err)
+}
+
+func (e *RouteError) Unwrap() error {
+	return e.Err
+}
+
+// RoutedClient is a Client which fetches every statistic group from its own
+// client, e.g. when the zones document is served by a separate, more
+// restricted statistics channel. The clients are queried concurrently and
+// their statistics are combined with Statistics.Merge.
+type RoutedClient struct {
+	routes map[StatisticGroup]Client
+	def    Client
+}
+
+// NewRoutedClient returns a RoutedClient fetching the groups in routes from
+// their client and all other groups from def, which may be nil if routes
+// covers all requested groups. Groups routed to the same client are fetched
+// with a single Stats call.
+func NewRoutedClient(routes map[StatisticGroup]Client, def Client) *RoutedClient {
+	r := make(map[StatisticGroup]Client, len(routes))
+	for g, c := range routes {
+		r[g] = c
+	}
+	return &RoutedClient{routes: r, def: def}
+}
+
+type route struct {
+	client Client
+	groups []StatisticGroup
+}
+
+// Stats implements Client. If some clients fail, it returns the statistics
+// of the others together with a RouteError for every failed client.
+// Statistics returned by a failed client along with its error, such as
+// truncated zones, are merged as well.
+func (c *RoutedClient) Stats(ctx context.Context, groups ...StatisticGroup) (Statistics, error) {
+	if len(groups) == 0 {
+		if c.def == nil {
+			return Statistics{}, errors.New("no default client")
+		}
+		return c.def.Stats(ctx)
+	}
+
+	routes := c.plan(groups)
+	stats := make([]Statistics, len(routes))
+	errs := make([]error, len(routes))
+	var wg sync.WaitGroup
+	for i, r := range routes {
+		if r.client == nil {
+			errs[i] = &RouteError{Groups: r.groups, Err: errors.New("no client for group")}
+			continue
+		}
+		wg.Add(1)
+		go func(i int, r route) {
+			defer wg.Done()
+			stats[i], errs[i] = r.client.Stats(ctx, r.groups...)
+			if errs[i] != nil {
+				errs[i] = &RouteError{Groups: r.groups, Err: errs[i]}
+			}
+		}(i, r)
+	}
+	wg.Wait()
+
+	s := Statistics{}
+	for i, r := range routes {
+		s.Merge(stats[i], r.groups...)
+	}
+	return s, errors.Join(errs...)
+}
+
+// plan assigns the groups to their clients in the order of groups.
+func (c *RoutedClient) plan(groups []StatisticGroup) []route {
+	var routes []route
+next:
+	for _, g := range groups {
+		client, ok := c.routes[g]
+		if !ok {
+			client = c.def
+		}
+		for i := range routes {
+			if containsGroup(routes[i].groups, g) {
+				continue next
+			}
+			if sameClient(routes[i].client, client) {
+				routes[i].groups = append(routes[i].groups, g)
+				continue next
+			}
+		}
+		routes = append(routes, route{client: client, groups: []StatisticGroup{g}})
+	}
+	return routes
+}
+
+// sameClient reports whether a and b are the same client. Clients of types
+// which are not comparable are never considered the same.
+func sameClient(a, b Client) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	t := reflect.TypeOf(a)
+	return t == reflect.TypeOf(b) && t.Comparable() && a == b
+}
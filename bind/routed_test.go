@@ -0,0 +1,99 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/bindtest"
+	"github.com/prometheus-community/bind_exporter/bind/xml"
+)
+
+func TestRoutedClient(t *testing.T) {
+	good := bindtest.NewServer()
+	defer good.Close()
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer bad.Close()
+
+	def := xml.NewClient(good.URL, nil)
+	c := bind.NewRoutedClient(map[bind.StatisticGroup]bind.Client{
+		bind.TaskStats: xml.NewClient(bad.URL, nil),
+	}, def)
+
+	s, err := c.Stats(context.Background(), bind.ServerStats, bind.ViewStats, bind.TaskStats)
+	var rerr *bind.RouteError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected RouteError, got %v", err)
+	}
+	if expected := []bind.StatisticGroup{bind.TaskStats}; !reflect.DeepEqual(rerr.Groups, expected) {
+		t.Errorf("expected error for groups %v, got %v", expected, rerr.Groups)
+	}
+	var serr *bind.StatusError
+	if !errors.As(err, &serr) || serr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected StatusError of failing client, got %v", err)
+	}
+
+	expected, err := def.Stats(context.Background(), bind.ServerStats, bind.ViewStats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The timing of the requests differs between the fetches.
+	expected.Source.FetchTime, expected.ClockSkew = s.Source.FetchTime, s.ClockSkew
+	for g, i := range expected.Decode {
+		i.Duration = s.Decode[g].Duration
+		expected.Decode[g] = i
+	}
+	if !reflect.DeepEqual(s, expected) {
+		t.Errorf("expected statistics of default client, got %+v", s)
+	}
+	if len(s.TaskManager.Tasks) != 0 {
+		t.Errorf("expected no tasks, got %d", len(s.TaskManager.Tasks))
+	}
+}
+
+func TestRoutedClientGroups(t *testing.T) {
+	good := bindtest.NewServer()
+	defer good.Close()
+
+	inner := xml.NewClient(good.URL, nil)
+	c := bind.NewRoutedClient(map[bind.StatisticGroup]bind.Client{
+		bind.ServerStats: inner,
+		bind.TaskStats:   inner,
+	}, nil)
+
+	s, err := c.Stats(context.Background(), bind.ServerStats, bind.TaskStats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Server.IncomingQueries) == 0 || len(s.TaskManager.Tasks) == 0 {
+		t.Errorf("expected server and task statistics, got %+v", s)
+	}
+	if len(s.Views) != 0 || len(s.ZoneViews) != 0 {
+		t.Errorf("expected no view statistics, got %d views and %d zone views", len(s.Views), len(s.ZoneViews))
+	}
+
+	_, err = c.Stats(context.Background(), bind.ViewStats)
+	var rerr *bind.RouteError
+	if !errors.As(err, &rerr) || !reflect.DeepEqual(rerr.Groups, []bind.StatisticGroup{bind.ViewStats}) {
+		t.Errorf("expected RouteError for view group, got %v", err)
+	}
+}
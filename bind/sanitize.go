@@ -0,0 +1,144 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// SanitizeZoneLabel returns the canonical form of the zone or view name,
+// suitable as a label value for systems which mishandle arbitrary strings.
+// The rules are stable across releases:
+//
+//   - Escapes of the DNS presentation format, "\DDD" and "\X", are decoded.
+//   - ASCII letters are lowercased, as names are compared case-insensitively.
+//   - A trailing dot is removed, except from the root zone ".".
+//   - Letters, digits, "-", "_" and "*" are kept, other bytes of a label, such
+//     as escaped dots, spaces and the bytes of non-ASCII names, are written as
+//     "\DDD". Internationalized names are thus best reported in punycode,
+//     which BIND does.
+//
+// Distinct names may have the same canonical form, e.g. "Example.com" and
+// "example.com.", see SanitizeZoneLabels.
+func SanitizeZoneLabel(name string) string {
+	if name == "" {
+		return ""
+	}
+	var labels []string
+	var label []byte
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c == '.':
+			labels = append(labels, escapeLabel(label))
+			label = label[:0]
+			continue
+		case c == '\\' && i+3 < len(name) && isDigits(name[i+1:i+4]):
+			n, _ := strconv.Atoi(name[i+1 : i+4])
+			if n <= 255 {
+				c = byte(n)
+				i += 3
+				break
+			}
+			fallthrough
+		case c == '\\' && i+1 < len(name):
+			i++
+			c = name[i]
+		}
+		label = append(label, c)
+	}
+	if len(label) > 0 || len(labels) == 0 {
+		labels = append(labels, escapeLabel(label))
+	}
+	if len(labels) == 1 && labels[0] == "" {
+		return "."
+	}
+	return strings.Join(labels, ".")
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func escapeLabel(label []byte) string {
+	var b strings.Builder
+	for _, c := range label {
+		switch {
+		case c >= 'A' && c <= 'Z':
+			b.WriteByte(c + 'a' - 'A')
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '-', c == '_', c == '*':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "\\%03d", c)
+		}
+	}
+	return b.String()
+}
+
+// SanitizeZoneLabels returns the canonical forms of names by SanitizeZoneLabel,
+// keyed by name. If distinct names share a canonical form, each of them is
+// suffixed with "~" and a hash of the name, e.g. "example.com~1b5f2a9c", so
+// that their label values remain distinct. The suffix only depends on the
+// name, not on the order of names, and cannot be part of a canonical form.
+func SanitizeZoneLabels(names []string) map[string]string {
+	labels := make(map[string]string, len(names))
+	owners := map[string][]string{}
+	for _, n := range names {
+		if _, ok := labels[n]; ok {
+			continue
+		}
+		l := SanitizeZoneLabel(n)
+		labels[n] = l
+		owners[l] = append(owners[l], n)
+	}
+	for l, ns := range owners {
+		if len(ns) < 2 {
+			continue
+		}
+		for _, n := range ns {
+			h := fnv.New32a()
+			h.Write([]byte(n))
+			labels[n] = fmt.Sprintf("%s~%08x", l, h.Sum32())
+		}
+	}
+	return labels
+}
+
+// SanitizeMetricName returns name with every character which is not allowed in
+// a Prometheus metric name replaced by "_", e.g. "QryRTT1600+" becomes
+// "QryRTT1600_". A leading digit is prefixed with "_" and the empty name is
+// returned as "_". Case is preserved.
+func SanitizeMetricName(name string) string {
+	if name == "" {
+		return "_"
+	}
+	b := []rune(name)
+	for i, c := range b {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == ':') {
+			b[i] = '_'
+		}
+	}
+	if b[0] >= '0' && b[0] <= '9' {
+		return "_" + string(b)
+	}
+	return string(b)
+}
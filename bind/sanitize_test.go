@@ -0,0 +1,87 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSanitizeZoneLabel(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{name: "example.com", want: "example.com"},
+		{name: "Example.COM.", want: "example.com"},
+		{name: ".", want: "."},
+		{name: "", want: ""},
+		{name: "*.example.com", want: "*.example.com"},
+		{name: "_sip._tcp.example.com", want: "_sip._tcp.example.com"},
+		{name: "host_.Example_.com", want: "host_.example_.com"},
+		{name: "xn--bcher-kva.example", want: "xn--bcher-kva.example"},
+		{name: "bücher.example", want: `b\195\188cher.example`},
+		{name: `\065\066.example`, want: "ab.example"},
+		{name: `a\.b.example`, want: `a\046b.example`},
+		{name: `a\046b.example`, want: `a\046b.example`},
+		{name: `sp\ ace.example`, want: `sp\032ace.example`},
+		{name: `back\\slash.example`, want: `back\092slash.example`},
+		{name: `\999.example`, want: "999.example"},
+		{name: `trailing\`, want: `trailing\092`},
+		{name: "1.0.10.in-addr.arpa", want: "1.0.10.in-addr.arpa"},
+		{name: "0/25.2.0.192.in-addr.arpa", want: `0\04725.2.0.192.in-addr.arpa`},
+	}
+	for _, tt := range tests {
+		got := SanitizeZoneLabel(tt.name)
+		if got != tt.want {
+			t.Errorf("SanitizeZoneLabel(%q): want %q, got %q", tt.name, tt.want, got)
+		}
+		if again := SanitizeZoneLabel(got); again != got {
+			t.Errorf("SanitizeZoneLabel(%q) is not stable: %q", got, again)
+		}
+	}
+}
+
+func TestSanitizeZoneLabels(t *testing.T) {
+	got := SanitizeZoneLabels([]string{"Example.com", "example.com.", "example.net", "Example.com"})
+	want := map[string]string{
+		"Example.com":  "example.com~e463ef46",
+		"example.com.": "example.com~ae85fd98",
+		"example.net":  "example.net",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if got := SanitizeZoneLabels([]string{"example.com.", "Example.com"}); !reflect.DeepEqual(got, map[string]string{
+		"Example.com":  want["Example.com"],
+		"example.com.": want["example.com."],
+	}) {
+		t.Errorf("labels depend on the order of names: %v", got)
+	}
+}
+
+func TestSanitizeMetricName(t *testing.T) {
+	for name, want := range map[string]string{
+		"QrySuccess":   "QrySuccess",
+		"QryRTT1600+":  "QryRTT1600_",
+		"NSAP-PTR":     "NSAP_PTR",
+		"bind:queries": "bind:queries",
+		"3rdParty":     "_3rdParty",
+		"bücher":       "b_cher",
+		"":             "_",
+	} {
+		if got := SanitizeMetricName(name); got != want {
+			t.Errorf("SanitizeMetricName(%q): want %q, got %q", name, want, got)
+		}
+	}
+}
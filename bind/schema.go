@@ -0,0 +1,162 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+// SchemaVersion is the version of the schema returned by Schema. Names are
+// only ever added to the schema of a version: a group, section, counter,
+// label or metric, once published, keeps its name, kind, unit and labels, so
+// that dashboards and alerting rules generated from the schema keep working
+// across upgrades of the package. Any other change increments the version.
+const SchemaVersion = 1
+
+// SchemaDoc is a machine-readable description of everything the package
+// reports, e.g. to generate dashboards and alerting rules. It marshals to JSON
+// and YAML.
+type SchemaDoc struct {
+	Version int `json:"version" yaml:"version"`
+	// Groups lists the statistic groups with the sections of Flatten
+	// reporting their statistics.
+	Groups []GroupSchema `json:"groups" yaml:"groups"`
+	// Counters lists the well-known statistics, see CounterCatalog.
+	Counters []CounterInfo `json:"counters" yaml:"counters"`
+	// Labels lists the labels of the sections and metrics.
+	Labels []LabelSchema `json:"labels" yaml:"labels"`
+	// Metrics lists the metric families of the exposition package, which
+	// are added by exposition.Schema.
+	Metrics []MetricSchema `json:"metrics,omitempty" yaml:"metrics,omitempty"`
+}
+
+// GroupSchema describes a statistic group.
+type GroupSchema struct {
+	Name     StatisticGroup  `json:"name" yaml:"name"`
+	Help     string          `json:"help" yaml:"help"`
+	Sections []SectionSchema `json:"sections,omitempty" yaml:"sections,omitempty"`
+}
+
+// SectionSchema describes the metrics of Flatten named after the section,
+// e.g. "bind.server.nsstats" for the section "server.nsstats" in the
+// NameDotted scheme. A name ending in "*" stands for the sanitized types of
+// the sections unknown to the package, see Extra.
+type SectionSchema struct {
+	Name string      `json:"name" yaml:"name"`
+	Kind CounterKind `json:"kind" yaml:"kind"`
+	// Unit is the unit of the values, or empty for counts.
+	Unit Unit `json:"unit,omitempty" yaml:"unit,omitempty"`
+	// Labels lists the labels the metrics may have.
+	Labels []string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// Counters is the group of the catalog describing the statistics named
+	// by the last label, if any.
+	Counters CounterGroup `json:"counters,omitempty" yaml:"counters,omitempty"`
+	Help     string       `json:"help" yaml:"help"`
+}
+
+// LabelSchema describes a label of sections and metrics.
+type LabelSchema struct {
+	Name string `json:"name" yaml:"name"`
+	Help string `json:"help" yaml:"help"`
+}
+
+// MetricSchema describes a metric family in the OpenMetrics sense.
+type MetricSchema struct {
+	// Name is the name of the family. The samples of counters have the
+	// suffix "_total".
+	Name string `json:"name" yaml:"name"`
+	// Type is "counter", "gauge" or "histogram".
+	Type string `json:"type" yaml:"type"`
+	Help string `json:"help" yaml:"help"`
+	// Group is the statistic group the samples are taken from, or empty
+	// for families about the statistics as a whole.
+	Group  StatisticGroup `json:"group,omitempty" yaml:"group,omitempty"`
+	Labels []string       `json:"labels,omitempty" yaml:"labels,omitempty"`
+}
+
+// Schema returns the description of the statistic groups, the sections of
+// Flatten, the counter catalog and the labels. The returned document may be
+// modified by the caller.
+func Schema() SchemaDoc {
+	memory := []SectionSchema{}
+	for _, c := range catalog {
+		if c.Group == MemoryCounters {
+			memory = append(memory, SectionSchema{Name: "memory." + c.MetricName(), Kind: c.Kind, Unit: c.Unit, Help: c.Help})
+		}
+	}
+	memory = append(memory, SectionSchema{Name: "memory.context_in_use_bytes", Kind: KindGauge, Unit: UnitBytes, Labels: []string{"context"},
+		Help: "Memory in use by the memory contexts of a name, for the names using most memory, see TopMemoryContexts."})
+
+	server := append([]SectionSchema{
+		{Name: "server.boot_time_seconds", Kind: KindGauge, Unit: UnitSeconds, Help: "Start time of named since unix epoch."},
+		{Name: "server.config_time_seconds", Kind: KindGauge, Unit: UnitSeconds, Help: "Time of the last reconfiguration since unix epoch."},
+		{Name: "server.current_time_seconds", Kind: KindGauge, Unit: UnitSeconds, Help: "Time the statistics have been produced since unix epoch."},
+		{Name: "server.qtypes", Kind: KindCounter, Labels: []string{"type"}, Help: "Incoming queries by query type."},
+		{Name: "server.opcodes", Kind: KindCounter, Labels: []string{"opcode"}, Counters: OpcodeCounters, Help: "Incoming requests by opcode."},
+		{Name: "server.nsstats", Kind: KindCounter, Labels: []string{"name"}, Counters: NameServerCounters, Help: "Name server statistics."},
+		{Name: "server.zonestats", Kind: KindCounter, Labels: []string{"name"}, Counters: ZoneMaintenanceCounters, Help: "Zone maintenance statistics."},
+		{Name: "server.rcodes", Kind: KindCounter, Labels: []string{"rcode"}, Counters: RcodeCounters, Help: "Responses sent by rcode."},
+		{Name: "server.unknown_*", Kind: KindCounter, Labels: []string{"name"}, Help: "Server counters of sections unknown to the package."},
+	}, memory...)
+
+	view := []string{"view"}
+	zone := []string{"view", "zone"}
+	views := []SectionSchema{
+		{Name: "view.cache_rrsets", Kind: KindGauge, Labels: append(view, "type"), Help: "RRsets in the cache by type."},
+		{Name: "view.cache_memory_bytes", Kind: KindGauge, Unit: UnitBytes, Labels: append(view, "name"), Help: "Memory statistics of the cache."},
+		{Name: "view.cachestats", Kind: KindCounter, Labels: append(view, "name"), Counters: CacheCounters, Help: "Cache statistics."},
+		{Name: "view.resstats", Kind: KindCounter, Labels: append(view, "name"), Counters: ResolverCounters, Help: "Resolver statistics."},
+		{Name: "view.resolver_gauges", Kind: KindGauge, Labels: append(view, "name"), Counters: ResolverCounters, Help: "Resolver statistics with a current value."},
+		{Name: "view.resqtypes", Kind: KindCounter, Labels: append(view, "type"), Help: "Outgoing queries by query type."},
+		{Name: "view.unknown_*", Kind: KindCounter, Labels: append(view, "name"), Help: "View counters of sections unknown to the package."},
+		{Name: "zone.serial", Kind: KindGauge, Labels: zone, Help: "Serial of the zone."},
+		{Name: "zone.zonestats", Kind: KindCounter, Labels: append(zone, "name"), Counters: ZoneMaintenanceCounters, Help: "Zone maintenance statistics of the zone."},
+		{Name: "zone.dnssec_sign", Kind: KindCounter, Labels: append(zone, "key"), Help: "Signatures generated by DNSSEC key."},
+		{Name: "zone.dnssec_refresh", Kind: KindCounter, Labels: append(zone, "key"), Help: "Signatures refreshed by DNSSEC key."},
+		{Name: "zone.query_results", Kind: KindCounter, Labels: append(zone, "name"), Counters: NameServerCounters, Help: "Query results of the zone."},
+		{Name: "zone.qtypes", Kind: KindCounter, Labels: append(zone, "type"), Help: "Incoming queries of the zone by query type."},
+		{Name: "zone.nsstats", Kind: KindCounter, Labels: append(zone, "name"), Counters: NameServerCounters, Help: "Name server statistics of the zone."},
+		{Name: "zone.unknown_*", Kind: KindCounter, Labels: append(zone, "name"), Help: "Zone counters of sections unknown to the package."},
+	}
+
+	tasks := []SectionSchema{
+		{Name: "tasks.running", Kind: KindGauge, Help: "Running tasks."},
+		{Name: "tasks.worker_threads", Kind: KindGauge, Help: "Available worker threads."},
+		{Name: "tasks.utilization", Kind: KindGauge, Help: "Running tasks per worker thread, see ThreadModel.Utilization."},
+		{Name: "tasks.saturated", Kind: KindGauge, Help: "Whether the worker threads are saturated, see ThreadModel.Saturated."},
+	}
+
+	return SchemaDoc{
+		Version: SchemaVersion,
+		Groups: []GroupSchema{
+			{Name: ServerStats, Help: "Server-wide statistics, including the memory summary.", Sections: server},
+			{Name: ViewStats, Help: "Statistics of the views and their zones.", Sections: views},
+			{Name: TaskStats, Help: "Statistics of the task manager.", Sections: tasks},
+			{Name: StatusStats, Help: "Boot, reconfiguration and current time and the version, which are reported by the sections of the server group."},
+		},
+		Counters: CounterCatalog(),
+		Labels: []LabelSchema{
+			{Name: "view", Help: "Name of the view, see WithDefaultViewAlias."},
+			{Name: "zone", Help: "Name of the zone, sanitized by SanitizeZoneLabels."},
+			{Name: "zone_name", Help: "Name of the zone in the exposition package, sanitized by SanitizeZoneLabels."},
+			{Name: "type", Help: "Query type, or RRset type of the cache."},
+			{Name: "opcode", Help: "Opcode of requests."},
+			{Name: "rcode", Help: "Rcode of responses."},
+			{Name: "result", Help: "Query result of the zone."},
+			{Name: "name", Help: "Name of the statistic, see Describe."},
+			{Name: "key", Help: "DNSSEC key."},
+			{Name: "section", Help: "Type of a section unknown to the package, see Extra."},
+			{Name: "context", Help: "Name of a memory context, or OtherMemoryContexts."},
+			{Name: "code", Help: "Code of warnings."},
+			{Name: "group", Help: "Statistic group."},
+			{Name: "class", Help: "Class of errors."},
+		},
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind_test
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/exposition"
+)
+
+func TestSchemaFlatten(t *testing.T) {
+	doc := bind.Schema()
+	labels := map[string]bool{}
+	for _, l := range doc.Labels {
+		labels[l.Name] = true
+	}
+	groups := map[bind.CounterGroup]bool{}
+	for _, c := range doc.Counters {
+		groups[c.Group] = true
+	}
+	var sections []bind.SectionSchema
+	for _, g := range doc.Groups {
+		for _, s := range g.Sections {
+			for _, l := range s.Labels {
+				if !labels[l] {
+					t.Errorf("section %s: label %s not declared by the schema", s.Name, l)
+				}
+			}
+			if s.Counters != "" && !groups[s.Counters] {
+				t.Errorf("section %s: counter group %s not in the catalog", s.Name, s.Counters)
+			}
+			sections = append(sections, s)
+		}
+	}
+	section := func(name string) (bind.SectionSchema, bool) {
+		for _, s := range sections {
+			if p := strings.TrimSuffix(s.Name, "*"); p == name || p != s.Name && strings.HasPrefix(name, p) {
+				return s, true
+			}
+		}
+		return bind.SectionSchema{}, false
+	}
+
+	s := fixtureStats(t)
+	s.Server.Extra = bind.Extra{"quic": {{Name: "QUICConnIn", Counter: 1}}}
+	s.Views[0].Extra = bind.Extra{"resquic": {{Name: "QUICQueryv4", Counter: 1}}}
+	s.ZoneViews[0].ZoneData[0].Extra = bind.Extra{"quic": {{Name: "QUICQryIn", Counter: 1}}}
+	s.TaskManager.ThreadModel = bind.ThreadModel{WorkerThreads: 4, TasksRunning: 1}
+	for _, m := range bind.Flatten(s) {
+		sec, ok := section(strings.TrimPrefix(m.Name, "bind."))
+		if !ok {
+			t.Errorf("metric %s not declared by the schema", m.Name)
+			continue
+		}
+		if sec.Kind != m.Kind {
+			t.Errorf("metric %s: want kind %s, got %s", m.Name, sec.Kind, m.Kind)
+		}
+		for l := range m.Labels {
+			if !contains(sec.Labels, l) {
+				t.Errorf("metric %s: label %s not declared by section %s", m.Name, l, sec.Name)
+			}
+		}
+	}
+}
+
+// TestSchemaStable checks that the schema only adds to the schema recorded in
+// the golden file, see SchemaVersion.
+func TestSchemaStable(t *testing.T) {
+	const file = "testdata/schema.json"
+	doc := exposition.Schema()
+	if *update {
+		b, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, append(b, '\n'), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var golden bind.SchemaDoc
+	if err := json.Unmarshal(b, &golden); err != nil {
+		t.Fatal(err)
+	}
+	if golden.Version != doc.Version {
+		// A new version may change anything, update the golden file.
+		t.Fatalf("want schema version %d, got %d, run go test -update to record the new version", golden.Version, doc.Version)
+	}
+
+	sections := map[string]bind.SectionSchema{}
+	for _, g := range doc.Groups {
+		for _, s := range g.Sections {
+			sections[s.Name] = s
+		}
+	}
+	for _, g := range golden.Groups {
+		for _, want := range g.Sections {
+			got, ok := sections[want.Name]
+			if !ok || got.Kind != want.Kind || got.Unit != want.Unit || !reflect.DeepEqual(got.Labels, want.Labels) {
+				t.Errorf("section %s: want %+v, got %+v", want.Name, want, got)
+			}
+		}
+	}
+	for _, want := range golden.Counters {
+		got, ok := bind.DescribeIn(want.Group, want.Name)
+		if !ok || got.Name != want.Name || got.Kind != want.Kind || got.Unit != want.Unit {
+			t.Errorf("counter %s/%s: want %+v, got %+v", want.Group, want.Name, want, got)
+		}
+	}
+	labels := map[string]bool{}
+	for _, l := range doc.Labels {
+		labels[l.Name] = true
+	}
+	for _, l := range golden.Labels {
+		if !labels[l.Name] {
+			t.Errorf("label %s removed", l.Name)
+		}
+	}
+	metrics := map[string]bind.MetricSchema{}
+	for _, m := range doc.Metrics {
+		metrics[m.Name] = m
+	}
+	for _, want := range golden.Metrics {
+		got, ok := metrics[want.Name]
+		if !ok || got.Type != want.Type || !reflect.DeepEqual(got.Labels, want.Labels) {
+			t.Errorf("metric %s: want %+v, got %+v", want.Name, want, got)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"regexp"
+	"strings"
+)
+
+// TaskState is the state of a task as reported by the task manager.
+type TaskState string
+
+// States of tasks.
+const (
+	TaskIdle    TaskState = "idle"
+	TaskReady   TaskState = "ready"
+	TaskRunning TaskState = "running"
+	TaskPaused  TaskState = "paused"
+	TaskDone    TaskState = "done"
+)
+
+// Known reports whether s is one of the states defined by this package.
+func (s TaskState) Known() bool {
+	switch s {
+	case TaskIdle, TaskReady, TaskRunning, TaskPaused, TaskDone:
+		return true
+	}
+	return false
+}
+
+// TaskFilter selects tasks. Zero fields match all tasks, and a task must
+// match all non-zero fields.
+type TaskFilter struct {
+	// Name matches tasks whose name contains Name.
+	Name string
+	// NameRegexp matches tasks whose name matches NameRegexp.
+	NameRegexp *regexp.Regexp
+	// State matches tasks in State.
+	State TaskState
+	// MinReferences matches tasks with at least MinReferences references.
+	MinReferences uint64
+}
+
+// Match reports whether t is selected by f.
+func (f TaskFilter) Match(t Task) bool {
+	if f.Name != "" && !strings.Contains(t.Name, f.Name) {
+		return false
+	}
+	if f.NameRegexp != nil && !f.NameRegexp.MatchString(t.Name) {
+		return false
+	}
+	if f.State != "" && t.State != f.State {
+		return false
+	}
+	return t.References >= f.MinReferences
+}
+
+// Filter returns the tasks selected by f in their original order. The
+// returned slice only grows with the matches and does not share memory with
+// m.Tasks. It is nil if no task matches.
+func (m TaskManager) Filter(f TaskFilter) []Task {
+	var tasks []Task
+	for _, t := range m.Tasks {
+		if f.Match(t) {
+			tasks = append(tasks, t)
+		}
+	}
+	return tasks
+}
+
+// Utilization returns the number of running tasks per worker thread, or NaN
+// if no worker threads are reported, e.g. by BIND versions without task
+// manager.
+func (m ThreadModel) Utilization() float64 {
+	return ratio(m.TasksRunning, m.WorkerThreads)
+}
+
+// Saturated reports whether at least as many tasks are running as there are
+// worker threads, so that further tasks have to wait. It is false if no worker
+// threads are reported.
+func (m ThreadModel) Saturated() bool {
+	return m.WorkerThreads > 0 && m.TasksRunning >= m.WorkerThreads
+}
@@ -0,0 +1,107 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"encoding/xml"
+	"io/fs"
+	"math"
+	"regexp"
+	"testing"
+
+	"github.com/prometheus-community/bind_exporter/fixtures"
+)
+
+func TestTaskManagerFilter(t *testing.T) {
+	b, err := fs.ReadFile(fixtures.FS, "xml/tasks-busy.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		TaskManager TaskManager `xml:"taskmgr"`
+	}
+	if err := xml.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+	tm := doc.TaskManager
+
+	for _, task := range tm.Tasks {
+		if !task.State.Known() {
+			t.Errorf("task %s has unknown state %q", task.ID, task.State)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter TaskFilter
+		want   int
+	}{
+		{name: "all", filter: TaskFilter{}, want: len(tm.Tasks)},
+		{name: "ready zones", filter: TaskFilter{Name: "zone", State: TaskReady}, want: 5},
+		{name: "exact zone", filter: TaskFilter{NameRegexp: regexp.MustCompile(`^zone$`), State: TaskReady}, want: 3},
+		{name: "references", filter: TaskFilter{MinReferences: 10}, want: 5},
+		{name: "running resolvers", filter: TaskFilter{NameRegexp: regexp.MustCompile(`^res\d+$`), State: TaskRunning, MinReferences: 20}, want: 1},
+		{name: "paused", filter: TaskFilter{State: TaskPaused}, want: 1},
+		{name: "none", filter: TaskFilter{Name: "nonexistent"}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tm.Filter(tt.filter)
+			if len(got) != tt.want {
+				t.Fatalf("want %d tasks, got %d: %+v", tt.want, len(got), got)
+			}
+			for _, task := range got {
+				if !tt.filter.Match(task) {
+					t.Errorf("task %+v does not match the filter", task)
+				}
+			}
+			if tt.want == 0 && got != nil {
+				t.Error("want nil slice without matches")
+			}
+		})
+	}
+
+	few := tm.Filter(TaskFilter{State: TaskPaused})
+	if cap(few) >= len(tm.Tasks) {
+		t.Errorf("want filter result not to copy all tasks, got capacity %d", cap(few))
+	}
+	few[0].Name = "changed"
+	for _, task := range tm.Tasks {
+		if task.Name == "changed" {
+			t.Error("want filter result not to share memory with the tasks")
+		}
+	}
+}
+
+func TestThreadModelUtilization(t *testing.T) {
+	for _, tc := range []struct {
+		m         ThreadModel
+		want      float64
+		saturated bool
+	}{
+		{m: ThreadModel{WorkerThreads: 16, TasksRunning: 8}, want: 0.5},
+		{m: ThreadModel{WorkerThreads: 4, TasksRunning: 4}, want: 1, saturated: true},
+		{m: ThreadModel{WorkerThreads: 4, TasksRunning: 7}, want: 1.75, saturated: true},
+		{m: ThreadModel{TasksRunning: 3}, want: math.NaN()},
+		{want: math.NaN()},
+	} {
+		got := tc.m.Utilization()
+		if got != tc.want && !(math.IsNaN(got) && math.IsNaN(tc.want)) {
+			t.Errorf("%+v: want utilization %v, got %v", tc.m, tc.want, got)
+		}
+		if got := tc.m.Saturated(); got != tc.saturated {
+			t.Errorf("%+v: want saturated %v, got %v", tc.m, tc.saturated, got)
+		}
+	}
+}
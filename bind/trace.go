@@ -0,0 +1,156 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"context"
+	"time"
+)
+
+// ClientTrace is a set of hooks run by clients while serving a Stats call.
+// Any particular hook may be nil. It is attached to the context passed to
+// Stats with WithClientTrace, which allows wrapping clients to observe the
+// individual HTTP requests of the wrapped client.
+type ClientTrace struct {
+	// GetStart is called before the document at url is requested for group
+	// g. The returned context is used for the request and passed to the
+	// remaining hooks of the request.
+	GetStart func(ctx context.Context, g StatisticGroup, url string) context.Context
+	// GetDone is called once the document has been fetched and decoded, or
+	// the request failed with err.
+	GetDone func(ctx context.Context, g StatisticGroup, info RequestInfo, err error)
+	// WaitRequest is called before every HTTP request. A non-nil error
+	// aborts the request and is returned by Stats.
+	WaitRequest func(ctx context.Context) error
+	// DecodeStart is called before the response body is decoded. The
+	// returned context is passed to DecodeDone.
+	DecodeStart func(ctx context.Context, g StatisticGroup) context.Context
+	// DecodeDone is called once the response body has been decoded, or
+	// decoding failed with err.
+	DecodeDone func(ctx context.Context, g StatisticGroup, info DecodeInfo, err error)
+}
+
+// RequestInfo describes a single request of a client.
+type RequestInfo struct {
+	URL        string
+	StatusCode int
+	Bytes      int64
+	// Sent is the local time the request was sent.
+	Sent time.Time
+	// Received is the local time the response headers were received.
+	Received time.Time
+}
+
+// DecodeInfo describes a decoded document.
+type DecodeInfo struct {
+	// Views, Zones and Tasks are the numbers of views, zones and tasks
+	// decoded.
+	Views int
+	Zones int
+	Tasks int
+	// Counters is the number of counters and gauges decoded, including those
+	// of unknown sections.
+	Counters int
+	// Duration is the time spent decoding, excluding the time spent waiting
+	// for the response body.
+	Duration time.Duration
+	// SectionBytes holds the size in bytes of every top-level section of
+	// the document, keyed by the name of the child element of the
+	// statistics element, e.g. "server", "views", "taskmgr" or "memory".
+	// The zones are part of the "views" section of the zones document. It
+	// is nil for the JSON client.
+	SectionBytes map[string]int64
+}
+
+// Add returns the sum of i and o.
+func (i DecodeInfo) Add(o DecodeInfo) DecodeInfo {
+	sum := DecodeInfo{
+		Views:    i.Views + o.Views,
+		Zones:    i.Zones + o.Zones,
+		Tasks:    i.Tasks + o.Tasks,
+		Counters: i.Counters + o.Counters,
+		Duration: i.Duration + o.Duration,
+	}
+	for _, m := range []map[string]int64{i.SectionBytes, o.SectionBytes} {
+		for k, n := range m {
+			if sum.SectionBytes == nil {
+				sum.SectionBytes = map[string]int64{}
+			}
+			sum.SectionBytes[k] += n
+		}
+	}
+	return sum
+}
+
+type clientTraceKey struct{}
+
+// ContextClientTrace returns the ClientTrace associated with ctx, or nil.
+func ContextClientTrace(ctx context.Context) *ClientTrace {
+	t, _ := ctx.Value(clientTraceKey{}).(*ClientTrace)
+	return t
+}
+
+// WithClientTrace returns a new context based on ctx carrying trace. Hooks of
+// a trace already present in ctx are run after the hooks of trace.
+func WithClientTrace(ctx context.Context, trace *ClientTrace) context.Context {
+	if old := ContextClientTrace(ctx); old != nil {
+		trace = trace.compose(old)
+	}
+	return context.WithValue(ctx, clientTraceKey{}, trace)
+}
+
+func (t *ClientTrace) compose(old *ClientTrace) *ClientTrace {
+	n := *t
+	if n.GetStart == nil {
+		n.GetStart = old.GetStart
+	} else if f, o := n.GetStart, old.GetStart; o != nil {
+		n.GetStart = func(ctx context.Context, g StatisticGroup, url string) context.Context {
+			return o(f(ctx, g, url), g, url)
+		}
+	}
+	if n.GetDone == nil {
+		n.GetDone = old.GetDone
+	} else if f, o := n.GetDone, old.GetDone; o != nil {
+		n.GetDone = func(ctx context.Context, g StatisticGroup, info RequestInfo, err error) {
+			f(ctx, g, info, err)
+			o(ctx, g, info, err)
+		}
+	}
+	if n.WaitRequest == nil {
+		n.WaitRequest = old.WaitRequest
+	} else if f, o := n.WaitRequest, old.WaitRequest; o != nil {
+		n.WaitRequest = func(ctx context.Context) error {
+			if err := f(ctx); err != nil {
+				return err
+			}
+			return o(ctx)
+		}
+	}
+	if n.DecodeStart == nil {
+		n.DecodeStart = old.DecodeStart
+	} else if f, o := n.DecodeStart, old.DecodeStart; o != nil {
+		n.DecodeStart = func(ctx context.Context, g StatisticGroup) context.Context {
+			return o(f(ctx, g), g)
+		}
+	}
+	if n.DecodeDone == nil {
+		n.DecodeDone = old.DecodeDone
+	} else if f, o := n.DecodeDone, old.DecodeDone; o != nil {
+		n.DecodeDone = func(ctx context.Context, g StatisticGroup, info DecodeInfo, err error) {
+			f(ctx, g, info, err)
+			o(ctx, g, info, err)
+		}
+	}
+	return &n
+}
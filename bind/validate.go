@@ -0,0 +1,200 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Warning describes a likely misconfiguration of the server which is apparent
+// from its statistics, or an anomaly of a document which has been decoded
+// regardless.
+type Warning struct {
+	// Code identifies the kind of warning, e.g. WarnZoneStatisticsDisabled.
+	Code    string
+	Message string
+	// Path locates the anomaly in the document, e.g.
+	// "statistics>server>counters[nsstat]>counter[QryDropped]". It is empty
+	// for warnings about the statistics as a whole.
+	Path string
+}
+
+func (w Warning) String() string {
+	if w.Path != "" {
+		return w.Code + ": " + w.Message + " (at " + w.Path + ")"
+	}
+	return w.Code + ": " + w.Message
+}
+
+// DefaultMaxWarnings is the number of warnings kept by clients unless
+// configured otherwise with WithMaxWarnings.
+const DefaultMaxWarnings = 100
+
+// WarnZoneStatisticsDisabled is the code of the warning that the zones of the
+// server report no query counters, although the server answers queries.
+const WarnZoneStatisticsDisabled = "zone-statistics-disabled"
+
+// WarnBlankCounter is the code of the warning that a counter of a document has
+// no value, e.g. <counter name="QryDropped"></counter>. Its value is taken as
+// zero. The message names the counter, the path its position in the document.
+const WarnBlankCounter = "blank-counter"
+
+// WarnCounterOverflow is the code of the warning that the sum of counters
+// exceeds math.MaxUint64 and has been saturated at that value. The message
+// names the summed counters.
+const WarnCounterOverflow = "counter-overflow"
+
+// WarnInvalidHistogram is the code of the warning that the QryRTT counters of
+// the resolver statistics of a view do not describe a histogram, e.g. because
+// of a bucket bound which is not a number. The QueryRTT of the view is left
+// empty. The message names the view.
+const WarnInvalidHistogram = "invalid-histogram"
+
+// WarnMixedFormats is the code of the warning that Statistics.Merge combined
+// statistics of different formats, e.g. of an XML and a JSON client routed by
+// a RoutedClient, whose counters may be named and grouped differently. The
+// message names both formats.
+const WarnMixedFormats = "mixed-formats"
+
+// MinZonesForZoneStatisticsWarning is the number of zones, not counting
+// BuiltinZones, below which WarnZoneStatisticsDisabled is never reported. A
+// server with a handful of zones may well receive no queries for any of them.
+const MinZonesForZoneStatisticsWarning = 10
+
+// Validate returns warnings about likely misconfigurations of the server
+// apparent from s. The clients store them in Statistics.Warnings.
+//
+// WarnZoneStatisticsDisabled is reported if s has at least
+// MinZonesForZoneStatisticsWarning zones, none of which has a nonzero query
+// counter, while the server counted incoming queries. This is the case if
+// zone-statistics is not enabled in named.conf.
+//
+// WarnCounterOverflow is reported for every view whose QueryRTT histogram has
+// been saturated because its QryRTT counters sum to more than
+// math.MaxUint64.
+func Validate(s Statistics) []Warning {
+	var ws []Warning
+	if w, ok := checkZoneStatistics(s); ok {
+		ws = append(ws, w)
+	}
+	for _, v := range s.Views {
+		var total uint64
+		overflow := false
+		for _, c := range v.ResolverStats {
+			if strings.HasPrefix(c.Name, QryRTT) {
+				var o bool
+				total, o = AddCounters(total, c.Counter)
+				overflow = overflow || o
+			}
+		}
+		if overflow {
+			ws = append(ws, overflowWarning("QryRTT counters of view "+v.Name))
+		}
+	}
+	return ws
+}
+
+func overflowWarning(what string) Warning {
+	return Warning{
+		Code:    WarnCounterOverflow,
+		Message: fmt.Sprintf("the sum of the %s exceeds %d and has been saturated", what, uint64(math.MaxUint64)),
+	}
+}
+
+func checkZoneStatistics(s Statistics) (Warning, bool) {
+	var queries uint64
+	for _, c := range s.Server.IncomingQueries {
+		queries, _ = AddCounters(queries, c.Counter)
+	}
+	if queries == 0 {
+		return Warning{}, false
+	}
+
+	builtin := make(map[string]bool, len(BuiltinZones))
+	for _, z := range BuiltinZones {
+		builtin[z] = true
+	}
+	zones := 0
+	for _, v := range s.ZoneViews {
+		for _, z := range v.ZoneData {
+			if builtin[ZoneKey(z.Name)] {
+				continue
+			}
+			for _, cs := range [][]Counter{z.QueryResults, z.IncomingQueries, z.NameServerStats} {
+				for _, c := range cs {
+					if c.Counter > 0 {
+						return Warning{}, false
+					}
+				}
+			}
+			zones++
+		}
+	}
+	if zones < MinZonesForZoneStatisticsWarning {
+		return Warning{}, false
+	}
+	return Warning{
+		Code: WarnZoneStatisticsDisabled,
+		Message: fmt.Sprintf("none of %d zones reports queries although the server received %d queries; "+
+			"set \"zone-statistics yes;\" in named.conf to collect per-zone statistics", zones, queries),
+	}, true
+}
+
+// AddWarnings appends ws to the warnings of s, keeping at most max warnings
+// in total if max is positive. Further warnings are only counted by
+// OmittedWarnings.
+func (s *Statistics) AddWarnings(max int, ws ...Warning) {
+	n := len(ws)
+	if max > 0 && len(s.Warnings)+n > max {
+		n = max - len(s.Warnings)
+		if n < 0 {
+			n = 0
+		}
+	}
+	s.Warnings = append(s.Warnings, ws[:n]...)
+	s.OmittedWarnings += len(ws) - n
+}
+
+// WarningError is returned by Stats together with the statistics if any of
+// their warnings has a code made fatal with WithFatalWarnings.
+type WarningError struct {
+	// Warnings lists the fatal warnings.
+	Warnings []Warning
+}
+
+func (e *WarningError) Error() string {
+	msg := e.Warnings[0].String()
+	if n := len(e.Warnings); n > 1 {
+		msg += fmt.Sprintf(" (and %d more)", n-1)
+	}
+	return msg
+}
+
+// CheckWarnings returns a WarningError listing the warnings of ws with a code
+// in FatalWarnings, or nil if there are none. Warnings omitted because of
+// MaxWarnings are not checked.
+func (o ClientOptions) CheckWarnings(ws []Warning) error {
+	var fatal []Warning
+	for _, w := range ws {
+		if o.FatalWarnings[w.Code] {
+			fatal = append(fatal, w)
+		}
+	}
+	if len(fatal) == 0 {
+		return nil
+	}
+	return &WarningError{Warnings: fatal}
+}
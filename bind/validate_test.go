@@ -0,0 +1,139 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateZoneStatistics(t *testing.T) {
+	zones := func(n int, c ...Counter) []ZoneView {
+		v := ZoneView{Name: "_default"}
+		for i := 0; i < n; i++ {
+			v.ZoneData = append(v.ZoneData, ZoneCounter{Name: "zone" + strings.Repeat("x", i) + ".example", QueryResults: c})
+		}
+		for _, z := range BuiltinZones {
+			v.ZoneData = append(v.ZoneData, ZoneCounter{Name: z})
+		}
+		return []ZoneView{v}
+	}
+	queries := Server{IncomingQueries: []Counter{{Name: "A", Counter: 100}}}
+	for _, tc := range []struct {
+		name string
+		s    Statistics
+		want bool
+	}{
+		{name: "disabled", s: Statistics{Server: queries, ZoneViews: zones(12, Counter{Name: "QrySuccess"})}, want: true},
+		{name: "few zones", s: Statistics{Server: queries, ZoneViews: zones(MinZonesForZoneStatisticsWarning - 1)}},
+		{name: "no queries", s: Statistics{ZoneViews: zones(12)}},
+		{name: "enabled", s: Statistics{Server: queries, ZoneViews: zones(12, Counter{Name: "QrySuccess", Counter: 1})}},
+	} {
+		ws := Validate(tc.s)
+		if got := len(ws) == 1 && ws[0].Code == WarnZoneStatisticsDisabled; got != tc.want || (!tc.want && len(ws) > 0) {
+			t.Errorf("%s: want warning %t, got %v", tc.name, tc.want, ws)
+		}
+	}
+}
+
+func TestAddWarnings(t *testing.T) {
+	w := Warning{Code: WarnBlankCounter, Message: "counter \"A\" has no value, taken as zero", Path: "statistics>server"}
+	var s Statistics
+	s.AddWarnings(3, w, w)
+	s.AddWarnings(3, w, w)
+	s.AddWarnings(3, w)
+	if len(s.Warnings) != 3 || s.OmittedWarnings != 2 {
+		t.Errorf("want 3 warnings and 2 omitted, got %d and %d", len(s.Warnings), s.OmittedWarnings)
+	}
+	s.AddWarnings(0, w)
+	if len(s.Warnings) != 4 {
+		t.Errorf("want no limit for zero, got %d warnings", len(s.Warnings))
+	}
+	if want := `blank-counter: counter "A" has no value, taken as zero (at statistics>server)`; w.String() != want {
+		t.Errorf("want %q, got %q", want, w.String())
+	}
+}
+
+func TestCheckWarnings(t *testing.T) {
+	ws := []Warning{
+		{Code: WarnZoneStatisticsDisabled, Message: "none of 12 zones reports queries"},
+		{Code: WarnBlankCounter, Message: "counter \"A\" has no value, taken as zero"},
+		{Code: WarnBlankCounter, Message: "counter \"B\" has no value, taken as zero"},
+	}
+	if err := NewClientOptions().CheckWarnings(ws); err != nil {
+		t.Errorf("want no error without fatal warnings, got %v", err)
+	}
+	err := NewClientOptions(WithFatalWarnings(WarnBlankCounter)).CheckWarnings(ws)
+	var werr *WarningError
+	if !errors.As(err, &werr) {
+		t.Fatalf("want WarningError, got %v", err)
+	}
+	if len(werr.Warnings) != 2 || werr.Warnings[0] != ws[1] {
+		t.Errorf("want the blank counter warnings, got %v", werr.Warnings)
+	}
+	if want := `blank-counter: counter "A" has no value, taken as zero (and 1 more)`; err.Error() != want {
+		t.Errorf("want error %q, got %q", want, err)
+	}
+}
+
+func TestValidateCounterOverflow(t *testing.T) {
+	s := Statistics{Views: []View{
+		{Name: "_default", ResolverStats: []Counter{{Name: "QryRTT10", Counter: math.MaxUint64 - 1}, {Name: "QryRTT100", Counter: 1}}},
+		{Name: "saturated", ResolverStats: []Counter{{Name: "QryRTT10", Counter: math.MaxUint64}, {Name: "QryRTT100", Counter: 1}, {Name: "QryRTT100+", Counter: 3}}},
+	}}
+	for i := range s.Views {
+		h, err := QueryRTTHistogram(s.Views[i].ResolverStats)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h.Count != math.MaxUint64 || h.Buckets[len(h.Buckets)-1].Count != math.MaxUint64 {
+			t.Errorf("%s: want saturated histogram, got %+v", s.Views[i].Name, h)
+		}
+		s.Views[i].QueryRTT = h
+	}
+	ws := Validate(s)
+	if len(ws) != 1 || ws[0].Code != WarnCounterOverflow || !strings.Contains(ws[0].Message, "view saturated") {
+		t.Fatalf("want overflow warning of view saturated, got %v", ws)
+	}
+
+	// Merge recomputes the warning instead of keeping it twice.
+	s.Warnings = ws
+	s.Merge(s, ViewStats)
+	if len(s.Warnings) != 1 {
+		t.Errorf("want single warning after merge, got %v", s.Warnings)
+	}
+}
+
+func TestMergeMixedFormats(t *testing.T) {
+	s := Statistics{Source: Source{Format: FormatXMLv3}}
+	s.Merge(Statistics{Source: Source{Format: FormatXMLv3}}, ServerStats)
+	if len(s.Warnings) != 0 {
+		t.Errorf("want no warnings merging the same format, got %v", s.Warnings)
+	}
+	j := Statistics{Source: Source{Format: FormatJSONv1}}
+	s.Merge(j)
+	if len(s.Warnings) != 0 {
+		t.Errorf("want no warnings merging no groups, got %v", s.Warnings)
+	}
+	for i := 0; i < 2; i++ {
+		s.Merge(j, ViewStats)
+	}
+	want := []Warning{{Code: WarnMixedFormats, Message: "statistics of format json/v1 merged into statistics of format xml/v3"}}
+	if !reflect.DeepEqual(s.Warnings, want) || s.Source.Format != FormatXMLv3 {
+		t.Errorf("want warnings %v of format %s, got %v of format %s", want, FormatXMLv3, s.Warnings, s.Source.Format)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bind
+
+import (
+	"math"
+	"time"
+)
+
+// RcodeWindow holds the responses sent by named by rcode during a rolling
+// window of time, see WithRcodeWindow.
+type RcodeWindow struct {
+	// Start and End delimit the time covered by the polls in the window.
+	// Covered may be shorter than End minus Start if the counters could
+	// not be compared between some polls, e.g. after a failed poll
+	// followed by a counter reset.
+	Start   time.Time
+	End     time.Time
+	Covered time.Duration
+	// Responses holds the number of responses by rcode, see
+	// NormalizeRcodeName, and Total their sum. Both saturate at
+	// math.MaxUint64, see AddCounters.
+	Responses map[string]uint64
+	Total     uint64
+	// Rates holds the responses per second by rcode.
+	Rates map[string]float64
+	// FailureRatio is the ratio of SERVFAIL and REFUSED responses to all
+	// responses, or NaN if no response has been sent.
+	FailureRatio float64
+}
+
+// WithRcodeWindow makes the Poller aggregate the responses sent by rcode, see
+// Server.ServerRcodes, over the polls of the last d, reported as the Window
+// of samples and by CurrentWindow. The responses between the last poll before
+// a restart of named and the restart are unknown and thus missing from the
+// window, whose rates are measured over the time covered by the polls.
+func WithRcodeWindow(d time.Duration) PollerOption {
+	return func(p *Poller) {
+		p.window = &rcodeRing{window: d}
+	}
+}
+
+// CurrentWindow returns the responses by rcode during the window set with
+// WithRcodeWindow up to now, and false if no window is configured. Polls
+// which fell out of the window since the last poll are no longer counted. It
+// does not block while a poll is running.
+func (p *Poller) CurrentWindow() (RcodeWindow, bool) {
+	if p.window == nil {
+		return RcodeWindow{}, false
+	}
+	p.windowMu.Lock()
+	defer p.windowMu.Unlock()
+	return p.window.current(p.now()), true
+}
+
+// addWindow adds the responses of the Delta of s to the window and returns
+// the window as of s, or nil if no window is configured.
+func (p *Poller) addWindow(s Sample) *RcodeWindow {
+	if p.window == nil {
+		return nil
+	}
+	p.windowMu.Lock()
+	defer p.windowMu.Unlock()
+	if s.Delta != nil && s.Interval > 0 {
+		p.window.add(rcodeBucket{time: s.Time, interval: s.Interval, rcodes: s.Delta.Server.ServerRcodes})
+	}
+	w := p.window.current(s.Time)
+	return &w
+}
+
+// rcodeBucket holds the increase of the rcode counters during the interval
+// ending at time.
+type rcodeBucket struct {
+	time     time.Time
+	interval time.Duration
+	rcodes   []Counter
+}
+
+// rcodeRing is a ring buffer of the buckets of a window in the order of
+// their time.
+type rcodeRing struct {
+	window  time.Duration
+	buckets []rcodeBucket
+	// head is the index of the oldest of the n buckets.
+	head, n int
+}
+
+// add appends b to r, growing the buffer if it is full.
+func (r *rcodeRing) add(b rcodeBucket) {
+	if r.n == len(r.buckets) {
+		grown := make([]rcodeBucket, 2*len(r.buckets)+1)
+		for i := 0; i < r.n; i++ {
+			grown[i] = r.buckets[(r.head+i)%len(r.buckets)]
+		}
+		r.buckets, r.head = grown, 0
+	}
+	r.buckets[(r.head+r.n)%len(r.buckets)] = b
+	r.n++
+}
+
+// evict removes the buckets which ended before the window up to now.
+func (r *rcodeRing) evict(now time.Time) {
+	start := now.Add(-r.window)
+	for r.n > 0 && !r.buckets[r.head].time.After(start) {
+		r.buckets[r.head] = rcodeBucket{}
+		r.head = (r.head + 1) % len(r.buckets)
+		r.n--
+	}
+}
+
+// current evicts the expired buckets and returns the window up to now.
+func (r *rcodeRing) current(now time.Time) RcodeWindow {
+	r.evict(now)
+	w := RcodeWindow{Responses: map[string]uint64{}, Rates: map[string]float64{}}
+	for i := 0; i < r.n; i++ {
+		b := r.buckets[(r.head+i)%len(r.buckets)]
+		if i == 0 {
+			w.Start = b.time.Add(-b.interval)
+		}
+		w.End = b.time
+		w.Covered += b.interval
+		for _, c := range b.rcodes {
+			name := NormalizeRcodeName(c.Name)
+			w.Responses[name], _ = AddCounters(w.Responses[name], c.Counter)
+			w.Total, _ = AddCounters(w.Total, c.Counter)
+		}
+	}
+	if w.Covered > 0 {
+		for rcode, n := range w.Responses {
+			w.Rates[rcode] = float64(n) / w.Covered.Seconds()
+		}
+	}
+	w.FailureRatio = math.NaN()
+	if w.Total > 0 {
+		w.FailureRatio = (float64(w.Responses[CounterSERVFAIL]) + float64(w.Responses[CounterREFUSED])) / float64(w.Total)
+	}
+	return w
+}
@@ -0,0 +1,372 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xml
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/internal/httpclient"
+)
+
+// maxDepth is the maximum nesting depth of elements accepted by the hardened
+// decoder. Documents of BIND are nested less than ten levels deep.
+const maxDepth = 64
+
+// xhtmlNamespace is the namespace of XHTML documents.
+const xhtmlNamespace = "http://www.w3.org/1999/xhtml"
+
+// errStatusPage is returned by tokenReader for an XHTML document, like the
+// status page served by some builds of BIND 9.9.
+var errStatusPage = errors.New("HTML status page")
+
+// tokenReader passes on the tokens of d while tracking the path of the
+// current element, which is reported by decodeError.
+//
+// If harden is set, it fails with bind.ErrSuspiciousDocument on directives,
+// which include DOCTYPE and ENTITY declarations, and on elements nested deeper
+// than maxDepth. encoding/xml does not expand entities other than the
+// predefined ones unless they are registered with Decoder.Entity, so entity
+// expansion bombs already fail to decode. Rejecting the declarations turns
+// them into a distinct error before any of the document is processed.
+//
+// If skip is set, elements for which it returns true are dropped from the
+// tokens without being passed on. It is called with the path of the parent of
+// the element.
+//
+// Errors of d caused by the end of a truncated document match
+// bind.ErrTruncatedResponse. If partial is set and reading d fails because the
+// deadline of the context expired or the document is truncated, the reader
+// ends the document by closing the open elements and records the error in cut.
+// cutZone is set if a zone element was open.
+//
+// An XHTML document, i.e. one declaring an html DOCTYPE or whose root element
+// is html or in the XHTML namespace, fails with errStatusPage, also if harden
+// is set.
+//
+// The reader records the size in bytes of every top-level section, i.e. of
+// every child element of a statistics element, in sections, see
+// bind.DecodeInfo.SectionBytes.
+//
+// Counters without a value, e.g. <counter name="QryDropped"></counter>, are
+// decoded as zero by encoding/xml, but counters holding only whitespace fail
+// to decode. The reader drops such whitespace, so that both decode as zero,
+// and records a warning for each of them in warnings. At most maxWarnings
+// are recorded if it is positive, further ones are counted by omitted.
+// The whitespace around the values of other counters, e.g. of pretty-printed
+// documents, is trimmed, and values which are not decimal numbers, see
+// bind.ParseCounter, fail with an error wrapping bind.ErrInvalidCounter.
+type tokenReader struct {
+	d       *xml.Decoder
+	harden  bool
+	skip    func(parent []pathElement, e xml.StartElement) bool
+	partial bool
+
+	cut         error
+	cutZone     bool
+	maxWarnings int
+	warnings    []bind.Warning
+	omitted     int
+	sections    map[string]int64
+	// sectionStart is the offset of the start of the current section.
+	sectionStart int64
+
+	path []pathElement
+	// pop is set after an EndElement has been returned. The element is only
+	// removed from path on the next token, as encoding/xml parses the
+	// character data of an element after reading its EndElement.
+	pop bool
+	// text holds the character data of the current element.
+	text []byte
+}
+
+type pathElement struct {
+	xmlName xml.Name
+	name    string
+	// id is the value of the name or type attribute identifying the element
+	// among its siblings.
+	id string
+	// label is the text of the name child element, which names the counter
+	// of e.g. <rrset> and <resstat> elements.
+	label    string
+	children bool
+}
+
+func (r *tokenReader) Token() (xml.Token, error) {
+	if r.pop {
+		r.path = r.path[:len(r.path)-1]
+		r.pop = false
+	}
+	if r.cut != nil {
+		if len(r.path) == 0 {
+			return nil, io.EOF
+		}
+		r.endSection()
+		r.pop = true
+		return xml.EndElement{Name: r.path[len(r.path)-1].xmlName}, nil
+	}
+	off := r.d.InputOffset()
+	t, err := r.next()
+	if err != nil {
+		err = r.truncated(err)
+		if r.partial && len(r.path) > 0 && (errors.Is(err, context.DeadlineExceeded) || errors.Is(err, bind.ErrTruncatedResponse)) {
+			r.cut = err
+			r.cutZone = inZone(r.path)
+			return r.Token()
+		}
+		return t, err
+	}
+	switch t := t.(type) {
+	case xml.Directive:
+		if len(r.path) == 0 && isHTMLDoctype(t) {
+			return nil, errStatusPage
+		}
+		if r.harden {
+			return nil, fmt.Errorf("%w: directive %.20q", bind.ErrSuspiciousDocument, t)
+		}
+	case xml.StartElement:
+		if len(r.path) == 0 && (strings.EqualFold(t.Name.Local, "html") || t.Name.Space == xhtmlNamespace) {
+			return nil, errStatusPage
+		}
+		if r.harden && len(r.path) >= maxDepth {
+			return nil, fmt.Errorf("%w: elements nested deeper than %d", bind.ErrSuspiciousDocument, maxDepth)
+		}
+		if n := len(r.path); n > 0 {
+			r.path[n-1].children = true
+			if r.path[n-1].name == "statistics" {
+				r.sectionStart = off
+			}
+		}
+		e := pathElement{xmlName: t.Name, name: t.Name.Local}
+		for _, a := range t.Attr {
+			if a.Name.Local == "name" || (a.Name.Local == "type" && e.id == "") {
+				e.id = a.Value
+			}
+		}
+		r.path = append(r.path, e)
+		r.text = r.text[:0]
+	case xml.CharData:
+		r.text = append(r.text, t...)
+		if _, ok := r.counter(); ok {
+			// Whitespace-only values of counters are dropped, and
+			// whitespace around values is trimmed.
+			v := bytes.TrimSpace(t)
+			if len(v) == 0 {
+				return r.Token()
+			}
+			if _, err := bind.ParseCounter(string(v)); err != nil {
+				return nil, err
+			}
+			return xml.CharData(v), nil
+		}
+	case xml.EndElement:
+		r.endElement()
+		r.endSection()
+		r.pop = true
+	}
+	return t, nil
+}
+
+// endElement inspects the character data of the element being closed, which
+// is the last one of path.
+func (r *tokenReader) endElement() {
+	n := len(r.path)
+	e := r.path[n-1]
+	if e.name == "name" && n > 1 {
+		r.path[n-2].label = strings.TrimSpace(string(r.text))
+	}
+	if e.children || len(bytes.TrimSpace(r.text)) != 0 {
+		return
+	}
+	name, ok := r.counter()
+	if !ok {
+		return
+	}
+	if r.maxWarnings > 0 && len(r.warnings) >= r.maxWarnings {
+		r.omitted++
+		return
+	}
+	r.warnings = append(r.warnings, bind.Warning{
+		Code:    bind.WarnBlankCounter,
+		Message: fmt.Sprintf("counter %q has no value, taken as zero", name),
+		Path:    r.pathString(),
+	})
+}
+
+// endSection adds the size of the element being closed, which is the last one
+// of path, to sections if it is a top-level section.
+func (r *tokenReader) endSection() {
+	n := len(r.path)
+	if n < 2 || r.path[n-2].name != "statistics" {
+		return
+	}
+	if r.sections == nil {
+		r.sections = map[string]int64{}
+	}
+	r.sections[r.path[n-1].name] += r.d.InputOffset() - r.sectionStart
+}
+
+// next returns the next token of d which is not part of a skipped element.
+func (r *tokenReader) next() (xml.Token, error) {
+	for {
+		t, err := r.d.Token()
+		if err != nil {
+			return t, err
+		}
+		if e, ok := t.(xml.StartElement); !ok || r.skip == nil || !r.skip(r.path, e) {
+			return t, nil
+		}
+		if err := r.skipElement(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// truncated wraps err into an error matching bind.ErrTruncatedResponse if the
+// document ended within an element, which encoding/xml reports as a syntax
+// error rather than io.ErrUnexpectedEOF.
+func (r *tokenReader) truncated(err error) error {
+	var serr *xml.SyntaxError
+	if off := r.d.InputOffset(); off > 0 && errors.As(err, &serr) && serr.Msg == "unexpected EOF" {
+		return fmt.Errorf("%w after %d bytes: %w", bind.ErrTruncatedResponse, off, err)
+	}
+	return httpclient.TruncatedResponse(err, r.d.InputOffset())
+}
+
+// isHTMLDoctype reports whether d is the DOCTYPE declaration of an HTML
+// document.
+func isHTMLDoctype(d xml.Directive) bool {
+	f := strings.Fields(string(d))
+	return len(f) >= 2 && strings.EqualFold(f[0], "DOCTYPE") && strings.EqualFold(f[1], "html")
+}
+
+// inZone reports whether path is inside a zone element of a zones document.
+func inZone(path []pathElement) bool {
+	for i := 1; i < len(path); i++ {
+		if path[i].name == "zone" && path[i-1].name == "zones" {
+			return true
+		}
+	}
+	return false
+}
+
+// skipElement reads the tokens up to the end of the element whose start has
+// just been read.
+func (r *tokenReader) skipElement() error {
+	for depth := 1; depth > 0; {
+		t, err := r.d.Token()
+		if err != nil {
+			return err
+		}
+		switch t.(type) {
+		case xml.Directive:
+			if r.harden {
+				return fmt.Errorf("%w: directive %.20q", bind.ErrSuspiciousDocument, t)
+			}
+		case xml.StartElement:
+			depth++
+			if r.harden && len(r.path)+depth > maxDepth {
+				return fmt.Errorf("%w: elements nested deeper than %d", bind.ErrSuspiciousDocument, maxDepth)
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+	return nil
+}
+
+// skipZone returns a skip function for tokenReader dropping the zone elements
+// of the v3 zones document which are excluded by o or refused by limit.
+func skipZone(o bind.ClientOptions, limit *httpclient.ZoneLimit) func([]pathElement, xml.StartElement) bool {
+	if o.ExcludedZones == nil && limit == nil {
+		return nil
+	}
+	return func(parent []pathElement, e xml.StartElement) bool {
+		if e.Name.Local != "zone" || len(parent) < 2 || parent[len(parent)-1].name != "zones" {
+			return false
+		}
+		var name, class string
+		for _, a := range e.Attr {
+			switch a.Name.Local {
+			case "name":
+				name = a.Value
+			case "rdataclass":
+				class = a.Value
+			}
+		}
+		// The zones of the v2 schema carry their name in a child element.
+		if name == "" {
+			return false
+		}
+		return o.ExcludesZone(name, class) || !limit.Admit(parent[len(parent)-2].id)
+	}
+}
+
+// decodeError wraps err into a bind.DecodeError describing the position of
+// the reader.
+func (r *tokenReader) decodeError(err error) error {
+	return &bind.DecodeError{
+		Path:   r.pathString(),
+		Offset: r.d.InputOffset(),
+		Text:   strings.TrimSpace(string(r.text)),
+		Err:    err,
+	}
+}
+
+// counter returns the name of the counter if the current element holds the
+// value of one. Counters are <counter> elements named by an attribute or a
+// sibling, and the children of the <counters> elements of v2 zones.
+func (r *tokenReader) counter() (string, bool) {
+	n := len(r.path)
+	if n == 0 {
+		return "", false
+	}
+	e := r.path[n-1]
+	var parent pathElement
+	if n > 1 {
+		parent = r.path[n-2]
+	}
+	switch {
+	case e.children:
+		return "", false
+	case e.name == "counter" && e.id != "":
+		return e.id, true
+	case e.name == "counter":
+		return parent.label, true
+	case parent.name == "counters" && parent.id == "":
+		return e.name, true
+	}
+	return "", false
+}
+
+// pathString formats the path of the reader like bind.DecodeError.Path.
+func (r *tokenReader) pathString() string {
+	var b strings.Builder
+	for i, e := range r.path {
+		if i > 0 {
+			b.WriteByte('>')
+		}
+		b.WriteString(e.name)
+		if e.id != "" {
+			b.WriteString("[" + e.id + "]")
+		}
+	}
+	return b.String()
+}
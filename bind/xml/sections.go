@@ -0,0 +1,67 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+)
+
+// decodeSections passes the sections of the document read from r which have
+// a decoder in decoders to their decoder, see bind.WithSectionDecoder, and
+// returns the results by type.
+func decodeSections(r io.Reader, decoders map[string]bind.XMLSectionDecoder) (map[string]any, error) {
+	var (
+		d       = xml.NewDecoder(r)
+		path    []string
+		results map[string]any
+	)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return results, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if len(path) == 1 || len(path) == 2 && path[1] == "server" {
+				typ := t.Name.Local
+				for _, a := range t.Attr {
+					if a.Name.Local == "type" {
+						typ = a.Value
+					}
+				}
+				if fn, ok := decoders[typ]; ok {
+					v, err := fn(d, t)
+					if err != nil {
+						return nil, fmt.Errorf("section %q: %w", typ, err)
+					}
+					if results == nil {
+						results = map[string]any{}
+					}
+					results[typ] = v
+					continue
+				}
+			}
+			path = append(path, t.Name.Local)
+		case xml.EndElement:
+			path = path[:len(path)-1]
+		}
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+)
+
+// knownElements maps the path of every element known to the package to its
+// known attributes.
+var knownElements = map[string][]string{
+	"statistics":                                        {"version"},
+	"statistics/server":                                 nil,
+	"statistics/server/boot-time":                       nil,
+	"statistics/server/config-time":                     nil,
+	"statistics/server/current-time":                    nil,
+	"statistics/server/version":                         nil,
+	"statistics/server/counters":                        {"type"},
+	"statistics/server/counters/counter":                {"name"},
+	"statistics/taskmgr":                                nil,
+	"statistics/taskmgr/tasks":                          nil,
+	"statistics/taskmgr/tasks/task":                     nil,
+	"statistics/taskmgr/tasks/task/events":              nil,
+	"statistics/taskmgr/tasks/task/id":                  nil,
+	"statistics/taskmgr/tasks/task/name":                nil,
+	"statistics/taskmgr/tasks/task/quantum":             nil,
+	"statistics/taskmgr/tasks/task/references":          nil,
+	"statistics/taskmgr/tasks/task/state":               nil,
+	"statistics/taskmgr/thread-model":                   nil,
+	"statistics/taskmgr/thread-model/default-quantum":   nil,
+	"statistics/taskmgr/thread-model/tasks-ready":       nil,
+	"statistics/taskmgr/thread-model/tasks-running":     nil,
+	"statistics/taskmgr/thread-model/type":              nil,
+	"statistics/taskmgr/thread-model/worker-threads":    nil,
+	"statistics/views":                                  nil,
+	"statistics/views/view":                             {"name"},
+	"statistics/views/view/cache":                       {"name"},
+	"statistics/views/view/cache/rrset":                 nil,
+	"statistics/views/view/cache/rrset/counter":         nil,
+	"statistics/views/view/cache/rrset/name":            nil,
+	"statistics/views/view/counters":                    {"type"},
+	"statistics/views/view/counters/counter":            {"name"},
+	"statistics/views/view/zones":                       nil,
+	"statistics/views/view/zones/zone":                  {"name", "rdataclass"},
+	"statistics/views/view/zones/zone/counters":         {"type"},
+	"statistics/views/view/zones/zone/counters/counter": {"name"},
+	"statistics/views/view/zones/zone/expires":          nil,
+	"statistics/views/view/zones/zone/loaded":           nil,
+	"statistics/views/view/zones/zone/refresh":          nil,
+	"statistics/views/view/zones/zone/serial":           nil,
+	"statistics/views/view/zones/zone/type":             nil,
+
+	// XML v2 documents, see v2.go.
+	"isc":                                  {"version"},
+	"isc/bind":                             nil,
+	"isc/bind/statistics":                  {"version"},
+	"isc/bind/statistics/server":           nil,
+	"isc/bind/statistics/server/boot-time": nil,
+	"isc/bind/statistics/server/current-time":                  nil,
+	"isc/bind/statistics/server/requests":                      nil,
+	"isc/bind/statistics/server/requests/opcode":               nil,
+	"isc/bind/statistics/server/requests/opcode/counter":       nil,
+	"isc/bind/statistics/server/requests/opcode/name":          nil,
+	"isc/bind/statistics/server/queries-in":                    nil,
+	"isc/bind/statistics/server/queries-in/rdtype":             nil,
+	"isc/bind/statistics/server/queries-in/rdtype/counter":     nil,
+	"isc/bind/statistics/server/queries-in/rdtype/name":        nil,
+	"isc/bind/statistics/server/nsstat":                        nil,
+	"isc/bind/statistics/server/nsstat/counter":                nil,
+	"isc/bind/statistics/server/nsstat/name":                   nil,
+	"isc/bind/statistics/server/zonestat":                      nil,
+	"isc/bind/statistics/server/zonestat/counter":              nil,
+	"isc/bind/statistics/server/zonestat/name":                 nil,
+	"isc/bind/statistics/taskmgr":                              nil,
+	"isc/bind/statistics/taskmgr/tasks":                        nil,
+	"isc/bind/statistics/taskmgr/tasks/task":                   nil,
+	"isc/bind/statistics/taskmgr/tasks/task/id":                nil,
+	"isc/bind/statistics/taskmgr/tasks/task/name":              nil,
+	"isc/bind/statistics/taskmgr/tasks/task/quantum":           nil,
+	"isc/bind/statistics/taskmgr/tasks/task/references":        nil,
+	"isc/bind/statistics/taskmgr/tasks/task/state":             nil,
+	"isc/bind/statistics/taskmgr/thread-model":                 nil,
+	"isc/bind/statistics/taskmgr/thread-model/default-quantum": nil,
+	"isc/bind/statistics/taskmgr/thread-model/tasks-running":   nil,
+	"isc/bind/statistics/taskmgr/thread-model/type":            nil,
+	"isc/bind/statistics/taskmgr/thread-model/worker-threads":  nil,
+	"isc/bind/statistics/views":                                nil,
+	"isc/bind/statistics/views/view":                           nil,
+	"isc/bind/statistics/views/view/name":                      nil,
+	"isc/bind/statistics/views/view/zones":                     nil,
+	"isc/bind/statistics/views/view/zones/zone":                nil,
+	"isc/bind/statistics/views/view/zones/zone/name":           nil,
+	"isc/bind/statistics/views/view/zones/zone/rdataclass":     nil,
+	"isc/bind/statistics/views/view/zones/zone/serial":         nil,
+}
+
+// ignoredElements are the paths of elements whose content is known but not
+// decoded by the package.
+var ignoredElements = map[string]bool{
+	"statistics/memory":    true,
+	"statistics/socketmgr": true,
+	"statistics/traffic":   true,
+
+	// The named counters of XML v2 zones are arbitrary elements. The
+	// resolver and socket statistics of the server are not decoded.
+	"isc/bind/statistics/views/view/zones/zone/counters": true,
+	"isc/bind/statistics/views/view/rdtype":              true,
+	"isc/bind/statistics/views/view/resstat":             true,
+	"isc/bind/statistics/views/view/cache":               true,
+	"isc/bind/statistics/server/resstat":                 true,
+	"isc/bind/statistics/server/sockstat":                true,
+	"isc/bind/statistics/memory":                         true,
+	"isc/bind/statistics/socketmgr":                      true,
+}
+
+// knownCounterTypes maps the path of counters elements to the known values of
+// their type attribute.
+var knownCounterTypes = map[string][]string{
+	"statistics/server/counters":                {nsstat, opcode, qtype, rcode, zonestat, "resstat", "sockstat"},
+	"statistics/views/view/counters":            {resqtype, resstats, cachestats, "adbstat"},
+	"statistics/views/view/zones/zone/counters": {dnssecRefresh, dnssecSign, zonestat, rcode, qtype},
+}
+
+// checkStrict returns an error wrapping bind.ErrUnknownItem which lists every
+// element, attribute and counter type of the document read from r which is
+// unknown to the package.
+func checkStrict(r io.Reader) error {
+	var (
+		d       = xml.NewDecoder(r)
+		path    []string
+		unknown []string
+	)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			path = append(path, t.Name.Local)
+			p := strings.Join(path, "/")
+			if ignoredElements[p] {
+				if err := d.Skip(); err != nil {
+					return err
+				}
+				path = path[:len(path)-1]
+				continue
+			}
+			attrs, ok := knownElements[p]
+			if !ok {
+				unknown = append(unknown, fmt.Sprintf("element /%s", p))
+				if err := d.Skip(); err != nil {
+					return err
+				}
+				path = path[:len(path)-1]
+				continue
+			}
+			for _, a := range t.Attr {
+				if !contains(attrs, a.Name.Local) {
+					unknown = append(unknown, fmt.Sprintf("attribute %q of /%s", a.Name.Local, p))
+				}
+				if types, ok := knownCounterTypes[p]; ok && a.Name.Local == "type" && !contains(types, a.Value) {
+					unknown = append(unknown, fmt.Sprintf("counter type %q at /%s", a.Value, p))
+				}
+			}
+		case xml.EndElement:
+			path = path[:len(path)-1]
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("%w: %s", bind.ErrUnknownItem, strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xml
+
+import (
+	"encoding/xml"
+	"strings"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+)
+
+// The document of the XML v2 schema used up to BIND 9.9 is served at V2Path,
+// and BIND 9.9 serves none of the v3 documents. If the server document is not
+// found, the client fetches the v2 document instead, see Client.Stats, and
+// takes the server, view, zone, task manager and memory statistics from it.
+// The v2 document can also be decoded as ZoneStatistics, which allows to
+// point the zones endpoint of the client at it with bind.WithEndpointOverride.
+
+type v2Statistics struct {
+	Version string           `xml:"version,attr"`
+	Views   []v2View         `xml:"views>view"`
+	Server  v2Server         `xml:"server"`
+	Taskmgr bind.TaskManager `xml:"taskmgr"`
+	Memory  Memory           `xml:"memory"`
+}
+
+// v2Server is the server section of the v2 document, whose counters are
+// grouped by elements named after their type.
+type v2Server struct {
+	BootTime    time.Time `xml:"boot-time"`
+	CurrentTime time.Time `xml:"current-time"`
+	Requests    []Counter `xml:"requests>opcode"`
+	QueriesIn   []Counter `xml:"queries-in>rdtype"`
+	NSStats     []Counter `xml:"nsstat"`
+	ZoneStats   []Counter `xml:"zonestat"`
+}
+
+type v2View struct {
+	Name     string    `xml:"name"`
+	Zones    []v2Zone  `xml:"zones>zone"`
+	Rdtypes  []Counter `xml:"rdtype"`
+	ResStats []Counter `xml:"resstat"`
+}
+
+type v2Zone struct {
+	// Name carries the class of the zone, e.g. "example.com/IN".
+	Name       string `xml:"name"`
+	Rdataclass string `xml:"rdataclass"`
+	Serial     string `xml:"serial"`
+	// Counters are named child elements, e.g. <QrySuccess>1</QrySuccess>.
+	Counters struct {
+		Counters []struct {
+			XMLName xml.Name
+			Value   uint64 `xml:",chardata"`
+		} `xml:",any"`
+	} `xml:"counters"`
+}
+
+// queryResults are the names of the zone counters which the v3 schema reports
+// as rcode counters.
+var queryResults = map[string]bool{
+	bind.CounterQrySuccess:   true,
+	bind.CounterQryAuthAns:   true,
+	bind.CounterQryNoauthAns: true,
+	bind.CounterQryReferral:  true,
+	bind.CounterQryNxrrset:   true,
+	bind.CounterQrySERVFAIL:  true,
+	bind.CounterQryFORMERR:   true,
+	bind.CounterQryNXDOMAIN:  true,
+	bind.CounterQryRecursion: true,
+	bind.CounterQryDuplicate: true,
+	bind.CounterQryDropped:   true,
+	bind.CounterQryFailure:   true,
+}
+
+// UnmarshalXML implements xml.Unmarshaler. It decodes both the v3 zones
+// document and the v2 document, whose zones are converted to the v3 schema.
+func (z *ZoneStatistics) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	if start.Name.Local != "isc" {
+		type plain ZoneStatistics
+		return d.DecodeElement((*plain)(z), &start)
+	}
+	var doc struct {
+		Statistics v2Statistics `xml:"bind>statistics"`
+	}
+	if err := d.DecodeElement(&doc, &start); err != nil {
+		return err
+	}
+	*z = doc.Statistics.zoneStatistics()
+	return nil
+}
+
+func (s v2Statistics) zoneStatistics() ZoneStatistics {
+	zs := ZoneStatistics{Version: s.Version}
+	for _, view := range s.Views {
+		v := ZoneView{Name: view.Name}
+		for _, zone := range view.Zones {
+			v.Zones = append(v.Zones, zone.zoneCounter())
+		}
+		zs.ZoneViews = append(zs.ZoneViews, v)
+		if len(view.Rdtypes) > 0 || len(view.ResStats) > 0 {
+			zs.Views = append(zs.Views, view.view())
+		}
+	}
+	zs.Statistics = &Statistics{
+		Version: s.Version,
+		Server:  s.Server.server(),
+		Taskmgr: s.Taskmgr,
+		Memory:  s.Memory,
+	}
+	return zs
+}
+
+// server converts the server section to the v3 schema.
+func (server v2Server) server() Server {
+	v := Server{BootTime: server.BootTime, CurrentTime: server.CurrentTime}
+	for _, c := range []struct {
+		typ      string
+		counters []Counter
+	}{{opcode, server.Requests}, {qtype, server.QueriesIn}, {nsstat, server.NSStats}, {zonestat, server.ZoneStats}} {
+		if len(c.counters) > 0 {
+			v.Counters = append(v.Counters, v3Counters(c.typ, c.counters))
+		}
+	}
+	return v
+}
+
+// view converts the resolver statistics of view to the v3 schema.
+func (view v2View) view() View {
+	v := View{Name: view.Name}
+	for _, c := range []struct {
+		typ      string
+		counters []Counter
+	}{{resqtype, view.Rdtypes}, {resstats, view.ResStats}} {
+		if len(c.counters) > 0 {
+			v.Counters = append(v.Counters, v3Counters(c.typ, c.counters))
+		}
+	}
+	return v
+}
+
+// v3Counters returns the v2 counters cs as v3 counters of type typ.
+func v3Counters(typ string, cs []Counter) Counters {
+	v := Counters{Type: typ}
+	for _, c := range cs {
+		v.Counters = append(v.Counters, bind.Counter{Name: c.Name, Counter: c.Counter})
+	}
+	return v
+}
+
+func (zone v2Zone) zoneCounter() ZoneCounter {
+	z := ZoneCounter{
+		Name:       strings.TrimSuffix(zone.Name, "/"+zone.Rdataclass),
+		Rdataclass: zone.Rdataclass,
+		Serial:     zone.Serial,
+	}
+	results := Counters{Type: rcode}
+	other := Counters{Type: nsstat}
+	for _, c := range zone.Counters.Counters {
+		counter := bind.Counter{Name: bind.NormalizeCounterName(c.XMLName.Local), Counter: c.Value}
+		if queryResults[counter.Name] {
+			results.Counters = append(results.Counters, counter)
+		} else {
+			other.Counters = append(other.Counters, counter)
+		}
+	}
+	for _, c := range []Counters{results, other} {
+		if len(c.Counters) > 0 {
+			z.Counters = append(z.Counters, c)
+		}
+	}
+	return z
+}
@@ -14,14 +14,17 @@
 package xml
 
 import (
+	"bytes"
+	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
-	"net/url"
-	"path"
 	"time"
 
 	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/internal/httpclient"
 )
 
 const (
@@ -33,30 +36,74 @@ const (
 	TasksPath = "/xml/v3/tasks"
 	// ZonesPath is the HTTP path of the v3 zones resource.
 	ZonesPath = "/xml/v3/zones"
+	// V2Path is the HTTP path of the document of the v2 schema served by
+	// BIND 9.9 and earlier, which holds all statistics.
+	V2Path = "/"
 
-	nsstat   = "nsstat"
-	opcode   = "opcode"
-	qtype    = "qtype"
-	resqtype = "resqtype"
-	resstats = "resstats"
-	zonestat = "zonestat"
-	rcode    = "rcode"
+	cachestats    = "cachestats"
+	dnssecRefresh = "dnssec-refresh"
+	dnssecSign    = "dnssec-sign"
+	nsstat        = "nsstat"
+	opcode        = "opcode"
+	qtype         = "qtype"
+	resqtype      = "resqtype"
+	resstats      = "resstats"
+	zonestat      = "zonestat"
+	rcode         = "rcode"
 )
 
 type Statistics struct {
+	Version string           `xml:"version,attr"`
 	Server  Server           `xml:"server"`
 	Taskmgr bind.TaskManager `xml:"taskmgr"`
 	Views   []View           `xml:"views>view"`
+	Memory  Memory           `xml:"memory"`
+	// Extensions holds the decoded sections, see bind.WithSectionDecoder.
+	Extensions map[string]any `xml:"-"`
+	// Warnings lists the counters without value, see bind.WarnBlankCounter.
+	// OmittedWarnings counts those beyond bind.WithMaxWarnings.
+	Warnings        []bind.Warning `xml:"-"`
+	OmittedWarnings int            `xml:"-"`
 }
 
 type ZoneStatistics struct {
+	Version   string     `xml:"version,attr"`
 	ZoneViews []ZoneView `xml:"views>view"`
+	// Views holds the resolver statistics of the views of a v2 document.
+	Views []View `xml:"-"`
+	// Statistics holds the server, task manager and memory statistics of a
+	// v2 document, and is nil for v3 documents.
+	Statistics *Statistics `xml:"-"`
+	// Extensions holds the decoded sections, see bind.WithSectionDecoder.
+	Extensions map[string]any `xml:"-"`
+	// Warnings lists the counters without value, see bind.WarnBlankCounter.
+	// OmittedWarnings counts those beyond bind.WithMaxWarnings.
+	Warnings        []bind.Warning `xml:"-"`
+	OmittedWarnings int            `xml:"-"`
 }
 
 type Server struct {
-	BootTime   time.Time  `xml:"boot-time"`
-	ConfigTime time.Time  `xml:"config-time"`
-	Counters   []Counters `xml:"counters"`
+	BootTime    time.Time  `xml:"boot-time"`
+	ConfigTime  time.Time  `xml:"config-time"`
+	CurrentTime time.Time  `xml:"current-time"`
+	Version     string     `xml:"version"`
+	Counters    []Counters `xml:"counters"`
+}
+
+// Memory is the memory section of the server document. The elements of the
+// summary are named like the fields of bind.MemorySummary.
+type Memory struct {
+	Contexts []MemoryContext    `xml:"contexts>context"`
+	Summary  bind.MemorySummary `xml:"summary"`
+}
+
+type MemoryContext struct {
+	ID         string `xml:"id"`
+	Name       string `xml:"name"`
+	References uint64 `xml:"references"`
+	Total      uint64 `xml:"total"`
+	InUse      uint64 `xml:"inuse"`
+	MaxInUse   uint64 `xml:"maxinuse"`
 }
 
 type View struct {
@@ -81,69 +128,244 @@ type Counter struct {
 }
 
 type ZoneCounter struct {
-	Name       string `xml:"name,attr"`
-	Rdataclass string `xml:"rdataclass,attr"`
-	Serial     string `xml:"serial"`
+	Name       string     `xml:"name,attr"`
+	Rdataclass string     `xml:"rdataclass,attr"`
+	Serial     string     `xml:"serial"`
+	Counters   []Counters `xml:"counters"`
 }
 
 // Client implements bind.Client and can be used to query a BIND XML v3 API.
+// A Client is safe for concurrent use by multiple goroutines.
 type Client struct {
-	url  string
-	http *http.Client
+	client *httpclient.Client
+	err    error
 }
 
-// NewClient returns an initialized Client.
-func NewClient(url string, c *http.Client) *Client {
-	return &Client{
-		url:  url,
-		http: c,
+// New returns a Client querying the statistics channel at url, which must be
+// an absolute http or https URL.
+func New(url string, opts ...bind.ClientOption) (*Client, error) {
+	c, err := httpclient.New(url, opts...)
+	if err != nil {
+		return nil, err
 	}
+	return &Client{client: c}, nil
+}
+
+// NewClient returns an initialized Client. If c is nil, an http.Client based
+// on bind.DefaultHTTPClient is used. Contrary to New, invalid options are
+// reported by Stats and Get.
+func NewClient(url string, c *http.Client, opts ...bind.ClientOption) *Client {
+	if c != nil {
+		opts = append([]bind.ClientOption{bind.WithHTTPClient(c)}, opts...)
+	}
+	client, err := New(url, opts...)
+	if err != nil {
+		return &Client{err: err}
+	}
+	return client
 }
 
 // Get queries the given path and stores the result in the value pointed to by
 // v. The endpoint must return a valid XML representation which can be
 // unmarshaled into the provided value.
-func (c *Client) Get(p string, v interface{}) error {
-	u, err := url.Parse(c.url)
-	if err != nil {
-		return fmt.Errorf("invalid URL %q: %s", c.url, err)
+func (c *Client) Get(ctx context.Context, p string, v interface{}) error {
+	if c.err != nil {
+		return c.err
 	}
-	u.Path = path.Join(u.Path, p)
+	_, err := c.client.Get(ctx, "", p, c.decoder(v))
+	return err
+}
 
-	resp, err := c.http.Get(u.String())
-	if err != nil {
-		return fmt.Errorf("error querying stats: %s", err)
+// get queries the document of group g, which is expected at the default path p
+// unless overridden by the client options.
+func (c *Client) get(ctx context.Context, g bind.StatisticGroup, p string, v interface{}) (bind.RequestInfo, error) {
+	return c.getPath(ctx, g, c.client.Options.Endpoint(g, p), v)
+}
+
+// getPath queries the document of group g at path p.
+func (c *Client) getPath(ctx context.Context, g bind.StatisticGroup, p string, v interface{}) (bind.RequestInfo, error) {
+	if c.client.Options.Coalesce {
+		return c.client.GetShared(ctx, g, p, v, c.decoder)
 	}
-	defer resp.Body.Close()
+	return c.client.Get(ctx, g, p, c.decoder(v))
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status %s", resp.Status)
+func (c *Client) decoder(v interface{}) httpclient.DecodeFunc {
+	called := false
+	return func(r io.Reader) (bind.DecodeInfo, error) {
+		if called {
+			// The response of a retry replaces the truncated one.
+			httpclient.Reset(v)
+		}
+		called = true
+		r = httpclient.SkipPreamble(r)
+		o := c.client.Options
+		if o.StrictDecoding || o.XMLSectionDecoders != nil {
+			b, err := io.ReadAll(r)
+			if err != nil {
+				return bind.DecodeInfo{}, fmt.Errorf("failed to read XML response: %w", err)
+			}
+			if o.StrictDecoding {
+				if err := checkStrict(bytes.NewReader(b)); err != nil {
+					return bind.DecodeInfo{}, fmt.Errorf("failed to unmarshal XML response: %w", err)
+				}
+			}
+			if o.XMLSectionDecoders != nil {
+				ext, err := decodeSections(bytes.NewReader(b), o.XMLSectionDecoders)
+				if err != nil {
+					return bind.DecodeInfo{}, fmt.Errorf("failed to decode XML section: %w", err)
+				}
+				switch v := v.(type) {
+				case *Statistics:
+					v.Extensions = ext
+				case *ZoneStatistics:
+					v.Extensions = ext
+				}
+			}
+			r = bytes.NewReader(b)
+		}
+		tr := &tokenReader{
+			d:           xml.NewDecoder(r),
+			harden:      !c.client.Options.DisableXMLHardening,
+			maxWarnings: c.client.Options.MaxWarnings,
+		}
+		zs, zones := v.(*ZoneStatistics)
+		var limit *httpclient.ZoneLimit
+		if zones {
+			limit = httpclient.NewZoneLimit(c.client.Options)
+			tr.skip = skipZone(c.client.Options, limit)
+			tr.partial = c.client.Options.PartialZones
+		}
+		if err := xml.NewTokenDecoder(tr).Decode(v); err != nil {
+			if errors.Is(err, errStatusPage) {
+				return bind.DecodeInfo{}, c.statusPageError()
+			}
+			return bind.DecodeInfo{}, fmt.Errorf("failed to unmarshal XML response: %w", tr.decodeError(err))
+		}
+		switch v := v.(type) {
+		case *Statistics:
+			v.Warnings, v.OmittedWarnings = tr.warnings, tr.omitted
+		case *ZoneStatistics:
+			v.Warnings, v.OmittedWarnings = tr.warnings, tr.omitted
+		}
+		if !zones {
+			info := decodeInfo(v)
+			info.SectionBytes = tr.sections
+			return info, nil
+		}
+		// The zone read when the document was cut is incomplete.
+		if n := len(zs.ZoneViews); tr.cutZone && n > 0 && len(zs.ZoneViews[n-1].Zones) > 0 {
+			last := &zs.ZoneViews[n-1]
+			last.Zones = last.Zones[:len(last.Zones)-1]
+		}
+		info := decodeInfo(v)
+		info.SectionBytes = tr.sections
+		return info, limit.Truncated(info.Zones, tr.cut)
 	}
+}
+
+// statusPageError returns the error of an HTML page served instead of a
+// document, which lists the paths queried by c.
+func (c *Client) statusPageError() error {
+	o := c.client.Options
+	return &bind.StatusPageError{Paths: map[bind.Format][]string{
+		bind.FormatXMLv3: {
+			o.Endpoint(bind.ServerStats, ServerPath),
+			o.Endpoint(bind.ViewStats, ZonesPath),
+			o.Endpoint(bind.TaskStats, TasksPath),
+			o.Endpoint(bind.StatusStats, StatusPath),
+		},
+	}}
+}
 
-	if err := xml.NewDecoder(resp.Body).Decode(v); err != nil {
-		return fmt.Errorf("failed to unmarshal XML response: %s", err)
+// Stats implements bind.Stats.
+func (c *Client) Stats(ctx context.Context, groups ...bind.StatisticGroup) (bind.Statistics, error) {
+	if c.err != nil {
+		return bind.Statistics{}, c.err
 	}
+	return c.stats(ctx, groups)
+}
 
+func (c *Client) stats(ctx context.Context, groups []bind.StatisticGroup) (bind.Statistics, error) {
+	var rec httpclient.DecodeRecorder
+	s, err := c.fetch(rec.Context(ctx), groups)
+	if c.client.Options.Coalesce {
+		// The decoded documents are shared with concurrent calls.
+		s = s.Clone()
+	}
+	var truncated *bind.TruncatedError
+	if err == nil || errors.As(err, &truncated) {
+		// Truncated zones are returned with the options applied like
+		// complete ones.
+		if perr := c.postprocess(&s, groups); perr != nil {
+			err = perr
+		}
+	}
+	s.Decode = rec.Result()
+	if err == nil {
+		err = c.client.Options.CheckWarnings(s.Warnings)
+	}
+	return s, err
+}
+
+// postprocess applies the options adjusting the counters of s fetched for
+// groups.
+func (c *Client) postprocess(s *bind.Statistics, groups []bind.StatisticGroup) error {
+	if c.client.Options.ZeroFill {
+		for _, g := range groups {
+			if g == bind.ServerStats || g == bind.ViewStats {
+				if err := bind.FillZeroCounters(s); err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+	if keep := c.client.Options.LongTailKeep; keep != nil {
+		bind.AggregateLongTail(s, keep)
+	}
 	return nil
 }
 
-// Stats implements bind.Stats.
-func (c *Client) Stats(groups ...bind.StatisticGroup) (bind.Statistics, error) {
+func (c *Client) fetch(ctx context.Context, groups []bind.StatisticGroup) (bind.Statistics, error) {
 	s := bind.Statistics{}
 	m := map[bind.StatisticGroup]bool{}
 	for _, g := range groups {
 		m[g] = true
 	}
 
+	s.Source.Format = bind.FormatXMLv3
+	if m[bind.StatusStats] && !m[bind.ServerStats] && !m[bind.ViewStats] {
+		// The server document covers the status document.
+		if err := c.fetchStatus(ctx, &s); err != nil {
+			return s, err
+		}
+		if !m[bind.TaskStats] {
+			return s, nil
+		}
+	}
 	var stats Statistics
 	var zonestats ZoneStatistics
+	var v2 *bind.RequestInfo
 	if m[bind.ServerStats] || m[bind.ViewStats] {
-		if err := c.Get(ServerPath, &stats); err != nil {
-			return s, err
+		info, err := c.get(ctx, bind.ServerStats, ServerPath, &stats)
+		if err != nil {
+			if v2 = c.getV2(ctx, err, &zonestats); v2 == nil {
+				return s, err
+			}
+			s.Source.Format = bind.FormatXMLv2
+			stats, info = *zonestats.Statistics, *v2
 		}
 
+		s.Source.SchemaVersion = stats.Version
+		s.Source.BINDVersion = stats.Server.Version
+		s.Source.FetchTime = info.Received
+
 		s.Server.BootTime = stats.Server.BootTime
 		s.Server.ConfigTime = stats.Server.ConfigTime
+		s.Server.CurrentTime = stats.Server.CurrentTime
+		s.ClockSkew = httpclient.ClockSkew(stats.Server.CurrentTime, info)
+		s.AddExtensions(stats.Extensions)
 		for _, c := range stats.Server.Counters {
 			switch c.Type {
 			case opcode:
@@ -153,56 +375,362 @@ func (c *Client) Stats(groups ...bind.StatisticGroup) (bind.Statistics, error) {
 			case nsstat:
 				s.Server.NameServerStats = c.Counters
 			case zonestat:
+				s.Server.ZoneMaintenance = c.Counters
 				s.Server.ZoneStatistics = c.Counters
 			case rcode:
-				s.Server.ServerRcodes = c.Counters
+				s.Server.ServerRcodes = normalizeRcodes(c.Counters)
+			default:
+				addExtra(&s.Server.Extra, "statistics/server/counters", c)
 			}
 		}
 
 		for _, view := range stats.Views {
-			v := bind.View{
-				Name:  view.Name,
-				Cache: view.Cache,
-			}
-			for _, c := range view.Counters {
-				switch c.Type {
-				case resqtype:
-					v.ResolverQueries = c.Counters
-				case resstats:
-					v.ResolverStats = c.Counters
-				}
-			}
+			v, ws := convertView(view)
+			s.AddWarnings(c.client.Options.MaxWarnings, ws...)
 			s.Views = append(s.Views, v)
 		}
+		s.Memory.Summary = stats.Memory.Summary
+		for _, c := range stats.Memory.Contexts {
+			s.Memory.Contexts = append(s.Memory.Contexts, bind.MemoryContext(c))
+		}
 	}
 
-	if err := c.Get(ZonesPath, &zonestats); err != nil {
+	var (
+		info     bind.RequestInfo
+		strategy bind.ZonesStrategy
+		err      error
+	)
+	if v2 != nil {
+		info = *v2
+	} else {
+		info, strategy, err = c.getZones(ctx, &zonestats)
+	}
+	var truncated *bind.TruncatedError
+	if err != nil && !errors.As(err, &truncated) {
 		return s, err
 	}
+	if s.Source.SchemaVersion == "" {
+		s.Source.SchemaVersion = zonestats.Version
+	}
+	s.Source.ZonesStrategy = strategy
+	s.AddExtensions(zonestats.Extensions)
+	if s.Source.FetchTime.IsZero() {
+		s.Source.FetchTime = info.Received
+	}
 
 	for _, view := range zonestats.ZoneViews {
+		if !c.client.Options.IncludesView(view.Name) {
+			continue
+		}
 		v := bind.ZoneView{
 			Name: view.Name,
 		}
 		for _, zone := range view.Zones {
-			if zone.Rdataclass != "IN" {
+			if c.client.Options.ExcludesZone(zone.Name, zone.Rdataclass) {
 				continue
 			}
-			z := bind.ZoneCounter{
-				Name:   zone.Name,
-				Serial: zone.Serial,
+			for _, cs := range zone.Counters {
+				if cs.Type == qtype {
+					v.QueriesByClass.Add(zone.Rdataclass, cs.Counters)
+				}
+			}
+			if zone.Rdataclass == bind.ClassIN {
+				v.ZoneData = append(v.ZoneData, convertZone(zone))
 			}
-			v.ZoneData = append(v.ZoneData, z)
 		}
 		s.ZoneViews = append(s.ZoneViews, v)
 	}
-
-	if m[bind.TaskStats] {
-		if err := c.Get(TasksPath, &stats); err != nil {
-			return s, err
+	if len(s.Views) == 0 {
+		for _, view := range zonestats.Views {
+			v, ws := convertView(view)
+			s.AddWarnings(c.client.Options.MaxWarnings, ws...)
+			s.Views = append(s.Views, v)
 		}
+	}
+	max := c.client.Options.MaxWarnings
+	s.AddWarnings(max, bind.Validate(s)...)
+	s.AddWarnings(max, stats.Warnings...)
+	s.AddWarnings(max, zonestats.Warnings...)
+	s.OmittedWarnings += stats.OmittedWarnings + zonestats.OmittedWarnings
+	if m[bind.TaskStats] && v2 != nil {
 		s.TaskManager = stats.Taskmgr
+	} else if m[bind.TaskStats] {
+		var tasks Statistics
+		if _, terr := c.get(ctx, bind.TaskStats, TasksPath, &tasks); terr == nil {
+			s.TaskManager = tasks.Taskmgr
+			s.AddExtensions(tasks.Extensions)
+			s.AddWarnings(c.client.Options.MaxWarnings, tasks.Warnings...)
+			s.OmittedWarnings += tasks.OmittedWarnings
+		} else if !c.missing(ctx, bind.TaskStats, terr, &s) {
+			if truncated == nil {
+				return s, terr
+			}
+			// The truncated zones are returned along with the error of
+			// the tasks.
+			err = errors.Join(err, terr)
+		}
 	}
 
+	if truncated != nil {
+		return s, err
+	}
 	return s, nil
 }
+
+// getZones fetches the zones document into zs, or the zones documents of the
+// views selected by bind.WithViews.
+func (c *Client) getZones(ctx context.Context, zs *ZoneStatistics) (bind.RequestInfo, bind.ZonesStrategy, error) {
+	full := c.client.Options.Endpoint(bind.ViewStats, ZonesPath)
+	return c.client.GetZones(full, func(p string) (bind.RequestInfo, error) {
+		if p == full {
+			*zs = ZoneStatistics{}
+			return c.getPath(ctx, bind.ViewStats, p, zs)
+		}
+		var view ZoneStatistics
+		info, err := c.getPath(ctx, bind.ViewStats, p, &view)
+		zs.merge(view)
+		return info, err
+	})
+}
+
+// getV2 fetches the v2 document into zs if err reports that the server
+// document is not found, as on BIND 9.9, and returns the information of the
+// request. It returns nil if the v2 document cannot be fetched either.
+func (c *Client) getV2(ctx context.Context, err error, zs *ZoneStatistics) *bind.RequestInfo {
+	if !httpclient.IsNotFound(err) {
+		return nil
+	}
+	info, err := c.getPath(ctx, bind.ServerStats, V2Path, zs)
+	if err != nil || zs.Statistics == nil {
+		return nil
+	}
+	return &info
+}
+
+// merge adds the zones document o of a single view to zs.
+func (zs *ZoneStatistics) merge(o ZoneStatistics) {
+	if zs.Version == "" {
+		zs.Version = o.Version
+	}
+	zs.ZoneViews = append(zs.ZoneViews, o.ZoneViews...)
+	zs.Views = append(zs.Views, o.Views...)
+	for k, v := range o.Extensions {
+		if zs.Extensions == nil {
+			zs.Extensions = map[string]any{}
+		}
+		zs.Extensions[k] = v
+	}
+	zs.Warnings = append(zs.Warnings, o.Warnings...)
+	zs.OmittedWarnings += o.OmittedWarnings
+}
+
+// fetchStatus fetches the status document into s.
+func (c *Client) fetchStatus(ctx context.Context, s *bind.Statistics) error {
+	var status Statistics
+	info, err := c.get(ctx, bind.StatusStats, StatusPath, &status)
+	if err != nil {
+		return err
+	}
+	s.Source.SchemaVersion = status.Version
+	s.Source.BINDVersion = status.Server.Version
+	s.Source.FetchTime = info.Received
+	s.Server.BootTime = status.Server.BootTime
+	s.Server.ConfigTime = status.Server.ConfigTime
+	s.Server.CurrentTime = status.Server.CurrentTime
+	s.ClockSkew = httpclient.ClockSkew(status.Server.CurrentTime, info)
+	return nil
+}
+
+// normalizeRcodes names numbered rcode counters consistently, see
+// bind.NormalizeRcodeName.
+func normalizeRcodes(cs []bind.Counter) []bind.Counter {
+	n := make([]bind.Counter, len(cs))
+	for i, c := range cs {
+		c.Name = bind.NormalizeRcodeName(c.Name)
+		n[i] = c
+	}
+	return n
+}
+
+// missing reports whether err has been caused by a missing document of group
+// g which is optional on the server version, and records g as missing in s.
+// The version is taken from the status document unless already known.
+func (c *Client) missing(ctx context.Context, g bind.StatisticGroup, err error, s *bind.Statistics) bool {
+	if !httpclient.IsNotFound(err) {
+		return false
+	}
+	if s.Source.BINDVersion == "" {
+		var status Statistics
+		if _, err := c.client.Get(ctx, "", c.client.Options.Endpoint(bind.StatusStats, StatusPath), c.decoder(&status)); err != nil {
+			return false
+		}
+		s.Source.BINDVersion = status.Server.Version
+	}
+	if !bind.GroupOptional(g, s.Source.BINDVersion) {
+		return false
+	}
+	s.MissingGroups = append(s.MissingGroups, g)
+	return true
+}
+
+// GetView returns the statistics of a single view. BIND has no resource for a
+// single view, so the view is taken from the server document.
+func (c *Client) GetView(ctx context.Context, view string) (bind.View, error) {
+	if c.err != nil {
+		return bind.View{}, c.err
+	}
+	var stats Statistics
+	if _, err := c.get(ctx, bind.ServerStats, ServerPath, &stats); err != nil {
+		return bind.View{}, err
+	}
+	for _, v := range stats.Views {
+		if v.Name == view {
+			bv, _ := convertView(v)
+			return bv, nil
+		}
+	}
+	return bind.View{}, fmt.Errorf("%w: %q", bind.ErrViewNotFound, view)
+}
+
+// GetZone returns the statistics of a single zone of class IN in view, which
+// are fetched from the zone's own resource below ZonesPath. The zone may be
+// named with or without trailing dot. The root zone has no resource of its
+// own, its path being that of the view, so it is looked up in the complete
+// zones document.
+func (c *Client) GetZone(ctx context.Context, view, zone string) (bind.ZoneCounter, error) {
+	if c.err != nil {
+		return bind.ZoneCounter{}, c.err
+	}
+	p := c.client.Options.Endpoint(bind.ViewStats, ZonesPath)
+	if name := bind.CanonicalZoneName(zone); name != "." {
+		var err error
+		if p, err = httpclient.JoinPath(p, view, name); err != nil {
+			return bind.ZoneCounter{}, err
+		}
+	}
+	var zonestats ZoneStatistics
+	if _, err := c.client.Get(ctx, bind.ViewStats, p, c.decoder(&zonestats)); err != nil {
+		if httpclient.IsNotFound(err) {
+			return bind.ZoneCounter{}, fmt.Errorf("%w: %q in view %q", bind.ErrZoneNotFound, zone, view)
+		}
+		return bind.ZoneCounter{}, err
+	}
+	for _, v := range zonestats.ZoneViews {
+		if v.Name != view {
+			continue
+		}
+		for _, z := range v.Zones {
+			if bind.ZoneKey(z.Name) == bind.ZoneKey(zone) && z.Rdataclass == "IN" {
+				return convertZone(z), nil
+			}
+		}
+	}
+	return bind.ZoneCounter{}, fmt.Errorf("%w: %q in view %q", bind.ErrZoneNotFound, zone, view)
+}
+
+// convertView converts view. Resolver statistics which do not describe a
+// histogram of query round-trip times are reported as warnings and leave the
+// QueryRTT of the view empty.
+func convertView(view View) (bind.View, []bind.Warning) {
+	v := bind.View{
+		Name:  view.Name,
+		Cache: view.Cache,
+	}
+	for _, c := range view.Counters {
+		switch c.Type {
+		case resqtype:
+			v.ResolverQueries = c.Counters
+		case resstats:
+			for _, n := range c.Counters {
+				if bind.ResolverGaugeStat(n.Name) {
+					v.ResolverGauges = append(v.ResolverGauges, bind.Gauge{Name: n.Name, Gauge: n.Counter})
+				} else {
+					v.ResolverStats = append(v.ResolverStats, n)
+				}
+			}
+		case cachestats:
+			for _, g := range c.Counters {
+				switch {
+				case bind.CacheMemoryStat(g.Name):
+					v.CacheMemory = append(v.CacheMemory, bind.Gauge{Name: g.Name, Gauge: g.Counter})
+				case bind.CacheCounterStat(g.Name):
+					v.CacheStats = append(v.CacheStats, g)
+				}
+			}
+		default:
+			addExtra(&v.Extra, "statistics/views/view/counters", c)
+		}
+	}
+	var err error
+	if v.QueryRTT, err = bind.QueryRTTHistogram(v.ResolverStats); err != nil {
+		return v, []bind.Warning{{
+			Code:    bind.WarnInvalidHistogram,
+			Message: fmt.Sprintf("invalid resolver statistics of view %q: %s", v.Name, err),
+			Path:    fmt.Sprintf("statistics>views>view[%s]>counters[%s]", v.Name, resstats),
+		}}
+	}
+	return v, nil
+}
+
+func convertZone(zone ZoneCounter) bind.ZoneCounter {
+	z := bind.ZoneCounter{Serial: zone.Serial}
+	z.SetName(zone.Name)
+	for _, c := range zone.Counters {
+		switch c.Type {
+		case dnssecSign:
+			z.DNSSECSignStats = c.Counters
+		case dnssecRefresh:
+			z.DNSSECRefreshStats = c.Counters
+		case zonestat:
+			z.ZoneStats = c.Counters
+		case rcode:
+			z.QueryResults = c.Counters
+		case qtype:
+			z.IncomingQueries = c.Counters
+		case nsstat:
+			z.NameServerStats = c.Counters
+		default:
+			addExtra(&z.Extra, "statistics/views/view/zones/zone/counters", c)
+		}
+	}
+	return z
+}
+
+// addExtra adds the counters of c to e unless their type is known to the
+// package at path, see knownCounterTypes.
+func addExtra(e *bind.Extra, path string, c Counters) {
+	if c.Type != "" && !contains(knownCounterTypes[path], c.Type) {
+		e.Add(c.Type, c.Counters)
+	}
+}
+
+func decodeInfo(v interface{}) bind.DecodeInfo {
+	info := bind.DecodeInfo{}
+	counters := func(cs []Counters) {
+		for _, c := range cs {
+			info.Counters += len(c.Counters)
+		}
+	}
+	switch v := v.(type) {
+	case *Statistics:
+		counters(v.Server.Counters)
+		info.Views = len(v.Views)
+		for _, view := range v.Views {
+			info.Counters += len(view.Cache)
+			counters(view.Counters)
+		}
+		info.Tasks = len(v.Taskmgr.Tasks)
+	case *ZoneStatistics:
+		info.Views = len(v.ZoneViews)
+		for _, view := range v.ZoneViews {
+			info.Zones += len(view.Zones)
+			for _, zone := range view.Zones {
+				counters(zone.Counters)
+			}
+		}
+		for _, view := range v.Views {
+			counters(view.Counters)
+		}
+	}
+	return info
+}
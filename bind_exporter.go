@@ -14,20 +14,23 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math"
 	"net/http"
 	_ "net/http/pprof"
 	"os"
-	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/auto"
 	"github.com/prometheus-community/bind_exporter/bind/json"
 	"github.com/prometheus-community/bind_exporter/bind/xml"
 	"github.com/prometheus/client_golang/prometheus"
@@ -103,48 +106,70 @@ var (
 		[]string{"view", "result"}, nil,
 	)
 	resolverMetricStats = map[string]*prometheus.Desc{
-		"Lame": prometheus.NewDesc(
+		bind.CounterLame: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, resolver, "response_lame_total"),
-			"Number of lame delegation responses received.",
+			counterHelp(bind.ResolverCounters, bind.CounterLame),
 			[]string{"view"}, nil,
 		),
-		"EDNS0Fail": prometheus.NewDesc(
+		bind.CounterEDNS0Fail: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, resolver, "query_edns0_errors_total"),
-			"Number of EDNS(0) query errors.",
+			counterHelp(bind.ResolverCounters, bind.CounterEDNS0Fail),
 			[]string{"view"}, nil,
 		),
-		"Mismatch": prometheus.NewDesc(
+		bind.CounterMismatch: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, resolver, "response_mismatch_total"),
-			"Number of mismatch responses received.",
+			counterHelp(bind.ResolverCounters, bind.CounterMismatch),
 			[]string{"view"}, nil,
 		),
-		"Retry": prometheus.NewDesc(
+		bind.CounterRetry: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, resolver, "query_retries_total"),
-			"Number of resolver query retries.",
+			counterHelp(bind.ResolverCounters, bind.CounterRetry),
 			[]string{"view"}, nil,
 		),
-		"Truncated": prometheus.NewDesc(
+		bind.CounterTruncated: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, resolver, "response_truncated_total"),
-			"Number of truncated responses received.",
+			counterHelp(bind.ResolverCounters, bind.CounterTruncated),
 			[]string{"view"}, nil,
 		),
-		"ValFail": prometheus.NewDesc(
+		bind.CounterValFail: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, resolver, "dnssec_validation_errors_total"),
-			"Number of DNSSEC validation attempt errors.",
+			counterHelp(bind.ResolverCounters, bind.CounterValFail),
+			[]string{"view"}, nil,
+		),
+	}
+	resolverGaugeStats = map[string]*prometheus.Desc{
+		bind.CounterNumFetch: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, resolver, "active_fetches"),
+			counterHelp(bind.ResolverCounters, bind.CounterNumFetch),
+			[]string{"view"}, nil,
+		),
+		bind.CounterBucketSize: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, resolver, "buckets"),
+			counterHelp(bind.ResolverCounters, bind.CounterBucketSize),
+			[]string{"view"}, nil,
+		),
+		bind.CounterQueryCurUDP: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, resolver, "udp_queries_in_progress"),
+			counterHelp(bind.ResolverCounters, bind.CounterQueryCurUDP),
+			[]string{"view"}, nil,
+		),
+		bind.CounterQueryCurTCP: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, resolver, "tcp_queries_in_progress"),
+			counterHelp(bind.ResolverCounters, bind.CounterQueryCurTCP),
 			[]string{"view"}, nil,
 		),
 	}
 	resolverLabelStats = map[string]*prometheus.Desc{
-		"QueryAbort":    resolverQueryErrors,
-		"QuerySockFail": resolverQueryErrors,
-		"QueryTimeout":  resolverQueryErrors,
-		"NXDOMAIN":      resolverResponseErrors,
-		"SERVFAIL":      resolverResponseErrors,
-		"FORMERR":       resolverResponseErrors,
-		"OtherError":    resolverResponseErrors,
-		"REFUSED":       resolverResponseErrors,
-		"ValOk":         resolverDNSSECSuccess,
-		"ValNegOk":      resolverDNSSECSuccess,
+		bind.CounterQueryAbort:    resolverQueryErrors,
+		bind.CounterQuerySockFail: resolverQueryErrors,
+		bind.CounterQueryTimeout:  resolverQueryErrors,
+		bind.CounterNXDOMAIN:      resolverResponseErrors,
+		bind.CounterSERVFAIL:      resolverResponseErrors,
+		bind.CounterFORMERR:       resolverResponseErrors,
+		bind.CounterOtherError:    resolverResponseErrors,
+		bind.CounterREFUSED:       resolverResponseErrors,
+		bind.CounterValOk:         resolverDNSSECSuccess,
+		bind.CounterValNegOk:      resolverDNSSECSuccess,
 	}
 	serverQueryErrors = prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, "", "query_errors_total"),
@@ -157,14 +182,14 @@ var (
 		[]string{"result"}, nil,
 	)
 	serverLabelStats = map[string]*prometheus.Desc{
-		"QryDropped":  serverQueryErrors,
-		"QryFailure":  serverQueryErrors,
-		"QrySuccess":  serverResponses,
-		"QryReferral": serverResponses,
-		"QryNxrrset":  serverResponses,
-		"QrySERVFAIL": serverResponses,
-		"QryFORMERR":  serverResponses,
-		"QryNXDOMAIN": serverResponses,
+		bind.CounterQryDropped:  serverQueryErrors,
+		bind.CounterQryFailure:  serverQueryErrors,
+		bind.CounterQrySuccess:  serverResponses,
+		bind.CounterQryReferral: serverResponses,
+		bind.CounterQryNxrrset:  serverResponses,
+		bind.CounterQrySERVFAIL: serverResponses,
+		bind.CounterQryFORMERR:  serverResponses,
+		bind.CounterQryNXDOMAIN: serverResponses,
 	}
 	serverRcodes = prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, "", "response_rcodes_total"),
@@ -172,34 +197,34 @@ var (
 		[]string{"rcode"}, nil,
 	)
 	serverMetricStats = map[string]*prometheus.Desc{
-		"QryDuplicate": prometheus.NewDesc(
+		bind.CounterQryDuplicate: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "", "query_duplicates_total"),
-			"Number of duplicated queries received.",
+			counterHelp(bind.NameServerCounters, bind.CounterQryDuplicate),
 			nil, nil,
 		),
-		"QryRecursion": prometheus.NewDesc(
+		bind.CounterQryRecursion: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "", "query_recursions_total"),
-			"Number of queries causing recursion.",
+			counterHelp(bind.NameServerCounters, bind.CounterQryRecursion),
 			nil, nil,
 		),
-		"XfrRej": prometheus.NewDesc(
+		bind.CounterXfrRej: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "", "zone_transfer_rejected_total"),
-			"Number of rejected zone transfers.",
+			counterHelp(bind.NameServerCounters, bind.CounterXfrRej),
 			nil, nil,
 		),
-		"XfrSuccess": prometheus.NewDesc(
+		bind.CounterXfrSuccess: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "", "zone_transfer_success_total"),
-			"Number of successful zone transfers.",
+			counterHelp(bind.ZoneMaintenanceCounters, bind.CounterXfrSuccess),
 			nil, nil,
 		),
-		"XfrFail": prometheus.NewDesc(
+		bind.CounterXfrFail: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "", "zone_transfer_failure_total"),
-			"Number of failed zone transfers.",
+			counterHelp(bind.ZoneMaintenanceCounters, bind.CounterXfrFail),
 			nil, nil,
 		),
-		"RecursClients": prometheus.NewDesc(
+		bind.CounterRecursClients: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "", "recursive_clients"),
-			"Number of current recursive clients.",
+			counterHelp(bind.NameServerCounters, bind.CounterRecursClients),
 			nil, nil,
 		),
 	}
@@ -222,6 +247,18 @@ var (
 
 type collectorConstructor func(log.Logger, *bind.Statistics) prometheus.Collector
 
+// collectorOptions adjusts the statistics passed to the collectors.
+type collectorOptions struct {
+	// foldQTypes reports query types by their mnemonic and query types
+	// which are not well-known as "other", see foldQTypeCounters.
+	foldQTypes bool
+	// sanitizeZoneNames reports zone names as sanitized by
+	// bind.SanitizeZoneLabels.
+	sanitizeZoneNames bool
+	// maxZones limits the number of zones reported, see bind.WithMaxZones.
+	maxZones int
+}
+
 type serverCollector struct {
 	logger log.Logger
 	stats  *bind.Statistics
@@ -284,7 +321,7 @@ func (c *serverCollector) Collect(ch chan<- prometheus.Metric) {
 			serverRcodes, prometheus.CounterValue, float64(s.Counter), s.Name,
 		)
 	}
-	for _, s := range c.stats.Server.ZoneStatistics {
+	for _, s := range c.stats.Server.ZoneMaintenance {
 		if desc, ok := serverMetricStats[s.Name]; ok {
 			ch <- prometheus.MustNewConstMetric(
 				desc, prometheus.CounterValue, float64(s.Counter),
@@ -313,6 +350,9 @@ func (c *viewCollector) Describe(ch chan<- *prometheus.Desc) {
 	for _, desc := range resolverMetricStats {
 		ch <- desc
 	}
+	for _, desc := range resolverGaugeStats {
+		ch <- desc
+	}
 }
 
 // Collect implements prometheus.Collector.
@@ -340,13 +380,20 @@ func (c *viewCollector) Collect(ch chan<- prometheus.Metric) {
 				)
 			}
 		}
-		if buckets, count, err := histogram(v.ResolverStats); err == nil {
-			ch <- prometheus.MustNewConstHistogram(
-				resolverQueryDuration, count, math.NaN(), buckets, v.Name,
-			)
-		} else {
-			level.Warn(c.logger).Log("msg", "Error parsing RTT", "err", err)
+		for _, s := range v.ResolverGauges {
+			if desc, ok := resolverGaugeStats[s.Name]; ok {
+				ch <- prometheus.MustNewConstMetric(
+					desc, prometheus.GaugeValue, float64(s.Gauge), v.Name,
+				)
+			}
 		}
+		buckets := make(map[float64]uint64, len(v.QueryRTT.Buckets))
+		for _, b := range v.QueryRTT.Buckets {
+			buckets[b.UpperBound] = b.Count
+		}
+		ch <- prometheus.MustNewConstHistogram(
+			resolverQueryDuration, v.QueryRTT.Count, math.NaN(), buckets, v.Name,
+		)
 	}
 
 	for _, v := range c.stats.ZoneViews {
@@ -387,6 +434,94 @@ func (c *taskCollector) Collect(ch chan<- prometheus.Metric) {
 	)
 }
 
+// withQTypeFolding returns a collectorConstructor passing the statistics with
+// folded query type counters to c, or c itself if fold is not set.
+func withQTypeFolding(c collectorConstructor, fold bool) collectorConstructor {
+	if !fold {
+		return c
+	}
+	return func(logger log.Logger, s *bind.Statistics) prometheus.Collector {
+		return c(logger, foldQTypeStats(s))
+	}
+}
+
+// withZoneNameSanitizing returns a collectorConstructor passing the statistics
+// with sanitized zone names to c, or c itself if sanitize is not set.
+func withZoneNameSanitizing(c collectorConstructor, sanitize bool) collectorConstructor {
+	if !sanitize {
+		return c
+	}
+	return func(logger log.Logger, s *bind.Statistics) prometheus.Collector {
+		f := *s
+		f.ZoneViews = make([]bind.ZoneView, len(s.ZoneViews))
+		for i, v := range s.ZoneViews {
+			names := make([]string, len(v.ZoneData))
+			for j, z := range v.ZoneData {
+				names[j] = z.Name
+			}
+			labels := bind.SanitizeZoneLabels(names)
+			zones := make([]bind.ZoneCounter, len(v.ZoneData))
+			for j, z := range v.ZoneData {
+				z.Name = labels[z.Name]
+				zones[j] = z
+			}
+			f.ZoneViews[i] = bind.ZoneView{Name: v.Name, ZoneData: zones}
+		}
+		return c(logger, &f)
+	}
+}
+
+// foldQTypeStats returns a shallow copy of s with the query type counters of
+// the server and the views folded by foldQTypeCounters.
+func foldQTypeStats(s *bind.Statistics) *bind.Statistics {
+	f := *s
+	f.Server.IncomingQueries = foldQTypeCounters(s.Server.IncomingQueries)
+	if s.Views != nil {
+		f.Views = make([]bind.View, len(s.Views))
+		for i, v := range s.Views {
+			v.ResolverQueries = foldQTypeCounters(v.ResolverQueries)
+			f.Views[i] = v
+		}
+	}
+	return &f
+}
+
+// foldQTypeCounters renames query type counters to their canonical names and
+// sums all counters of types which are not well-known into a single counter
+// named "other", bounding the cardinality of the type label. Counters with the
+// same canonical name, e.g. "AAAA" and "TYPE28", are summed as well. Sums
+// saturate at the maximum counter value.
+func foldQTypeCounters(cs []bind.Counter) []bind.Counter {
+	if len(cs) == 0 {
+		return cs
+	}
+	folded := make([]bind.Counter, 0, len(cs))
+	index := map[string]int{}
+	for _, c := range cs {
+		name, class := bind.ClassifyQType(c.Name)
+		if class != bind.QTypeWellKnown {
+			name = "other"
+		}
+		if i, ok := index[name]; ok {
+			folded[i].Counter, _ = bind.AddCounters(folded[i].Counter, c.Counter)
+			continue
+		}
+		index[name] = len(folded)
+		folded = append(folded, bind.Counter{Name: name, Counter: c.Counter})
+	}
+	return folded
+}
+
+// counterHelp returns the help text of the counter name of group g from the
+// catalog of the bind package.
+func counterHelp(g bind.CounterGroup, name string) string {
+	info, ok := bind.DescribeIn(g, name)
+	if !ok {
+		panic(fmt.Sprintf("counter %s/%s is not in the catalog", g, name))
+	}
+	return info.Help
+}
+
 // Exporter collects Binds stats from the given server and exports them using
 // the prometheus metrics package.
 type Exporter struct {
@@ -394,25 +529,34 @@ type Exporter struct {
 	collectors []collectorConstructor
 	groups     []bind.StatisticGroup
 	logger     log.Logger
+
+	mu     sync.Mutex
+	warned map[string]bool
 }
 
 // NewExporter returns an initialized Exporter.
-func NewExporter(logger log.Logger, version, url string, timeout time.Duration, g []bind.StatisticGroup) *Exporter {
+func NewExporter(logger log.Logger, version, url string, timeout time.Duration, g []bind.StatisticGroup, o collectorOptions) *Exporter {
+	var opts []bind.ClientOption
+	if o.maxZones > 0 {
+		opts = append(opts, bind.WithMaxZones(o.maxZones))
+	}
 	var c bind.Client
 	switch version {
 	case "xml", "xml.v3":
-		c = xml.NewClient(url, &http.Client{Timeout: timeout})
+		c = xml.NewClient(url, &http.Client{Timeout: timeout}, opts...)
+	case "auto":
+		c = auto.NewClient(url, &http.Client{Timeout: timeout}, append(opts, bind.WithLogger(logger))...)
 	default:
-		c = json.NewClient(url, &http.Client{Timeout: timeout})
+		c = json.NewClient(url, &http.Client{Timeout: timeout}, opts...)
 	}
 
 	var cs []collectorConstructor
 	for _, g := range g {
 		switch g {
 		case bind.ServerStats:
-			cs = append(cs, newServerCollector)
+			cs = append(cs, withQTypeFolding(newServerCollector, o.foldQTypes))
 		case bind.ViewStats:
-			cs = append(cs, newViewCollector)
+			cs = append(cs, withZoneNameSanitizing(withQTypeFolding(newViewCollector, o.foldQTypes), o.sanitizeZoneNames))
 		case bind.TaskStats:
 			cs = append(cs, newTaskCollector)
 		}
@@ -434,7 +578,13 @@ func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
 // Prometheus metrics. It implements prometheus.Collector.
 func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
 	status := 0.
-	if stats, err := e.client.Stats(e.groups...); err == nil {
+	stats, err := e.client.Stats(context.Background(), e.groups...)
+	if errors.Is(err, bind.ErrTooManyZones) {
+		level.Warn(e.logger).Log("msg", "Zone limit exceeded, omitting zones", "err", err)
+		err = nil
+	}
+	if err == nil {
+		e.warn(stats.Warnings)
 		for _, c := range e.collectors {
 			c(e.logger, &stats).Collect(ch)
 		}
@@ -445,40 +595,25 @@ func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
 	ch <- prometheus.MustNewConstMetric(up, prometheus.GaugeValue, status)
 }
 
-func histogram(stats []bind.Counter) (map[float64]uint64, uint64, error) {
-	buckets := map[float64]uint64{}
-	var count uint64
-
-	for _, s := range stats {
-		if strings.HasPrefix(s.Name, bind.QryRTT) {
-			b := math.Inf(0)
-			if !strings.HasSuffix(s.Name, "+") {
-				var err error
-				rrt := strings.TrimPrefix(s.Name, bind.QryRTT)
-				b, err = strconv.ParseFloat(rrt, 32)
-				if err != nil {
-					return buckets, 0, fmt.Errorf("could not parse RTT: %s", rrt)
-				}
-			}
-
-			buckets[b/1000] = s.Counter
+// warn logs the warnings about the configuration of the server, each kind
+// only once as it is reported on every scrape.
+func (e *Exporter) warn(ws []bind.Warning) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, w := range ws {
+		if e.warned[w.Code] {
+			continue
 		}
+		if e.warned == nil {
+			e.warned = map[string]bool{}
+		}
+		e.warned[w.Code] = true
+		kv := []interface{}{"msg", w.Message, "warning", w.Code}
+		if w.Path != "" {
+			kv = append(kv, "path", w.Path)
+		}
+		level.Warn(e.logger).Log(kv...)
 	}
-
-	// Don't assume that QryRTT counters were in ascending order before summing them.
-	// JSON stats are unmarshaled into a map, which won't preserve the order that BIND renders.
-	keys := make([]float64, 0, len(buckets))
-	for k := range buckets {
-		keys = append(keys, k)
-	}
-	sort.Float64s(keys)
-
-	for _, k := range keys {
-		buckets[k] += count
-		count = buckets[k]
-	}
-
-	return buckets, count, nil
 }
 
 type statisticGroups []bind.StatisticGroup
@@ -492,31 +627,20 @@ func (s *statisticGroups) String() string {
 	return strings.Join(groups, ",")
 }
 
-// Set implements flag.Value.
+// Set implements flag.Value. Only the groups with a collector are accepted.
 func (s *statisticGroups) Set(value string) error {
-	*s = []bind.StatisticGroup{}
-	if len(value) == 0 {
-		return nil
-	}
-	var sg bind.StatisticGroup
-	for _, dt := range strings.Split(value, ",") {
-		switch dt {
-		case string(bind.ServerStats):
-			sg = bind.ServerStats
-		case string(bind.ViewStats):
-			sg = bind.ViewStats
-		case string(bind.TaskStats):
-			sg = bind.TaskStats
+	groups, err := bind.ParseStatisticGroups(value)
+	if err != nil {
+		return err
+	}
+	for _, g := range groups {
+		switch g {
+		case bind.ServerStats, bind.ViewStats, bind.TaskStats:
 		default:
-			return fmt.Errorf("unknown stats group %q", dt)
-		}
-		for _, existing := range *s {
-			if existing == sg {
-				return fmt.Errorf("duplicated stats group %q", sg)
-			}
+			return fmt.Errorf("unknown stats group %q", g)
 		}
-		*s = append(*s, sg)
 	}
+	*s = groups
 	return nil
 }
 
@@ -534,6 +658,15 @@ func main() {
 		bindVersion = kingpin.Flag("bind.stats-version",
 			"BIND statistics channel",
 		).Default("json").Enum("json", "xml", "xml.v3", "auto")
+		foldQTypes = kingpin.Flag("bind.fold-qtypes",
+			"Report query types by their mnemonic and query types which are not well-known as \"other\"",
+		).Default("false").Bool()
+		sanitizeZoneNames = kingpin.Flag("bind.sanitize-zone-names",
+			"Report zone names lowercased, without trailing dot and with unusual characters escaped",
+		).Default("false").Bool()
+		maxZones = kingpin.Flag("bind.max-zones",
+			"Maximum number of zones to report, 0 for no limit",
+		).Default("0").Int()
 		metricsPath = kingpin.Flag(
 			"web.telemetry-path", "Path under which to expose metrics",
 		).Default("/metrics").String()
@@ -562,7 +695,11 @@ func main() {
 
 	prometheus.MustRegister(
 		version.NewCollector(exporter),
-		NewExporter(logger, *bindVersion, *bindURI, *bindTimeout, groups),
+		NewExporter(logger, *bindVersion, *bindURI, *bindTimeout, groups, collectorOptions{
+			foldQTypes:        *foldQTypes,
+			sanitizeZoneNames: *sanitizeZoneNames,
+			maxZones:          *maxZones,
+		}),
 	)
 	if *bindPidFile != "" {
 		procExporter := collectors.NewProcessCollector(collectors.ProcessCollectorOpts{
@@ -15,6 +15,7 @@ package main
 
 import (
 	"bytes"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -110,7 +111,7 @@ type bindExporterTest struct {
 func (b bindExporterTest) run(t *testing.T) {
 	defer b.server.Close()
 
-	o, err := collect(NewExporter(log.NewNopLogger(), b.version, b.server.URL, time.Second, b.groups))
+	o, err := collect(NewExporter(log.NewNopLogger(), b.version, b.server.URL, time.Second, b.groups, collectorOptions{}))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -184,3 +185,198 @@ func newJSONServer() *httptest.Server {
 		}
 	}))
 }
+
+func TestViewCollectorQueryRTT(t *testing.T) {
+	stats := &bind.Statistics{Views: []bind.View{
+		{Name: "internal", QueryRTT: bind.Histogram{Count: 10, Buckets: []bind.Bucket{{UpperBound: 0.01, Count: 8}, {UpperBound: math.Inf(1), Count: 10}}}},
+		{Name: "external", QueryRTT: bind.Histogram{Count: 5, Buckets: []bind.Bucket{{UpperBound: 0.01, Count: 1}, {UpperBound: math.Inf(1), Count: 5}}}},
+		{Name: "idle"},
+	}}
+	o, err := collect(newViewCollector(log.NewNopLogger(), stats))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []string{
+		`bind_resolver_query_duration_seconds_bucket{view="internal",le="0.01"} 8`,
+		`bind_resolver_query_duration_seconds_count{view="internal"} 10`,
+		`bind_resolver_query_duration_seconds_bucket{view="external",le="0.01"} 1`,
+		`bind_resolver_query_duration_seconds_count{view="external"} 5`,
+		`bind_resolver_query_duration_seconds_count{view="idle"} 0`,
+	} {
+		if !bytes.Contains(o, []byte(m)) {
+			t.Errorf("expected to find metric %q in output\n%s", m, o)
+		}
+	}
+}
+
+func TestViewCollectorResolverGauges(t *testing.T) {
+	stats := &bind.Statistics{Views: []bind.View{
+		{Name: "loaded", ResolverGauges: []bind.Gauge{
+			{Name: "NumFetch", Gauge: 1873},
+			{Name: "BucketSize", Gauge: 31},
+			{Name: "QueryCurUDP", Gauge: 2417},
+			{Name: "QueryCurTCP", Gauge: 96},
+		}},
+		{Name: "old"},
+	}}
+	o, err := collect(newViewCollector(log.NewNopLogger(), stats))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []string{
+		`# TYPE bind_resolver_active_fetches gauge`,
+		`bind_resolver_active_fetches{view="loaded"} 1873`,
+		`# TYPE bind_resolver_buckets gauge`,
+		`bind_resolver_buckets{view="loaded"} 31`,
+		`# TYPE bind_resolver_udp_queries_in_progress gauge`,
+		`bind_resolver_udp_queries_in_progress{view="loaded"} 2417`,
+		`# TYPE bind_resolver_tcp_queries_in_progress gauge`,
+		`bind_resolver_tcp_queries_in_progress{view="loaded"} 96`,
+	} {
+		if !bytes.Contains(o, []byte(m)) {
+			t.Errorf("expected to find metric %q in output\n%s", m, o)
+		}
+	}
+	if bytes.Contains(o, []byte(`bind_resolver_active_fetches{view="old"}`)) {
+		t.Errorf("want no gauges for a view not reporting them, got\n%s", o)
+	}
+}
+
+func TestFoldQTypes(t *testing.T) {
+	stats := &bind.Statistics{
+		Server: bind.Server{IncomingQueries: []bind.Counter{
+			{Name: "A", Counter: 31017},
+			{Name: "TYPE65", Counter: 412},
+			{Name: "HTTPS", Counter: 1203},
+			{Name: "TYPE65280", Counter: 17},
+			{Name: "TYPE65535", Counter: 2},
+			{Name: "TYPE4711", Counter: 1},
+		}},
+		Views: []bind.View{{Name: "_default", ResolverQueries: []bind.Counter{
+			{Name: "TYPE28", Counter: 388},
+			{Name: "TYPE65281", Counter: 6},
+		}}},
+	}
+	for _, tc := range []struct {
+		fold bool
+		want []string
+	}{
+		{
+			want: []string{
+				`bind_incoming_queries_total{type="HTTPS"} 1203`,
+				`bind_incoming_queries_total{type="TYPE65"} 412`,
+				`bind_incoming_queries_total{type="TYPE4711"} 1`,
+				`bind_resolver_queries_total{type="TYPE28",view="_default"} 388`,
+			},
+		},
+		{
+			fold: true,
+			want: []string{
+				`bind_incoming_queries_total{type="A"} 31017`,
+				`bind_incoming_queries_total{type="HTTPS"} 1615`,
+				`bind_incoming_queries_total{type="other"} 20`,
+				`bind_resolver_queries_total{type="AAAA",view="_default"} 388`,
+				`bind_resolver_queries_total{type="other",view="_default"} 6`,
+			},
+		},
+	} {
+		var o []byte
+		for _, c := range []collectorConstructor{newServerCollector, newViewCollector} {
+			b, err := collect(withQTypeFolding(c, tc.fold)(log.NewNopLogger(), stats))
+			if err != nil {
+				t.Fatal(err)
+			}
+			o = append(o, b...)
+		}
+		for _, m := range tc.want {
+			if !bytes.Contains(o, []byte(m)) {
+				t.Errorf("fold %t: expected to find metric %q in output\n%s", tc.fold, m, o)
+			}
+		}
+	}
+	if stats.Server.IncomingQueries[1].Name != "TYPE65" {
+		t.Errorf("folding modified the statistics: %v", stats.Server.IncomingQueries)
+	}
+}
+
+func TestSanitizeZoneNames(t *testing.T) {
+	stats := &bind.Statistics{ZoneViews: []bind.ZoneView{{Name: "_default", ZoneData: []bind.ZoneCounter{
+		{Name: "TEST_ZONE", Serial: "123"},
+		{Name: "Example.com.", Serial: "2024031501"},
+		{Name: "example.com", Serial: "2024031502"},
+	}}}}
+	o, err := collect(withZoneNameSanitizing(newViewCollector, true)(log.NewNopLogger(), stats))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []string{
+		`bind_zone_serial{view="_default",zone_name="test_zone"} 123`,
+		`bind_zone_serial{view="_default",zone_name="example.com~f151e0b8"} 2.024031501e+09`,
+		`bind_zone_serial{view="_default",zone_name="example.com~431ceb26"} 2.024031502e+09`,
+	} {
+		if !bytes.Contains(o, []byte(m)) {
+			t.Errorf("expected to find metric %q in output\n%s", m, o)
+		}
+	}
+	if stats.ZoneViews[0].ZoneData[0].Name != "TEST_ZONE" {
+		t.Errorf("sanitizing modified the statistics: %v", stats.ZoneViews)
+	}
+}
+
+func TestMaxZones(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/xml/v3/server":
+			http.ServeFile(w, r, "fixtures/xml/server.xml")
+		case "/xml/v3/zones":
+			http.ServeFile(w, r, "fixtures/xml/zones-resolver.xml")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	e := NewExporter(log.NewNopLogger(), "xml", ts.URL, time.Second, []bind.StatisticGroup{bind.ViewStats}, collectorOptions{maxZones: 5})
+	o, err := collect(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(o, []byte("bind_up 1")) {
+		t.Errorf("want bind_up 1 when zones are omitted\n%s", o)
+	}
+	if n := bytes.Count(o, []byte("bind_zone_serial{")); n != 5 {
+		t.Errorf("want 5 zone serials, got %d", n)
+	}
+}
+
+func TestMaxZonesTasks(t *testing.T) {
+	ts := newV3Server()
+	defer ts.Close()
+
+	// Omitting zones must not omit the groups fetched after the zones.
+	e := NewExporter(log.NewNopLogger(), "xml", ts.URL, time.Second, []bind.StatisticGroup{bind.ServerStats, bind.ViewStats, bind.TaskStats}, collectorOptions{maxZones: 1})
+	o, err := collect(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []string{"bind_up 1", "bind_worker_threads ", "bind_tasks_running ", "bind_zone_serial{"} {
+		if !bytes.Contains(o, []byte(m)) {
+			t.Errorf("want metric %q when zones are omitted\n%s", m, o)
+		}
+	}
+	if n := bytes.Count(o, []byte("bind_zone_serial{")); n != 1 {
+		t.Errorf("want 1 zone serial, got %d", n)
+	}
+}
+
+func TestStatisticGroupsFlag(t *testing.T) {
+	var groups statisticGroups
+	if err := groups.Set("server,view,tasks"); err != nil || groups.String() != "server,view,tasks" {
+		t.Errorf("want server,view,tasks, got %s (%v)", groups.String(), err)
+	}
+	for _, value := range []string{"status", "server,status", "server,server", "zones"} {
+		if err := groups.Set(value); err == nil {
+			t.Errorf("%s: want error", value)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command bindstats dumps the statistics of a BIND statistics channel once, or
+// watches them and prints the rates of the counters.
+//
+// Usage:
+//
+//	bindstats -target http://localhost:8053 -groups server,view -format json|table|openmetrics
+//	bindstats -target http://localhost:8053 -watch 10s [-hot-zones 10]
+//
+// In watch mode the table format prints counter rates, the json format prints
+// one bind.Sample per line and the openmetrics format prints the statistics
+// of every poll. With -hot-zones the table format also prints the most active
+// zones since the previous poll, see bind.HotZones.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/auto"
+	"github.com/prometheus-community/bind_exporter/bind/exposition"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command with the given arguments and returns the exit code.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("bindstats", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var (
+		target  = fs.String("target", "http://localhost:8053", "URL of the BIND statistics channel")
+		groups  = fs.String("groups", "server,view", "Comma separated list of statistic groups: server, view, tasks")
+		format  = fs.String("format", "table", "Output format: json, table or openmetrics")
+		watch   = fs.Duration("watch", 0, "Poll at this interval and print rates instead of dumping once")
+		count   = fs.Int("count", 0, "Number of polls in watch mode, 0 for no limit")
+		timeout = fs.Duration("timeout", bind.DefaultTimeout, "Timeout of a single fetch")
+		hot     = fs.Int("hot-zones", 0, "Number of most active zones to print in watch mode with the table format, 0 for none")
+	)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *hot > 0 && (*watch <= 0 || *format != "table") {
+		fmt.Fprintln(stderr, "-hot-zones requires -watch and the table format")
+		return 2
+	}
+	g, err := bind.ParseStatisticGroups(*groups)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	var write func(io.Writer, bind.Sample) error
+	switch *format {
+	case "json":
+		write = writeJSON(*watch > 0)
+	case "table":
+		write = writeTable(*watch > 0)
+	case "openmetrics":
+		write = func(w io.Writer, s bind.Sample) error { return exposition.WriteOpenMetrics(w, s.Stats) }
+	default:
+		fmt.Fprintf(stderr, "unknown format %q\n", *format)
+		return 2
+	}
+
+	client := auto.NewClient(*target, nil)
+	fetch := func(ctx context.Context) (bind.Sample, error) {
+		s, err := client.Stats(ctx, g...)
+		return bind.Sample{Time: time.Now(), Stats: s}, err
+	}
+	if *watch > 0 {
+		poller := bind.NewPoller(client, *watch, bind.WithPollGroups(g...))
+		fetch = poller.Poll
+	}
+
+	var prev bind.Statistics
+	for i := 0; ; i++ {
+		fctx, cancel := context.WithTimeout(ctx, *timeout)
+		s, err := fetch(fctx)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return 0
+			}
+			fmt.Fprintf(stderr, "failed to fetch statistics: %s\n", err)
+			fmt.Fprint(stderr, auto.Diagnose(ctx, *target))
+			return 1
+		}
+		if i == 0 {
+			for _, w := range s.Stats.Warnings {
+				if w.Path != "" {
+					fmt.Fprintf(stderr, "warning: %s at %s\n", w.Message, w.Path)
+				} else {
+					fmt.Fprintf(stderr, "warning: %s\n", w.Message)
+				}
+			}
+		}
+		if err := write(stdout, s); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		if *hot > 0 && i > 0 {
+			if err := writeHotZones(stdout, bind.HotZones(prev, s.Stats, 1), s.Delta, *hot); err != nil {
+				fmt.Fprintln(stderr, err)
+				return 1
+			}
+		}
+		prev = s.Stats
+		if *watch <= 0 || *count > 0 && i+1 >= *count {
+			return 0
+		}
+		select {
+		case <-ctx.Done():
+			return 0
+		case <-time.After(*watch):
+		}
+	}
+}
+
+func writeJSON(watch bool) func(io.Writer, bind.Sample) error {
+	return func(w io.Writer, s bind.Sample) error {
+		if watch {
+			return json.NewEncoder(w).Encode(s)
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s.Stats)
+	}
+}
+
+func writeTable(watch bool) func(io.Writer, bind.Sample) error {
+	return func(w io.Writer, s bind.Sample) error {
+		if !watch {
+			return exposition.WriteTable(w, s.Stats)
+		}
+		if s.Delta == nil {
+			_, err := fmt.Fprintf(w, "%s: baseline\n", s.Time.Format(time.RFC3339))
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s: rates over %s\n", s.Time.Format(time.RFC3339), s.Interval.Round(time.Millisecond)); err != nil {
+			return err
+		}
+		if err := exposition.WriteRateTable(w, s); err != nil {
+			return err
+		}
+		if n := s.Delta.Server.TotalResponses(); n > 0 {
+			r := rcodeRatios(s.Delta.Server.RcodeRatio(bind.CounterNXDOMAIN), s.Delta.Server.RcodeRatio(bind.CounterSERVFAIL))
+			if _, err := fmt.Fprintf(w, "responses: %d, %s\n", n, r); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func rcodeRatios(nxdomain, servfail float64) string {
+	return fmt.Sprintf("NXDOMAIN %.1f%%, SERVFAIL %.1f%%", 100*nxdomain, 100*servfail)
+}
+
+// writeHotZones prints the first n zones of as, one per line, with the
+// NXDOMAIN and SERVFAIL ratios of the zones of delta.
+func writeHotZones(w io.Writer, as []bind.ZoneActivity, delta *bind.Statistics, n int) error {
+	if len(as) > n {
+		as = as[:n]
+	}
+	if _, err := fmt.Fprintf(w, "hot zones: %d\n", len(as)); err != nil {
+		return err
+	}
+	for _, a := range as {
+		note := ""
+		switch {
+		case a.Added:
+			note = " (added)"
+		case a.Removed:
+			note = " (removed)"
+		case a.SerialChanged():
+			note = fmt.Sprintf(" (serial %s -> %s)", a.PrevSerial, a.Serial)
+		}
+		if delta != nil {
+			for _, z := range delta.Zone(a.Zone) {
+				if z.View == a.View && z.Zone.TotalResponses() > 0 {
+					note += fmt.Sprintf(", %s", rcodeRatios(z.Zone.RcodeRatio(bind.CounterQryNXDOMAIN), z.Zone.RcodeRatio(bind.CounterQrySERVFAIL)))
+				}
+			}
+		}
+		if _, err := fmt.Fprintf(w, "  %s/%s: %d queries%s\n", a.View, a.Zone, a.Queries, note); err != nil {
+			return err
+		}
+	}
+	return nil
+}
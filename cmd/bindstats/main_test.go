@@ -0,0 +1,131 @@
+// Copyright 2024 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus-community/bind_exporter/bind"
+	"github.com/prometheus-community/bind_exporter/bind/bindtest"
+)
+
+func TestRun(t *testing.T) {
+	srv := bindtest.NewServer()
+	defer srv.Close()
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{format: "table", want: []string{"METRIC", "bind_incoming_queries", `type="A"`}},
+		{format: "openmetrics", want: []string{"# TYPE bind_incoming_queries counter", "bind_incoming_queries_total{type=\"A\"}", "# EOF"}},
+		{format: "json", want: []string{`"BINDVersion"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(context.Background(), []string{"-target", srv.URL, "-format", tt.format}, &stdout, &stderr)
+			if code != 0 {
+				t.Fatalf("want exit code 0, got %d: %s", code, stderr.String())
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(stdout.String(), w) {
+					t.Errorf("want output to contain %q, got:\n%s", w, stdout.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRunJSON(t *testing.T) {
+	srv := bindtest.NewServer()
+	defer srv.Close()
+
+	var stdout, stderr bytes.Buffer
+	if code := run(context.Background(), []string{"-target", srv.URL, "-format", "json", "-groups", "server"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("want exit code 0, got %d: %s", code, stderr.String())
+	}
+	var s bind.Statistics
+	if err := json.Unmarshal(stdout.Bytes(), &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Source.Format != bind.FormatJSONv1 {
+		t.Errorf("want format %q, got %q", bind.FormatJSONv1, s.Source.Format)
+	}
+	if s.Server.BootTime.IsZero() {
+		t.Error("want boot time")
+	}
+}
+
+func TestRunWatch(t *testing.T) {
+	srv := bindtest.NewServer()
+	defer srv.Close()
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"-target", srv.URL, "-watch", "10ms", "-count", "2"}
+	if code := run(context.Background(), args, &stdout, &stderr); code != 0 {
+		t.Fatalf("want exit code 0, got %d: %s", code, stderr.String())
+	}
+	out := stdout.String()
+	if !strings.Contains(out, "baseline") || !strings.Contains(out, "rates over") {
+		t.Errorf("want baseline and rates, got:\n%s", out)
+	}
+}
+
+func TestRunWatchHotZones(t *testing.T) {
+	srv := bindtest.NewServer()
+	defer srv.Close()
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"-target", srv.URL, "-watch", "10ms", "-count", "2", "-hot-zones", "5"}
+	if code := run(context.Background(), args, &stdout, &stderr); code != 0 {
+		t.Fatalf("want exit code 0, got %d: %s", code, stderr.String())
+	}
+	if out := stdout.String(); strings.Count(out, "hot zones: ") != 1 {
+		t.Errorf("want hot zones after the second poll, got:\n%s", out)
+	}
+}
+
+func TestRunUnreachable(t *testing.T) {
+	srv := httptest.NewServer(nil)
+	srv.Close()
+
+	var stdout, stderr bytes.Buffer
+	if code := run(context.Background(), []string{"-target", srv.URL}, &stdout, &stderr); code != 1 {
+		t.Fatalf("want exit code 1, got %d", code)
+	}
+	for _, w := range []string{string(bind.FormatJSONv1), string(bind.FormatXMLv3)} {
+		if !strings.Contains(stderr.String(), w) {
+			t.Errorf("want report to mention %q, got:\n%s", w, stderr.String())
+		}
+	}
+}
+
+func TestRunInvalidFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"-groups", "server,bogus"},
+		{"-format", "yaml"},
+		{"-hot-zones", "3"},
+	} {
+		var stdout, stderr bytes.Buffer
+		if code := run(context.Background(), args, &stdout, &stderr); code != 2 {
+			t.Errorf("%v: want exit code 2, got %d", args, code)
+		}
+	}
+}